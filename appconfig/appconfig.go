@@ -0,0 +1,288 @@
+// Package appconfig loads the options of godcr and godcr-cli from the
+// command line and the config file in the app directory, so the network and
+// app directory options behave the same in both.
+package appconfig
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/decred/dcrd/dcrutil/v4"
+	flags "github.com/jessevdk/go-flags"
+	"github.com/planetdecred/godcr/version"
+)
+
+const (
+	// DefaultNetwork is the network used when none is set.
+	DefaultNetwork = "mainnet"
+
+	// DefaultConfigFileName is the name of the config file in the app
+	// directory.
+	DefaultConfigFileName = "godcr.conf"
+)
+
+var (
+	// DefaultHomeDir is the default app directory.
+	DefaultHomeDir = dcrutil.AppDataDir("godcr", false)
+
+	// DefaultConfigFile is the default config file.
+	DefaultConfigFile = filepath.Join(DefaultHomeDir, DefaultConfigFileName)
+)
+
+// AppOptions are the options shared by godcr and godcr-cli. They are
+// embedded in the options of each command.
+type AppOptions struct {
+	Network     string `long:"network" description:"Network to use"`
+	HomeDir     string `long:"appdata" description:"Directory where the app configuration file and wallet data is stored"`
+	ConfigFile  string `long:"configfile" description:"Filename of the config file in the app directory"`
+	ShowVersion bool   `short:"V" long:"version" description:"Display version information and exit"`
+	ProfileName string `long:"appprofile" description:"Profile to use, with its own app directory, network and config file. The profiles are listed in profiles.json in the default app directory"`
+
+	// profileChosen is set if the profile or the app directory was set
+	// before loading the options, so there is no profile to choose at
+	// startup.
+	profileChosen bool
+	// profileNetwork is the network of the profile, it takes precedence
+	// over the config file of the profile.
+	profileNetwork string
+}
+
+// DefaultAppOptions returns the default shared options.
+func DefaultAppOptions() AppOptions {
+	return AppOptions{
+		Network:    DefaultNetwork,
+		HomeDir:    DefaultHomeDir,
+		ConfigFile: DefaultConfigFile,
+	}
+}
+
+// NetType returns the dcrlibwallet name of the network option.
+func (o *AppOptions) NetType() string {
+	if o.Network == "testnet" {
+		return "testnet3"
+	}
+	return o.Network
+}
+
+// ProfileChosen returns true if the profile or the app directory was set on
+// the command line or before the options were loaded.
+func (o *AppOptions) ProfileChosen() bool {
+	return o.profileChosen
+}
+
+// Load parses the options into cfg from the config file and the command
+// line, app holds the AppOptions embedded in cfg. The command line options
+// take precedence over the config file. The options of the config file that
+// cfg does not have are skipped if ignoreUnknown is set, the config file of
+// godcr is shared by all the commands. The app directory is created if it
+// does not exist. The arguments that are not options are returned.
+func Load(cfg interface{}, app *AppOptions, usage string, ignoreUnknown bool) ([]string, error) {
+	defaultConfigFile := app.ConfigFile
+
+	// Pre-parse the command line options to see if an alternative config file
+	// or the version flag was specified. Override any environment variables
+	// with parsed command line flags.
+	preParser := flags.NewParser(cfg, flags.HelpFlag|flags.PassDoubleDash)
+	if usage != "" {
+		preParser.Usage = usage
+	}
+	_, err := preParser.Parse()
+	if err != nil {
+		e, ok := err.(*flags.Error)
+		if !ok || e.Type != flags.ErrHelp {
+			preParser.WriteHelp(os.Stderr)
+		}
+		if ok && e.Type == flags.ErrHelp {
+			preParser.WriteHelp(os.Stdout)
+			os.Exit(0)
+		}
+		return nil, err
+	}
+
+	// Show the version and exit if the version flag was specified.
+	appName := filepath.Base(os.Args[0])
+	appName = strings.TrimSuffix(appName, filepath.Ext(appName))
+	if app.ShowVersion {
+		fmt.Printf("%s version %s (Go version %s)\n", appName,
+			version.Version(), runtime.Version())
+		os.Exit(0)
+	}
+
+	// Use the app directory and network of the profile, the default profile
+	// unless a profile or an app directory is set. The command line options
+	// are parsed again so they take precedence over the profile.
+	app.profileChosen = app.ProfileName != "" || app.HomeDir != DefaultHomeDir
+	if err := applyProfile(app); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, err
+	}
+	if _, err := preParser.Parse(); err != nil {
+		return nil, err
+	}
+
+	// If a non-default appdata folder is specified on the command line, it may
+	// be necessary adjust the config file location. If the the config file
+	// location was not specified on the command line, the default location
+	// should be under the non-default appdata directory. However, if the config
+	// file was specified on the command line, it should be used regardless of
+	// the appdata directory.
+	if DefaultHomeDir != app.HomeDir && defaultConfigFile == app.ConfigFile {
+		app.ConfigFile = filepath.Join(app.HomeDir, DefaultConfigFileName)
+		// Update the default to avoid an error if the config file in this
+		// "new default" location does not exist.
+		defaultConfigFile = app.ConfigFile
+	}
+
+	// Load additional config from file.
+	var configFileError error
+	parser := flags.NewParser(cfg, flags.Default)
+	if usage != "" {
+		parser.Usage = usage
+	}
+
+	// Do not error default config file is missing.
+	if _, err := os.Stat(app.ConfigFile); os.IsNotExist(err) {
+		// Non-default config file must exist
+		if defaultConfigFile != app.ConfigFile {
+			fmt.Fprintln(os.Stderr, err)
+			return nil, err
+		}
+		// Warn about missing default config file, but continue
+		fmt.Fprintf(os.Stderr, "Config file (%s) does not exist. Using defaults.\n",
+			app.ConfigFile)
+	} else {
+		// The config file exists, so attempt to parse it.
+		if ignoreUnknown {
+			parser.Options |= flags.IgnoreUnknown
+		}
+		err = flags.NewIniParser(parser).ParseFile(app.ConfigFile)
+		parser.Options &^= flags.IgnoreUnknown
+		if err != nil {
+			if _, ok := err.(*os.PathError); !ok {
+				fmt.Fprintln(os.Stderr, err)
+				parser.WriteHelp(os.Stderr)
+				return nil, err
+			}
+			configFileError = err
+		}
+	}
+
+	// The network of the profile takes precedence over the config file, only
+	// an empty network falls back to it.
+	if app.profileNetwork != "" {
+		app.Network = app.profileNetwork
+	}
+
+	// Parse command line options again to ensure they take precedence.
+	args, err := parser.Parse()
+	if err != nil {
+		if e, ok := err.(*flags.Error); !ok || e.Type != flags.ErrHelp {
+			parser.WriteHelp(os.Stderr)
+		}
+		return nil, err
+	}
+
+	// Create the home directory if it doesn't already exist.
+	err = os.MkdirAll(app.HomeDir, 0700)
+	if err != nil {
+		// Show a nicer error message if it's because a symlink is linked to a
+		// directory that does not exist (probably because it's not mounted).
+		if e, ok := err.(*os.PathError); ok && os.IsExist(err) {
+			if link, lerr := os.Readlink(e.Path); lerr == nil {
+				str := "is symlink %s -> %s mounted?"
+				err = fmt.Errorf(str, e.Path, link)
+			}
+		}
+
+		err := fmt.Errorf("failed to create home directory: %v", err)
+		fmt.Fprintln(os.Stderr, err)
+		return nil, err
+	}
+
+	// Warn about missing config file after the final command line parse
+	// succeeds.  This prevents the warning on help messages and invalid
+	// options.
+	if configFileError != nil {
+		fmt.Printf("%v\n", configFileError)
+		return nil, configFileError
+	}
+
+	return args, nil
+}
+
+// applyProfile sets the app directory and network of the profile of app.
+func applyProfile(app *AppOptions) error {
+	if app.ProfileName == "" && app.HomeDir != DefaultHomeDir {
+		return nil
+	}
+	profiles, err := LoadProfiles(DefaultProfilesFile)
+	if err != nil {
+		return err
+	}
+	if app.ProfileName == "" {
+		app.ProfileName = profiles.Default
+	}
+	profile, ok := profiles.Profile(app.ProfileName)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, app.ProfileName)
+	}
+	app.HomeDir = profile.HomeDir
+	app.profileNetwork = profile.Network
+	if profile.Network != "" {
+		app.Network = profile.Network
+	}
+	return nil
+}
+
+// CleanAndExpandPath expands environment variables and leading ~ in the passed
+// path, cleans the result, and returns it.
+func CleanAndExpandPath(path string) string {
+	// NOTE: The os.ExpandEnv doesn't work with Windows cmd.exe-style
+	// %VARIABLE%, but the variables can still be expanded via POSIX-style
+	// $VARIABLE.
+	path = os.ExpandEnv(path)
+
+	if !strings.HasPrefix(path, "~") {
+		return filepath.Clean(path)
+	}
+
+	// Expand initial ~ to the current user's home directory, or ~otheruser to
+	// otheruser's home directory.  On Windows, both forward and backward
+	// slashes can be used.
+	path = path[1:]
+
+	var pathSeparators string
+	if runtime.GOOS == "windows" {
+		pathSeparators = string(os.PathSeparator) + "/"
+	} else {
+		pathSeparators = string(os.PathSeparator)
+	}
+
+	userName := ""
+	if i := strings.IndexAny(path, pathSeparators); i != -1 {
+		userName = path[:i]
+		path = path[i:]
+	}
+
+	homeDir := ""
+	var u *user.User
+	var err error
+	if userName == "" {
+		u, err = user.Current()
+	} else {
+		u, err = user.Lookup(userName)
+	}
+	if err == nil {
+		homeDir = u.HomeDir
+	}
+	// Fallback to CWD if user lookup fails or user has no home directory.
+	if homeDir == "" {
+		homeDir = "."
+	}
+
+	return filepath.Join(homeDir, path)
+}
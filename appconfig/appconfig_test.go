@@ -0,0 +1,61 @@
+package appconfig_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/planetdecred/godcr/appconfig"
+)
+
+var _ = Describe("Load", func() {
+	var (
+		dir                 string
+		args                []string
+		defaultProfilesFile string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "godcr-appconfig")
+		Expect(err).ToNot(HaveOccurred())
+		args, defaultProfilesFile = os.Args, appconfig.DefaultProfilesFile
+		appconfig.DefaultProfilesFile = filepath.Join(dir, "default", appconfig.DefaultProfilesFileName)
+
+		profiles, err := appconfig.LoadProfiles(appconfig.DefaultProfilesFile)
+		Expect(err).ToNot(HaveOccurred())
+		simDir := filepath.Join(dir, "sim")
+		Expect(profiles.Add(appconfig.Profile{Name: "sim", HomeDir: simDir, Network: "simnet"})).To(Succeed())
+		Expect(profiles.Save()).To(Succeed())
+
+		Expect(os.MkdirAll(simDir, 0700)).To(Succeed())
+		conf := filepath.Join(simDir, appconfig.DefaultConfigFileName)
+		Expect(os.WriteFile(conf, []byte("network=testnet\n"), 0600)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.Args, appconfig.DefaultProfilesFile = args, defaultProfilesFile
+		os.RemoveAll(dir)
+	})
+
+	load := func(args ...string) *appconfig.AppOptions {
+		os.Args = append([]string{"godcr"}, args...)
+		app := appconfig.DefaultAppOptions()
+		_, err := appconfig.Load(&app, &app, "", true)
+		Expect(err).ToNot(HaveOccurred())
+		return &app
+	}
+
+	It("uses the network of the profile over the one of its config file", func() {
+		app := load("--appprofile=sim")
+		Expect(app.HomeDir).To(Equal(filepath.Join(dir, "sim")))
+		Expect(app.Network).To(Equal("simnet"))
+	})
+
+	It("uses the network of the command line over the one of the profile", func() {
+		app := load("--appprofile=sim", "--network=mainnet")
+		Expect(app.Network).To(Equal("mainnet"))
+	})
+})
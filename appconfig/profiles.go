@@ -0,0 +1,217 @@
+package appconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// DefaultProfileName is the name of the profile of the default app
+	// directory.
+	DefaultProfileName = "default"
+
+	// DefaultProfilesFileName is the name of the file listing the profiles,
+	// in the default app directory.
+	DefaultProfilesFileName = "profiles.json"
+)
+
+// DefaultProfilesFile is the file listing the profiles.
+var DefaultProfilesFile = filepath.Join(DefaultHomeDir, DefaultProfilesFileName)
+
+var (
+	// ErrNoProfileName is returned when a profile has no name.
+	ErrNoProfileName = errors.New("enter a name for the profile")
+	// ErrProfileNotFound is returned for a profile that is not listed.
+	ErrProfileNotFound = errors.New("profile not found")
+	// ErrDefaultProfile is returned when the profile used by default is
+	// removed.
+	ErrDefaultProfile = errors.New("the profile used by default cannot be removed")
+)
+
+// networks are the networks a profile may be on.
+var networks = map[string]bool{
+	"mainnet":  true,
+	"testnet":  true,
+	"testnet3": true,
+	"simnet":   true,
+	"regnet":   true,
+}
+
+// Profile is an app directory, with its own wallets, config file and logs,
+// and the network used in it. An empty network uses the network of the
+// config file of the profile.
+type Profile struct {
+	Name    string `json:"name"`
+	HomeDir string `json:"homedir"`
+	Network string `json:"network"`
+}
+
+// Profiles lists the profiles, the default profile is used when none is set
+// on the command line or chosen at startup.
+type Profiles struct {
+	Default         string    `json:"default"`
+	ChooseOnStartup bool      `json:"chooseonstartup"`
+	Profiles        []Profile `json:"profiles"`
+
+	path string
+}
+
+// LoadProfiles reads the profiles listed in the file at path. Only the
+// profile of the app directory of the file is listed if the file does not
+// exist.
+func LoadProfiles(path string) (*Profiles, error) {
+	profiles := &Profiles{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		profiles.Default = DefaultProfileName
+		profiles.ChooseOnStartup = true
+		profiles.Profiles = []Profile{{Name: DefaultProfileName, HomeDir: filepath.Dir(path)}}
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, profiles); err != nil {
+		return nil, fmt.Errorf("error reading the profiles in %s: %v", path, err)
+	}
+	return profiles, nil
+}
+
+// Save writes the profiles to the file they were read from.
+func (p *Profiles) Save() error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0700); err != nil {
+		return err
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.path)
+}
+
+// Profile returns the profile named name.
+func (p *Profiles) Profile(name string) (Profile, bool) {
+	for _, profile := range p.Profiles {
+		if profile.Name == name {
+			return profile, true
+		}
+	}
+	return Profile{}, false
+}
+
+// Add validates the profile and adds it. The app directory is created when
+// the profile is used.
+func (p *Profiles) Add(profile Profile) error {
+	profile.Name = strings.TrimSpace(profile.Name)
+	if profile.Name == "" {
+		return ErrNoProfileName
+	}
+	if _, ok := p.Profile(profile.Name); ok {
+		return fmt.Errorf("there is already a profile named %s", profile.Name)
+	}
+	if profile.Network != "" && !networks[profile.Network] {
+		return fmt.Errorf("unknown network %q", profile.Network)
+	}
+	homeDir, err := p.CheckHomeDir(profile.Name, profile.HomeDir)
+	if err != nil {
+		return err
+	}
+	profile.HomeDir = homeDir
+
+	p.Profiles = append(p.Profiles, profile)
+	return nil
+}
+
+// Remove removes the profile named name from the list, its app directory is
+// left as is.
+func (p *Profiles) Remove(name string) error {
+	if name == p.Default {
+		return ErrDefaultProfile
+	}
+	for i, profile := range p.Profiles {
+		if profile.Name == name {
+			p.Profiles = append(p.Profiles[:i], p.Profiles[i+1:]...)
+			return nil
+		}
+	}
+	return ErrProfileNotFound
+}
+
+// SetDefault sets the profile used when none is set on the command line or
+// chosen at startup.
+func (p *Profiles) SetDefault(name string) error {
+	if _, ok := p.Profile(name); !ok {
+		return ErrProfileNotFound
+	}
+	p.Default = name
+	return nil
+}
+
+// SetHomeDir sets the app directory of the profile named name, once its
+// data is moved there.
+func (p *Profiles) SetHomeDir(name, dir string) error {
+	if _, ok := p.Profile(name); !ok {
+		return ErrProfileNotFound
+	}
+	homeDir, err := p.CheckHomeDir(name, dir)
+	if err != nil {
+		return err
+	}
+	for i := range p.Profiles {
+		if p.Profiles[i].Name == name {
+			p.Profiles[i].HomeDir = homeDir
+		}
+	}
+	return nil
+}
+
+// CheckHomeDir expands the app directory dir of the profile named name and
+// returns an error if it is the app directory of another profile, or is
+// inside it or contains it: the data of a profile is moved and removed with
+// its app directory.
+func (p *Profiles) CheckHomeDir(name, dir string) (string, error) {
+	homeDir, err := profileHomeDir(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, other := range p.Profiles {
+		if other.Name == name {
+			continue
+		}
+		if other.HomeDir == homeDir {
+			return "", fmt.Errorf("the profile %s already uses %s", other.Name, homeDir)
+		}
+		if IsInsideDir(other.HomeDir, homeDir) || IsInsideDir(homeDir, other.HomeDir) {
+			return "", fmt.Errorf("the app directory %s cannot be inside the app directory of the profile %s or contain it",
+				homeDir, other.Name)
+		}
+	}
+	return homeDir, nil
+}
+
+// IsInsideDir returns true if path is dir or is inside it.
+func IsInsideDir(dir, path string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// profileHomeDir expands the app directory of a profile, it must be an
+// absolute path.
+func profileHomeDir(dir string) (string, error) {
+	if strings.TrimSpace(dir) == "" {
+		return "", errors.New("enter the app directory of the profile")
+	}
+	homeDir := CleanAndExpandPath(dir)
+	if !filepath.IsAbs(homeDir) {
+		return "", fmt.Errorf("the app directory %s is not an absolute path", dir)
+	}
+	return homeDir, nil
+}
@@ -0,0 +1,83 @@
+package appconfig_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/planetdecred/godcr/appconfig"
+)
+
+var _ = Describe("Profiles", func() {
+	var dir, defaultDir, path string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "godcr-profiles")
+		Expect(err).ToNot(HaveOccurred())
+		defaultDir = filepath.Join(dir, "default")
+		path = filepath.Join(defaultDir, appconfig.DefaultProfilesFileName)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("lists the profile of the app directory if none were saved", func() {
+		profiles, err := appconfig.LoadProfiles(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(profiles.Default).To(Equal(appconfig.DefaultProfileName))
+		Expect(profiles.Profiles).To(Equal([]appconfig.Profile{{Name: appconfig.DefaultProfileName, HomeDir: defaultDir}}))
+	})
+
+	It("adds, saves and removes profiles", func() {
+		profiles, err := appconfig.LoadProfiles(path)
+		Expect(err).ToNot(HaveOccurred())
+
+		testnetDir := filepath.Join(dir, "testnet")
+		Expect(profiles.Add(appconfig.Profile{Name: " testnet ", HomeDir: testnetDir, Network: "testnet"})).To(Succeed())
+		Expect(profiles.Add(appconfig.Profile{Name: "testnet", HomeDir: filepath.Join(dir, "other")})).To(HaveOccurred())
+		Expect(profiles.Add(appconfig.Profile{Name: "other", HomeDir: testnetDir})).To(HaveOccurred())
+		Expect(profiles.Add(appconfig.Profile{Name: "other", HomeDir: "relative"})).To(HaveOccurred())
+		Expect(profiles.Add(appconfig.Profile{Name: "other", HomeDir: filepath.Join(dir, "other"), Network: "testnet2"})).To(HaveOccurred())
+		Expect(profiles.Add(appconfig.Profile{HomeDir: filepath.Join(dir, "other")})).To(Equal(appconfig.ErrNoProfileName))
+		Expect(profiles.SetDefault("testnet")).To(Succeed())
+		Expect(profiles.Save()).To(Succeed())
+
+		profiles, err = appconfig.LoadProfiles(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(profiles.Default).To(Equal("testnet"))
+		profile, ok := profiles.Profile("testnet")
+		Expect(ok).To(BeTrue())
+		Expect(profile).To(Equal(appconfig.Profile{Name: "testnet", HomeDir: testnetDir, Network: "testnet"}))
+
+		Expect(profiles.Remove("testnet")).To(Equal(appconfig.ErrDefaultProfile))
+		Expect(profiles.Remove(appconfig.DefaultProfileName)).To(Succeed())
+		Expect(profiles.Remove(appconfig.DefaultProfileName)).To(Equal(appconfig.ErrProfileNotFound))
+	})
+
+	It("sets the app directory of a profile once its data is moved", func() {
+		profiles, err := appconfig.LoadProfiles(path)
+		Expect(err).ToNot(HaveOccurred())
+
+		moved := filepath.Join(dir, "moved")
+		Expect(profiles.SetHomeDir(appconfig.DefaultProfileName, moved)).To(Succeed())
+		profile, _ := profiles.Profile(appconfig.DefaultProfileName)
+		Expect(profile.HomeDir).To(Equal(moved))
+		Expect(profiles.SetHomeDir("missing", moved)).To(Equal(appconfig.ErrProfileNotFound))
+	})
+
+	It("rejects an app directory inside another profile's or containing it", func() {
+		profiles, err := appconfig.LoadProfiles(path)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(profiles.Add(appconfig.Profile{Name: "nested", HomeDir: filepath.Join(defaultDir, "testnet")})).To(HaveOccurred())
+		Expect(profiles.Add(appconfig.Profile{Name: "parent", HomeDir: dir})).To(HaveOccurred())
+		Expect(profiles.Add(appconfig.Profile{Name: "testnet", HomeDir: filepath.Join(dir, "testnet")})).To(Succeed())
+
+		Expect(profiles.SetHomeDir(appconfig.DefaultProfileName, filepath.Join(dir, "testnet", "default"))).To(HaveOccurred())
+		Expect(profiles.SetHomeDir(appconfig.DefaultProfileName, filepath.Join(defaultDir, "moved"))).To(Succeed())
+	})
+})
@@ -0,0 +1,469 @@
+// godcr-cli calls the JSON-RPC API of a godcr running in headless mode. It
+// reads the config file of godcr, so the network and app directory options
+// behave the same in both.
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/planetdecred/godcr/appconfig"
+	"github.com/planetdecred/godcr/rpcserver"
+	"golang.org/x/term"
+)
+
+// passphraseEnv is read for the spending passphrase before prompting for
+// it, so scripts do not have to pass it on the command line.
+const passphraseEnv = "GODCR_SPENDING_PASSWORD"
+
+// exportPageSize is the number of transactions fetched per request by
+// exporttransactions.
+const exportPageSize = 100
+
+type config struct {
+	appconfig.AppOptions
+	RPCListen string   `long:"rpclisten" description:"Address of the API of the headless godcr, on localhost or a Unix socket (eg. unix:/path/to/godcr.sock)"`
+	RPCTokens []string `long:"rpctoken" description:"Auth token of the API, the token in rpc.token in the app directory is used if none is set"`
+	JSON      bool     `long:"json" description:"Print the results as JSON"`
+}
+
+type command struct {
+	name        string
+	args        string
+	description string
+	run         func(cli *cli, args []string) error
+}
+
+var commands = []command{
+	{"listwallets", "", "List the wallets", listWallets},
+	{"listaccounts", "[walletid]", "List the accounts with their balances", listAccounts},
+	{"getnewaddress", "<walletid> [account]", "Generate a new receiving address", getNewAddress},
+	{"send", "<walletid> <account> <address> <amount|max>", "Send DCR to an address", send},
+	{"listtransactions", "<walletid> [offset] [limit]", "List the transactions, the most recent first", listTransactions},
+	{"exporttransactions", "<walletid> [file]", "Export all the transactions as CSV, to stdout if no file is set", exportTransactions},
+	{"syncstatus", "", "Show the sync status", syncStatus},
+	{"startmixer", "<walletid>", "Start the account mixer", startMixer},
+	{"stopmixer", "<walletid>", "Stop the account mixer", stopMixer},
+	{"startticketbuyer", "<walletid>", "Start the automatic ticket purchase", startTicketBuyer},
+	{"stopticketbuyer", "<walletid>", "Stop the automatic ticket purchase", stopTicketBuyer},
+}
+
+type cli struct {
+	cfg    *config
+	client *rpcserver.Client
+	out    io.Writer
+}
+
+func usage() string {
+	var b strings.Builder
+	b.WriteString("[OPTIONS] <command> [args...]\n\nCommands:\n")
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %s %s\t%s\n", c.name, c.args, c.description)
+	}
+	w.Flush()
+	return b.String()
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	cfg := config{
+		AppOptions: appconfig.DefaultAppOptions(),
+		RPCListen:  rpcserver.DefaultListen,
+	}
+	args, err := appconfig.Load(&cfg, &cfg.AppOptions, usage(), true)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("missing command, usage: godcr-cli %s", usage())
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == args[0] {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		return fmt.Errorf("unknown command %q, usage: godcr-cli %s", args[0], usage())
+	}
+
+	var token string
+	if len(cfg.RPCTokens) > 0 {
+		token = cfg.RPCTokens[0]
+	} else {
+		tokenFile := filepath.Join(cfg.HomeDir, rpcserver.TokenFileName)
+		b, err := ioutil.ReadFile(tokenFile)
+		if err != nil {
+			return fmt.Errorf("error reading the API auth token, is godcr running with --headless? %v", err)
+		}
+		token = strings.TrimSpace(string(b))
+	}
+
+	c := &cli{
+		cfg:    &cfg,
+		client: rpcserver.NewClient(cfg.RPCListen, token),
+		out:    os.Stdout,
+	}
+	if err := c.checkNetwork(); err != nil {
+		return err
+	}
+	return cmd.run(c, args[1:])
+}
+
+// checkNetwork checks that godcr runs on the network of the options, so a
+// command is not run against the wallets of another network.
+func (c *cli) checkNetwork() error {
+	var status rpcserver.SyncStatusResult
+	if err := c.client.Call(rpcserver.MethodSyncStatus, nil, &status); err != nil {
+		return fmt.Errorf("error calling godcr at %s: %v", c.cfg.RPCListen, err)
+	}
+	if status.Network != c.cfg.NetType() {
+		return fmt.Errorf("godcr at %s runs on %s, not %s", c.cfg.RPCListen, status.Network, c.cfg.NetType())
+	}
+	return nil
+}
+
+// print prints v as JSON with the --json option, with human otherwise.
+func (c *cli) print(v interface{}, human func(w io.Writer)) error {
+	if c.cfg.JSON {
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(c.out, string(b))
+		return err
+	}
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	human(w)
+	return w.Flush()
+}
+
+// printDone prints the outcome of a command without a result.
+func (c *cli) printDone(msg string) error {
+	return c.print(map[string]bool{"ok": true}, func(w io.Writer) {
+		fmt.Fprintln(w, msg)
+	})
+}
+
+func parseWalletID(args []string, i int) (int, error) {
+	if len(args) <= i {
+		return 0, errors.New("missing wallet ID")
+	}
+	id, err := strconv.Atoi(args[i])
+	if err != nil {
+		return 0, fmt.Errorf("invalid wallet ID %q", args[i])
+	}
+	return id, nil
+}
+
+func parseInt32(args []string, i int, name string) (int32, error) {
+	if len(args) <= i {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(args[i], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", name, args[i])
+	}
+	return int32(n), nil
+}
+
+// readPassphrase reads the spending passphrase of the wallet from the
+// environment, or prompts for it.
+func readPassphrase(walletID int) (string, error) {
+	if passphrase := os.Getenv(passphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Spending passphrase of wallet %d: ", walletID)
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		b, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return string(b), err
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func listWallets(c *cli, args []string) error {
+	var wallets []rpcserver.WalletResult
+	if err := c.client.Call(rpcserver.MethodListWallets, nil, &wallets); err != nil {
+		return err
+	}
+	return c.print(wallets, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tName\tBest block\tStatus")
+		for _, wall := range wallets {
+			var status []string
+			if wall.WatchingOnly {
+				status = append(status, "watch-only")
+			}
+			if wall.Locked {
+				status = append(status, "locked")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%d\t%s\n", wall.ID, wall.Name, wall.BestBlockHeight, strings.Join(status, ", "))
+		}
+	})
+}
+
+func listAccounts(c *cli, args []string) error {
+	var params rpcserver.BalanceParams
+	if len(args) > 0 {
+		id, err := parseWalletID(args, 0)
+		if err != nil {
+			return err
+		}
+		params.WalletID = &id
+	}
+
+	var balances []rpcserver.BalanceResult
+	if err := c.client.Call(rpcserver.MethodGetBalance, params, &balances); err != nil {
+		return err
+	}
+	return c.print(balances, func(w io.Writer) {
+		fmt.Fprintln(w, "Wallet\tAccount\tName\tTotal\tSpendable\tUnconfirmed\tLocked by tickets")
+		for _, b := range balances {
+			fmt.Fprintf(w, "%d\t%d\t%s\t%v DCR\t%v DCR\t%v DCR\t%v DCR\n", b.WalletID, b.Account, b.AccountName,
+				b.Total, b.Spendable, b.Unconfirmed, b.LockedByTickets)
+		}
+	})
+}
+
+func getNewAddress(c *cli, args []string) error {
+	id, err := parseWalletID(args, 0)
+	if err != nil {
+		return err
+	}
+	account, err := parseInt32(args, 1, "account")
+	if err != nil {
+		return err
+	}
+
+	var address string
+	err = c.client.Call(rpcserver.MethodGetNewAddress, rpcserver.AddressParams{WalletID: id, Account: account}, &address)
+	if err != nil {
+		return err
+	}
+	return c.print(address, func(w io.Writer) {
+		fmt.Fprintln(w, address)
+	})
+}
+
+func send(c *cli, args []string) error {
+	if len(args) != 4 {
+		return errors.New("usage: send <walletid> <account> <address> <amount|max>")
+	}
+	id, err := parseWalletID(args, 0)
+	if err != nil {
+		return err
+	}
+	account, err := parseInt32(args, 1, "account")
+	if err != nil {
+		return err
+	}
+	params := rpcserver.SendParams{
+		WalletID: id,
+		Account:  account,
+		Address:  args[2],
+		SendMax:  args[3] == "max",
+	}
+	if !params.SendMax {
+		params.Amount, err = strconv.ParseFloat(args[3], 64)
+		if err != nil {
+			return fmt.Errorf("invalid amount %q", args[3])
+		}
+	}
+	params.Passphrase, err = readPassphrase(id)
+	if err != nil {
+		return err
+	}
+
+	var hash string
+	if err := c.client.Call(rpcserver.MethodSendToAddress, params, &hash); err != nil {
+		return err
+	}
+	return c.print(hash, func(w io.Writer) {
+		fmt.Fprintf(w, "Sent, transaction %s\n", hash)
+	})
+}
+
+func listTransactions(c *cli, args []string) error {
+	id, err := parseWalletID(args, 0)
+	if err != nil {
+		return err
+	}
+	offset, err := parseInt32(args, 1, "offset")
+	if err != nil {
+		return err
+	}
+	limit, err := parseInt32(args, 2, "limit")
+	if err != nil {
+		return err
+	}
+
+	var txs []rpcserver.TransactionResult
+	params := rpcserver.ListTransactionsParams{WalletID: id, Offset: offset, Limit: limit}
+	if err := c.client.Call(rpcserver.MethodListTransactions, params, &txs); err != nil {
+		return err
+	}
+	return c.print(txs, func(w io.Writer) {
+		fmt.Fprintln(w, "Date\tType\tDirection\tAmount\tFee\tConfirmations\tHash")
+		for _, tx := range txs {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%v DCR\t%v DCR\t%d\t%s\n", formatTime(tx.Timestamp), tx.Type, tx.Direction,
+				tx.Amount, tx.Fee, tx.Confirmations, tx.Hash)
+		}
+	})
+}
+
+func exportTransactions(c *cli, args []string) error {
+	id, err := parseWalletID(args, 0)
+	if err != nil {
+		return err
+	}
+
+	var txs []rpcserver.TransactionResult
+	for {
+		var page []rpcserver.TransactionResult
+		params := rpcserver.ListTransactionsParams{WalletID: id, Offset: int32(len(txs)), Limit: exportPageSize}
+		if err := c.client.Call(rpcserver.MethodListTransactions, params, &page); err != nil {
+			return err
+		}
+		txs = append(txs, page...)
+		if len(page) < exportPageSize {
+			break
+		}
+	}
+
+	out := c.out
+	if len(args) > 1 {
+		f, err := os.Create(args[1])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	if c.cfg.JSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(txs)
+	}
+
+	w := csv.NewWriter(out)
+	err = w.Write([]string{"Date", "Hash", "Type", "Direction", "Amount", "Fee", "Block height", "Confirmations"})
+	if err != nil {
+		return err
+	}
+	for _, tx := range txs {
+		err = w.Write([]string{
+			formatTime(tx.Timestamp),
+			tx.Hash,
+			tx.Type,
+			tx.Direction,
+			strconv.FormatFloat(tx.Amount, 'f', -1, 64),
+			strconv.FormatFloat(tx.Fee, 'f', -1, 64),
+			strconv.Itoa(int(tx.BlockHeight)),
+			strconv.Itoa(int(tx.Confirmations)),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func syncStatus(c *cli, args []string) error {
+	var status rpcserver.SyncStatusResult
+	if err := c.client.Call(rpcserver.MethodSyncStatus, nil, &status); err != nil {
+		return err
+	}
+	return c.print(status, func(w io.Writer) {
+		state := "not synced"
+		switch {
+		case status.Synced:
+			state = "synced"
+		case status.Syncing:
+			state = "syncing"
+		}
+		fmt.Fprintf(w, "Network:\t%s\n", status.Network)
+		fmt.Fprintf(w, "Status:\t%s\n", state)
+		fmt.Fprintf(w, "Connected peers:\t%d\n", status.ConnectedPeers)
+		fmt.Fprintf(w, "Best block:\t%d (%s)\n", status.BestBlockHeight, formatTime(status.BestBlockTime))
+		if status.LastError != "" {
+			fmt.Fprintf(w, "Last error:\t%s\n", status.LastError)
+		}
+	})
+}
+
+func startMixer(c *cli, args []string) error {
+	return c.unlockAndCall(rpcserver.MethodStartMixer, args, "Account mixer started")
+}
+
+func stopMixer(c *cli, args []string) error {
+	return c.callForWallet(rpcserver.MethodStopMixer, args, "Account mixer stopped")
+}
+
+func startTicketBuyer(c *cli, args []string) error {
+	return c.unlockAndCall(rpcserver.MethodStartTicketBuyer, args, "Automatic ticket purchase started")
+}
+
+func stopTicketBuyer(c *cli, args []string) error {
+	return c.callForWallet(rpcserver.MethodStopTicketBuyer, args, "Automatic ticket purchase stopped")
+}
+
+// unlockAndCall calls a method that takes the spending passphrase of the
+// wallet in the arguments.
+func (c *cli) unlockAndCall(method string, args []string, done string) error {
+	id, err := parseWalletID(args, 0)
+	if err != nil {
+		return err
+	}
+	passphrase, err := readPassphrase(id)
+	if err != nil {
+		return err
+	}
+	if err := c.client.Call(method, rpcserver.UnlockParams{WalletID: id, Passphrase: passphrase}, nil); err != nil {
+		return err
+	}
+	return c.printDone(done)
+}
+
+// callForWallet calls a method that takes the wallet in the arguments.
+func (c *cli) callForWallet(method string, args []string, done string) error {
+	id, err := parseWalletID(args, 0)
+	if err != nil {
+		return err
+	}
+	if err := c.client.Call(method, rpcserver.WalletParams{WalletID: id}, nil); err != nil {
+		return err
+	}
+	return c.printDone(done)
+}
+
+func formatTime(timestamp int64) string {
+	if timestamp == 0 {
+		return "-"
+	}
+	return time.Unix(timestamp, 0).Format("2006-01-02 15:04:05")
+}
@@ -4,11 +4,19 @@ go 1.16
 
 require (
 	decred.org/dcrdex v0.4.1
+	decred.org/dcrwallet/v2 v2.0.1
 	gioui.org v0.0.0-20211011183043-05f0f5c20f45
 	github.com/JohannesKaufmann/html-to-markdown v1.2.1
 	github.com/PuerkitoBio/goquery v1.6.1
 	github.com/ararog/timeago v0.0.0-20160328174124-e9969cf18b8d
+	github.com/decred/dcrd/blockchain/stake/v4 v4.0.0
+	github.com/decred/dcrd/blockchain/standalone/v2 v2.1.0
+	github.com/decred/dcrd/chaincfg/chainhash v1.0.3
+	github.com/decred/dcrd/chaincfg/v3 v3.1.1
 	github.com/decred/dcrd/dcrutil/v4 v4.0.0
+	github.com/decred/dcrd/hdkeychain/v3 v3.1.0
+	github.com/decred/dcrd/wire v1.5.0
+	github.com/decred/go-socks v1.1.0
 	github.com/decred/slog v1.2.0
 	github.com/gen2brain/beeep v0.0.0-20210529141713-5586760f0cc1
 	github.com/gomarkdown/markdown v0.0.0-20210208175418-bda154fe17d8
@@ -19,7 +27,12 @@ require (
 	github.com/onsi/gomega v1.10.1
 	github.com/planetdecred/dcrlibwallet v1.6.2-0.20220404055157-8bb0572a1743
 	github.com/yeqown/go-qrcode v1.5.1
+	golang.org/x/crypto v0.0.0-20210711020723-a769d52b0f97
 	golang.org/x/exp v0.0.0-20210722180016-6781d3edade3
 	golang.org/x/image v0.0.0-20210628002857-a66eb6448b8d
+	golang.org/x/term v0.0.0-20210220032956-6a3ed077a48d
 	golang.org/x/text v0.3.7
 )
+
+// dcrlibwallet is patched in third_party, see the README there.
+replace github.com/planetdecred/dcrlibwallet => ./third_party/dcrlibwallet
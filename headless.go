@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/planetdecred/godcr/listeners"
+	"github.com/planetdecred/godcr/rpcserver"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+const (
+	headlessListenerID = "headless"
+
+	// The secrets of the headless mode are read from the environment so
+	// they are not visible in the process list.
+	startupPasswordEnv = "GODCR_STARTUP_PASSWORD"
+	ticketBuyerPINEnv  = "GODCR_TICKETBUYER_PIN"
+
+	headlessMixerScheduleInterval = time.Minute
+	apiShutdownTimeout            = 10 * time.Second
+)
+
+// runHeadless opens the wallets without the window, syncs them, runs the
+// mixer schedules and the ticket buyers and serves the JSON-RPC API until
+// the process is interrupted.
+func runHeadless(cfg *config, wal *wallet.Wallet) error {
+	if err := wal.InitMultiWallet(); err != nil {
+		return fmt.Errorf("init multiwallet error: %v", err)
+	}
+	defer wal.Shutdown()
+
+	mw := wal.GetMultiWallet()
+	if mw.LoadedWalletsCount() == 0 {
+		return errors.New("there are no wallets, create or restore a wallet with the window first")
+	}
+	startupPassword := os.Getenv(startupPasswordEnv)
+	if mw.IsStartupSecuritySet() && startupPassword == "" {
+		return fmt.Errorf("the wallets are protected by a startup password, set it in %s", startupPasswordEnv)
+	}
+	if err := wal.OpenWallets([]byte(startupPassword)); err != nil {
+		return fmt.Errorf("error opening wallets: %v", err)
+	}
+
+	tokens := cfg.RPCTokens
+	if len(tokens) == 0 {
+		tokenFile := filepath.Join(cfg.HomeDir, rpcserver.TokenFileName)
+		token, err := rpcserver.LoadOrCreateToken(tokenFile)
+		if err != nil {
+			return fmt.Errorf("error reading the API auth token: %v", err)
+		}
+		log.Infof("API auth token is in %s", tokenFile)
+		tokens = []string{token}
+	}
+	ln, err := rpcserver.Listen(cfg.RPCListen)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %v", cfg.RPCListen, err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if err := startHeadlessSync(ctx, wal); err != nil {
+		return err
+	}
+
+	server := rpcserver.NewServer(wal, tokens)
+	go func() {
+		if err := server.Serve(ln); err != nil {
+			log.Errorf("API server error: %v", err)
+			cancel()
+		}
+	}()
+
+	<-ctx.Done()
+	log.Info("Shutting down")
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), apiShutdownTimeout)
+	defer shutdownCancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// startHeadlessSync starts the sync and handles its notifications as the
+// main page does: failed syncs are retried under the retry policy, the
+// mixer schedules run and the ticket buyers resume once the wallets are
+// synced.
+func startHeadlessSync(ctx context.Context, wal *wallet.Wallet) error {
+	mw := wal.GetMultiWallet()
+	syncListener := listeners.NewSyncProgress()
+	if err := mw.AddSyncProgressListener(syncListener, headlessListenerID); err != nil {
+		return fmt.Errorf("error adding sync progress listener: %v", err)
+	}
+	if err := wal.RecordMixerSessions(headlessListenerID); err != nil {
+		return fmt.Errorf("error adding account mixer session recorder: %v", err)
+	}
+
+	startSync := func() {
+		if err := wal.StartSync(); err != nil {
+			log.Errorf("Error starting sync: %v", err)
+			wal.RecordSyncEvent("Error starting sync: " + err.Error())
+		}
+	}
+
+	go func() {
+		mixerScheduleTicker := time.NewTicker(headlessMixerScheduleInterval)
+		defer mixerScheduleTicker.Stop()
+		ticketBuyersResumed := false
+		for {
+			select {
+			case <-ctx.Done():
+				mw.RemoveSyncProgressListener(headlessListenerID)
+				return
+			case <-mixerScheduleTicker.C:
+				wal.RunMixerSchedules()
+			case n := <-syncListener.SyncStatusChan:
+				switch n.Stage {
+				case wallet.SyncStarted:
+					log.Info("Sync started")
+					wal.RecordSyncEvent("Sync started")
+				case wallet.PeersConnected:
+					log.Infof("Connected peers: %d", n.ConnectedPeers)
+				case wallet.SyncCanceled:
+					log.Info("Sync canceled")
+					wal.RecordSyncEvent("Sync canceled")
+				case wallet.SyncCompleted:
+					log.Info("Sync completed")
+					wal.SyncCompleted()
+					wal.RecordSyncEvent("Sync completed")
+					go wal.RunMixerSchedules()
+					go wal.ApplyRestoredAccountNames()
+					if !ticketBuyersResumed {
+						ticketBuyersResumed = true
+						go resumeHeadlessTicketBuyers(wal)
+					}
+				case wallet.SyncEndedWithError:
+					syncErr := wal.HandleSyncError(n.Error, startSync)
+					log.Errorf("%s: %v", syncErr.Kind.Title(), syncErr.Err)
+				}
+			}
+		}
+	}()
+
+	wal.RecordSyncEvent("Headless sync on startup")
+	startSync()
+	return nil
+}
+
+// resumeHeadlessTicketBuyers starts the ticket buyers that were started
+// with the resume option, unlocking their passphrases with the PIN in the
+// environment.
+func resumeHeadlessTicketBuyers(wal *wallet.Wallet) {
+	wallets := wal.ResumableTicketBuyers()
+	if len(wallets) == 0 {
+		return
+	}
+	pin := os.Getenv(ticketBuyerPINEnv)
+	if pin == "" {
+		log.Infof("Set %s to resume the automatic ticket purchase of %d wallet(s)", ticketBuyerPINEnv, len(wallets))
+		return
+	}
+
+	for _, wall := range wallets {
+		passphrase, err := wal.ReadCredential(wall.ID, wallet.AutoBuyerCredentialConfigKey, []byte(pin))
+		if err == nil {
+			err = wal.CheckConnection(wallet.ProxySubsystemVSP)
+		}
+		if err == nil {
+			err = wall.StartTicketBuyer(passphrase)
+		}
+		if err != nil {
+			log.Errorf("[%d] Error resuming automatic ticket purchase: %v", wall.ID, err)
+			continue
+		}
+		log.Infof("[%d] Automatic ticket purchase resumed", wall.ID)
+	}
+}
@@ -68,8 +68,19 @@ func (sp *SyncProgressListener) OnSyncCanceled(willRestart bool) {
 		Stage: wallet.SyncCanceled,
 	})
 }
-func (sp *SyncProgressListener) OnSyncEndedWithError(err error)          {}
-func (sp *SyncProgressListener) Debug(debugInfo *dcrlibwallet.DebugInfo) {}
+
+func (sp *SyncProgressListener) OnSyncEndedWithError(err error) {
+	sp.sendNotification(wallet.SyncStatusUpdate{
+		Stage: wallet.SyncEndedWithError,
+		Error: err,
+	})
+}
+
+func (sp *SyncProgressListener) Debug(debugInfo *dcrlibwallet.DebugInfo) {
+	log.Debugf("Sync debug info: %ds elapsed, %ds remaining, stage %ds elapsed, %ds remaining",
+		debugInfo.TotalTimeElapsed, debugInfo.TotalTimeRemaining,
+		debugInfo.CurrentStageTimeElapsed, debugInfo.CurrentStageTimeRemaining)
+}
 
 func (sp *SyncProgressListener) sendNotification(signal wallet.SyncStatusUpdate) {
 	sp.SyncStatusChan <- signal
@@ -0,0 +1,168 @@
+package listeners
+
+import (
+	"encoding/json"
+
+	"github.com/planetdecred/dcrlibwallet"
+)
+
+// TicketNotificationListener satisfies dcrlibwallet
+// TxAndBlockNotificationListener interface contract. It derives ticket
+// lifecycle events from the transaction and block notifications.
+type TicketNotificationListener struct {
+	multi           *dcrlibwallet.MultiWallet
+	TicketNotifChan chan TicketNotification
+}
+
+func NewTicketNotificationListener(multi *dcrlibwallet.MultiWallet) *TicketNotificationListener {
+	return &TicketNotificationListener{
+		multi:           multi,
+		TicketNotifChan: make(chan TicketNotification, 4),
+	}
+}
+
+func (tn *TicketNotificationListener) OnTransaction(transaction string) {
+	var tx dcrlibwallet.Transaction
+	err := json.Unmarshal([]byte(transaction), &tx)
+	if err != nil {
+		log.Errorf("Error unmarshalling transaction: %v", err)
+		return
+	}
+
+	switch tx.Type {
+	case dcrlibwallet.TxTypeVote:
+		tn.sendNotification(TicketNotification{
+			Type:        TicketVoted,
+			WalletID:    tx.WalletID,
+			Hash:        tx.TicketSpentHash,
+			Transaction: &tx,
+		})
+	case dcrlibwallet.TxTypeRevocation:
+		tn.sendNotification(TicketNotification{
+			Type:        TicketRevoked,
+			WalletID:    tx.WalletID,
+			Hash:        tx.TicketSpentHash,
+			Transaction: &tx,
+		})
+
+		if tn.ticketMissed(&tx) {
+			tn.sendNotification(TicketNotification{
+				Type:        TicketMissed,
+				WalletID:    tx.WalletID,
+				Hash:        tx.TicketSpentHash,
+				Transaction: &tx,
+			})
+		}
+	}
+}
+
+// ticketMissed checks if the ticket spent by the revocation was revoked
+// before it expired, i.e. it was called to vote but missed the vote.
+func (tn *TicketNotificationListener) ticketMissed(revocation *dcrlibwallet.Transaction) bool {
+	wal := tn.multi.WalletWithID(revocation.WalletID)
+	if wal == nil {
+		return false
+	}
+
+	ticket, err := wal.GetTransactionRaw(revocation.TicketSpentHash)
+	if err != nil || ticket.BlockHeight <= 0 {
+		return false
+	}
+
+	revokedAt := revocation.BlockHeight
+	if revokedAt <= 0 {
+		revokedAt = wal.GetBestBlock()
+	}
+
+	expiryBlock := revokedAt - tn.multi.TicketMaturity() - tn.multi.TicketExpiry()
+	return ticket.BlockHeight > expiryBlock
+}
+
+// OnBlockAttached checks the tickets of the wallet for state changes caused
+// by the new block. Only the transactions mined at the heights that the new
+// block matures or expires are loaded. Nothing is checked until the wallets
+// are synced to avoid a flood of notifications while catching up with the
+// chain.
+func (tn *TicketNotificationListener) OnBlockAttached(walletID int, blockHeight int32) {
+	if !tn.multi.IsSynced() {
+		return
+	}
+
+	wal := tn.multi.WalletWithID(walletID)
+	if wal == nil {
+		return
+	}
+
+	maturityBlock := blockHeight - tn.multi.TicketMaturity()
+	expiryBlock := maturityBlock - tn.multi.TicketExpiry()
+
+	var heights []int32
+	for _, height := range []int32{maturityBlock, expiryBlock} {
+		if height > 0 {
+			heights = append(heights, height)
+		}
+	}
+	if len(heights) == 0 {
+		return
+	}
+
+	txs, err := wal.GetTransactionsAtHeightsRaw(heights...)
+	if err != nil {
+		log.Errorf("Error loading tickets: %v", err)
+		return
+	}
+
+	for i := range txs {
+		tx := txs[i]
+		notifType, ok := ticketStateChange(&tx, maturityBlock, expiryBlock)
+		if !ok {
+			continue
+		}
+
+		hash := tx.Hash
+		if tx.Type != dcrlibwallet.TxTypeTicketPurchase {
+			hash = tx.TicketSpentHash
+		}
+
+		tn.sendNotification(TicketNotification{
+			Type:        notifType,
+			WalletID:    wal.ID,
+			Hash:        hash,
+			Transaction: &tx,
+		})
+	}
+}
+
+// ticketStateChange returns the ticket event caused by the block that
+// matures the transactions mined at maturityBlock and expires the tickets
+// mined at expiryBlock.
+func ticketStateChange(tx *dcrlibwallet.Transaction, maturityBlock, expiryBlock int32) (TicketNotifType, bool) {
+	switch tx.Type {
+	case dcrlibwallet.TxTypeTicketPurchase:
+		if tx.TicketSpender != "" {
+			return 0, false
+		}
+		switch tx.BlockHeight {
+		case maturityBlock:
+			// tickets mined exactly maturityBlock blocks ago just became live.
+			return TicketLive, true
+		case expiryBlock:
+			// tickets that were not spent at the end of their expiry window.
+			return TicketExpired, true
+		}
+	case dcrlibwallet.TxTypeVote, dcrlibwallet.TxTypeRevocation:
+		// the stake returned by votes and revocations is spendable once they
+		// reach the ticket maturity.
+		if tx.BlockHeight == maturityBlock {
+			return TicketMatured, true
+		}
+	}
+	return 0, false
+}
+
+func (tn *TicketNotificationListener) OnTransactionConfirmed(walletID int, hash string, blockHeight int32) {
+}
+
+func (tn *TicketNotificationListener) sendNotification(signal TicketNotification) {
+	tn.TicketNotifChan <- signal
+}
@@ -21,3 +21,25 @@ type TxNotification struct {
 	BlockHeight int32
 	Hash        string
 }
+
+type TicketNotifType int
+
+const (
+	// Ticket notification types
+	TicketMatured TicketNotifType = iota // 0 = Stake returned by a vote or revocation is spendable.
+	TicketLive                           // 1 = Ticket matured and joined the live ticket pool.
+	TicketVoted                          // 2 = Ticket voted.
+	TicketRevoked                        // 3 = Ticket revoked.
+	TicketExpired                        // 4 = Ticket expired without voting.
+	TicketMissed                         // 5 = Ticket was called to vote but missed.
+)
+
+// TicketNotification models ticket lifecycle notifications. Hash is the
+// hash of the ticket purchase, Transaction is the transaction that caused
+// the notification.
+type TicketNotification struct {
+	Type        TicketNotifType
+	WalletID    int
+	Hash        string
+	Transaction *dcrlibwallet.Transaction
+}
@@ -13,10 +13,12 @@ import (
 	"github.com/jrick/logrotate/rotator"
 	"github.com/planetdecred/dcrlibwallet"
 	"github.com/planetdecred/godcr/listeners"
+	"github.com/planetdecred/godcr/rpcserver"
 	"github.com/planetdecred/godcr/ui"
 	"github.com/planetdecred/godcr/ui/load"
 	"github.com/planetdecred/godcr/ui/page"
 	"github.com/planetdecred/godcr/ui/page/components"
+	"github.com/planetdecred/godcr/ui/page/dexclient"
 	"github.com/planetdecred/godcr/ui/page/governance"
 	"github.com/planetdecred/godcr/ui/page/overview"
 	"github.com/planetdecred/godcr/ui/page/staking"
@@ -59,6 +61,7 @@ var (
 	winLog     = backendLog.Logger("UI")
 	dlwlLog    = backendLog.Logger("DLWL")
 	lstnersLog = backendLog.Logger("LSTN")
+	rpcLog     = backendLog.Logger("RPCS")
 )
 
 // Initialize package-global logger variables.
@@ -69,12 +72,14 @@ func init() {
 	page.UseLogger(winLog)
 	load.UseLogger(log)
 	listeners.UseLogger(lstnersLog)
+	rpcserver.UseLogger(rpcLog)
 	components.UseLogger(winLog)
 	transaction.UseLogger(winLog)
 	governance.UseLogger(winLog)
 	walletPage.UseLogger(winLog)
 	overview.UseLogger(winLog)
 	staking.UseLogger(winLog)
+	dexclient.UseLogger(winLog)
 }
 
 // subsystemLoggers maps each subsystem identifier to its associated logger.
@@ -84,6 +89,7 @@ var subsystemLoggers = map[string]slog.Logger{
 	"UI":   winLog,
 	"GDCR": log,
 	"LSTN": lstnersLog,
+	"RPCS": rpcLog,
 }
 
 // initLogRotator initializes the logging rotater to write logs to logFile and
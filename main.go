@@ -11,6 +11,7 @@ import (
 	"gioui.org/app"
 
 	"github.com/planetdecred/dcrlibwallet"
+	"github.com/planetdecred/godcr/appconfig"
 	"github.com/planetdecred/godcr/ui"
 	_ "github.com/planetdecred/godcr/ui/assets"
 	"github.com/planetdecred/godcr/wallet"
@@ -23,7 +24,7 @@ var (
 )
 
 func main() {
-	cfg, err := loadConfig()
+	cfg, err := loadConfig("")
 	if err != nil {
 		fmt.Printf("Error: %s\n", err.Error())
 		return
@@ -49,39 +50,97 @@ func main() {
 		buildDate = time.Now()
 	}
 
-	var net string
-	switch cfg.Network {
-	case "testnet":
-		net = "testnet3"
-	default:
-		net = cfg.Network
+	if cfg.Headless {
+		wal, err := newWallet(cfg, buildDate)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+		if err := runHeadless(cfg, wal); err != nil {
+			log.Error(err)
+		}
+		return
 	}
 
+	go func() {
+		// Let the user choose the profile before the options of the
+		// profile are loaded, if there are several.
+		if !cfg.ProfileChosen() {
+			profiles, err := appconfig.LoadProfiles(appconfig.DefaultProfilesFile)
+			if err != nil {
+				log.Error(err)
+				os.Exit(1)
+			}
+			if profiles.ChooseOnStartup && len(profiles.Profiles) > 1 {
+				name, ok := ui.ChooseProfile(profiles)
+				if !ok {
+					os.Exit(0)
+				}
+				if name != cfg.ProfileName {
+					if cfg, err = loadConfig(name); err != nil {
+						fmt.Printf("Error: %s\n", err.Error())
+						os.Exit(1)
+					}
+					dcrlibwallet.SetLogLevels(cfg.DebugLevel)
+				}
+			}
+		}
+
+		runWindow(cfg, buildDate)
+	}()
+
+	// Start the GUI frontend.
+	app.Main()
+}
+
+// newWallet creates the wallet of the app directory and network of cfg,
+// with the options set on the command line.
+func newWallet(cfg *config, buildDate time.Time) (*wallet.Wallet, error) {
 	logFile := filepath.Join(cfg.LogDir, defaultLogFilename)
-	wal, err := wallet.NewWallet(cfg.HomeDir, net, Version, logFile, buildDate)
+	wal, err := wallet.NewWallet(cfg.HomeDir, cfg.NetType(), Version, logFile, buildDate)
+	if err != nil {
+		return nil, err
+	}
+	wal.SetProfile(cfg.ProfileName)
+
+	err = wal.SetProxyOverride(cfg.Proxy, cfg.ProxyIsolation)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy: %v", err)
+	}
+
+	if cfg.SPVConnect != "" {
+		if err := wal.SetConnectPeers(cfg.SPVConnect); err != nil {
+			return nil, fmt.Errorf("invalid spvconnect: %v", err)
+		}
+	}
+	if err := wal.SetExplorerURLOverride(cfg.ExplorerURL); err != nil {
+		return nil, fmt.Errorf("invalid explorerurl: %v", err)
+	}
+	return wal, nil
+}
+
+// runWindow opens the wallets in the app window and exits once the window
+// is closed.
+func runWindow(cfg *config, buildDate time.Time) {
+	wal, err := newWallet(cfg, buildDate)
 	if err != nil {
 		log.Error(err)
-		return
+		os.Exit(1)
 	}
 
 	win, err := ui.CreateWindow(wal)
 	if err != nil {
 		log.Errorf("Could not initialize window: %s\ns", err)
-		return
+		os.Exit(1)
 	}
 
 	err = wal.InitMultiWallet()
 	if err != nil {
 		log.Errorf("init multiwallet error: %v", err)
-		return
+		os.Exit(1)
 	}
 
-	go func() {
-		win.HandleEvents() // blocks until the app window is closed
-		wal.Shutdown()
-		os.Exit(0)
-	}()
-
-	// Start the GUI frontend.
-	app.Main()
+	win.HandleEvents() // blocks until the app window is closed
+	wal.Shutdown()
+	os.Exit(0)
 }
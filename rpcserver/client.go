@@ -0,0 +1,95 @@
+package rpcserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const clientTimeout = 5 * time.Minute
+
+// Client calls the API of a headless godcr.
+type Client struct {
+	url    string
+	token  string
+	http   *http.Client
+	nextID uint64
+}
+
+// NewClient returns a client of the API at addr, a Unix socket if addr
+// starts with UnixPrefix, that authenticates with the token.
+func NewClient(addr, token string) *Client {
+	c := &Client{
+		url:   "http://" + addr,
+		token: token,
+		http:  &http.Client{Timeout: clientTimeout},
+	}
+	if strings.HasPrefix(addr, UnixPrefix) {
+		path := strings.TrimPrefix(addr, UnixPrefix)
+		c.url = "http://unix"
+		c.http.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		}
+	}
+	return c
+}
+
+// Call calls the method with the parameters, params may be nil. The result
+// is decoded into result unless it is nil. A failed request returns an
+// *Error.
+func (c *Client) Call(method string, params, result interface{}) error {
+	req := Request{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(fmt.Sprint(atomic.AddUint64(&c.nextID, 1))),
+		Method:  method,
+	}
+	if params != nil {
+		b, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = b
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpResp, err := c.http.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(httpResp.Body)
+		return fmt.Errorf("%s: %s", httpResp.Status, strings.TrimSpace(string(msg)))
+	}
+	var resp Response
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, result)
+}
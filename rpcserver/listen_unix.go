@@ -0,0 +1,54 @@
+//go:build !windows
+// +build !windows
+
+package rpcserver
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// unixListener is a listener on a socket that was created under another name
+// and renamed to path, which is removed when the listener is closed.
+type unixListener struct {
+	*net.UnixListener
+	path string
+}
+
+func (l *unixListener) Close() error {
+	err := l.UnixListener.Close()
+	os.Remove(l.path)
+	return err
+}
+
+// listenUnix listens on the Unix socket at path, which is only accessible
+// by the user. The socket is created in a private directory next to path,
+// restricted to the user and then renamed to path, so it is never open to
+// other users. The umask of the process is left alone.
+func listenUnix(path string) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".godcr-rpc-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Chmod(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	tmp := filepath.Join(dir, "rpc.sock")
+	ln, err := net.ListenUnix("unix", &net.UnixAddr{Name: tmp, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	ln.SetUnlinkOnClose(false)
+	if err := os.Chmod(tmp, 0600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return &unixListener{UnixListener: ln, path: path}, nil
+}
@@ -0,0 +1,20 @@
+package rpcserver
+
+import (
+	"net"
+	"os"
+)
+
+// listenUnix listens on the Unix socket at path. Windows has no umask, the
+// socket is restricted to the user once it is created.
+func listenUnix(path string) (net.Listener, error) {
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
@@ -0,0 +1,240 @@
+package rpcserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/planetdecred/dcrlibwallet"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+// defaultTransactionsLimit is the number of transactions listed when the
+// request sets no limit.
+const defaultTransactionsLimit = 20
+
+var txDirections = map[int32]string{
+	dcrlibwallet.TxDirectionSent:        "sent",
+	dcrlibwallet.TxDirectionReceived:    "received",
+	dcrlibwallet.TxDirectionTransferred: "transferred",
+}
+
+func invalidParams(format string, args ...interface{}) error {
+	return &Error{Code: ErrCodeInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+func (s *Server) listWallets(params json.RawMessage) (interface{}, error) {
+	wallets := make([]WalletResult, 0)
+	for _, wall := range s.wal.GetMultiWallet().AllWallets() {
+		wallets = append(wallets, WalletResult{
+			ID:              wall.ID,
+			Name:            wall.Name,
+			WatchingOnly:    wall.IsWatchingOnlyWallet(),
+			Locked:          wall.IsLocked(),
+			BestBlockHeight: wall.GetBestBlock(),
+		})
+	}
+	return wallets, nil
+}
+
+func (s *Server) getBalance(params json.RawMessage) (interface{}, error) {
+	var p BalanceParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.wal.AccountBalances()
+	if err != nil {
+		return nil, err
+	}
+	balances := make([]BalanceResult, 0, len(accounts))
+	for _, acct := range accounts {
+		if p.WalletID != nil && acct.WalletID != *p.WalletID {
+			continue
+		}
+		balances = append(balances, BalanceResult{
+			WalletID:        acct.WalletID,
+			Account:         acct.Number,
+			AccountName:     acct.Name,
+			Total:           dcrutil.Amount(acct.Balance.Total).ToCoin(),
+			Spendable:       dcrutil.Amount(acct.Balance.Spendable).ToCoin(),
+			Unconfirmed:     dcrutil.Amount(acct.Balance.UnConfirmed).ToCoin(),
+			LockedByTickets: dcrutil.Amount(acct.Balance.LockedByTickets).ToCoin(),
+		})
+	}
+	return balances, nil
+}
+
+func (s *Server) getAddress(params json.RawMessage) (interface{}, error) {
+	var p AddressParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	return s.wal.CurrentAddress(p.WalletID, p.Account)
+}
+
+func (s *Server) getNewAddress(params json.RawMessage) (interface{}, error) {
+	var p AddressParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	return s.wal.NextAddress(p.WalletID, p.Account)
+}
+
+func (s *Server) sendToAddress(params json.RawMessage) (interface{}, error) {
+	var p SendParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Address == "" {
+		return nil, invalidParams("missing address")
+	}
+	if p.Passphrase == "" {
+		return nil, invalidParams("missing passphrase")
+	}
+
+	var atoms int64
+	if !p.SendMax {
+		amount, err := dcrutil.NewAmount(p.Amount)
+		if err != nil || amount <= 0 {
+			return nil, invalidParams("invalid amount %v", p.Amount)
+		}
+		atoms = int64(amount)
+	}
+
+	return s.wal.SendToAddress(p.WalletID, p.Account, p.Address, atoms, p.SendMax, []byte(p.Passphrase))
+}
+
+func (s *Server) listTransactions(params json.RawMessage) (interface{}, error) {
+	var p ListTransactionsParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Offset < 0 || p.Limit < 0 {
+		return nil, invalidParams("offset and limit cannot be negative")
+	}
+	if p.Limit == 0 {
+		p.Limit = defaultTransactionsLimit
+	}
+
+	txs, err := s.wal.Transactions(p.WalletID, p.Offset, p.Limit)
+	if err != nil {
+		return nil, err
+	}
+	bestBlock := s.wal.GetMultiWallet().GetBestBlock()
+	results := make([]TransactionResult, 0, len(txs))
+	for _, tx := range txs {
+		var confirmations int32
+		if tx.BlockHeight > 0 && bestBlock != nil {
+			confirmations = bestBlock.Height - tx.BlockHeight + 1
+		}
+		results = append(results, TransactionResult{
+			Hash:          tx.Hash,
+			Type:          tx.Type,
+			Direction:     txDirections[tx.Direction],
+			Amount:        dcrutil.Amount(tx.Amount).ToCoin(),
+			Fee:           dcrutil.Amount(tx.Fee).ToCoin(),
+			Timestamp:     tx.Timestamp,
+			BlockHeight:   tx.BlockHeight,
+			Confirmations: confirmations,
+		})
+	}
+	return results, nil
+}
+
+func (s *Server) syncStatus(params json.RawMessage) (interface{}, error) {
+	mw := s.wal.GetMultiWallet()
+	status := SyncStatusResult{
+		Network:        s.wal.Net,
+		Synced:         mw.IsSynced(),
+		Syncing:        mw.IsSyncing(),
+		ConnectedPeers: mw.ConnectedPeers(),
+	}
+	if bestBlock := mw.GetBestBlock(); bestBlock != nil {
+		status.BestBlockHeight = bestBlock.Height
+		status.BestBlockTime = bestBlock.Timestamp
+	}
+	if syncErr, _ := s.wal.LastSyncError(); syncErr != nil {
+		status.LastError = fmt.Sprintf("%s: %v", syncErr.Kind.Title(), syncErr.Err)
+	}
+	return status, nil
+}
+
+func (s *Server) startMixer(params json.RawMessage) (interface{}, error) {
+	var p UnlockParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Passphrase == "" {
+		return nil, invalidParams("missing passphrase")
+	}
+	wall := s.wal.GetMultiWallet().WalletWithID(p.WalletID)
+	if wall == nil {
+		return nil, wallet.ErrIDNotExist
+	}
+	if !wall.AccountMixerConfigIsSet() {
+		return nil, errors.New("the mixer of the wallet is not set up, set it up with the window first")
+	}
+
+	err := s.wal.CheckConnection(wallet.ProxySubsystemMixer)
+	if err == nil {
+		err = s.wal.GetMultiWallet().StartAccountMixer(p.WalletID, p.Passphrase)
+	}
+	if err != nil {
+		if err.Error() != dcrlibwallet.ErrInvalidPassphrase {
+			s.wal.RecordMixerError(p.WalletID, err)
+		}
+		return nil, err
+	}
+	s.wal.SetMixerRunState(p.WalletID, true)
+	return nil, nil
+}
+
+func (s *Server) stopMixer(params json.RawMessage) (interface{}, error) {
+	var p WalletParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	s.wal.SetMixerRunState(p.WalletID, false)
+	return nil, s.wal.StopAccountMixer(p.WalletID)
+}
+
+func (s *Server) startTicketBuyer(params json.RawMessage) (interface{}, error) {
+	var p UnlockParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Passphrase == "" {
+		return nil, invalidParams("missing passphrase")
+	}
+	wall := s.wal.GetMultiWallet().WalletWithID(p.WalletID)
+	if wall == nil {
+		return nil, wallet.ErrIDNotExist
+	}
+	if !wall.TicketBuyerConfigIsSet() {
+		return nil, errors.New("the ticket buyer of the wallet is not set up, set it up with the window first")
+	}
+	if !s.wal.GetMultiWallet().IsConnectedToDecredNetwork() {
+		return nil, errors.New("not connected to the decred network")
+	}
+
+	if err := s.wal.CheckConnection(wallet.ProxySubsystemVSP); err != nil {
+		return nil, err
+	}
+	return nil, wall.StartTicketBuyer([]byte(p.Passphrase))
+}
+
+func (s *Server) stopTicketBuyer(params json.RawMessage) (interface{}, error) {
+	var p WalletParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if s.wal.GetMultiWallet().WalletWithID(p.WalletID) == nil {
+		return nil, wallet.ErrIDNotExist
+	}
+	s.wal.GetMultiWallet().StopAutoTicketsPurchase(p.WalletID)
+	// A manually stopped ticket buyer should not be resumed on the next start.
+	s.wal.DeleteCredential(p.WalletID, wallet.AutoBuyerCredentialConfigKey)
+	return nil, nil
+}
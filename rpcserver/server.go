@@ -0,0 +1,225 @@
+// Package rpcserver serves a JSON-RPC 2.0 API over HTTP for the wallets of
+// a headless godcr.
+package rpcserver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/planetdecred/godcr/wallet"
+)
+
+const (
+	// UnixPrefix is the prefix of the listen addresses of Unix sockets.
+	UnixPrefix = "unix:"
+
+	// DefaultListen is the address the API listens on by default.
+	DefaultListen = "127.0.0.1:7778"
+
+	// TokenFileName is the name of the file the generated auth token is
+	// saved to, in the app directory.
+	TokenFileName = "rpc.token"
+
+	maxRequestSize = 1 << 20
+)
+
+// ErrNotLoopback is returned when the API is set to listen on an address
+// that is reachable from other hosts.
+var ErrNotLoopback = errors.New("the API only listens on localhost or a Unix socket")
+
+type handler func(params json.RawMessage) (interface{}, error)
+
+// Server serves the API of the wallets. Every request carries one of the
+// auth tokens as a bearer token.
+type Server struct {
+	wal     *wallet.Wallet
+	tokens  [][]byte
+	methods map[string]handler
+	http    *http.Server
+}
+
+// NewServer returns a server of the API of the wallets that accepts the
+// auth tokens.
+func NewServer(wal *wallet.Wallet, tokens []string) *Server {
+	s := &Server{wal: wal}
+	for _, token := range tokens {
+		s.tokens = append(s.tokens, []byte(token))
+	}
+	s.methods = map[string]handler{
+		MethodListWallets:      s.listWallets,
+		MethodGetBalance:       s.getBalance,
+		MethodGetAddress:       s.getAddress,
+		MethodGetNewAddress:    s.getNewAddress,
+		MethodSendToAddress:    s.sendToAddress,
+		MethodListTransactions: s.listTransactions,
+		MethodSyncStatus:       s.syncStatus,
+		MethodStartMixer:       s.startMixer,
+		MethodStopMixer:        s.stopMixer,
+		MethodStartTicketBuyer: s.startTicketBuyer,
+		MethodStopTicketBuyer:  s.stopTicketBuyer,
+	}
+	s.http = &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+// Listen listens on addr, a Unix socket if addr starts with UnixPrefix.
+// TCP addresses must be on the loopback interface.
+func Listen(addr string) (net.Listener, error) {
+	if strings.HasPrefix(addr, UnixPrefix) {
+		path := strings.TrimPrefix(addr, UnixPrefix)
+		// Remove the socket left by an unclean shutdown.
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		return listenUnix(path)
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if host != "localhost" {
+		ip := net.ParseIP(host)
+		if ip == nil || !ip.IsLoopback() {
+			return nil, ErrNotLoopback
+		}
+	}
+	return net.Listen("tcp", addr)
+}
+
+// LoadOrCreateToken reads the auth token saved at path, a new token is
+// generated and saved if there is none.
+func LoadOrCreateToken(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err == nil {
+		if token := strings.TrimSpace(string(b)); token != "" {
+			return token, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(secret)
+	if err := ioutil.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Serve serves the API on the listener until Shutdown is called.
+func (s *Server) Serve(ln net.Listener) error {
+	log.Infof("API listening on %s", ln.Addr())
+	err := s.http.Serve(ln)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops the server, the requests in progress are completed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.http.Shutdown(ctx)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+	token := []byte(strings.TrimPrefix(auth, "Bearer "))
+	authorized := false
+	for _, t := range s.tokens {
+		if subtle.ConstantTimeCompare(token, t) == 1 {
+			authorized = true
+		}
+	}
+	return authorized
+}
+
+// ServeHTTP handles a JSON-RPC request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST requests are accepted", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "invalid auth token", http.StatusUnauthorized)
+		return
+	}
+
+	var req Request
+	var resp Response
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req)
+	switch {
+	case err != nil:
+		resp.Error = &Error{Code: ErrCodeParse, Message: err.Error()}
+	case req.Method == "":
+		resp.Error = &Error{Code: ErrCodeInvalidRequest, Message: "missing method"}
+	default:
+		resp = s.handle(&req)
+	}
+	resp.JSONRPC = "2.0"
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Errorf("Error writing API response: %v", err)
+	}
+}
+
+func (s *Server) handle(req *Request) Response {
+	resp := Response{ID: req.ID}
+	method, ok := s.methods[req.Method]
+	if !ok {
+		resp.Error = &Error{Code: ErrCodeMethodNotFound, Message: fmt.Sprintf("unknown method %q", req.Method)}
+		return resp
+	}
+
+	log.Debugf("API request %s", req.Method)
+	result, err := method(req.Params)
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: ErrCodeWallet, Message: err.Error()}
+		}
+		resp.Error = rpcErr
+		return resp
+	}
+
+	resp.Result, err = json.Marshal(result)
+	if err != nil {
+		resp.Error = &Error{Code: ErrCodeInternal, Message: err.Error()}
+	}
+	return resp
+}
+
+// decodeParams decodes the parameters of a request into v, a request
+// without parameters leaves v unchanged.
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &Error{Code: ErrCodeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
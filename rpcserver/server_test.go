@@ -0,0 +1,137 @@
+package rpcserver_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/planetdecred/godcr/rpcserver"
+)
+
+var _ = Describe("Server", func() {
+	server := rpcserver.NewServer(nil, []string{"secret"})
+
+	post := func(token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	decode := func(rec *httptest.ResponseRecorder) rpcserver.Response {
+		var resp rpcserver.Response
+		Expect(json.NewDecoder(rec.Body).Decode(&resp)).To(Succeed())
+		return resp
+	}
+
+	It("rejects requests without a valid token", func() {
+		Expect(post("", `{"method":"syncstatus"}`).Code).To(Equal(http.StatusUnauthorized))
+		Expect(post("wrong", `{"method":"syncstatus"}`).Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("returns JSON-RPC errors", func() {
+		resp := decode(post("secret", `{"jsonrpc":"2.0","id":1,"method":"unknown"}`))
+		Expect(resp.Error.Code).To(Equal(rpcserver.ErrCodeMethodNotFound))
+		Expect(string(resp.ID)).To(Equal("1"))
+
+		resp = decode(post("secret", `{"method":`))
+		Expect(resp.Error.Code).To(Equal(rpcserver.ErrCodeParse))
+
+		resp = decode(post("secret", `{"id":2,"method":"sendtoaddress","params":{"walletid":"one"}}`))
+		Expect(resp.Error.Code).To(Equal(rpcserver.ErrCodeInvalidParams))
+	})
+
+	It("only listens on localhost", func() {
+		_, err := rpcserver.Listen("0.0.0.0:0")
+		Expect(err).To(Equal(rpcserver.ErrNotLoopback))
+
+		ln, err := rpcserver.Listen("127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		ln.Close()
+	})
+
+	It("creates the Unix socket for the user only", func() {
+		if runtime.GOOS == "windows" {
+			Skip("Windows has no file modes")
+		}
+		dir, err := ioutil.TempDir("", "godcr-rpc")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "godcr.sock")
+		ln, err := rpcserver.Listen(rpcserver.UnixPrefix + path)
+		Expect(err).ToNot(HaveOccurred())
+
+		info, err := os.Stat(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+		// The private directory the socket was created in is removed.
+		entries, err := ioutil.ReadDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+
+		conn, err := net.Dial("unix", path)
+		Expect(err).ToNot(HaveOccurred())
+		conn.Close()
+
+		Expect(ln.Close()).To(Succeed())
+		Expect(path).ToNot(BeAnExistingFile())
+	})
+})
+
+var _ = Describe("Client", func() {
+	var server *httptest.Server
+	var addr string
+
+	BeforeEach(func() {
+		server = httptest.NewServer(rpcserver.NewServer(nil, []string{"secret"}))
+		addr = strings.TrimPrefix(server.URL, "http://")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("returns the JSON-RPC errors", func() {
+		err := rpcserver.NewClient(addr, "secret").Call("unknown", nil, nil)
+		Expect(err).To(HaveOccurred())
+		rpcErr, ok := err.(*rpcserver.Error)
+		Expect(ok).To(BeTrue())
+		Expect(rpcErr.Code).To(Equal(rpcserver.ErrCodeMethodNotFound))
+	})
+
+	It("sends the auth token", func() {
+		err := rpcserver.NewClient(addr, "wrong").Call(rpcserver.MethodSyncStatus, nil, nil)
+		Expect(err).To(MatchError(ContainSubstring("401")))
+	})
+})
+
+var _ = Describe("Auth token", func() {
+	It("is generated once", func() {
+		dir, err := ioutil.TempDir("", "godcr-rpc")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, rpcserver.TokenFileName)
+		token, err := rpcserver.LoadOrCreateToken(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token).To(HaveLen(64))
+
+		again, err := rpcserver.LoadOrCreateToken(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(again).To(Equal(token))
+	})
+})
@@ -0,0 +1,143 @@
+package rpcserver
+
+import "encoding/json"
+
+// The methods of the API.
+const (
+	MethodListWallets      = "listwallets"
+	MethodGetBalance       = "getbalance"
+	MethodGetAddress       = "getaddress"
+	MethodGetNewAddress    = "getnewaddress"
+	MethodSendToAddress    = "sendtoaddress"
+	MethodListTransactions = "listtransactions"
+	MethodSyncStatus       = "syncstatus"
+	MethodStartMixer       = "startmixer"
+	MethodStopMixer        = "stopmixer"
+	MethodStartTicketBuyer = "startticketbuyer"
+	MethodStopTicketBuyer  = "stopticketbuyer"
+)
+
+// The JSON-RPC error codes.
+const (
+	ErrCodeParse          = -32700
+	ErrCodeInvalidRequest = -32600
+	ErrCodeMethodNotFound = -32601
+	ErrCodeInvalidParams  = -32602
+	ErrCodeInternal       = -32603
+	// ErrCodeWallet is returned when the wallet fails to run a valid
+	// request.
+	ErrCodeWallet = -32000
+)
+
+// Request is a JSON-RPC 2.0 request.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC 2.0 response, either Result or Error is set.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is the error of a failed request.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// WalletResult is a wallet listed by listwallets.
+type WalletResult struct {
+	ID              int    `json:"id"`
+	Name            string `json:"name"`
+	WatchingOnly    bool   `json:"watchingonly"`
+	Locked          bool   `json:"locked"`
+	BestBlockHeight int32  `json:"bestblockheight"`
+}
+
+// BalanceParams are the parameters of getbalance, all the wallets are
+// listed if WalletID is not set.
+type BalanceParams struct {
+	WalletID *int `json:"walletid,omitempty"`
+}
+
+// BalanceResult is the balance of an account in DCR.
+type BalanceResult struct {
+	WalletID        int     `json:"walletid"`
+	Account         int32   `json:"account"`
+	AccountName     string  `json:"accountname"`
+	Total           float64 `json:"total"`
+	Spendable       float64 `json:"spendable"`
+	Unconfirmed     float64 `json:"unconfirmed"`
+	LockedByTickets float64 `json:"lockedbytickets"`
+}
+
+// AddressParams are the parameters of getaddress and getnewaddress.
+type AddressParams struct {
+	WalletID int   `json:"walletid"`
+	Account  int32 `json:"account"`
+}
+
+// SendParams are the parameters of sendtoaddress. Amount is in DCR, with
+// SendMax the whole spendable balance of the account is sent.
+type SendParams struct {
+	WalletID   int     `json:"walletid"`
+	Account    int32   `json:"account"`
+	Address    string  `json:"address"`
+	Amount     float64 `json:"amount"`
+	SendMax    bool    `json:"sendmax,omitempty"`
+	Passphrase string  `json:"passphrase"`
+}
+
+// ListTransactionsParams are the parameters of listtransactions.
+type ListTransactionsParams struct {
+	WalletID int   `json:"walletid"`
+	Offset   int32 `json:"offset,omitempty"`
+	Limit    int32 `json:"limit,omitempty"`
+}
+
+// TransactionResult is a transaction listed by listtransactions, the
+// amounts are in DCR.
+type TransactionResult struct {
+	Hash          string  `json:"hash"`
+	Type          string  `json:"type"`
+	Direction     string  `json:"direction"`
+	Amount        float64 `json:"amount"`
+	Fee           float64 `json:"fee"`
+	Timestamp     int64   `json:"timestamp"`
+	BlockHeight   int32   `json:"blockheight"`
+	Confirmations int32   `json:"confirmations"`
+}
+
+// WalletParams are the parameters of stopmixer and stopticketbuyer.
+type WalletParams struct {
+	WalletID int `json:"walletid"`
+}
+
+// UnlockParams are the parameters of startmixer and startticketbuyer, the
+// spending passphrase of the wallet unlocks it while the mixer or the ticket
+// buyer runs.
+type UnlockParams struct {
+	WalletID   int    `json:"walletid"`
+	Passphrase string `json:"passphrase"`
+}
+
+// SyncStatusResult is the result of syncstatus.
+type SyncStatusResult struct {
+	Network         string `json:"network"`
+	Synced          bool   `json:"synced"`
+	Syncing         bool   `json:"syncing"`
+	ConnectedPeers  int32  `json:"connectedpeers"`
+	BestBlockHeight int32  `json:"bestblockheight"`
+	BestBlockTime   int64  `json:"bestblocktime"`
+	LastError       string `json:"lasterror,omitempty"`
+}
@@ -0,0 +1,83 @@
+package dcrlibwallet
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// The kinds of outbound connections, the dialer set with SetDialer returns
+// the dial function of each.
+const (
+	// DialSync is the SPV sync, its peers and seeders.
+	DialSync = "sync"
+	// DialPoliteia is the Politeia client.
+	DialPoliteia = "politeia"
+	// DialVSP is the VSP clients and the info of the VSPs.
+	DialVSP = "vsp"
+	// DialVSPList is the list of VSPs from api.decred.org.
+	DialVSPList = "vsplist"
+	// DialMixer is the CoinShuffle++ server of the account mixer.
+	DialMixer = "mixer"
+	// DialAgendas is the agendas from dcrdata.
+	DialAgendas = "agendas"
+)
+
+// DialFunc opens a connection to the address on the named network.
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+var (
+	dialerMu sync.RWMutex
+	dialer   func(kind string) DialFunc
+)
+
+// SetDialer sets the function that returns the dial function of each kind
+// of outbound connection, such as the dialer of a SOCKS5 proxy. A nil
+// dialer, or a nil dial function, connects directly. The SPV sync uses the
+// dial function from when it starts, the other kinds from when they dial.
+func SetDialer(d func(kind string) DialFunc) {
+	dialerMu.Lock()
+	dialer = d
+	dialerMu.Unlock()
+}
+
+// dialFunc returns the dial function set for the kind, nil to connect
+// directly.
+func dialFunc(kind string) DialFunc {
+	dialerMu.RLock()
+	d := dialer
+	dialerMu.RUnlock()
+	if d == nil {
+		return nil
+	}
+	return d(kind)
+}
+
+// dialContext returns a dial function that dials with the dial function set
+// for the kind at the time of each dial.
+func dialContext(kind string) DialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if dial := dialFunc(kind); dial != nil {
+			return dial(ctx, network, addr)
+		}
+		var d net.Dialer
+		return d.DialContext(ctx, network, addr)
+	}
+}
+
+// httpTransport returns a transport that dials with the dial function set
+// for the kind.
+func httpTransport(kind string) *http.Transport {
+	return &http.Transport{
+		Proxy:       http.ProxyFromEnvironment,
+		DialContext: dialContext(kind),
+	}
+}
+
+// lookupIP refuses to resolve host names locally while the SPV sync dials
+// through a proxy, the proxy resolves them.
+func lookupIP(host string) ([]net.IP, error) {
+	return nil, errors.New("host names are resolved by the proxy")
+}
@@ -0,0 +1,17 @@
+package certs
+
+const CSPP = `-----BEGIN CERTIFICATE-----
+MIICFjCCAbugAwIBAgIRALybxefeCAo7bRNPQCShItowCgYIKoZIzj0EAwIwKjEP
+MA0GA1UEChMGRGVjcmVkMRcwFQYDVQQDEw5taXguZGVjcmVkLm9yZzAeFw0yMTEy
+MTMyMjI2NTRaFw0zMTEyMTIyMjI2NTRaMCoxDzANBgNVBAoTBkRlY3JlZDEXMBUG
+A1UEAxMObWl4LmRlY3JlZC5vcmcwWTATBgcqhkjOPQIBBggqhkjOPQMBBwNCAAQK
+hX0D+UJnK51uIgJ3Fay5YPza8W0DY589srMuB4Gy6xSC/oUz1xbnMaUH+IEuxF1q
+le1wNMHLnuBhXOUuj8U3o4HBMIG+MA4GA1UdDwEB/wQEAwIChDAPBgNVHRMBAf8E
+BTADAQH/MB0GA1UdDgQWBBRG+HHZ+ksuzroDQ7rbcY+JMLsa5jB8BgNVHREEdTBz
+gg5taXguZGVjcmVkLm9yZ4I+cnRsaHBobmNwcXZ5eGJ3eHp0Z28ybDc2cjduZHo3
+b3puajV2dWhhdjRpd3dvajJ3azRib3E1eWQub25pb26CCWxvY2FsaG9zdIcEfwAA
+AYcQAAAAAAAAAAAAAAAAAAAAATAKBggqhkjOPQQDAgNJADBGAiEAmt6TPKT+frCw
+i05K3CCZTIoiZJSlfYDwfi4uHDV3T8MCIQCoK73gLGdqMdZGPTfi8JhlGtE7sHea
+RE9/S0ootEuw9g==
+-----END CERTIFICATE-----
+`
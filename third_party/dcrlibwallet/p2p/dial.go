@@ -0,0 +1,69 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package p2p
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/decred/dcrd/addrmgr/v2"
+	"github.com/decred/dcrd/wire"
+)
+
+// DialFunc opens a connection to the address on the named network.
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// SetDialFunc sets the function that connects to the remote peers and the
+// seeders, such as the dialer of a SOCKS5 proxy. The addresses of peers
+// given as host names are not resolved locally when a dial function is set.
+// It must be set before connecting to peers.
+func (lp *LocalPeer) SetDialFunc(dial DialFunc) {
+	lp.dial = dial
+}
+
+// dialContext dials with the dial function or the default dialer.
+func (lp *LocalPeer) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if lp.dial != nil {
+		return lp.dial(ctx, network, addr)
+	}
+	return lp.dialer.DialContext(ctx, network, addr)
+}
+
+// outboundNetAddress returns the net address of a peer to connect to. With a
+// dial function, a host name is left for the dialer to resolve and the net
+// address has an unspecified IP.
+func (lp *LocalPeer) outboundNetAddress(addr string) (*addrmgr.NetAddress, error) {
+	if lp.dial != nil {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if net.ParseIP(host) == nil {
+			p, err := strconv.ParseUint(port, 10, 16)
+			if err != nil {
+				return nil, err
+			}
+			return addrmgr.NewNetAddressIPPort(net.IPv4zero, uint16(p), wire.SFNodeNetwork), nil
+		}
+	}
+
+	tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return addrmgr.NewNetAddressIPPort(tcpAddr.IP, uint16(tcpAddr.Port), wire.SFNodeNetwork), nil
+}
+
+// dialedConn is a connection opened by the dial function. A proxy reports
+// its own addresses, dialedConn reports the address of the remote peer and
+// an unspecified local address, which are sent in the version message.
+type dialedConn struct {
+	net.Conn
+	raddr *net.TCPAddr
+}
+
+func (c *dialedConn) LocalAddr() net.Addr  { return &net.TCPAddr{IP: net.IPv4zero} }
+func (c *dialedConn) RemoteAddr() net.Addr { return c.raddr }
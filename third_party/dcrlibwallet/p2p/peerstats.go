@@ -0,0 +1,54 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package p2p
+
+import (
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// countingConn counts the bytes read from and written to a connection.
+type countingConn struct {
+	// atomics
+	atomicSent     uint64
+	atomicReceived uint64
+
+	net.Conn
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	atomic.AddUint64(&c.atomicReceived, uint64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	atomic.AddUint64(&c.atomicSent, uint64(n))
+	return n, err
+}
+
+// BytesSent returns the number of bytes written to the peer's connection.
+func (rp *RemotePeer) BytesSent() uint64 {
+	if c, ok := rp.c.(*countingConn); ok {
+		return atomic.LoadUint64(&c.atomicSent)
+	}
+	return 0
+}
+
+// BytesReceived returns the number of bytes read from the peer's connection.
+func (rp *RemotePeer) BytesReceived() uint64 {
+	if c, ok := rp.c.(*countingConn); ok {
+		return atomic.LoadUint64(&c.atomicReceived)
+	}
+	return 0
+}
+
+// PingTime returns the round trip time of the last ping answered by the
+// peer, 0 until the first pong is received.
+func (rp *RemotePeer) PingTime() time.Duration {
+	return time.Duration(atomic.LoadInt64(&rp.atomicPingTime))
+}
@@ -0,0 +1,21 @@
+package txhelper
+
+import (
+	dcrutil "github.com/decred/dcrd/dcrutil/v4"
+	"github.com/decred/dcrd/wire"
+	"github.com/planetdecred/dcrlibwallet/addresshelper"
+)
+
+func MakeTxOutput(address string, amountInAtom int64, net dcrutil.AddressParams) (output *wire.TxOut, err error) {
+	pkScript, err := addresshelper.PkScript(address, net)
+	if err != nil {
+		return
+	}
+
+	output = &wire.TxOut{
+		Value:    amountInAtom,
+		Version:  scriptVersion,
+		PkScript: pkScript,
+	}
+	return
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"strings"
+
+	"decred.org/dcrwallet/v2/errors"
+	"github.com/decred/dcrd/chaincfg/v3"
+)
+
+var (
+	mainnetParams = chaincfg.MainNetParams()
+	testnetParams = chaincfg.TestNet3Params()
+	simnetParams  = chaincfg.SimNetParams()
+	regnetParams  = chaincfg.RegNetParams()
+)
+
+func ChainParams(netType string) (*chaincfg.Params, error) {
+	switch strings.ToLower(netType) {
+	case strings.ToLower(mainnetParams.Name):
+		return mainnetParams, nil
+	case strings.ToLower(testnetParams.Name):
+		return testnetParams, nil
+	case strings.ToLower(simnetParams.Name):
+		return simnetParams, nil
+	case strings.ToLower(regnetParams.Name):
+		return regnetParams, nil
+	default:
+		return nil, errors.New("invalid net type")
+	}
+}
@@ -94,6 +94,13 @@ func (d *DropDown) SelectedIndex() int {
 	return d.selectedIndex
 }
 
+// SetSelectedIndex selects the item at index, if there is one.
+func (d *DropDown) SetSelectedIndex(index int) {
+	if index >= 0 && index < len(d.items) {
+		d.selectedIndex = index
+	}
+}
+
 func (d *DropDown) Len() int {
 	return len(d.items)
 }
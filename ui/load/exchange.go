@@ -7,9 +7,11 @@ import (
 	"golang.org/x/text/message"
 )
 
-func GetUSDExchangeValue(target interface{}) error {
+// GetUSDExchangeValue fetches the DCR-USDT ticker with the client, the
+// client connects through the proxy when the proxy is on.
+func GetUSDExchangeValue(client *http.Client, target interface{}) error {
 	url := "https://api.bittrex.com/v3/markets/DCR-USDT/ticker"
-	res, err := http.Get(url)
+	res, err := client.Get(url)
 	// TODO: include user agent in req header
 	if err != nil {
 		return err
@@ -69,6 +69,9 @@ type Load struct {
 
 	Toast *notification.Toast
 
+	// Notifications records the notifications shown to the user.
+	Notifications *notification.Center
+
 	SelectedUTXO map[int]map[int32]map[string]*wallet.UnspentOutput
 
 	ToggleSync          func()
@@ -83,6 +86,7 @@ type Load struct {
 	SubscribeKeyEvent   func(eventChan chan *key.Event, pageID string) // Widgets call this function to recieve key events.
 	UnsubscribeKeyEvent func(pageID string) error
 	ReloadApp           func()
+	CloseWindow         func()
 }
 
 func (l *Load) RefreshTheme() {
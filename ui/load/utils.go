@@ -1,5 +1,10 @@
 package load
 
+import (
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
 const Uint32Size = 32 << (^uint32(0) >> 32 & 1) // 32 or 64
 const MaxInt32 = 1<<(Uint32Size-1) - 1
 
@@ -9,8 +14,61 @@ const (
 	AutoSyncConfigKey                = "autoSync"
 	LanguagePreferenceKey            = "app_language"
 	DarkModeConfigKey                = "dark_mode"
-	FetchProposalConfigKey           = "fetch_proposals"
+	FetchProposalConfigKey           = wallet.FetchProposalConfigKey
 	SeedBackupNotificationConfigKey  = "seed_backup_notification"
 	ProposalNotificationConfigKey    = "proposal_notification_key"
 	TransactionNotificationConfigKey = "transaction_notification_key"
+
+	// ticket notification config keys, each holds one of the
+	// values.TicketNotification* delivery options.
+	TicketMaturedNotificationConfigKey = "ticket_matured_notification_key"
+	TicketLiveNotificationConfigKey    = "ticket_live_notification_key"
+	TicketVotedNotificationConfigKey   = "ticket_voted_notification_key"
+	TicketRevokedNotificationConfigKey = "ticket_revoked_notification_key"
+	TicketExpiredNotificationConfigKey = "ticket_expired_notification_key"
+	TicketMissedNotificationConfigKey  = "ticket_missed_notification_key"
+
+	// DEX notification config keys, the notifications of each category are
+	// delivered unless the key is set to false.
+	DexTradeNotificationConfigKey      = "dex_trade_notification_key"
+	DexSwapNotificationConfigKey       = "dex_swap_notification_key"
+	DexRefundNotificationConfigKey     = "dex_refund_notification_key"
+	DexConnectionNotificationConfigKey = "dex_connection_notification_key"
 )
+
+// BackupConfigKeys are the godcr config keys saved in and restored from a
+// backup of all the wallets.
+var BackupConfigKeys = []string{
+	HideBalanceConfigKey,
+	AutoSyncConfigKey,
+	LanguagePreferenceKey,
+	DarkModeConfigKey,
+	FetchProposalConfigKey,
+	ProposalNotificationConfigKey,
+	TransactionNotificationConfigKey,
+	TicketMaturedNotificationConfigKey,
+	TicketLiveNotificationConfigKey,
+	TicketVotedNotificationConfigKey,
+	TicketRevokedNotificationConfigKey,
+	TicketExpiredNotificationConfigKey,
+	TicketMissedNotificationConfigKey,
+	DexTradeNotificationConfigKey,
+	DexSwapNotificationConfigKey,
+	DexRefundNotificationConfigKey,
+	DexConnectionNotificationConfigKey,
+}
+
+// MigrateTicketNotifications keeps the vote and revocation desktop
+// notifications of the users who had the transaction notifications on
+// before the ticket notifications had their own settings. The settings that
+// were already chosen are left as is.
+func MigrateTicketNotifications(wal *wallet.Wallet) {
+	if !wal.ReadBoolConfigValueForKey(TransactionNotificationConfigKey) {
+		return
+	}
+	for _, key := range []string{TicketVotedNotificationConfigKey, TicketRevokedNotificationConfigKey} {
+		if wal.ReadStringConfigValueForKey(key) == "" {
+			wal.SaveConfigValueForKey(key, values.TicketNotificationSystem)
+		}
+	}
+}
@@ -95,14 +95,7 @@ func (wl *WalletLoad) SpendableWalletBalance(walletID int) (dcrutil.Amount, erro
 }
 
 func (wl *WalletLoad) HDPrefix() string {
-	switch wl.Wallet.Net {
-	case dcrlibwallet.Testnet3:
-		return dcrlibwallet.TestnetHDPath
-	case "mainnet":
-		return dcrlibwallet.MainnetHDPath
-	default:
-		return ""
-	}
+	return wl.Wallet.HDPrefix()
 }
 
 func (wl *WalletLoad) WalletDirectory() string {
@@ -0,0 +1,63 @@
+package notification
+
+import (
+	"sync"
+	"time"
+)
+
+// maxCenterItems is the number of notifications kept by the Center, the
+// oldest are dropped first.
+const maxCenterItems = 200
+
+// Item is a notification recorded by the Center.
+type Item struct {
+	Category string
+	Title    string
+	Details  string
+	IsError  bool
+	Time     time.Time
+}
+
+// Center keeps the recent notifications so they can be reviewed after their
+// toast was dismissed. It is safe for concurrent use.
+type Center struct {
+	mu    sync.Mutex
+	items []Item
+}
+
+func NewCenter() *Center {
+	return &Center{}
+}
+
+// Add records a notification.
+func (c *Center) Add(item Item) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if item.Time.IsZero() {
+		item.Time = time.Now()
+	}
+	c.items = append(c.items, item)
+	if len(c.items) > maxCenterItems {
+		c.items = c.items[len(c.items)-maxCenterItems:]
+	}
+}
+
+// Items returns the recorded notifications, newest first.
+func (c *Center) Items() []Item {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	items := make([]Item, len(c.items))
+	for i, item := range c.items {
+		items[len(c.items)-1-i] = item
+	}
+	return items
+}
+
+// Clear deletes all the recorded notifications.
+func (c *Center) Clear() {
+	c.mu.Lock()
+	c.items = nil
+	c.mu.Unlock()
+}
@@ -0,0 +1,163 @@
+package page
+
+import (
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+const blockExplorerModalID = "block_explorer_modal"
+
+// blockExplorerModal adds a block explorer or edits a saved one.
+type blockExplorerModal struct {
+	*load.Load
+
+	oldName string
+	saved   func()
+
+	modal         *decredmaterial.Modal
+	nameEditor    decredmaterial.Editor
+	txEditor      decredmaterial.Editor
+	addressEditor decredmaterial.Editor
+	blockEditor   decredmaterial.Editor
+	ticketEditor  decredmaterial.Editor
+	save          decredmaterial.Button
+	cancel        decredmaterial.Button
+	saveError     string
+}
+
+// newBlockExplorerModal edits the explorer, a nil explorer is added.
+func newBlockExplorerModal(l *load.Load, explorer *wallet.BlockExplorer) *blockExplorerModal {
+	md := &blockExplorerModal{
+		Load:          l,
+		modal:         l.Theme.ModalFloatTitle(),
+		nameEditor:    l.Theme.Editor(new(widget.Editor), "Name"),
+		txEditor:      l.Theme.Editor(new(widget.Editor), "Transaction URL, with {hash}"),
+		addressEditor: l.Theme.Editor(new(widget.Editor), "Address URL, with {address}"),
+		blockEditor:   l.Theme.Editor(new(widget.Editor), "Block URL, with {block}"),
+		ticketEditor:  l.Theme.Editor(new(widget.Editor), "Ticket URL, with {hash}"),
+		save:          l.Theme.Button("Save"),
+		cancel:        l.Theme.OutlineButton(values.String(values.StrCancel)),
+	}
+	for _, e := range md.editors() {
+		e.SingleLine = true
+	}
+
+	if explorer != nil {
+		md.oldName = explorer.Name
+		md.nameEditor.Editor.SetText(explorer.Name)
+		md.txEditor.Editor.SetText(explorer.TxURL)
+		md.addressEditor.Editor.SetText(explorer.AddressURL)
+		md.blockEditor.Editor.SetText(explorer.BlockURL)
+		md.ticketEditor.Editor.SetText(explorer.TicketURL)
+	}
+
+	return md
+}
+
+// Saved sets the function called once the explorer is saved.
+func (md *blockExplorerModal) Saved(saved func()) *blockExplorerModal {
+	md.saved = saved
+	return md
+}
+
+func (md *blockExplorerModal) editors() []*widget.Editor {
+	return []*widget.Editor{md.nameEditor.Editor, md.txEditor.Editor, md.addressEditor.Editor,
+		md.blockEditor.Editor, md.ticketEditor.Editor}
+}
+
+func (md *blockExplorerModal) ModalID() string {
+	return blockExplorerModalID
+}
+
+func (md *blockExplorerModal) Show() {
+	md.ShowModal(md)
+}
+
+func (md *blockExplorerModal) Dismiss() {
+	md.DismissModal(md)
+}
+
+func (md *blockExplorerModal) OnDismiss() {}
+
+func (md *blockExplorerModal) OnResume() {
+	md.nameEditor.Editor.Focus()
+}
+
+func (md *blockExplorerModal) Handle() {
+	if md.cancel.Clicked() {
+		md.Dismiss()
+	}
+
+	_, changed := decredmaterial.HandleEditorEvents(md.editors()...)
+	if changed {
+		md.saveError = ""
+	}
+
+	if md.save.Clicked() {
+		explorer := wallet.BlockExplorer{
+			Name:       md.nameEditor.Editor.Text(),
+			TxURL:      md.txEditor.Editor.Text(),
+			AddressURL: md.addressEditor.Editor.Text(),
+			BlockURL:   md.blockEditor.Editor.Text(),
+			TicketURL:  md.ticketEditor.Editor.Text(),
+		}
+		if err := md.WL.Wallet.SaveBlockExplorer(md.oldName, explorer); err != nil {
+			md.saveError = err.Error()
+			return
+		}
+
+		md.Dismiss()
+		if md.saved != nil {
+			md.saved()
+		}
+	}
+}
+
+func (md *blockExplorerModal) Layout(gtx layout.Context) D {
+	title := "Add block explorer"
+	if md.oldName != "" {
+		title = "Edit block explorer"
+	}
+
+	w := []layout.Widget{
+		func(gtx C) D {
+			t := md.Theme.H6(title)
+			t.Font.Weight = text.SemiBold
+			return t.Layout(gtx)
+		},
+		func(gtx C) D {
+			lbl := md.Theme.Body2("Leave a URL empty if the explorer has no page of that kind.")
+			lbl.Color = md.Theme.Color.GrayText2
+			return lbl.Layout(gtx)
+		},
+		md.nameEditor.Layout,
+		md.txEditor.Layout,
+		md.addressEditor.Layout,
+		md.blockEditor.Layout,
+		md.ticketEditor.Layout,
+		func(gtx C) D {
+			if md.saveError == "" {
+				return D{}
+			}
+			return md.Theme.ErrorLabel(md.saveError).Layout(gtx)
+		},
+		func(gtx C) D {
+			return layout.E.Layout(gtx, func(gtx C) D {
+				return layout.Flex{}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, md.cancel.Layout)
+					}),
+					layout.Rigid(md.save.Layout),
+				)
+			})
+		},
+	}
+
+	return md.modal.Layout(gtx, w)
+}
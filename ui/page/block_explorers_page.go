@@ -0,0 +1,235 @@
+package page
+
+import (
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/modal"
+	"github.com/planetdecred/godcr/ui/page/components"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+const BlockExplorersPageID = "BlockExplorers"
+
+// explorerRow is a saved block explorer with its actions.
+type explorerRow struct {
+	explorer wallet.BlockExplorer
+	edit     decredmaterial.Button
+	remove   decredmaterial.Button
+}
+
+// BlockExplorersPage lists the block explorers the transactions, addresses,
+// blocks and tickets are opened on, and adds, edits and removes them.
+type BlockExplorersPage struct {
+	*load.Load
+
+	container  *widget.List
+	backButton decredmaterial.IconButton
+	add        decredmaterial.Button
+	reset      decredmaterial.Button
+
+	explorers []*explorerRow
+}
+
+func NewBlockExplorersPage(l *load.Load) *BlockExplorersPage {
+	pg := &BlockExplorersPage{
+		Load:      l,
+		container: &widget.List{List: layout.List{Axis: layout.Vertical}},
+		add:       l.Theme.OutlineButton("Add explorer"),
+		reset:     l.Theme.OutlineButton("Reset to defaults"),
+	}
+	pg.backButton, _ = components.SubpageHeaderButtons(l)
+
+	return pg
+}
+
+// ID is a unique string that identifies the page and may be used
+// to differentiate this page from other pages.
+// Part of the load.Page interface.
+func (pg *BlockExplorersPage) ID() string {
+	return BlockExplorersPageID
+}
+
+// OnNavigatedTo is called when the page is about to be displayed and
+// may be used to initialize page features that are only relevant when
+// the page is displayed.
+// Part of the load.Page interface.
+func (pg *BlockExplorersPage) OnNavigatedTo() {
+	pg.loadExplorers()
+}
+
+// loadExplorers lists the saved block explorers.
+func (pg *BlockExplorersPage) loadExplorers() {
+	var rows []*explorerRow
+	for _, explorer := range pg.WL.Wallet.SavedBlockExplorers() {
+		row := &explorerRow{
+			explorer: explorer,
+			edit:     pg.Theme.OutlineButton("Edit"),
+			remove:   pg.Theme.OutlineButton("Delete"),
+		}
+		row.edit.Inset = layout.UniformInset(values.MarginPadding8)
+		row.remove.Inset = layout.UniformInset(values.MarginPadding8)
+		rows = append(rows, row)
+	}
+	pg.explorers = rows
+}
+
+// HandleUserInteractions is called just before Layout() to determine
+// if any user interaction recently occurred on the page and may be
+// used to update the page's UI components shortly before they are
+// displayed.
+// Part of the load.Page interface.
+func (pg *BlockExplorersPage) HandleUserInteractions() {
+	for pg.add.Clicked() {
+		newBlockExplorerModal(pg.Load, nil).
+			Saved(pg.loadExplorers).
+			Show()
+	}
+
+	for pg.reset.Clicked() {
+		modal.NewInfoModal(pg.Load).
+			Title("Reset block explorers").
+			Body("Replace the block explorers with the default explorers of the network?").
+			NegativeButton(values.String(values.StrCancel), func() {}).
+			PositiveButtonStyle(pg.Theme.Color.Surface, pg.Theme.Color.Danger).
+			PositiveButton("Reset", func() {
+				pg.WL.Wallet.ResetBlockExplorers()
+				pg.loadExplorers()
+			}).Show()
+	}
+
+	for _, row := range pg.explorers {
+		for row.edit.Clicked() {
+			explorer := row.explorer
+			newBlockExplorerModal(pg.Load, &explorer).
+				Saved(pg.loadExplorers).
+				Show()
+		}
+		for row.remove.Clicked() {
+			pg.WL.Wallet.RemoveBlockExplorer(row.explorer.Name)
+			pg.loadExplorers()
+		}
+	}
+}
+
+// OnNavigatedFrom is called when the page is about to be removed from
+// the displayed window. This method should ideally be used to disable
+// features that are irrelevant when the page is NOT displayed.
+// NOTE: The page may be re-displayed on the app's window, in which case
+// OnNavigatedTo() will be called again. This method should not destroy UI
+// components unless they'll be recreated in the OnNavigatedTo() method.
+// Part of the load.Page interface.
+func (pg *BlockExplorersPage) OnNavigatedFrom() {}
+
+// Layout draws the page UI components into the provided layout context
+// to be eventually drawn on screen.
+// Part of the load.Page interface.
+func (pg *BlockExplorersPage) Layout(gtx C) D {
+	container := func(gtx C) D {
+		sp := components.SubPage{
+			Load:       pg.Load,
+			Title:      "Block explorers",
+			BackButton: pg.backButton,
+			Back: func() {
+				pg.PopFragment()
+			},
+			Body: func(gtx C) D {
+				return pg.Theme.List(pg.container).Layout(gtx, 1, func(gtx C, i int) D {
+					return pg.explorersSection(gtx)
+				})
+			},
+		}
+		return sp.Layout(gtx)
+	}
+	return components.UniformPadding(gtx, container)
+}
+
+func (pg *BlockExplorersPage) explorersSection(gtx C) D {
+	card := pg.Theme.Card()
+	card.Radius = decredmaterial.Radius(14)
+	return card.Layout(gtx, func(gtx C) D {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		return layout.UniformInset(values.MarginPadding15).Layout(gtx, func(gtx C) D {
+			children := []layout.FlexChild{
+				layout.Rigid(func(gtx C) D {
+					return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+						layout.Flexed(1, func(gtx C) D {
+							lbl := pg.Theme.Body1("Explorers")
+							lbl.Font.Weight = text.SemiBold
+							return lbl.Layout(gtx)
+						}),
+						layout.Rigid(func(gtx C) D {
+							return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, pg.reset.Layout)
+						}),
+						layout.Rigid(pg.add.Layout),
+					)
+				}),
+			}
+
+			var notes []string
+			if pg.WL.Wallet.FeatureDisabled(wallet.ProxySubsystemExplorer) {
+				notes = append(notes, "The block explorer is turned off in the network privacy settings.")
+			}
+			for _, e := range pg.WL.Wallet.BlockExplorers() {
+				if e.Name == "--explorerurl" {
+					notes = append(notes, "The transactions open first on the explorer set with --explorerurl: "+e.TxURL)
+				}
+			}
+			if len(pg.explorers) == 0 {
+				notes = append(notes, "No block explorers. Add one with the URLs of its pages to open transactions, addresses, blocks and tickets on it.")
+			}
+			for _, note := range notes {
+				note := note
+				children = append(children, layout.Rigid(func(gtx C) D {
+					lbl := pg.Theme.Body2(note)
+					lbl.Color = pg.Theme.Color.GrayText2
+					return layout.Inset{Top: values.MarginPadding10}.Layout(gtx, lbl.Layout)
+				}))
+			}
+
+			for _, row := range pg.explorers {
+				row := row
+				children = append(children, layout.Rigid(func(gtx C) D {
+					return layout.Inset{Top: values.MarginPadding10}.Layout(gtx, func(gtx C) D {
+						return pg.explorerRowLayout(gtx, row)
+					})
+				}))
+			}
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+		})
+	})
+}
+
+func (pg *BlockExplorersPage) explorerRowLayout(gtx C, row *explorerRow) D {
+	var pages []string
+	for _, kind := range []string{wallet.ExplorerTransaction, wallet.ExplorerAddress, wallet.ExplorerBlock, wallet.ExplorerTicket} {
+		if row.explorer.Template(kind) != "" {
+			pages = append(pages, kind+"s")
+		}
+	}
+
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(pg.Theme.Body2(row.explorer.Name).Layout),
+				layout.Rigid(func(gtx C) D {
+					lbl := pg.Theme.Caption(strings.Join(pages, ", "))
+					lbl.Color = pg.Theme.Color.GrayText2
+					return lbl.Layout(gtx)
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{Left: values.MarginPadding8}.Layout(gtx, row.edit.Layout)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{Left: values.MarginPadding8}.Layout(gtx, row.remove.Layout)
+		}),
+	)
+}
@@ -0,0 +1,137 @@
+package components
+
+import (
+	"gioui.org/layout"
+	"gioui.org/text"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+const ModalExplorer = "explorer_modal"
+
+var explorerModalTitles = map[string]string{
+	wallet.ExplorerTransaction: "Open transaction in explorer",
+	wallet.ExplorerAddress:     "Open address in explorer",
+	wallet.ExplorerBlock:       "Open block in explorer",
+	wallet.ExplorerTicket:      "Open ticket in explorer",
+}
+
+type explorerLink struct {
+	name      string
+	url       string
+	clickable *decredmaterial.Clickable
+}
+
+// ExplorerModal lists the block explorers that have a page for a
+// transaction, address, block or ticket and opens the one clicked.
+type ExplorerModal struct {
+	*load.Load
+
+	modal  *decredmaterial.Modal
+	title  string
+	links  []*explorerLink
+	cancel decredmaterial.Button
+}
+
+// OpenInExplorer opens the page of the transaction hash, address, block or
+// ticket hash on the block explorer, a modal lets the user pick one of the
+// explorers if there are several.
+func OpenInExplorer(l *load.Load, kind, id string) {
+	if err := l.WL.Wallet.CheckConnection(wallet.ProxySubsystemExplorer); err != nil {
+		l.Toast.NotifyError(err.Error())
+		return
+	}
+
+	explorers := l.WL.Wallet.BlockExplorersFor(kind)
+	switch len(explorers) {
+	case 0:
+		l.Toast.NotifyError("There is no block explorer with " + kind + " pages, add one in the settings.")
+	case 1:
+		GoToURL(explorers[0].URL(kind, id))
+	default:
+		md := &ExplorerModal{
+			Load:   l,
+			modal:  l.Theme.ModalFloatTitle(),
+			title:  explorerModalTitles[kind],
+			cancel: l.Theme.OutlineButton(values.String(values.StrCancel)),
+		}
+		for _, e := range explorers {
+			md.links = append(md.links, &explorerLink{
+				name:      e.Name,
+				url:       e.URL(kind, id),
+				clickable: l.Theme.NewClickable(true),
+			})
+		}
+		md.Show()
+	}
+}
+
+func (md *ExplorerModal) ModalID() string {
+	return ModalExplorer
+}
+
+func (md *ExplorerModal) Show() {
+	md.ShowModal(md)
+}
+
+func (md *ExplorerModal) Dismiss() {
+	md.DismissModal(md)
+}
+
+func (md *ExplorerModal) OnDismiss() {}
+
+func (md *ExplorerModal) OnResume() {}
+
+func (md *ExplorerModal) Handle() {
+	for _, link := range md.links {
+		for link.clickable.Clicked() {
+			GoToURL(link.url)
+			md.Dismiss()
+		}
+	}
+
+	if md.cancel.Clicked() || md.modal.BackdropClicked(true) {
+		md.Dismiss()
+	}
+}
+
+func (md *ExplorerModal) Layout(gtx layout.Context) D {
+	w := []layout.Widget{
+		func(gtx C) D {
+			t := md.Theme.H6(md.title)
+			t.Font.Weight = text.SemiBold
+			return t.Layout(gtx)
+		},
+	}
+	for _, link := range md.links {
+		link := link
+		w = append(w, func(gtx C) D {
+			return link.clickable.Layout(gtx, func(gtx C) D {
+				gtx.Constraints.Min.X = gtx.Constraints.Max.X
+				return layout.Inset{Top: values.MarginPadding8, Bottom: values.MarginPadding8}.Layout(gtx, func(gtx C) D {
+					return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+						layout.Flexed(1, func(gtx C) D {
+							return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+								layout.Rigid(md.Theme.Body1(link.name).Layout),
+								layout.Rigid(func(gtx C) D {
+									lbl := md.Theme.Caption(link.url)
+									lbl.Color = md.Theme.Color.GrayText2
+									return lbl.Layout(gtx)
+								}),
+							)
+						}),
+						layout.Rigid(md.Icons.RedirectIcon.Layout24dp),
+					)
+				})
+			})
+		})
+	}
+	w = append(w, func(gtx C) D {
+		return layout.E.Layout(gtx, md.cancel.Layout)
+	})
+
+	return md.modal.Layout(gtx, w)
+}
@@ -155,7 +155,7 @@ func newVSPSelectorModal(l *load.Load) *vspSelectorModal {
 func (v *vspSelectorModal) OnResume() {
 	if len(v.WL.MultiWallet.KnownVSPs()) == 0 {
 		go func() {
-			v.WL.MultiWallet.ReloadVSPList(context.TODO())
+			v.WL.Wallet.ReloadVSPList(context.TODO())
 			v.RefreshWindow()
 		}()
 	}
@@ -177,7 +177,7 @@ func (v *vspSelectorModal) Handle() {
 	v.addVSP.SetEnabled(v.editorsNotEmpty(v.inputVSP.Editor))
 	if v.addVSP.Clicked() {
 		go func() {
-			err := v.WL.MultiWallet.SaveVSP(v.inputVSP.Editor.Text())
+			err := v.WL.Wallet.SaveVSP(v.inputVSP.Editor.Text())
 			if err != nil {
 				v.Toast.NotifyError(err.Error())
 			} else {
@@ -5,8 +5,8 @@ import (
 
 	"github.com/planetdecred/godcr/ui/decredmaterial"
 	"github.com/planetdecred/godcr/ui/load"
-	"github.com/planetdecred/godcr/ui/modal"
 	"github.com/planetdecred/godcr/ui/page/components"
+	"github.com/planetdecred/godcr/ui/page/dexclient"
 	"github.com/planetdecred/godcr/ui/values"
 )
 
@@ -52,14 +52,12 @@ func NewDebugPage(l *load.Load) *DebugPage {
 	pg.list.Radius = decredmaterial.Radius(14)
 	pg.list.IsShadowEnabled = true
 
-	// TODO: temp removal till V1.0 is release
-	// Add a "Reset DEX Client" option.
-	// pg.debugItems = append(pg.debugItems, debugItem{
-	// 	text: "Reset DEX Client",
-	// 	action: func() {
-	// 		pg.resetDexData()
-	// 	},
-	// })
+	pg.debugItems = append(pg.debugItems, debugItem{
+		text: "Reset DEX Client",
+		action: func() {
+			pg.resetDexData()
+		},
+	})
 
 	pg.backButton, _ = components.SubpageHeaderButtons(l)
 
@@ -164,18 +162,8 @@ func (pg *DebugPage) Layout(gtx C) D {
 	return components.UniformPadding(gtx, container)
 }
 
+// resetDexData resets the DEX client after a confirmation, it does not need
+// the login so that a forgotten app password can be reset.
 func (pg *DebugPage) resetDexData() {
-	// Show confirm modal before resetting dex client data.
-	confirmModal := modal.NewInfoModal(pg.Load).
-		Title("Confirm DEX Client Reset").
-		Body("You may need to restart godcr before you can use the DEX again. Proceed?").
-		NegativeButton(values.String(values.StrCancel), func() {}).
-		PositiveButton("Reset DEX Client", func() {
-			if pg.Dexc().Reset() {
-				pg.Toast.Notify("DEX client data reset complete.")
-			} else {
-				pg.Toast.NotifyError("DEX client data reset failed. Check the logs.")
-			}
-		})
-	pg.ShowModal(confirmModal)
+	dexclient.ShowResetModal(pg.Load)
 }
@@ -17,11 +17,17 @@ import (
 	"github.com/planetdecred/godcr/ui/modal"
 	"github.com/planetdecred/godcr/ui/page/components"
 	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
 )
 
 const addDexModalID = "add_dex_modal"
 
-const testDexHost = "dex-test.ssgen.io:7232"
+const (
+	testDexHost = "dex-test.ssgen.io:7232"
+	// simnetDexHost is the address of the dcrdex server of the dcrdex simnet
+	// harness, running on loopback.
+	simnetDexHost = "127.0.0.1:17273"
+)
 
 type addDexModal struct {
 	*load.Load
@@ -46,8 +52,11 @@ func newAddDexModal(l *load.Load) *addDexModal {
 	}
 
 	md.dexServerAddress.Editor.SingleLine = true
-	if l.WL.MultiWallet.NetType() == dcrlibwallet.Testnet3 {
+	switch l.WL.MultiWallet.NetType() {
+	case dcrlibwallet.Testnet3:
 		md.dexServerAddress.Editor.SetText(testDexHost)
+	case "simnet":
+		md.dexServerAddress.Editor.SetText(simnetDexHost)
 	}
 
 	return md
@@ -198,6 +207,11 @@ func (md *addDexModal) completeRegistration(dex *core.Exchange, feeAssetName str
 					pm.SetLoading(false)
 					return
 				}
+				md.WL.Wallet.SaveDexServer(&wallet.DexServer{
+					Host:     dex.Host,
+					Cert:     cert,
+					FeeAsset: feeAssetName,
+				})
 				pm.Dismiss()
 			}()
 
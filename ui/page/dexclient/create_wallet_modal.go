@@ -7,6 +7,7 @@ import (
 
 	"decred.org/dcrdex/client/asset/btc"
 	"decred.org/dcrdex/client/asset/dcr"
+	"decred.org/dcrdex/client/core"
 
 	"gioui.org/layout"
 	"gioui.org/widget"
@@ -17,6 +18,7 @@ import (
 	"github.com/planetdecred/godcr/ui/load"
 	"github.com/planetdecred/godcr/ui/page/components"
 	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
 )
 
 const dexCreateWalletModalID = "dex_create_wallet_modal"
@@ -36,6 +38,7 @@ type createWalletModal struct {
 	walletInfoWidget      *walletInfoWidget
 	materialLoader        material.LoaderStyle
 	isSending             bool
+	reconfigure           bool
 	walletCreated         func()
 }
 
@@ -77,6 +80,14 @@ func newCreateWalletModal(l *load.Load, wallInfo *walletInfoWidget, walletCreate
 	return md
 }
 
+// Reconfigure changes the account used by the existing wallet instead of
+// creating one.
+func (md *createWalletModal) Reconfigure() *createWalletModal {
+	md.reconfigure = true
+	md.submit.Text = "Save"
+	return md
+}
+
 func (md *createWalletModal) ModalID() string {
 	return dexCreateWalletModalID
 }
@@ -124,7 +135,7 @@ func (md *createWalletModal) Handle() {
 
 			coinID := md.walletInfoWidget.coinID
 			coinName := md.walletInfoWidget.coinName
-			if md.Dexc().HasWallet(int32(coinID)) {
+			if !md.reconfigure && md.Dexc().HasWallet(int32(coinID)) {
 				md.Toast.NotifyError(fmt.Sprintf("already connected a %s wallet", coinName))
 				return
 			}
@@ -142,11 +153,24 @@ func (md *createWalletModal) Handle() {
 				settings["password"] = md.walletPassword.Editor.Text()
 				walletType = dcrlibwallet.CustomDexDcrWalletType
 			case btc.BipID:
+				if err := md.WL.Wallet.CheckConnection(wallet.ProxySubsystemDEXSPV); err != nil {
+					md.Toast.NotifyError(err.Error())
+					return
+				}
 				walletType = "SPV" // decred.org/dcrdex/client/asset/btc.walletTypeSPV
 				walletPass = nil   // Core doesn't accept wallet passwords for dex-managed spv wallets.
 			}
 
-			err := md.Dexc().AddWallet(coinID, walletType, settings, appPass, walletPass)
+			var err error
+			if md.reconfigure {
+				err = md.Dexc().Core().ReconfigureWallet(appPass, walletPass, &core.WalletForm{
+					AssetID: coinID,
+					Config:  settings,
+					Type:    walletType,
+				})
+			} else {
+				err = md.Dexc().AddWallet(coinID, walletType, settings, appPass, walletPass)
+			}
 			if err != nil {
 				md.Toast.NotifyError(err.Error())
 				return
@@ -163,7 +187,11 @@ func (md *createWalletModal) Layout(gtx layout.Context) D {
 		func(gtx C) D {
 			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
 				layout.Rigid(func(gtx C) D {
-					return md.Load.Theme.Label(values.TextSize20, "Add a").Layout(gtx)
+					title := "Add a"
+					if md.reconfigure {
+						title = "Reconfigure"
+					}
+					return md.Load.Theme.Label(values.TextSize20, title).Layout(gtx)
 				}),
 				layout.Rigid(func(gtx C) D {
 					return layout.Inset{Left: values.MarginPadding8, Right: values.MarginPadding8}.Layout(gtx, func(gtx C) D {
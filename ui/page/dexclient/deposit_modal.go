@@ -0,0 +1,152 @@
+package dexclient
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"time"
+
+	"decred.org/dcrdex/client/core"
+	"gioui.org/io/clipboard"
+	"gioui.org/layout"
+	"gioui.org/text"
+	qrcode "github.com/yeqown/go-qrcode"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/values"
+)
+
+const dexDepositModalID = "dex_deposit_modal"
+
+// depositModal shows the deposit address of a DEX wallet with its QR code.
+type depositModal struct {
+	*load.Load
+
+	asset   *core.SupportedAsset
+	address string
+	qrImage image.Image
+
+	modal      *decredmaterial.Modal
+	copyBtn    decredmaterial.Button
+	newAddrBtn decredmaterial.Button
+	closeBtn   decredmaterial.Button
+}
+
+func newDepositModal(l *load.Load, a *core.SupportedAsset) *depositModal {
+	md := &depositModal{
+		Load:       l,
+		asset:      a,
+		modal:      l.Theme.ModalFloatTitle(),
+		copyBtn:    l.Theme.OutlineButton("Copy"),
+		newAddrBtn: l.Theme.OutlineButton("New address"),
+		closeBtn:   l.Theme.Button("Close"),
+	}
+	md.setAddress(a.Wallet.Address)
+	return md
+}
+
+func (md *depositModal) ModalID() string {
+	return dexDepositModalID
+}
+
+func (md *depositModal) Show() {
+	md.ShowModal(md)
+}
+
+func (md *depositModal) Dismiss() {
+	md.DismissModal(md)
+}
+
+func (md *depositModal) OnDismiss() {}
+
+func (md *depositModal) OnResume() {}
+
+func (md *depositModal) setAddress(address string) {
+	md.address, md.qrImage = address, nil
+	if address == "" {
+		return
+	}
+
+	qrCode, err := qrcode.New(address)
+	if err != nil {
+		md.Toast.NotifyError(fmt.Sprintf("Error generating address qrCode: %v", err))
+		return
+	}
+
+	var buff bytes.Buffer
+	if err = qrCode.SaveTo(&buff); err != nil {
+		md.Toast.NotifyError(err.Error())
+		return
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(buff.Bytes()))
+	if err != nil {
+		md.Toast.NotifyError(err.Error())
+		return
+	}
+	md.qrImage = img
+}
+
+func (md *depositModal) Handle() {
+	if md.closeBtn.Clicked() || md.modal.BackdropClicked(true) {
+		md.Dismiss()
+	}
+
+	if md.newAddrBtn.Clicked() {
+		go func() {
+			address, err := md.Dexc().Core().NewDepositAddress(md.asset.ID)
+			if err != nil {
+				md.Toast.NotifyError(err.Error())
+				return
+			}
+			md.setAddress(address)
+			md.RefreshWindow()
+		}()
+	}
+}
+
+func (md *depositModal) Layout(gtx layout.Context) D {
+	if md.copyBtn.Clicked() {
+		clipboard.WriteOp{Text: md.address}.Add(gtx.Ops)
+		md.copyBtn.Text = "Copied!"
+		time.AfterFunc(time.Second*3, func() {
+			md.copyBtn.Text = "Copy"
+			md.RefreshWindow()
+		})
+	}
+
+	w := []layout.Widget{
+		func(gtx C) D {
+			t := md.Theme.H6(fmt.Sprintf("Deposit %s", md.asset.Info.Name))
+			t.Font.Weight = text.SemiBold
+			return t.Layout(gtx)
+		},
+		func(gtx C) D {
+			if md.qrImage == nil {
+				return D{}
+			}
+			return layout.Center.Layout(gtx, func(gtx C) D {
+				return md.Theme.ImageIcon(gtx, md.qrImage, 240)
+			})
+		},
+		func(gtx C) D {
+			return layout.Center.Layout(gtx, md.Theme.Body2(md.address).Layout)
+		},
+		func(gtx C) D {
+			return layout.E.Layout(gtx, func(gtx C) D {
+				return layout.Flex{}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, md.copyBtn.Layout)
+					}),
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, md.newAddrBtn.Layout)
+					}),
+					layout.Rigid(md.closeBtn.Layout),
+				)
+			})
+		},
+	}
+
+	return md.modal.Layout(gtx, w)
+}
@@ -0,0 +1,110 @@
+package dexclient
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/f32"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+)
+
+// depthChartAreaAlpha is the alpha of the filled areas of the depth chart.
+const depthChartAreaAlpha = 0x60
+
+// depthChart draws the cumulative quantities of the bids and asks of an
+// order book as step areas, bids on the left and asks on the right.
+type depthChart struct {
+	theme  *decredmaterial.Theme
+	height unit.Value
+}
+
+func newDepthChart(th *decredmaterial.Theme, height unit.Value) *depthChart {
+	return &depthChart{
+		theme:  th,
+		height: height,
+	}
+}
+
+func (dc *depthChart) layout(gtx C, bids, asks []depthPoint) D {
+	size := image.Point{X: gtx.Constraints.Max.X, Y: gtx.Px(dc.height)}
+	if len(bids) == 0 && len(asks) == 0 {
+		return D{Size: size}
+	}
+
+	// The rate axis spans from the lowest bid to the highest ask, the
+	// quantity axis from zero to the largest cumulative quantity.
+	minRate, maxRate, maxQty := rateRange(bids, asks)
+	if maxRate == minRate {
+		minRate, maxRate = minRate*0.9, maxRate*1.1
+	}
+	if maxQty == 0 {
+		return D{Size: size}
+	}
+
+	width, height := float32(size.X), float32(size.Y)
+	point := func(rate, qty float64) f32.Point {
+		return f32.Point{
+			X: float32((rate - minRate) / (maxRate - minRate) * float64(width)),
+			Y: height - float32(qty/maxQty*float64(height)),
+		}
+	}
+
+	dc.drawSide(gtx, bids, point, 0, dc.theme.Color.Success)
+	dc.drawSide(gtx, asks, point, width, dc.theme.Color.Danger)
+
+	return D{Size: size}
+}
+
+// drawSide fills the area under the steps of one side of the book, from the
+// best rate outwards to edgeX.
+func (dc *depthChart) drawSide(gtx C, points []depthPoint, point func(rate, qty float64) f32.Point, edgeX float32, col color.NRGBA) {
+	if len(points) == 0 {
+		return
+	}
+
+	bottom := float32(gtx.Px(dc.height))
+	var p clip.Path
+	p.Begin(gtx.Ops)
+
+	start := point(points[0].Rate, 0)
+	p.MoveTo(f32.Point{X: start.X, Y: bottom})
+	prev := point(points[0].Rate, points[0].Qty)
+	p.LineTo(prev)
+	for _, pt := range points[1:] {
+		next := point(pt.Rate, pt.Qty)
+		p.LineTo(f32.Point{X: next.X, Y: prev.Y})
+		p.LineTo(next)
+		prev = next
+	}
+	p.LineTo(f32.Point{X: edgeX, Y: prev.Y})
+	p.LineTo(f32.Point{X: edgeX, Y: bottom})
+	p.Close()
+
+	col.A = depthChartAreaAlpha
+	paint.FillShape(gtx.Ops, col, clip.Outline{Path: p.End()}.Op())
+}
+
+// rateRange returns the lowest and highest rates of the bids and asks, and
+// the largest cumulative quantity of both sides.
+func rateRange(bids, asks []depthPoint) (minRate, maxRate, maxQty float64) {
+	first := true
+	for _, side := range [][]depthPoint{bids, asks} {
+		for _, pt := range side {
+			if first || pt.Rate < minRate {
+				minRate = pt.Rate
+			}
+			if first || pt.Rate > maxRate {
+				maxRate = pt.Rate
+			}
+			if pt.Qty > maxQty {
+				maxQty = pt.Qty
+			}
+			first = false
+		}
+	}
+	return
+}
@@ -0,0 +1,370 @@
+package dexclient
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"decred.org/dcrdex/client/core"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/modal"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+// serverRow is a registered DEX server of the settings tab, with the buttons
+// to manage its account.
+type serverRow struct {
+	*core.Exchange
+	export  decredmaterial.Button
+	disable decredmaterial.Button
+}
+
+// settingsView lists the registered DEX servers and holds the actions that
+// manage the DEX accounts and the DEX client itself.
+type settingsView struct {
+	*load.Load
+
+	// serversChanged is called after a server was added, imported or
+	// disabled.
+	serversChanged func()
+
+	servers    []*serverRow
+	changed    chan struct{}
+	container  *widget.List
+	addDex     decredmaterial.Button
+	importAcct decredmaterial.Button
+	changePass decredmaterial.Button
+	reset      decredmaterial.Button
+}
+
+func newSettingsView(l *load.Load, serversChanged func()) *settingsView {
+	sv := &settingsView{
+		Load:           l,
+		serversChanged: serversChanged,
+		changed:        make(chan struct{}, 1),
+		container:      &widget.List{List: layout.List{Axis: layout.Vertical}},
+		addDex:         l.Theme.OutlineButton("Add a dex"),
+		importAcct:     l.Theme.OutlineButton("Import account"),
+		changePass:     l.Theme.OutlineButton("Change app password"),
+		reset:          l.Theme.DangerButton("Reset DEX client"),
+	}
+	return sv
+}
+
+// refresh reloads the registered DEX servers.
+func (sv *settingsView) refresh() {
+	exchanges := sv.Dexc().DEXServers()
+	servers := make([]*serverRow, 0, len(exchanges))
+	for _, dex := range exchanges {
+		servers = append(servers, &serverRow{
+			Exchange: dex,
+			export:   sv.Theme.OutlineButton("Export account"),
+			disable:  sv.Theme.OutlineButton("Disable"),
+		})
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Host < servers[j].Host })
+	sv.servers = servers
+}
+
+// notifyChanged schedules a refresh of the servers list. It is safe to call
+// from any goroutine.
+func (sv *settingsView) notifyChanged() {
+	select {
+	case sv.changed <- struct{}{}:
+	default:
+	}
+	sv.RefreshWindow()
+}
+
+func (sv *settingsView) onServersChanged() {
+	sv.notifyChanged()
+	if sv.serversChanged != nil {
+		sv.serversChanged()
+	}
+}
+
+func (sv *settingsView) handle() {
+	select {
+	case <-sv.changed:
+		sv.refresh()
+	default:
+	}
+
+	for _, row := range sv.servers {
+		if row.export.Clicked() {
+			sv.exportAccount(row.Host)
+		}
+		if row.disable.Clicked() {
+			sv.disableAccount(row.Host)
+		}
+	}
+
+	if sv.addDex.Clicked() {
+		newAddDexModal(sv.Load).Show()
+	}
+
+	if sv.importAcct.Clicked() {
+		newImportAccountModal(sv.Load, sv.onServersChanged).Show()
+	}
+
+	if sv.changePass.Clicked() {
+		sv.changeAppPassword()
+	}
+
+	if sv.reset.Clicked() {
+		ShowResetModal(sv.Load)
+	}
+}
+
+func (sv *settingsView) exportAccount(host string) {
+	modal.NewPasswordModal(sv.Load).
+		Title("Export account").
+		Description(fmt.Sprintf("The account of %s is written to a file with its private key. Keep the file safe, anyone with it can use your account.", host)).
+		Hint("App password").
+		NegativeButton(values.String(values.StrCancel), func() {}).
+		PositiveButton("Export", func(password string, pm *modal.PasswordModal) bool {
+			go func() {
+				acct, err := sv.Dexc().Core().AccountExport([]byte(password), host)
+				if err != nil {
+					pm.SetError(err.Error())
+					pm.SetLoading(false)
+					return
+				}
+
+				path, err := sv.WL.Wallet.ExportDexAccount(acct)
+				if err != nil {
+					pm.SetError(err.Error())
+					pm.SetLoading(false)
+					return
+				}
+				pm.Dismiss()
+				sv.Toast.Notify(fmt.Sprintf("Account exported to %s", path))
+			}()
+			return false
+		}).Show()
+}
+
+// disableAccount disables the account of a DEX server, which removes the
+// server from the client. The account can be restored later from a backup.
+func (sv *settingsView) disableAccount(host string) {
+	modal.NewPasswordModal(sv.Load).
+		Title("Disable account").
+		Description(fmt.Sprintf("%s will be removed from the DEX client. Export the account first to be able to restore it later. Accounts with active orders cannot be disabled.", host)).
+		Hint("App password").
+		NegativeButton(values.String(values.StrCancel), func() {}).
+		PositiveButton("Disable", func(password string, pm *modal.PasswordModal) bool {
+			go func() {
+				if err := sv.Dexc().Core().AccountDisable([]byte(password), host); err != nil {
+					pm.SetError(err.Error())
+					pm.SetLoading(false)
+					return
+				}
+				sv.WL.Wallet.RemoveDexServer(host)
+				pm.Dismiss()
+				sv.onServersChanged()
+			}()
+			return false
+		}).Show()
+}
+
+func (sv *settingsView) changeAppPassword() {
+	modal.NewPasswordModal(sv.Load).
+		Title("Change app password").
+		Hint("Current app password").
+		NegativeButton(values.String(values.StrCancel), func() {}).
+		PositiveButton(values.String(values.StrConfirm), func(password string, pm *modal.PasswordModal) bool {
+			// The current password is checked by ChangeAppPass.
+			pm.Dismiss()
+			modal.NewCreatePasswordModal(sv.Load).
+				Title("New app password").
+				PasswordHint("New app password").
+				ConfirmPasswordHint("Confirm new app password").
+				PasswordCreated(func(_, newPassword string, m *modal.CreatePasswordModal) bool {
+					go func() {
+						err := sv.Dexc().Core().ChangeAppPass([]byte(password), []byte(newPassword))
+						if err != nil {
+							m.SetError(err.Error())
+							m.SetLoading(false)
+							return
+						}
+						m.Dismiss()
+						sv.Toast.Notify("App password changed")
+					}()
+					return false
+				}).Show()
+			return false
+		}).Show()
+}
+
+// ShowResetModal asks for a confirmation before resetting the DEX client,
+// which deletes all the DEX data. It needs neither the login nor the app
+// password, so that a forgotten app password can be reset. The reset is
+// refused while logged in with active orders, since their swaps could not be
+// completed. Without the login the active orders cannot be resumed anyway,
+// they are counted in the warning instead.
+func ShowResetModal(l *load.Load) {
+	loggedIn := l.Dexc().Initialized() && l.Dexc().IsLoggedIn()
+	active := 0
+	if l.Dexc().Initialized() {
+		orders, err := l.Dexc().SelectOrders(&core.OrderFilter{})
+		if err != nil {
+			l.Toast.NotifyError(err.Error())
+			return
+		}
+		for _, ord := range orders {
+			if isOpenOrder(ord) {
+				active++
+			}
+		}
+	}
+	if loggedIn && active > 0 {
+		l.Toast.NotifyError("There are active orders. Wait for them to complete or cancel them before resetting.")
+		return
+	}
+
+	body := "All the DEX data, including the accounts of the registered servers and the app password, will be deleted. " +
+		"Export the accounts first to be able to restore them. You may need to restart godcr before you can use the DEX again."
+	if active > 0 {
+		body += fmt.Sprintf("\n\n%d active orders will be lost, their swaps cannot be completed without the app password.", active)
+	}
+
+	modal.NewInfoModal(l).
+		Title("Reset DEX client").
+		Body(body).
+		NegativeButton(values.String(values.StrCancel), func() {}).
+		PositiveButtonStyle(l.Theme.Color.Surface, l.Theme.Color.Danger).
+		PositiveButton("Reset", func() {
+			go func() {
+				if l.Dexc().Reset() {
+					l.Toast.Notify("DEX client data reset complete.")
+				} else {
+					l.Toast.NotifyError("DEX client data reset failed. Check the logs.")
+				}
+			}()
+		}).Show()
+}
+
+func (sv *settingsView) layout(gtx C) D {
+	sections := []layout.Widget{
+		func(gtx C) D {
+			return layout.Flex{}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, sv.addDex.Layout)
+				}),
+				layout.Rigid(func(gtx C) D {
+					return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, sv.importAcct.Layout)
+				}),
+				layout.Rigid(func(gtx C) D {
+					return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, sv.changePass.Layout)
+				}),
+				layout.Rigid(sv.reset.Layout),
+			)
+		},
+	}
+	for _, row := range sv.servers {
+		row := row
+		sections = append(sections, func(gtx C) D {
+			return sv.serverLayout(gtx, row)
+		})
+	}
+
+	return sv.Theme.List(sv.container).Layout(gtx, len(sections), func(gtx C, i int) D {
+		return layout.Inset{Bottom: values.MarginPadding16}.Layout(gtx, sections[i])
+	})
+}
+
+func (sv *settingsView) serverLayout(gtx C, row *serverRow) D {
+	return sv.Theme.Card().Layout(gtx, func(gtx C) D {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		return layout.UniformInset(values.MarginPadding16).Layout(gtx, func(gtx C) D {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+						layout.Rigid(func(gtx C) D {
+							lbl := sv.Theme.Body1(row.Host)
+							lbl.Font.Weight = text.SemiBold
+							return lbl.Layout(gtx)
+						}),
+						layout.Flexed(1, func(gtx C) D {
+							return layout.E.Layout(gtx, func(gtx C) D {
+								status := "Disconnected"
+								if row.Connected {
+									status = "Connected"
+								}
+								lbl := sv.Theme.Body2(status)
+								lbl.Color = sv.Theme.Color.GrayText2
+								return lbl.Layout(gtx)
+							})
+						}),
+					)
+				}),
+				layout.Rigid(func(gtx C) D {
+					info := sv.WL.Wallet.DexServers()[row.Host]
+					return layout.Inset{Top: values.MarginPadding8}.Layout(gtx, func(gtx C) D {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(sv.detailRow("Account", row.AcctID)),
+							layout.Rigid(sv.detailRow("Certificate", certStatus(info))),
+							layout.Rigid(sv.detailRow("Fee asset", feeAsset(row.Exchange, info))),
+						)
+					})
+				}),
+				layout.Rigid(func(gtx C) D {
+					return layout.Inset{Top: values.MarginPadding16}.Layout(gtx, func(gtx C) D {
+						return layout.Flex{}.Layout(gtx,
+							layout.Rigid(func(gtx C) D {
+								return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, row.export.Layout)
+							}),
+							layout.Rigid(row.disable.Layout),
+						)
+					})
+				}),
+			)
+		})
+	})
+}
+
+func (sv *settingsView) detailRow(label, value string) layout.Widget {
+	return func(gtx C) D {
+		return layout.Inset{Bottom: values.MarginPadding4}.Layout(gtx, func(gtx C) D {
+			return layout.Flex{Spacing: layout.SpaceBetween}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					lbl := sv.Theme.Body2(label)
+					lbl.Color = sv.Theme.Color.GrayText2
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(sv.Theme.Body2(value).Layout),
+			)
+		})
+	}
+}
+
+// certStatus describes the TLS certificate used to connect to a DEX server.
+func certStatus(info *wallet.DexServer) string {
+	switch {
+	case info == nil:
+		return "Unknown"
+	case len(info.Cert) == 0:
+		return "None (system CA)"
+	default:
+		return fmt.Sprintf("Custom, %d bytes", len(info.Cert))
+	}
+}
+
+// feeAsset returns the asset the registration fee of a DEX server was paid
+// with, or is being paid with.
+func feeAsset(dex *core.Exchange, info *wallet.DexServer) string {
+	switch {
+	case dex.PendingFee != nil:
+		return fmt.Sprintf("%s (pending, %d confirmations)", strings.ToUpper(dex.PendingFee.Symbol), dex.PendingFee.Confs)
+	case info != nil && info.FeeAsset != "":
+		return strings.ToUpper(info.FeeAsset)
+	default:
+		return "Unknown"
+	}
+}
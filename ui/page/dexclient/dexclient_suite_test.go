@@ -0,0 +1,13 @@
+package dexclient
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestDexClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "DexClient Suite")
+}
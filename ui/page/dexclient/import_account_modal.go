@@ -0,0 +1,156 @@
+package dexclient
+
+import (
+	"encoding/hex"
+
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+const dexImportAccountModalID = "dex_import_account_modal"
+
+// importAccountModal restores a DEX account from a backup made with the
+// export account action.
+type importAccountModal struct {
+	*load.Load
+
+	imported func()
+
+	modal          *decredmaterial.Modal
+	backupEditor   decredmaterial.Editor
+	appPassword    decredmaterial.Editor
+	submit         decredmaterial.Button
+	cancel         decredmaterial.Button
+	materialLoader material.LoaderStyle
+	isSending      bool
+	importError    string
+}
+
+func newImportAccountModal(l *load.Load, imported func()) *importAccountModal {
+	md := &importAccountModal{
+		Load:           l,
+		imported:       imported,
+		modal:          l.Theme.ModalFloatTitle(),
+		backupEditor:   l.Theme.Editor(new(widget.Editor), "Backup file path or account JSON"),
+		appPassword:    l.Theme.EditorPassword(new(widget.Editor), "App Password"),
+		submit:         l.Theme.Button("Import"),
+		cancel:         l.Theme.OutlineButton("Cancel"),
+		materialLoader: material.Loader(l.Theme.Base),
+	}
+	md.appPassword.Editor.SingleLine = true
+
+	return md
+}
+
+func (md *importAccountModal) ModalID() string {
+	return dexImportAccountModalID
+}
+
+func (md *importAccountModal) Show() {
+	md.ShowModal(md)
+}
+
+func (md *importAccountModal) Dismiss() {
+	md.DismissModal(md)
+}
+
+func (md *importAccountModal) OnDismiss() {}
+
+func (md *importAccountModal) OnResume() {}
+
+func (md *importAccountModal) Handle() {
+	if md.cancel.Clicked() && !md.isSending {
+		md.Dismiss()
+	}
+
+	_, changed := decredmaterial.HandleEditorEvents(md.backupEditor.Editor, md.appPassword.Editor)
+	if changed {
+		md.importError = ""
+	}
+
+	if md.submit.Clicked() && !md.isSending {
+		acct, err := wallet.ReadDexAccount(md.backupEditor.Editor.Text())
+		if err != nil {
+			md.importError = err.Error()
+			return
+		}
+
+		appPass := []byte(md.appPassword.Editor.Text())
+		if len(appPass) == 0 {
+			md.importError = "enter the app password"
+			return
+		}
+
+		md.isSending = true
+		md.modal.SetDisabled(true)
+		go func() {
+			defer func() {
+				md.isSending = false
+				md.modal.SetDisabled(false)
+			}()
+
+			if err := md.Dexc().Core().AccountImport(appPass, *acct); err != nil {
+				md.importError = err.Error()
+				md.RefreshWindow()
+				return
+			}
+
+			cert, _ := hex.DecodeString(acct.Cert)
+			md.WL.Wallet.SaveDexServer(&wallet.DexServer{Host: acct.Host, Cert: cert})
+
+			md.Toast.Notify("DEX account imported")
+			md.Dismiss()
+			if md.imported != nil {
+				md.imported()
+			}
+		}()
+	}
+}
+
+func (md *importAccountModal) Layout(gtx layout.Context) D {
+	w := []layout.Widget{
+		func(gtx C) D {
+			t := md.Theme.H6("Import DEX account")
+			t.Font.Weight = text.SemiBold
+			return t.Layout(gtx)
+		},
+		func(gtx C) D {
+			lbl := md.Theme.Body2("Restore a DEX account from an exported backup. The account is added with the server it was registered on.")
+			lbl.Color = md.Theme.Color.GrayText2
+			return lbl.Layout(gtx)
+		},
+		func(gtx C) D {
+			gtx.Constraints.Max.Y = gtx.Px(values.MarginPadding150)
+			return md.backupEditor.Layout(gtx)
+		},
+		md.appPassword.Layout,
+		func(gtx C) D {
+			if md.importError == "" {
+				return D{}
+			}
+			return md.Theme.ErrorLabel(md.importError).Layout(gtx)
+		},
+		func(gtx C) D {
+			return layout.E.Layout(gtx, func(gtx C) D {
+				if md.isSending {
+					return md.materialLoader.Layout(gtx)
+				}
+				return layout.Flex{}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, md.cancel.Layout)
+					}),
+					layout.Rigid(md.submit.Layout),
+				)
+			})
+		},
+	}
+
+	return md.modal.Layout(gtx, w)
+}
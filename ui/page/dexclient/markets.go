@@ -3,10 +3,14 @@ package dexclient
 import (
 	"context"
 	"fmt"
+	"image/color"
+	"sort"
+	"strings"
 
 	"decred.org/dcrdex/client/core"
-	"decred.org/dcrdex/client/db"
 	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
 
 	"github.com/planetdecred/godcr/ui/decredmaterial"
 	"github.com/planetdecred/godcr/ui/load"
@@ -22,6 +26,29 @@ type (
 
 const MarketPageID = "Markets"
 
+// maxBookRows is the number of orders shown for each side of the book.
+const maxBookRows = 15
+
+// dexMarket is a market of a DEX server.
+type dexMarket struct {
+	host string
+	*core.Market
+}
+
+// base returns the symbol of the base asset of the market.
+func (m *dexMarket) base() string {
+	return strings.ToUpper(m.Market.BaseSymbol)
+}
+
+// quote returns the symbol of the quote asset of the market.
+func (m *dexMarket) quote() string {
+	return strings.ToUpper(m.Market.QuoteSymbol)
+}
+
+func (m *dexMarket) String() string {
+	return fmt.Sprintf("%s %s-%s", m.host, m.base(), m.quote())
+}
+
 type Page struct {
 	*load.Load
 	ctx        context.Context
@@ -30,26 +57,54 @@ type Page struct {
 	initialize decredmaterial.Button
 	addDex     decredmaterial.Button
 	sync       decredmaterial.Button
-}
 
-// TODO: Add collapsible button to select a market.
-// Use mktName="DCR-BTC" in the meantime.
-func (pg *Page) dexMarket(mktName string) *core.Market {
-	dex := pg.dex()
-	if dex == nil {
-		return nil
-	}
-	return dex.Markets[mktName]
+	markets        []*dexMarket
+	marketDropDown *decredmaterial.DropDown
+	marketsChanged chan struct{}
+
+	selectedMarket *dexMarket
+	book           *orderBook
+	bookCancel     context.CancelFunc
+	depthChart     *depthChart
+	bidsList       *layout.List
+	asksList       *layout.List
+	tradesList     *layout.List
+
+	orderForm       *orderForm
+	ordersView      *ordersView
+	ordersChanged   chan struct{}
+	scrollContainer *widget.List
+
+	tabSwitch         *decredmaterial.SwitchButtonText
+	walletsView       *walletsView
+	settingsView      *settingsView
+	notificationsView *notificationsView
 }
 
 func NewMarketPage(l *load.Load) *Page {
 	pg := &Page{
-		Load:       l,
-		login:      l.Theme.Button("Login"),
-		initialize: l.Theme.Button("Start using now"),
-		addDex:     l.Theme.Button("Add a dex"),
-		sync:       l.Theme.Button("Start sync to continue"),
+		Load:           l,
+		login:          l.Theme.Button("Login"),
+		initialize:     l.Theme.Button("Start using now"),
+		addDex:         l.Theme.Button("Add a dex"),
+		sync:           l.Theme.Button("Start sync to continue"),
+		marketsChanged: make(chan struct{}, 1),
+		book:           newOrderBook(),
+		depthChart:     newDepthChart(l.Theme, values.MarginPadding150),
+		bidsList:       &layout.List{Axis: layout.Vertical},
+		asksList:       &layout.List{Axis: layout.Vertical},
+		tradesList:     &layout.List{Axis: layout.Vertical},
+		ordersView:     newOrdersView(l),
+		ordersChanged:  make(chan struct{}, 1),
+		scrollContainer: &widget.List{
+			List: layout.List{Axis: layout.Vertical},
+		},
+		tabSwitch:         l.Theme.SwitchButtonText([]decredmaterial.SwitchItem{{Text: "Markets"}, {Text: "Wallets"}, {Text: "Settings"}, {Text: "Notifications"}}),
+		walletsView:       newWalletsView(l),
+		notificationsView: newNotificationsView(l),
 	}
+	pg.settingsView = newSettingsView(l, pg.notifyMarketsChanged)
+	pg.orderForm = newOrderForm(l, pg.ordersView.refresh)
 
 	return pg
 }
@@ -68,6 +123,12 @@ func (pg *Page) Layout(gtx C) D {
 	var body func(gtx C) D
 
 	switch {
+	case pg.showWallets():
+		body = pg.walletsView.layout
+	case pg.showSettings():
+		body = pg.settingsView.layout
+	case pg.showNotifications():
+		body = pg.notificationsView.layout
 	case !pg.WL.MultiWallet.IsConnectedToDecredNetwork():
 		body = func(gtx C) D {
 			return pg.pageSections(gtx, func(gtx C) D {
@@ -92,15 +153,58 @@ func (pg *Page) Layout(gtx C) D {
 				return pg.welcomeLayout(gtx, pg.addDex)
 			})
 		}
+	case pg.selectedMarket == nil:
+		body = func(gtx C) D {
+			return pg.pageSections(gtx, func(gtx C) D {
+				return pg.registrationStatusLayout(gtx, pg.dex())
+			})
+		}
 	default:
+		body = pg.marketLayout
+	}
+
+	if pg.Dexc().IsLoggedIn() {
+		content := body
 		body = func(gtx C) D {
-			return pg.pageSections(gtx, pg.registrationStatusLayout)
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					return layout.Inset{Bottom: values.MarginPadding16}.Layout(gtx, func(gtx C) D {
+						return layout.E.Layout(gtx, pg.tabSwitch.Layout)
+					})
+				}),
+				layout.Flexed(1, content),
+			)
 		}
 	}
 
 	return components.UniformPadding(gtx, body)
 }
 
+// showWallets checks if the wallets tab is selected.
+func (pg *Page) showWallets() bool {
+	return pg.Dexc().IsLoggedIn() && pg.tabSwitch.SelectedIndex() == 2
+}
+
+// showSettings checks if the settings tab is selected.
+func (pg *Page) showSettings() bool {
+	return pg.Dexc().IsLoggedIn() && pg.tabSwitch.SelectedIndex() == 3
+}
+
+// showNotifications checks if the notifications tab is selected.
+func (pg *Page) showNotifications() bool {
+	return pg.Dexc().IsLoggedIn() && pg.tabSwitch.SelectedIndex() == 4
+}
+
+// notifyMarketsChanged schedules an update of the markets. It is safe to
+// call from any goroutine.
+func (pg *Page) notifyMarketsChanged() {
+	select {
+	case pg.marketsChanged <- struct{}{}:
+	default:
+	}
+	pg.RefreshWindow()
+}
+
 func (pg *Page) pageSections(gtx layout.Context, body layout.Widget) layout.Dimensions {
 	return pg.Theme.Card().Layout(gtx, func(gtx C) D {
 		gtx.Constraints.Min.X = gtx.Constraints.Max.X
@@ -133,15 +237,13 @@ func (pg *Page) dex() *core.Exchange {
 	return nil
 }
 
-func (pg *Page) registrationStatusLayout(gtx C) D {
-	dex := pg.dex()
+func (pg *Page) registrationStatusLayout(gtx C, dex *core.Exchange) D {
 	if !dex.Connected {
 		// TODO: render error or UI to connect to dex
 		return pg.Theme.Label(values.TextSize14, fmt.Sprintf("%s not connected yet", dex.Host)).Layout(gtx)
 	}
 
 	if dex.PendingFee == nil {
-		// TODO: render trade UI
 		return pg.Theme.Label(values.TextSize14, "Registration fee payment successful!").Layout(gtx)
 	}
 
@@ -166,6 +268,13 @@ func (pg *Page) registrationStatusLayout(gtx C) D {
 func (pg *Page) OnNavigatedTo() {
 	pg.ctx, pg.ctxCancel = context.WithCancel(context.TODO())
 	go pg.readNotifications()
+
+	pg.updateMarkets()
+	// Resubscribe to the book of the market that was displayed before
+	// navigating away.
+	if pg.selectedMarket != nil && pg.bookCancel == nil {
+		pg.selectMarket(pg.selectedMarket)
+	}
 }
 
 // OnNavigatedFrom is called when the page is about to be removed from
@@ -177,6 +286,7 @@ func (pg *Page) OnNavigatedTo() {
 // Part of the load.Page interface.
 func (pg *Page) OnNavigatedFrom() {
 	pg.ctxCancel()
+	pg.bookCancel = nil
 }
 
 // HandleUserInteractions is called just before Layout() to determine
@@ -185,8 +295,52 @@ func (pg *Page) OnNavigatedFrom() {
 // displayed.
 // Part of the load.Page interface.
 func (pg *Page) HandleUserInteractions() {
+	select {
+	case <-pg.marketsChanged:
+		pg.updateMarkets()
+	default:
+	}
+
+	select {
+	case <-pg.ordersChanged:
+		pg.ordersView.refresh()
+	default:
+	}
+
+	if pg.tabSwitch.Changed() {
+		if pg.showWallets() {
+			pg.walletsView.refresh()
+		}
+		if pg.showSettings() {
+			pg.settingsView.refresh()
+		}
+	}
+
+	if pg.showWallets() {
+		pg.walletsView.handle()
+	}
+
+	if pg.showSettings() {
+		pg.settingsView.handle()
+	}
+
+	if pg.showNotifications() {
+		pg.notificationsView.handle()
+	}
+
+	if pg.marketDropDown != nil {
+		for pg.marketDropDown.Changed() {
+			pg.selectMarket(pg.markets[pg.marketDropDown.SelectedIndex()])
+		}
+	}
+
+	if pg.selectedMarket != nil {
+		pg.orderForm.handle()
+		pg.ordersView.handle()
+	}
+
 	if pg.sync.Button.Clicked() {
-		err := pg.WL.MultiWallet.SpvSync()
+		err := pg.WL.Wallet.StartSync()
 		if err != nil {
 			pg.Toast.NotifyError(err.Error())
 		}
@@ -253,16 +407,285 @@ func (pg *Page) readNotifications() {
 	for {
 		select {
 		case n := <-ch:
-			if n.Type() == core.NoteTypeFeePayment {
+			switch n.Type() {
+			case core.NoteTypeFeePayment, core.NoteTypeConnEvent:
+				pg.notifyMarketsChanged()
+				pg.settingsView.notifyChanged()
+			case core.NoteTypeOrder, core.NoteTypeMatch:
+				select {
+				case pg.ordersChanged <- struct{}{}:
+				default:
+				}
 				pg.RefreshWindow()
+			case core.NoteTypeBalance, core.NoteTypeWalletState, core.NoteTypeWalletConfig:
+				pg.walletsView.notifyChanged()
 			}
 
-			if n.Severity() > db.Success {
-				pg.Toast.NotifyError(n.Details())
+		case <-pg.ctx.Done():
+			return
+		}
+	}
+}
+
+// updateMarkets lists the markets of the connected DEX servers, and
+// recreates the market dropdown if they changed. The first market is
+// selected if the selected market is no longer available.
+func (pg *Page) updateMarkets() {
+	var markets []*dexMarket
+	for host, dex := range pg.Dexc().DEXServers() {
+		if !dex.Connected {
+			continue
+		}
+		for _, mkt := range dex.Markets {
+			markets = append(markets, &dexMarket{host: host, Market: mkt})
+		}
+	}
+	sort.Slice(markets, func(i, j int) bool {
+		return markets[i].String() < markets[j].String()
+	})
+
+	if sameMarkets(markets, pg.markets) {
+		return
+	}
+	pg.markets = markets
+
+	if len(markets) == 0 {
+		pg.marketDropDown = nil
+		pg.selectMarket(nil)
+		return
+	}
+
+	selected := 0
+	if pg.selectedMarket != nil {
+		for i, mkt := range markets {
+			if mkt.String() == pg.selectedMarket.String() {
+				selected = i
+				break
 			}
+		}
+	}
 
-		case <-pg.ctx.Done():
+	items := make([]decredmaterial.DropDownItem, 0, len(markets))
+	for _, mkt := range markets {
+		items = append(items, decredmaterial.DropDownItem{Text: mkt.String()})
+	}
+	pg.marketDropDown = pg.Theme.DropDown(items, values.DexMarketDropdownGroup, 0)
+	pg.marketDropDown.SetSelectedIndex(selected)
+	pg.selectMarket(markets[selected])
+}
+
+func sameMarkets(a, b []*dexMarket) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].String() != b[i].String() {
+			return false
+		}
+	}
+	return true
+}
+
+// selectMarket displays the order book of the market, replacing the book of
+// the previously selected market.
+func (pg *Page) selectMarket(mkt *dexMarket) {
+	if pg.bookCancel != nil {
+		pg.bookCancel()
+		pg.bookCancel = nil
+	}
+
+	pg.selectedMarket = mkt
+	pg.book = newOrderBook()
+	if mkt == nil {
+		return
+	}
+
+	pg.orderForm.setMarket(mkt, pg.book)
+	pg.ordersView.setMarket(mkt)
+
+	ctx, cancel := context.WithCancel(pg.ctx)
+	pg.bookCancel = cancel
+	go pg.syncBook(ctx, mkt, pg.book)
+}
+
+// syncBook keeps the book up to date with the book feed of the market until
+// ctx is canceled.
+func (pg *Page) syncBook(ctx context.Context, mkt *dexMarket, book *orderBook) {
+	feed, err := pg.Dexc().Core().SyncBook(mkt.host, mkt.BaseID, mkt.QuoteID)
+	if err != nil {
+		pg.Toast.NotifyError(fmt.Sprintf("Error syncing %s order book: %v", mkt, err))
+		return
+	}
+	defer feed.Close()
+
+	for {
+		select {
+		case u := <-feed.Next():
+			if book.apply(u) {
+				pg.RefreshWindow()
+			}
+		case <-ctx.Done():
 			return
 		}
 	}
 }
+
+func (pg *Page) marketLayout(gtx C) D {
+	dex := pg.Dexc().DEXServers()[pg.selectedMarket.host]
+
+	sections := []layout.Widget{
+		func(gtx C) D {
+			if dex == nil || dex.PendingFee == nil {
+				return D{}
+			}
+			return layout.Inset{Bottom: values.MarginPadding16}.Layout(gtx, func(gtx C) D {
+				return pg.pageSections(gtx, func(gtx C) D {
+					return pg.registrationStatusLayout(gtx, dex)
+				})
+			})
+		},
+		func(gtx C) D {
+			return layout.Flex{}.Layout(gtx,
+				layout.Flexed(1, func(gtx C) D {
+					return pg.pageSections(gtx, pg.orderForm.layout)
+				}),
+				layout.Rigid(layout.Spacer{Width: values.MarginPadding16}.Layout),
+				layout.Flexed(2, func(gtx C) D {
+					return pg.pageSections(gtx, func(gtx C) D {
+						bids, asks := pg.book.depth()
+						return pg.depthChart.layout(gtx, bids, asks)
+					})
+				}),
+			)
+		},
+		func(gtx C) D {
+			return layout.Inset{Top: values.MarginPadding16}.Layout(gtx, func(gtx C) D {
+				return layout.Flex{}.Layout(gtx,
+					layout.Flexed(2, func(gtx C) D {
+						return pg.pageSections(gtx, pg.orderBookLayout)
+					}),
+					layout.Rigid(layout.Spacer{Width: values.MarginPadding16}.Layout),
+					layout.Flexed(1, func(gtx C) D {
+						return pg.pageSections(gtx, pg.recentTradesLayout)
+					}),
+				)
+			})
+		},
+		func(gtx C) D {
+			return layout.Inset{Top: values.MarginPadding16}.Layout(gtx, func(gtx C) D {
+				return pg.pageSections(gtx, pg.ordersView.layout)
+			})
+		},
+	}
+
+	return layout.Stack{}.Layout(gtx,
+		layout.Expanded(func(gtx C) D {
+			return layout.Inset{Top: values.MarginPadding60}.Layout(gtx, func(gtx C) D {
+				return pg.Theme.List(pg.scrollContainer).Layout(gtx, len(sections), func(gtx C, i int) D {
+					return sections[i](gtx)
+				})
+			})
+		}),
+		layout.Expanded(func(gtx C) D {
+			return pg.marketDropDown.Layout(gtx, 0, false)
+		}),
+	)
+}
+
+func (pg *Page) orderBookLayout(gtx C) D {
+	buys, sells := pg.book.sides()
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(pg.sectionTitle("Order Book")),
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{}.Layout(gtx,
+				layout.Flexed(1, func(gtx C) D {
+					return pg.bookSideLayout(gtx, pg.bidsList, "Bids", buys, pg.Theme.Color.Success)
+				}),
+				layout.Rigid(layout.Spacer{Width: values.MarginPadding16}.Layout),
+				layout.Flexed(1, func(gtx C) D {
+					return pg.bookSideLayout(gtx, pg.asksList, "Asks", sells, pg.Theme.Color.Danger)
+				}),
+			)
+		}),
+	)
+}
+
+func (pg *Page) bookSideLayout(gtx C, list *layout.List, title string, orders []*core.MiniOrder, col color.NRGBA) D {
+	if len(orders) > maxBookRows {
+		orders = orders[:maxBookRows]
+	}
+
+	mkt := pg.selectedMarket
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			rate := fmt.Sprintf("%s (%s)", title, mkt.quote())
+			return pg.tableRow(gtx, pg.Theme.Color.GrayText2, rate, fmt.Sprintf("Quantity (%s)", mkt.base()))
+		}),
+		layout.Rigid(func(gtx C) D {
+			if len(orders) == 0 {
+				return pg.Theme.Body2("No orders").Layout(gtx)
+			}
+			return list.Layout(gtx, len(orders), func(gtx C, i int) D {
+				return pg.tableRow(gtx, col, formatRate(orders[i].Rate), formatQty(orders[i].Qty))
+			})
+		}),
+	)
+}
+
+func (pg *Page) recentTradesLayout(gtx C) D {
+	trades := pg.book.recentTrades()
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(pg.sectionTitle("Recent Trades")),
+		layout.Rigid(func(gtx C) D {
+			return pg.tableRow(gtx, pg.Theme.Color.GrayText2, "Rate", "Quantity", "Time")
+		}),
+		layout.Rigid(func(gtx C) D {
+			if len(trades) == 0 {
+				return pg.Theme.Body2("No trades yet").Layout(gtx)
+			}
+			if len(trades) > maxBookRows {
+				trades = trades[:maxBookRows]
+			}
+			return pg.tradesList.Layout(gtx, len(trades), func(gtx C, i int) D {
+				t := trades[i]
+				col := pg.Theme.Color.Success
+				if t.Sell {
+					col = pg.Theme.Color.Danger
+				}
+				return pg.tableRow(gtx, col, formatRate(t.Rate), formatQty(t.Qty), t.Stamp.Format("15:04:05"))
+			})
+		}),
+	)
+}
+
+func (pg *Page) sectionTitle(title string) layout.Widget {
+	return func(gtx C) D {
+		lbl := pg.Theme.Body1(title)
+		lbl.Font.Weight = text.SemiBold
+		return layout.Inset{Bottom: values.MarginPadding8}.Layout(gtx, lbl.Layout)
+	}
+}
+
+// tableRow lays out the cells in columns of equal width, the first column
+// in col.
+func (pg *Page) tableRow(gtx C, col color.NRGBA, cells ...string) D {
+	children := make([]layout.FlexChild, 0, len(cells))
+	for i, cell := range cells {
+		lbl := pg.Theme.Body2(cell)
+		if i == 0 {
+			lbl.Color = col
+		}
+		children = append(children, layout.Flexed(1, lbl.Layout))
+	}
+	return layout.Inset{Bottom: values.MarginPadding4}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{}.Layout(gtx, children...)
+	})
+}
+
+func formatRate(rate float64) string {
+	return fmt.Sprintf("%.8f", rate)
+}
+
+func formatQty(qty float64) string {
+	return fmt.Sprintf("%.4f", qty)
+}
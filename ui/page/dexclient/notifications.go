@@ -0,0 +1,172 @@
+package dexclient
+
+import (
+	"fmt"
+	"sync"
+
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/db"
+
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/notification"
+)
+
+// NotificationCategory is a group of DEX notifications whose delivery can be
+// switched on or off.
+type NotificationCategory struct {
+	Title     string
+	ConfigKey string
+	// critical categories always deliver their warnings and errors, even
+	// when switched off.
+	critical bool
+}
+
+var (
+	tradeNotifications      = &NotificationCategory{Title: "DEX trades", ConfigKey: load.DexTradeNotificationConfigKey}
+	swapNotifications       = &NotificationCategory{Title: "DEX swaps and redemptions", ConfigKey: load.DexSwapNotificationConfigKey}
+	refundNotifications     = &NotificationCategory{Title: "DEX refunds and revocations", ConfigKey: load.DexRefundNotificationConfigKey, critical: true}
+	connectionNotifications = &NotificationCategory{Title: "DEX server connectivity", ConfigKey: load.DexConnectionNotificationConfigKey, critical: true}
+
+	// otherNotifications holds the warnings and errors that are not part of
+	// any other category. They are always delivered.
+	otherNotifications = &NotificationCategory{Title: "DEX", critical: true}
+)
+
+// NotificationCategories are the DEX notification categories that can be
+// switched on or off from the settings.
+var NotificationCategories = []*NotificationCategory{
+	tradeNotifications,
+	swapNotifications,
+	refundNotifications,
+	connectionNotifications,
+}
+
+// Enabled checks if the notifications of the category are delivered. They
+// are unless switched off.
+func (nc *NotificationCategory) Enabled(l *load.Load) bool {
+	if nc.ConfigKey == "" {
+		return true
+	}
+	return l.WL.MultiWallet.ReadBoolConfigValueForKey(nc.ConfigKey, true)
+}
+
+var swapTopics = map[core.Topic]bool{
+	core.TopicSwapSendError:       true,
+	core.TopicInitError:           true,
+	core.TopicSwapsInitiated:      true,
+	core.TopicRedemptionError:     true,
+	core.TopicReportRedeemError:   true,
+	core.TopicMatchComplete:       true,
+	core.TopicMatchRecovered:      true,
+	core.TopicMatchErrorCoin:      true,
+	core.TopicMatchErrorContract:  true,
+	core.TopicMatchesMade:         true,
+	core.TopicMissingMatches:      true,
+	core.TopicOrderCoinError:      true,
+	core.TopicOrderCoinFetchError: true,
+}
+
+var refundTopics = map[core.Topic]bool{
+	core.TopicRefundFailure:        true,
+	core.TopicMatchesRefunded:      true,
+	core.TopicMatchRevoked:         true,
+	core.TopicOrderRevoked:         true,
+	core.TopicOrderAutoRevoked:     true,
+	core.TopicMatchResolutionError: true,
+	core.TopicMatchRecoveryError:   true,
+}
+
+// notificationCategory returns the category of a DEX notification, or nil
+// if the notification is not delivered to the user.
+func notificationCategory(n core.Notification) *NotificationCategory {
+	switch {
+	case refundTopics[n.Topic()]:
+		return refundNotifications
+	case n.Type() == core.NoteTypeMatch || swapTopics[n.Topic()]:
+		return swapNotifications
+	case n.Type() == core.NoteTypeOrder:
+		return tradeNotifications
+	case n.Type() == core.NoteTypeConnEvent || n.Type() == core.NoteTypeDEXAuth ||
+		n.Type() == core.NoteTypeServerNotify || n.Type() == core.NoteTypeFeePayment:
+		return connectionNotifications
+	case n.Severity() >= db.WarningLevel:
+		return otherNotifications
+	}
+	return nil
+}
+
+var (
+	notificationsMtx  sync.Mutex
+	notificationsCore *core.Core
+)
+
+// ListenForNotifications routes the notifications of the running DEX client
+// core to the toast, the desktop and the notification center, for as long as
+// the core runs. It does nothing if the core is not started or its
+// notifications are already routed.
+func ListenForNotifications(l *load.Load) {
+	c := l.Dexc().Core()
+
+	notificationsMtx.Lock()
+	defer notificationsMtx.Unlock()
+	if c == nil || c == notificationsCore {
+		return
+	}
+	notificationsCore = c
+
+	feed := c.NotificationFeed()
+	go func() {
+		for n := range feed {
+			postNotification(l, n)
+		}
+	}()
+}
+
+func postNotification(l *load.Load, n core.Notification) {
+	// Notifications below Poke only carry data for the UI.
+	if n.Severity() < db.Poke {
+		return
+	}
+
+	category := notificationCategory(n)
+	if category == nil {
+		return
+	}
+
+	isError := n.Severity() >= db.WarningLevel
+	l.Notifications.Add(notification.Item{
+		Category: category.Title,
+		Title:    n.Subject(),
+		Details:  n.Details(),
+		IsError:  isError,
+	})
+
+	// The warnings and errors of critical categories, e.g. a failed refund,
+	// are delivered even when the category is switched off.
+	if !category.Enabled(l) && !(category.critical && isError) {
+		l.RefreshWindow()
+		return
+	}
+
+	if isError {
+		l.Toast.NotifyError(n.Subject())
+	} else {
+		l.Toast.Notify(n.Subject())
+	}
+	l.RefreshWindow()
+
+	// Poke notifications are transient, only the lasting ones are posted to
+	// the desktop.
+	if n.Severity() >= db.Success {
+		systemNotification, err := notification.NewSystemNotification()
+		if err != nil {
+			log.Errorf("could not initiate desktop notification: %v", err)
+			return
+		}
+
+		err = systemNotification.Notify(fmt.Sprintf("%s\n%s", n.Subject(), n.Details()))
+		if err != nil {
+			log.Info("could not initiate desktop notification, reason:", err.Error())
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package dexclient
+
+import (
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/values"
+)
+
+// notificationsView is the notification center, it lists the recent DEX
+// notifications.
+type notificationsView struct {
+	*load.Load
+
+	container *widget.List
+	clear     decredmaterial.Button
+}
+
+func newNotificationsView(l *load.Load) *notificationsView {
+	return &notificationsView{
+		Load:      l,
+		container: &widget.List{List: layout.List{Axis: layout.Vertical}},
+		clear:     l.Theme.OutlineButton("Clear"),
+	}
+}
+
+func (nv *notificationsView) handle() {
+	if nv.clear.Clicked() {
+		nv.Notifications.Clear()
+	}
+}
+
+func (nv *notificationsView) layout(gtx C) D {
+	items := nv.Notifications.Items()
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			if len(items) == 0 {
+				lbl := nv.Theme.Body2("No notifications yet")
+				lbl.Color = nv.Theme.Color.GrayText2
+				return lbl.Layout(gtx)
+			}
+			return layout.Inset{Bottom: values.MarginPadding16}.Layout(gtx, nv.clear.Layout)
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return nv.Theme.List(nv.container).Layout(gtx, len(items), func(gtx C, i int) D {
+				item := items[i]
+				return layout.Inset{Bottom: values.MarginPadding8}.Layout(gtx, func(gtx C) D {
+					return nv.Theme.Card().Layout(gtx, func(gtx C) D {
+						gtx.Constraints.Min.X = gtx.Constraints.Max.X
+						return layout.UniformInset(values.MarginPadding16).Layout(gtx, func(gtx C) D {
+							return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+								layout.Rigid(func(gtx C) D {
+									return layout.Flex{}.Layout(gtx,
+										layout.Rigid(func(gtx C) D {
+											lbl := nv.Theme.Body1(item.Title)
+											lbl.Font.Weight = text.SemiBold
+											if item.IsError {
+												lbl.Color = nv.Theme.Color.Danger
+											}
+											return lbl.Layout(gtx)
+										}),
+										layout.Flexed(1, func(gtx C) D {
+											return layout.E.Layout(gtx, func(gtx C) D {
+												lbl := nv.Theme.Caption(item.Category + " · " + item.Time.Format("Jan 2 15:04:05"))
+												lbl.Color = nv.Theme.Color.GrayText2
+												return lbl.Layout(gtx)
+											})
+										}),
+									)
+								}),
+								layout.Rigid(func(gtx C) D {
+									if item.Details == "" {
+										return D{}
+									}
+									return layout.Inset{Top: values.MarginPadding4}.Layout(gtx, nv.Theme.Body2(item.Details).Layout)
+								}),
+							)
+						})
+					})
+				})
+			})
+		}),
+	)
+}
@@ -0,0 +1,172 @@
+package dexclient
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/client/core"
+)
+
+// maxRecentTrades is the number of fills kept in the recent trades list.
+const maxRecentTrades = 50
+
+// recentTrade is a fill of a booked order, seen as a decrease of the
+// remaining quantity of the order.
+type recentTrade struct {
+	Rate  float64
+	Qty   float64
+	Sell  bool
+	Stamp time.Time
+}
+
+// depthPoint is the cumulative quantity of one side of the book at a rate.
+type depthPoint struct {
+	Rate float64
+	Qty  float64
+}
+
+// orderBook is a local copy of the order book of a market, kept up to date
+// with the updates of a core.BookFeed. The orders in the book are never
+// modified, updates replace them.
+type orderBook struct {
+	mu     sync.Mutex
+	orders map[string]*core.MiniOrder // keyed by token
+	trades []*recentTrade             // newest first
+}
+
+func newOrderBook() *orderBook {
+	return &orderBook{
+		orders: make(map[string]*core.MiniOrder),
+	}
+}
+
+// reset replaces the orders with the ones of a fresh book.
+func (ob *orderBook) reset(book *core.OrderBook) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	ob.orders = make(map[string]*core.MiniOrder)
+	ob.trades = nil
+	if book == nil {
+		return
+	}
+	for _, side := range [][]*core.MiniOrder{book.Buys, book.Sells} {
+		for _, o := range side {
+			ob.orders[o.Token] = o
+		}
+	}
+}
+
+// apply updates the book with an update of the book feed. It returns false
+// for updates that do not change the book, such as epoch orders and candles.
+func (ob *orderBook) apply(u *core.BookUpdate) bool {
+	switch u.Action {
+	case core.FreshBookAction:
+		mob, ok := u.Payload.(*core.MarketOrderBook)
+		if !ok {
+			return false
+		}
+		ob.reset(mob.Book)
+		return true
+
+	case core.BookOrderAction:
+		o, ok := u.Payload.(*core.MiniOrder)
+		if !ok {
+			return false
+		}
+		ob.mu.Lock()
+		ob.orders[o.Token] = o
+		ob.mu.Unlock()
+		return true
+
+	case core.UnbookOrderAction:
+		o, ok := u.Payload.(*core.MiniOrder)
+		if !ok {
+			return false
+		}
+		ob.mu.Lock()
+		delete(ob.orders, o.Token)
+		ob.mu.Unlock()
+		return true
+
+	case core.UpdateRemainingAction:
+		r, ok := u.Payload.(*core.RemainderUpdate)
+		if !ok {
+			return false
+		}
+		ob.mu.Lock()
+		defer ob.mu.Unlock()
+		o, found := ob.orders[r.Token]
+		if !found {
+			return false
+		}
+
+		if filled := o.Qty - r.Qty; filled > 0 {
+			ob.trades = append([]*recentTrade{{
+				Rate:  o.Rate,
+				Qty:   filled,
+				Sell:  o.Sell,
+				Stamp: time.Now(),
+			}}, ob.trades...)
+			if len(ob.trades) > maxRecentTrades {
+				ob.trades = ob.trades[:maxRecentTrades]
+			}
+		}
+		// The order is replaced rather than changed, the layout reads the
+		// orders returned by sides without the lock.
+		updated := *o
+		updated.Qty, updated.QtyAtomic = r.Qty, r.QtyAtomic
+		ob.orders[r.Token] = &updated
+		return true
+	}
+
+	return false
+}
+
+// sides returns the buy orders, best (highest) rate first, and the sell
+// orders, best (lowest) rate first.
+func (ob *orderBook) sides() (buys, sells []*core.MiniOrder) {
+	ob.mu.Lock()
+	for _, o := range ob.orders {
+		if o.Sell {
+			sells = append(sells, o)
+		} else {
+			buys = append(buys, o)
+		}
+	}
+	ob.mu.Unlock()
+
+	sort.Slice(buys, func(i, j int) bool { return buys[i].Rate > buys[j].Rate })
+	sort.Slice(sells, func(i, j int) bool { return sells[i].Rate < sells[j].Rate })
+	return buys, sells
+}
+
+// depth returns the cumulative quantities of both sides of the book, from
+// the best rate outwards.
+func (ob *orderBook) depth() (bids, asks []depthPoint) {
+	buys, sells := ob.sides()
+	cumulate := func(orders []*core.MiniOrder) []depthPoint {
+		points := make([]depthPoint, 0, len(orders))
+		var total float64
+		for _, o := range orders {
+			total += o.Qty
+			if n := len(points); n > 0 && points[n-1].Rate == o.Rate {
+				points[n-1].Qty = total
+				continue
+			}
+			points = append(points, depthPoint{Rate: o.Rate, Qty: total})
+		}
+		return points
+	}
+	return cumulate(buys), cumulate(sells)
+}
+
+// recentTrades returns the fills seen on the book, newest first.
+func (ob *orderBook) recentTrades() []*recentTrade {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	trades := make([]*recentTrade, len(ob.trades))
+	copy(trades, ob.trades)
+	return trades
+}
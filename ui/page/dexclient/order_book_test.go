@@ -0,0 +1,122 @@
+package dexclient
+
+import (
+	"decred.org/dcrdex/client/core"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+func bookOrder(token string, rate, qty float64, sell bool) *core.MiniOrder {
+	return &core.MiniOrder{Token: token, Rate: rate, Qty: qty, Sell: sell}
+}
+
+func freshBook() *orderBook {
+	ob := newOrderBook()
+	ob.reset(&core.OrderBook{
+		Buys: []*core.MiniOrder{
+			bookOrder("b1", 0.010, 5, false),
+			bookOrder("b2", 0.012, 2, false),
+			bookOrder("b3", 0.010, 1, false),
+		},
+		Sells: []*core.MiniOrder{
+			bookOrder("s1", 0.015, 3, true),
+			bookOrder("s2", 0.013, 4, true),
+		},
+	})
+	return ob
+}
+
+// rates returns the rates of the orders, in order.
+func rates(orders []*core.MiniOrder) []float64 {
+	r := make([]float64, len(orders))
+	for i, o := range orders {
+		r[i] = o.Rate
+	}
+	return r
+}
+
+var _ = Describe("orderBook", func() {
+	table.DescribeTable("apply",
+		func(update *core.BookUpdate, changed bool, buys, sells []float64, trades int) {
+			ob := freshBook()
+			Expect(ob.apply(update)).To(Equal(changed))
+
+			b, s := ob.sides()
+			Expect(rates(b)).To(Equal(buys))
+			Expect(rates(s)).To(Equal(sells))
+			Expect(ob.recentTrades()).To(HaveLen(trades))
+		},
+		table.Entry("books an order",
+			&core.BookUpdate{Action: core.BookOrderAction, Payload: bookOrder("b4", 0.011, 1, false)},
+			true, []float64{0.012, 0.011, 0.010, 0.010}, []float64{0.013, 0.015}, 0),
+		table.Entry("unbooks an order",
+			&core.BookUpdate{Action: core.UnbookOrderAction, Payload: bookOrder("s2", 0.013, 4, true)},
+			true, []float64{0.012, 0.010, 0.010}, []float64{0.015}, 0),
+		table.Entry("records a fill",
+			&core.BookUpdate{Action: core.UpdateRemainingAction, Payload: &core.RemainderUpdate{Token: "s1", Qty: 1}},
+			true, []float64{0.012, 0.010, 0.010}, []float64{0.013, 0.015}, 1),
+		table.Entry("ignores the remainder of an unknown order",
+			&core.BookUpdate{Action: core.UpdateRemainingAction, Payload: &core.RemainderUpdate{Token: "x", Qty: 1}},
+			false, []float64{0.012, 0.010, 0.010}, []float64{0.013, 0.015}, 0),
+		table.Entry("replaces the book",
+			&core.BookUpdate{Action: core.FreshBookAction, Payload: &core.MarketOrderBook{Book: &core.OrderBook{
+				Sells: []*core.MiniOrder{bookOrder("s9", 0.020, 1, true)},
+			}}},
+			true, []float64{}, []float64{0.020}, 0),
+		table.Entry("ignores epoch orders",
+			&core.BookUpdate{Action: core.EpochOrderAction, Payload: bookOrder("e1", 0.011, 1, false)},
+			false, []float64{0.012, 0.010, 0.010}, []float64{0.013, 0.015}, 0),
+		table.Entry("ignores a payload of the wrong type",
+			&core.BookUpdate{Action: core.BookOrderAction, Payload: &core.RemainderUpdate{Token: "b1"}},
+			false, []float64{0.012, 0.010, 0.010}, []float64{0.013, 0.015}, 0),
+	)
+
+	It("does not change the orders returned by sides", func() {
+		ob := freshBook()
+		_, sells := ob.sides()
+		Expect(ob.apply(&core.BookUpdate{
+			Action:  core.UpdateRemainingAction,
+			Payload: &core.RemainderUpdate{Token: "s2", Qty: 1},
+		})).To(BeTrue())
+
+		Expect(sells[0].Token).To(Equal("s2"))
+		Expect(sells[0].Qty).To(Equal(4.0))
+		_, sells = ob.sides()
+		Expect(sells[0].Qty).To(Equal(1.0))
+
+		trades := ob.recentTrades()
+		Expect(trades).To(HaveLen(1))
+		Expect(trades[0].Rate).To(Equal(0.013))
+		Expect(trades[0].Qty).To(Equal(3.0))
+		Expect(trades[0].Sell).To(BeTrue())
+	})
+
+	table.DescribeTable("depth",
+		func(book *core.OrderBook, bids, asks []depthPoint) {
+			ob := newOrderBook()
+			ob.reset(book)
+			b, a := ob.depth()
+			Expect(b).To(Equal(bids))
+			Expect(a).To(Equal(asks))
+		},
+		table.Entry("of an empty book", &core.OrderBook{}, []depthPoint{}, []depthPoint{}),
+		table.Entry("cumulates from the best rate outwards", &core.OrderBook{
+			Buys: []*core.MiniOrder{
+				bookOrder("b1", 0.010, 5, false),
+				bookOrder("b2", 0.012, 2, false),
+			},
+			Sells: []*core.MiniOrder{
+				bookOrder("s1", 0.015, 3, true),
+				bookOrder("s2", 0.013, 4, true),
+			},
+		}, []depthPoint{{0.012, 2}, {0.010, 7}}, []depthPoint{{0.013, 4}, {0.015, 7}}),
+		table.Entry("merges orders at the same rate", &core.OrderBook{
+			Buys: []*core.MiniOrder{
+				bookOrder("b1", 0.010, 5, false),
+				bookOrder("b2", 0.010, 1, false),
+				bookOrder("b3", 0.012, 2, false),
+			},
+		}, []depthPoint{{0.012, 2}, {0.010, 8}}, []depthPoint{}),
+	)
+})
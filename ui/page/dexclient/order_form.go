@@ -0,0 +1,392 @@
+package dexclient
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/dex/calc"
+	"gioui.org/layout"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/dcrlibwallet"
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/modal"
+	"github.com/planetdecred/godcr/ui/values"
+)
+
+// defaultConversionFactor is used for assets whose unit info is not known,
+// it is the conversion factor of DCR and BTC.
+const defaultConversionFactor = 1e8
+
+// orderForm is the form to place limit and market orders on a market.
+type orderForm struct {
+	*load.Load
+
+	market *dexMarket
+	book   *orderBook
+	// orderPlaced is called after an order was placed, to refresh the
+	// orders list.
+	orderPlaced func()
+
+	sideSwitch *decredmaterial.SwitchButtonText
+	typeSwitch *decredmaterial.SwitchButtonText
+	rateEditor decredmaterial.Editor
+	qtyEditor  decredmaterial.Editor
+	submitBtn  decredmaterial.Button
+	formError  string
+
+	// estimatesMu guards the estimates, which are fetched in the
+	// background. estimatesSeq numbers the fetches so that a slow fetch
+	// does not overwrite the estimates of a newer one.
+	estimatesMu  sync.Mutex
+	estimatesSeq int
+	maxEstimate  string
+	feeEstimate  string
+}
+
+func newOrderForm(l *load.Load, orderPlaced func()) *orderForm {
+	of := &orderForm{
+		Load:        l,
+		orderPlaced: orderPlaced,
+		sideSwitch:  l.Theme.SwitchButtonText([]decredmaterial.SwitchItem{{Text: "Buy"}, {Text: "Sell"}}),
+		typeSwitch:  l.Theme.SwitchButtonText([]decredmaterial.SwitchItem{{Text: "Limit"}, {Text: "Market"}}),
+		submitBtn:   l.Theme.Button("Place order"),
+	}
+
+	of.rateEditor = l.Theme.Editor(new(widget.Editor), "Price")
+	of.rateEditor.Editor.SingleLine = true
+	of.qtyEditor = l.Theme.Editor(new(widget.Editor), "Quantity")
+	of.qtyEditor.Editor.SingleLine = true
+
+	return of
+}
+
+func (of *orderForm) isSell() bool {
+	return of.sideSwitch.SelectedIndex() == 2
+}
+
+func (of *orderForm) isLimit() bool {
+	return of.typeSwitch.SelectedIndex() == 1
+}
+
+// setMarket clears the form for a newly selected market.
+func (of *orderForm) setMarket(mkt *dexMarket, book *orderBook) {
+	of.market, of.book = mkt, book
+	of.rateEditor.Editor.SetText("")
+	of.qtyEditor.Editor.SetText("")
+	of.formError = ""
+	of.updateHints()
+	of.updateEstimates()
+}
+
+func (of *orderForm) updateHints() {
+	if of.market == nil {
+		return
+	}
+
+	of.rateEditor.Hint = fmt.Sprintf("Price (%s/%s)", of.market.quote(), of.market.base())
+	// Market buy orders are sized in the quote asset.
+	qtySymbol := of.market.base()
+	if !of.isSell() && !of.isLimit() {
+		qtySymbol = of.market.quote()
+	}
+	of.qtyEditor.Hint = fmt.Sprintf("Quantity (%s)", qtySymbol)
+}
+
+func (of *orderForm) handle() {
+	if of.market == nil {
+		return
+	}
+
+	if of.sideSwitch.Changed() || of.typeSwitch.Changed() {
+		of.formError = ""
+		of.updateHints()
+		of.updateEstimates()
+	}
+
+	_, changed := decredmaterial.HandleEditorEvents(of.rateEditor.Editor, of.qtyEditor.Editor)
+	if changed {
+		of.formError = ""
+		of.updateEstimates()
+	}
+
+	if of.submitBtn.Clicked() {
+		order, err := of.freshOrder()
+		if err != nil {
+			of.formError = err.Error()
+			return
+		}
+		of.confirmOrder(order)
+	}
+}
+
+// freshOrder validates the form against the lot size and rate step of the
+// market, and converts it to an order.
+func (of *orderForm) freshOrder() (*dcrlibwallet.FreshOrder, error) {
+	mkt := of.market
+	baseFactor, quoteFactor := of.conversionFactors()
+	order := &dcrlibwallet.FreshOrder{
+		Sell:         of.isSell(),
+		BaseAssetID:  mkt.BaseID,
+		QuoteAssetID: mkt.QuoteID,
+		IsLimit:      of.isLimit(),
+	}
+
+	qty, err := strconv.ParseFloat(of.qtyEditor.Editor.Text(), 64)
+	if err != nil || qty <= 0 {
+		return nil, errors.New("enter a valid quantity")
+	}
+
+	if !order.Sell && !order.IsLimit {
+		// Market buys are sized in the quote asset, the server checks
+		// that they buy at least one lot.
+		order.Qty = uint64(math.Round(qty * float64(quoteFactor)))
+		return order, nil
+	}
+
+	order.Qty = uint64(math.Round(qty * float64(baseFactor)))
+	if order.Qty == 0 || order.Qty%mkt.LotSize != 0 {
+		return nil, fmt.Errorf("quantity must be a multiple of the lot size, %s %s",
+			formatAtoms(mkt.LotSize, baseFactor), mkt.base())
+	}
+
+	if !order.IsLimit {
+		return order, nil
+	}
+
+	rate, err := strconv.ParseFloat(of.rateEditor.Editor.Text(), 64)
+	if err != nil || rate <= 0 {
+		return nil, errors.New("enter a valid price")
+	}
+	order.Rate = messageRate(rate, baseFactor, quoteFactor)
+	if order.Rate == 0 || order.Rate%mkt.RateStep != 0 {
+		return nil, fmt.Errorf("price must be a multiple of the rate step, %s %s/%s",
+			strconv.FormatFloat(calc.ConventionalRateAlt(mkt.RateStep, baseFactor, quoteFactor), 'f', -1, 64),
+			mkt.quote(), mkt.base())
+	}
+	return order, nil
+}
+
+// updateEstimates fetches the maximum order size and, for a valid form, the
+// fees of the order from the dcrdex core.
+func (of *orderForm) updateEstimates() {
+	mkt := of.market
+	sell, isLimit := of.isSell(), of.isLimit()
+	order, orderErr := of.freshOrder()
+	baseFactor, quoteFactor := of.conversionFactors()
+
+	rate := uint64(0)
+	switch {
+	case isLimit && order != nil:
+		rate = order.Rate
+	case !sell:
+		// Market buys are estimated at the best sell rate.
+		if _, sells := of.book.sides(); len(sells) > 0 {
+			rate = sells[0].MsgRate
+		}
+	}
+
+	of.estimatesMu.Lock()
+	of.estimatesSeq++
+	seq := of.estimatesSeq
+	of.maxEstimate, of.feeEstimate = "", ""
+	of.estimatesMu.Unlock()
+
+	go func() {
+		dexCore := of.Dexc().Core()
+
+		var maxEstimate, feeEstimate string
+		var max *core.MaxOrderEstimate
+		var err error
+		switch {
+		case sell:
+			max, err = dexCore.MaxSell(mkt.host, mkt.BaseID, mkt.QuoteID)
+		case rate > 0:
+			max, err = dexCore.MaxBuy(mkt.host, mkt.BaseID, mkt.QuoteID, rate)
+		}
+
+		switch {
+		case err != nil:
+			maxEstimate = err.Error()
+		case max != nil:
+			side := "buy"
+			if sell {
+				side = "sell"
+			}
+			maxEstimate = fmt.Sprintf("Max %s: %d lots (%s %s)", side, max.Swap.Lots,
+				formatAtoms(max.Swap.Lots*mkt.LotSize, baseFactor), mkt.base())
+		}
+
+		if orderErr == nil {
+			estimate, err := dexCore.PreOrder(&core.TradeForm{
+				Host:    mkt.host,
+				IsLimit: order.IsLimit,
+				Sell:    order.Sell,
+				Base:    order.BaseAssetID,
+				Quote:   order.QuoteAssetID,
+				Qty:     order.Qty,
+				Rate:    order.Rate,
+				TifNow:  order.TifNow,
+			})
+			if err != nil {
+				feeEstimate = err.Error()
+			} else {
+				fromSymbol, fromFactor, toSymbol, toFactor := mkt.quote(), quoteFactor, mkt.base(), baseFactor
+				if sell {
+					fromSymbol, fromFactor, toSymbol, toFactor = toSymbol, toFactor, fromSymbol, fromFactor
+				}
+				feeEstimate = fmt.Sprintf("Estimated fees: up to %s %s to swap, %s %s to redeem",
+					formatAtoms(estimate.Swap.Estimate.RealisticWorstCase, fromFactor), fromSymbol,
+					formatAtoms(estimate.Redeem.Estimate.RealisticWorstCase, toFactor), toSymbol)
+			}
+		}
+
+		of.estimatesMu.Lock()
+		defer of.estimatesMu.Unlock()
+		if seq != of.estimatesSeq {
+			// The form changed while fetching.
+			return
+		}
+		of.maxEstimate, of.feeEstimate = maxEstimate, feeEstimate
+		of.RefreshWindow()
+	}()
+}
+
+// confirmOrder asks for the app password to place the order.
+func (of *orderForm) confirmOrder(order *dcrlibwallet.FreshOrder) {
+	mkt := of.market
+	baseFactor, quoteFactor := of.conversionFactors()
+
+	side := "buy"
+	if order.Sell {
+		side = "sell"
+	}
+	var description string
+	switch {
+	case order.IsLimit:
+		description = fmt.Sprintf("Place a limit %s order for %s %s at %s %s/%s on %s?", side,
+			formatAtoms(order.Qty, baseFactor), mkt.base(),
+			strconv.FormatFloat(calc.ConventionalRateAlt(order.Rate, baseFactor, quoteFactor), 'f', -1, 64),
+			mkt.quote(), mkt.base(), mkt.host)
+	case order.Sell:
+		description = fmt.Sprintf("Place a market sell order for %s %s on %s?",
+			formatAtoms(order.Qty, baseFactor), mkt.base(), mkt.host)
+	default:
+		description = fmt.Sprintf("Place a market buy order with %s %s on %s?",
+			formatAtoms(order.Qty, quoteFactor), mkt.quote(), mkt.host)
+	}
+	if _, feeEstimate := of.estimates(); feeEstimate != "" {
+		description += "\n\n" + feeEstimate
+	}
+
+	modal.NewPasswordModal(of.Load).
+		Title("Confirm order").
+		Description(description).
+		Hint("App password").
+		NegativeButton(values.String(values.StrCancel), func() {}).
+		PositiveButton("Place order", func(password string, pm *modal.PasswordModal) bool {
+			go func() {
+				_, err := of.Dexc().PlaceOrderWithServer(mkt.host, order, []byte(password))
+				if err != nil {
+					pm.SetError(err.Error())
+					pm.SetLoading(false)
+					return
+				}
+				pm.Dismiss()
+				of.Toast.Notify("Order placed")
+				of.qtyEditor.Editor.SetText("")
+				if of.orderPlaced != nil {
+					of.orderPlaced()
+				}
+			}()
+			return false
+		}).Show()
+}
+
+// estimates returns the last fetched maximum order size and fees.
+func (of *orderForm) estimates() (maxEstimate, feeEstimate string) {
+	of.estimatesMu.Lock()
+	defer of.estimatesMu.Unlock()
+	return of.maxEstimate, of.feeEstimate
+}
+
+// conversionFactors returns the conventional conversion factors of the base
+// and quote assets of the market.
+func (of *orderForm) conversionFactors() (base, quote uint64) {
+	return conversionFactor(of.Load, of.market.host, of.market.BaseID),
+		conversionFactor(of.Load, of.market.host, of.market.QuoteID)
+}
+
+func (of *orderForm) layout(gtx C) D {
+	maxEstimate, feeEstimate := of.estimates()
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{}.Layout(gtx,
+				layout.Rigid(of.sideSwitch.Layout),
+				layout.Rigid(layout.Spacer{Width: values.MarginPadding8}.Layout),
+				layout.Rigid(of.typeSwitch.Layout),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			if !of.isLimit() {
+				return D{}
+			}
+			return layout.Inset{Top: values.MarginPadding8}.Layout(gtx, of.rateEditor.Layout)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{Top: values.MarginPadding8}.Layout(gtx, of.qtyEditor.Layout)
+		}),
+		layout.Rigid(of.noteLayout(maxEstimate)),
+		layout.Rigid(of.noteLayout(feeEstimate)),
+		layout.Rigid(func(gtx C) D {
+			if of.formError == "" {
+				return D{}
+			}
+			return layout.Inset{Top: values.MarginPadding8}.Layout(gtx, of.Theme.ErrorLabel(of.formError).Layout)
+		}),
+		layout.Rigid(func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			return layout.Inset{Top: values.MarginPadding16}.Layout(gtx, of.submitBtn.Layout)
+		}),
+	)
+}
+
+func (of *orderForm) noteLayout(note string) layout.Widget {
+	return func(gtx C) D {
+		if note == "" {
+			return D{}
+		}
+		lbl := of.Theme.Caption(note)
+		lbl.Color = of.Theme.Color.GrayText2
+		return layout.Inset{Top: values.MarginPadding4}.Layout(gtx, lbl.Layout)
+	}
+}
+
+// conversionFactor returns the conventional conversion factor of an asset
+// of the DEX server.
+func conversionFactor(l *load.Load, host string, assetID uint32) uint64 {
+	dex := l.Dexc().DEXServers()[host]
+	if dex == nil {
+		return defaultConversionFactor
+	}
+	if a := dex.Assets[assetID]; a != nil && a.UnitInfo.Conventional.ConversionFactor > 0 {
+		return a.UnitInfo.Conventional.ConversionFactor
+	}
+	return defaultConversionFactor
+}
+
+// messageRate converts a conventional rate to the message-rate encoding of
+// the dcrdex protocol.
+func messageRate(rate float64, baseFactor, quoteFactor uint64) uint64 {
+	return uint64(math.Round(rate * calc.RateEncodingFactor * float64(quoteFactor) / float64(baseFactor)))
+}
+
+// formatAtoms formats an amount in atoms of an asset in conventional units.
+func formatAtoms(atoms, conversionFactor uint64) string {
+	return strconv.FormatFloat(float64(atoms)/float64(conversionFactor), 'f', -1, 64)
+}
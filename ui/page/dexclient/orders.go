@@ -0,0 +1,318 @@
+package dexclient
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/order"
+	"gioui.org/layout"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/modal"
+	"github.com/planetdecred/godcr/ui/values"
+)
+
+// maxHistoryOrders is the number of past orders shown in the order history.
+const maxHistoryOrders = 50
+
+// orderRow is an order of the orders list, with its buttons.
+type orderRow struct {
+	*core.Order
+	details *decredmaterial.Clickable
+	cancel  decredmaterial.Button
+}
+
+// ordersView lists the open orders or the order history of a market.
+type ordersView struct {
+	*load.Load
+
+	market    *dexMarket
+	tabSwitch *decredmaterial.SwitchButtonText
+	orders    []*orderRow
+	list      *layout.List
+}
+
+func newOrdersView(l *load.Load) *ordersView {
+	return &ordersView{
+		Load:      l,
+		tabSwitch: l.Theme.SwitchButtonText([]decredmaterial.SwitchItem{{Text: "Open orders"}, {Text: "Order history"}}),
+		list:      &layout.List{Axis: layout.Vertical},
+	}
+}
+
+func (ov *ordersView) showHistory() bool {
+	return ov.tabSwitch.SelectedIndex() == 2
+}
+
+func (ov *ordersView) setMarket(mkt *dexMarket) {
+	ov.market = mkt
+	ov.orders = nil
+	ov.refresh()
+}
+
+// refresh reloads the orders of the market from the dcrdex core.
+func (ov *ordersView) refresh() {
+	if ov.market == nil {
+		return
+	}
+
+	mkt, history := ov.market, ov.showHistory()
+	go func() {
+		orders, err := ov.Dexc().SelectOrders(&core.OrderFilter{
+			Hosts:  []string{mkt.host},
+			Assets: []uint32{mkt.BaseID, mkt.QuoteID},
+		})
+		if err != nil {
+			ov.Toast.NotifyError(err.Error())
+			return
+		}
+
+		rows := make([]*orderRow, 0)
+		for _, ord := range orders {
+			if ord.MarketID != mkt.Name || isOpenOrder(ord) == history {
+				continue
+			}
+			rows = append(rows, &orderRow{
+				Order:   ord,
+				details: ov.Theme.NewClickable(true),
+				cancel:  ov.Theme.OutlineButton("Cancel"),
+			})
+		}
+		sort.Slice(rows, func(i, j int) bool { return rows[i].Stamp > rows[j].Stamp })
+		if len(rows) > maxHistoryOrders {
+			rows = rows[:maxHistoryOrders]
+		}
+
+		ov.orders = rows
+		ov.RefreshWindow()
+	}()
+}
+
+// isOpenOrder checks if the order is in the book or epoch queue, or has
+// matches that are still being settled.
+func isOpenOrder(ord *core.Order) bool {
+	if ord.Status == order.OrderStatusEpoch || ord.Status == order.OrderStatusBooked {
+		return true
+	}
+	for _, match := range ord.Matches {
+		if match.Active {
+			return true
+		}
+	}
+	return false
+}
+
+// canCancel checks if the order is a standing limit order that can still be
+// canceled.
+func canCancel(ord *core.Order) bool {
+	return ord.Type == order.LimitOrderType && ord.TimeInForce == order.StandingTiF &&
+		ord.Status == order.OrderStatusBooked && !ord.Cancelling
+}
+
+func (ov *ordersView) handle() {
+	if ov.tabSwitch.Changed() {
+		ov.orders = nil
+		ov.refresh()
+	}
+
+	for _, row := range ov.orders {
+		if row.details.Clicked() {
+			ov.showOrderDetails(row.Order)
+		}
+		if row.cancel.Clicked() {
+			ov.cancelOrder(row.Order)
+		}
+	}
+}
+
+// cancelOrder asks for the app password to cancel the order.
+func (ov *ordersView) cancelOrder(ord *core.Order) {
+	modal.NewPasswordModal(ov.Load).
+		Title("Cancel order").
+		Description(fmt.Sprintf("Cancel the remaining %s %s of this order?",
+			formatAtoms(ord.Qty-ord.Filled, ov.baseFactor()), ov.market.base())).
+		Hint("App password").
+		NegativeButton(values.String(values.StrCancel), func() {}).
+		PositiveButton("Cancel order", func(password string, pm *modal.PasswordModal) bool {
+			go func() {
+				err := ov.Dexc().CancelOrder(ord.ID, []byte(password))
+				if err != nil {
+					pm.SetError(err.Error())
+					pm.SetLoading(false)
+					return
+				}
+				pm.Dismiss()
+				ov.Toast.Notify("Cancel order submitted")
+				ov.refresh()
+			}()
+			return false
+		}).Show()
+}
+
+// showOrderDetails lists the matches of the order with the status of their
+// swap, redeem and refund.
+func (ov *ordersView) showOrderDetails(ord *core.Order) {
+	list := &widget.List{List: layout.List{Axis: layout.Vertical}}
+	baseFactor, quoteFactor := ov.baseFactor(), ov.quoteFactor()
+
+	coin := func(c *core.Coin) string {
+		if c == nil {
+			return "-"
+		}
+		return c.StringID
+	}
+
+	modal.NewInfoModal(ov.Load).
+		Title(fmt.Sprintf("Order %s", orderToken(ord))).
+		UseCustomWidget(func(gtx C) D {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(ov.detailRow("Status", orderStatus(ord))),
+				layout.Rigid(ov.detailRow("Filled", ov.filledText(ord))),
+				layout.Rigid(func(gtx C) D {
+					if len(ord.Matches) == 0 {
+						return ov.Theme.Body2("No matches").Layout(gtx)
+					}
+
+					gtx.Constraints.Max.Y = gtx.Px(values.MarginPadding280)
+					return ov.Theme.List(list).Layout(gtx, len(ord.Matches), func(gtx C, i int) D {
+						match := ord.Matches[i]
+						status := match.Status.String()
+						if match.Revoked {
+							status += " (revoked)"
+						}
+						return layout.Inset{Top: values.MarginPadding8}.Layout(gtx, func(gtx C) D {
+							return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+								layout.Rigid(ov.detailRow(fmt.Sprintf("Match %d", i+1), fmt.Sprintf("%s, %s", match.Side, status))),
+								layout.Rigid(ov.detailRow("Quantity", fmt.Sprintf("%s %s at %s",
+									formatAtoms(match.Qty, baseFactor), ov.market.base(),
+									formatConventionalRate(match.Rate, baseFactor, quoteFactor)))),
+								layout.Rigid(ov.detailRow("Swap", coin(match.Swap))),
+								layout.Rigid(ov.detailRow("Redeem", coin(match.Redeem))),
+								layout.Rigid(ov.detailRow("Refund", coin(match.Refund))),
+							)
+						})
+					})
+				}),
+			)
+		}).
+		PositiveButton("Close", func() {}).
+		Show()
+}
+
+func (ov *ordersView) detailRow(label, value string) layout.Widget {
+	return func(gtx C) D {
+		return layout.Flex{Spacing: layout.SpaceBetween}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				lbl := ov.Theme.Body2(label)
+				lbl.Color = ov.Theme.Color.GrayText2
+				return lbl.Layout(gtx)
+			}),
+			layout.Rigid(ov.Theme.Body2(value).Layout),
+		)
+	}
+}
+
+// orderStatus describes the status of the order, including the settlement
+// of its matches.
+func orderStatus(ord *core.Order) string {
+	switch {
+	case ord.Cancelling:
+		return "cancelling"
+	case ord.Canceled:
+		return "canceled"
+	}
+
+	status := ord.Status.String()
+	for _, match := range ord.Matches {
+		if match.Active {
+			return status + ", settling"
+		}
+	}
+	return status
+}
+
+// filledText describes the filled quantity of the order, in the quote asset
+// for market buys and in the base asset otherwise.
+func (ov *ordersView) filledText(ord *core.Order) string {
+	factor, symbol := ov.baseFactor(), ov.market.base()
+	if ord.Type == order.MarketOrderType && !ord.Sell {
+		factor, symbol = ov.quoteFactor(), ov.market.quote()
+	}
+	return fmt.Sprintf("%s / %s %s", formatAtoms(ord.Filled, factor), formatAtoms(ord.Qty, factor), symbol)
+}
+
+// orderToken is the short form of the order ID shown to the user.
+func orderToken(ord *core.Order) string {
+	id := ord.ID.String()
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+func (ov *ordersView) baseFactor() uint64 {
+	return conversionFactor(ov.Load, ov.market.host, ov.market.BaseID)
+}
+
+func (ov *ordersView) quoteFactor() uint64 {
+	return conversionFactor(ov.Load, ov.market.host, ov.market.QuoteID)
+}
+
+func (ov *ordersView) layout(gtx C) D {
+	baseFactor, quoteFactor := ov.baseFactor(), ov.quoteFactor()
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{Bottom: values.MarginPadding8}.Layout(gtx, ov.tabSwitch.Layout)
+		}),
+		layout.Rigid(func(gtx C) D {
+			if len(ov.orders) == 0 {
+				return ov.Theme.Body2("No orders").Layout(gtx)
+			}
+
+			return ov.list.Layout(gtx, len(ov.orders), func(gtx C, i int) D {
+				row := ov.orders[i]
+				side, col := "Buy", ov.Theme.Color.Success
+				if row.Sell {
+					side, col = "Sell", ov.Theme.Color.Danger
+				}
+				rate := "market"
+				if row.Type == order.LimitOrderType {
+					rate = formatConventionalRate(row.Rate, baseFactor, quoteFactor)
+				}
+				stamp := time.Unix(int64(row.Stamp/1000), 0).Format("Jan 2 15:04")
+
+				return row.details.Layout(gtx, func(gtx C) D {
+					return layout.Inset{Top: values.MarginPadding4, Bottom: values.MarginPadding4}.Layout(gtx, func(gtx C) D {
+						return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+							layout.Flexed(1, func(gtx C) D {
+								lbl := ov.Theme.Body2(side)
+								lbl.Color = col
+								return lbl.Layout(gtx)
+							}),
+							layout.Flexed(1, ov.Theme.Body2(rate).Layout),
+							layout.Flexed(1, ov.Theme.Body2(ov.filledText(row.Order)).Layout),
+							layout.Flexed(1, ov.Theme.Body2(orderStatus(row.Order)).Layout),
+							layout.Flexed(1, ov.Theme.Body2(stamp).Layout),
+							layout.Rigid(func(gtx C) D {
+								if !canCancel(row.Order) {
+									return D{}
+								}
+								return row.cancel.Layout(gtx)
+							}),
+						)
+					})
+				})
+			})
+		}),
+	)
+}
+
+func formatConventionalRate(msgRate, baseFactor, quoteFactor uint64) string {
+	return formatRate(calc.ConventionalRateAlt(msgRate, baseFactor, quoteFactor))
+}
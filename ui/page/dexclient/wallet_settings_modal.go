@@ -0,0 +1,292 @@
+package dexclient
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/core"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/values"
+)
+
+const dexWalletSettingsModalID = "dex_wallet_settings_modal"
+
+// configOptionInput is the input of a wallet config option.
+type configOptionInput struct {
+	opt    *asset.ConfigOption
+	editor decredmaterial.Editor
+	check  *widget.Bool
+}
+
+// walletSettingsModal creates a DEX wallet of any of the available wallet
+// types of an asset, or reconfigures an existing one. The form is built from
+// the config options of the wallet type.
+type walletSettingsModal struct {
+	*load.Load
+
+	asset       *core.SupportedAsset
+	reconfigure bool
+	settings    map[string]string
+	saved       func()
+
+	modal          *decredmaterial.Modal
+	typeGroup      *widget.Enum
+	inputs         []*configOptionInput
+	inputsList     *widget.List
+	walletPassword decredmaterial.Editor
+	appPassword    decredmaterial.Editor
+	submit         decredmaterial.Button
+	cancel         decredmaterial.Button
+	materialLoader material.LoaderStyle
+	isSending      bool
+	settingsError  string
+}
+
+func newWalletSettingsModal(l *load.Load, a *core.SupportedAsset, reconfigure bool) *walletSettingsModal {
+	md := &walletSettingsModal{
+		Load:           l,
+		asset:          a,
+		reconfigure:    reconfigure,
+		modal:          l.Theme.ModalFloatTitle(),
+		typeGroup:      new(widget.Enum),
+		inputsList:     &widget.List{List: layout.List{Axis: layout.Vertical}},
+		walletPassword: l.Theme.EditorPassword(new(widget.Editor), "Wallet Password"),
+		appPassword:    l.Theme.EditorPassword(new(widget.Editor), "App Password"),
+		submit:         l.Theme.Button("Add"),
+		cancel:         l.Theme.OutlineButton("Cancel"),
+		materialLoader: material.Loader(l.Theme.Base),
+	}
+	md.walletPassword.Editor.SingleLine = true
+	md.appPassword.Editor.SingleLine = true
+
+	if len(a.Info.AvailableWallets) > 0 {
+		md.typeGroup.Value = a.Info.AvailableWallets[0].Type
+	}
+
+	if reconfigure {
+		md.submit.Text = "Save"
+		md.typeGroup.Value = a.Wallet.WalletType
+		settings, err := l.Dexc().Core().WalletSettings(a.ID)
+		if err != nil {
+			md.settingsError = err.Error()
+		}
+		md.settings = settings
+	}
+
+	md.buildInputs()
+	return md
+}
+
+// SettingsSaved sets the function called after the wallet was created or
+// reconfigured.
+func (md *walletSettingsModal) SettingsSaved(saved func()) *walletSettingsModal {
+	md.saved = saved
+	return md
+}
+
+func (md *walletSettingsModal) ModalID() string {
+	return dexWalletSettingsModalID
+}
+
+func (md *walletSettingsModal) Show() {
+	md.ShowModal(md)
+}
+
+func (md *walletSettingsModal) Dismiss() {
+	md.DismissModal(md)
+}
+
+func (md *walletSettingsModal) OnDismiss() {}
+
+func (md *walletSettingsModal) OnResume() {}
+
+// walletDefinition returns the definition of the selected wallet type.
+func (md *walletSettingsModal) walletDefinition() *asset.WalletDefinition {
+	for _, def := range md.asset.Info.AvailableWallets {
+		if def.Type == md.typeGroup.Value {
+			return def
+		}
+	}
+	return nil
+}
+
+// buildInputs creates the inputs of the config options of the selected
+// wallet type, filled with the current settings or the default values.
+func (md *walletSettingsModal) buildInputs() {
+	md.inputs = nil
+	def := md.walletDefinition()
+	if def == nil {
+		return
+	}
+
+	for _, opt := range def.ConfigOpts {
+		value, found := md.settings[strings.ToLower(opt.Key)]
+		if !found && opt.DefaultValue != nil {
+			value = fmt.Sprintf("%v", opt.DefaultValue)
+		}
+
+		input := &configOptionInput{opt: opt}
+		if opt.IsBoolean {
+			checked, _ := strconv.ParseBool(value)
+			input.check = &widget.Bool{Value: checked}
+		} else {
+			if opt.NoEcho {
+				input.editor = md.Theme.EditorPassword(new(widget.Editor), opt.DisplayName)
+			} else {
+				input.editor = md.Theme.Editor(new(widget.Editor), opt.DisplayName)
+			}
+			input.editor.Editor.SingleLine = true
+			input.editor.Editor.SetText(value)
+		}
+		md.inputs = append(md.inputs, input)
+	}
+}
+
+func (md *walletSettingsModal) Handle() {
+	if md.cancel.Clicked() && !md.isSending {
+		md.Dismiss()
+	}
+
+	if md.typeGroup.Changed() {
+		md.settingsError = ""
+		md.buildInputs()
+	}
+
+	if md.submit.Clicked() && !md.isSending {
+		def := md.walletDefinition()
+		appPass := []byte(md.appPassword.Editor.Text())
+		if def == nil || len(appPass) == 0 {
+			md.settingsError = "enter the app password"
+			return
+		}
+
+		settings := make(map[string]string)
+		for _, input := range md.inputs {
+			key := strings.ToLower(input.opt.Key)
+			if input.opt.IsBoolean {
+				settings[key] = strconv.FormatBool(input.check.Value)
+				continue
+			}
+			settings[key] = input.editor.Editor.Text()
+		}
+
+		var walletPass []byte
+		if !def.Seeded {
+			walletPass = []byte(md.walletPassword.Editor.Text())
+		}
+
+		md.isSending = true
+		md.modal.SetDisabled(true)
+		go func() {
+			defer func() {
+				md.isSending = false
+				md.modal.SetDisabled(false)
+			}()
+
+			var err error
+			if md.reconfigure {
+				err = md.Dexc().Core().ReconfigureWallet(appPass, walletPass, &core.WalletForm{
+					AssetID: md.asset.ID,
+					Config:  settings,
+					Type:    def.Type,
+				})
+			} else {
+				err = md.Dexc().AddWallet(md.asset.ID, def.Type, settings, appPass, walletPass)
+			}
+			if err != nil {
+				md.settingsError = err.Error()
+				md.RefreshWindow()
+				return
+			}
+
+			md.Dismiss()
+			if md.saved != nil {
+				md.saved()
+			}
+		}()
+	}
+}
+
+func (md *walletSettingsModal) Layout(gtx layout.Context) D {
+	def := md.walletDefinition()
+
+	w := []layout.Widget{
+		func(gtx C) D {
+			title := fmt.Sprintf("Add a %s wallet", md.asset.Info.Name)
+			if md.reconfigure {
+				title = fmt.Sprintf("%s wallet settings", md.asset.Info.Name)
+			}
+			t := md.Theme.H6(title)
+			t.Font.Weight = text.SemiBold
+			return t.Layout(gtx)
+		},
+		func(gtx C) D {
+			wallets := md.asset.Info.AvailableWallets
+			if len(wallets) < 2 {
+				return D{}
+			}
+			radios := make([]layout.FlexChild, 0, len(wallets))
+			for _, wallet := range wallets {
+				radios = append(radios, layout.Rigid(md.Theme.RadioButton(md.typeGroup, wallet.Type, wallet.Tab,
+					md.Theme.Color.DeepBlue, md.Theme.Color.Primary).Layout))
+			}
+			return layout.Flex{}.Layout(gtx, radios...)
+		},
+		func(gtx C) D {
+			if def == nil || def.Description == "" {
+				return D{}
+			}
+			lbl := md.Theme.Body2(def.Description)
+			lbl.Color = md.Theme.Color.GrayText2
+			return lbl.Layout(gtx)
+		},
+		func(gtx C) D {
+			gtx.Constraints.Max.Y = gtx.Px(values.MarginPadding280)
+			return md.Theme.List(md.inputsList).Layout(gtx, len(md.inputs), func(gtx C, i int) D {
+				input := md.inputs[i]
+				return layout.Inset{Bottom: values.MarginPadding8}.Layout(gtx, func(gtx C) D {
+					if input.opt.IsBoolean {
+						return md.Theme.CheckBox(input.check, input.opt.DisplayName).Layout(gtx)
+					}
+					return input.editor.Layout(gtx)
+				})
+			})
+		},
+		func(gtx C) D {
+			if def == nil || def.Seeded {
+				return D{}
+			}
+			return md.walletPassword.Layout(gtx)
+		},
+		md.appPassword.Layout,
+		func(gtx C) D {
+			if md.settingsError == "" {
+				return D{}
+			}
+			return md.Theme.ErrorLabel(md.settingsError).Layout(gtx)
+		},
+		func(gtx C) D {
+			return layout.E.Layout(gtx, func(gtx C) D {
+				if md.isSending {
+					return md.materialLoader.Layout(gtx)
+				}
+				return layout.Flex{}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, md.cancel.Layout)
+					}),
+					layout.Rigid(md.submit.Layout),
+				)
+			})
+		},
+	}
+
+	return md.modal.Layout(gtx, w)
+}
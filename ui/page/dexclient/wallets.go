@@ -0,0 +1,323 @@
+package dexclient
+
+import (
+	"fmt"
+	"sort"
+
+	"decred.org/dcrdex/client/asset/dcr"
+	_ "decred.org/dcrdex/client/asset/ltc" // register the ltc asset driver
+	"decred.org/dcrdex/client/core"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/modal"
+	"github.com/planetdecred/godcr/ui/page/components"
+	"github.com/planetdecred/godcr/ui/values"
+)
+
+// walletRow is a supported asset of the wallets list, with the buttons to
+// manage its DEX wallet.
+type walletRow struct {
+	*core.SupportedAsset
+	create      decredmaterial.Button
+	connect     decredmaterial.Button
+	deposit     decredmaterial.Button
+	withdraw    decredmaterial.Button
+	lockToggle  decredmaterial.Button
+	reconfigure decredmaterial.Button
+}
+
+// walletsView lists the DEX wallets of all supported assets.
+type walletsView struct {
+	*load.Load
+
+	wallets   []*walletRow
+	changed   chan struct{}
+	container *widget.List
+}
+
+func newWalletsView(l *load.Load) *walletsView {
+	return &walletsView{
+		Load:      l,
+		changed:   make(chan struct{}, 1),
+		container: &widget.List{List: layout.List{Axis: layout.Vertical}},
+	}
+}
+
+// notifyChanged schedules a refresh of the wallets list. It is safe to call
+// from any goroutine.
+func (wv *walletsView) notifyChanged() {
+	select {
+	case wv.changed <- struct{}{}:
+	default:
+	}
+	wv.RefreshWindow()
+}
+
+// refresh reloads the supported assets and the state of their wallets from
+// the dcrdex core.
+func (wv *walletsView) refresh() {
+	assets := wv.Dexc().Core().SupportedAssets()
+	wallets := make([]*walletRow, 0, len(assets))
+	for _, a := range assets {
+		if a.Info == nil {
+			continue
+		}
+		wallets = append(wallets, &walletRow{
+			SupportedAsset: a,
+			create:         wv.Theme.Button("Create wallet"),
+			connect:        wv.Theme.OutlineButton("Connect"),
+			deposit:        wv.Theme.OutlineButton("Deposit"),
+			withdraw:       wv.Theme.OutlineButton("Withdraw"),
+			lockToggle:     wv.Theme.OutlineButton("Unlock"),
+			reconfigure:    wv.Theme.OutlineButton("Settings"),
+		})
+	}
+	sort.Slice(wallets, func(i, j int) bool { return wallets[i].ID < wallets[j].ID })
+	wv.wallets = wallets
+}
+
+func (wv *walletsView) handle() {
+	select {
+	case <-wv.changed:
+		wv.refresh()
+	default:
+	}
+
+	for _, row := range wv.wallets {
+		a := row.SupportedAsset
+
+		if row.create.Clicked() {
+			wv.createWallet(a)
+		}
+
+		if row.connect.Clicked() {
+			go func() {
+				if err := wv.Dexc().Core().ConnectWallet(a.ID); err != nil {
+					wv.Toast.NotifyError(err.Error())
+				}
+			}()
+		}
+
+		if row.deposit.Clicked() {
+			newDepositModal(wv.Load, a).Show()
+		}
+
+		if row.withdraw.Clicked() {
+			newWithdrawModal(wv.Load, a).Show()
+		}
+
+		if row.lockToggle.Clicked() {
+			if a.Wallet.Open {
+				go func() {
+					if err := wv.Dexc().Core().CloseWallet(a.ID); err != nil {
+						wv.Toast.NotifyError(err.Error())
+					}
+				}()
+			} else {
+				wv.unlockWallet(a)
+			}
+		}
+
+		if row.reconfigure.Clicked() {
+			wv.reconfigureWallet(a)
+		}
+	}
+}
+
+func (wv *walletsView) createWallet(a *core.SupportedAsset) {
+	// The DCR wallet uses an account of one of the godcr wallets.
+	if a.ID == dcr.BipID {
+		newCreateWalletModal(wv.Load, &walletInfoWidget{
+			image:    components.CoinImageBySymbol(&wv.Icons, a.Symbol),
+			coinName: a.Symbol,
+			coinID:   a.ID,
+		}, wv.notifyChanged).Show()
+		return
+	}
+
+	newWalletSettingsModal(wv.Load, a, false).
+		SettingsSaved(wv.notifyChanged).
+		Show()
+}
+
+func (wv *walletsView) reconfigureWallet(a *core.SupportedAsset) {
+	if a.ID == dcr.BipID {
+		newCreateWalletModal(wv.Load, &walletInfoWidget{
+			image:    components.CoinImageBySymbol(&wv.Icons, a.Symbol),
+			coinName: a.Symbol,
+			coinID:   a.ID,
+		}, wv.notifyChanged).Reconfigure().Show()
+		return
+	}
+
+	newWalletSettingsModal(wv.Load, a, true).
+		SettingsSaved(wv.notifyChanged).
+		Show()
+}
+
+func (wv *walletsView) unlockWallet(a *core.SupportedAsset) {
+	modal.NewPasswordModal(wv.Load).
+		Title(fmt.Sprintf("Unlock %s wallet", a.Info.Name)).
+		Hint("App password").
+		NegativeButton(values.String(values.StrCancel), func() {}).
+		PositiveButton("Unlock", func(password string, pm *modal.PasswordModal) bool {
+			go func() {
+				err := wv.Dexc().Core().OpenWallet(a.ID, []byte(password))
+				if err != nil {
+					pm.SetError(err.Error())
+					pm.SetLoading(false)
+					return
+				}
+				pm.Dismiss()
+			}()
+			return false
+		}).Show()
+}
+
+func (wv *walletsView) layout(gtx C) D {
+	return wv.Theme.List(wv.container).Layout(gtx, len(wv.wallets), func(gtx C, i int) D {
+		return layout.Inset{Bottom: values.MarginPadding16}.Layout(gtx, func(gtx C) D {
+			return wv.Theme.Card().Layout(gtx, func(gtx C) D {
+				gtx.Constraints.Min.X = gtx.Constraints.Max.X
+				return layout.UniformInset(values.MarginPadding16).Layout(gtx, func(gtx C) D {
+					return wv.walletLayout(gtx, wv.wallets[i])
+				})
+			})
+		})
+	})
+}
+
+func (wv *walletsView) walletLayout(gtx C, row *walletRow) D {
+	a := row.SupportedAsset
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					img := components.CoinImageBySymbol(&wv.Icons, a.Symbol)
+					if img == nil {
+						return D{}
+					}
+					img.Scale = 0.2
+					return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, img.Layout)
+				}),
+				layout.Rigid(func(gtx C) D {
+					lbl := wv.Theme.Body1(fmt.Sprintf("%s (%s)", a.Info.Name, unitSymbol(a)))
+					lbl.Font.Weight = text.SemiBold
+					return lbl.Layout(gtx)
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					return layout.E.Layout(gtx, func(gtx C) D {
+						lbl := wv.Theme.Body2(walletStatus(a.Wallet))
+						lbl.Color = wv.Theme.Color.GrayText2
+						return lbl.Layout(gtx)
+					})
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			w := a.Wallet
+			if w == nil {
+				return layout.Inset{Top: values.MarginPadding16}.Layout(gtx, row.create.Layout)
+			}
+
+			factor := a.Info.UnitInfo.Conventional.ConversionFactor
+			amount := func(atoms uint64) string {
+				return fmt.Sprintf("%s %s", formatAtoms(atoms, factor), unitSymbol(a))
+			}
+
+			rows := []layout.FlexChild{
+				layout.Rigid(wv.detailRow("Wallet type", w.WalletType)),
+				layout.Rigid(wv.detailRow("Sync", syncStatus(w))),
+			}
+			if w.Balance != nil && w.Balance.Balance != nil {
+				rows = append(rows,
+					layout.Rigid(wv.detailRow("Available", amount(w.Balance.Available))),
+					layout.Rigid(wv.detailRow("Locked", amount(w.Balance.Locked))),
+					layout.Rigid(wv.detailRow("Immature", amount(w.Balance.Immature))),
+				)
+			}
+			if w.Address != "" {
+				rows = append(rows, layout.Rigid(wv.detailRow("Deposit address", w.Address)))
+			}
+			rows = append(rows, layout.Rigid(func(gtx C) D {
+				return wv.actionsLayout(gtx, row)
+			}))
+
+			return layout.Inset{Top: values.MarginPadding8}.Layout(gtx, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
+			})
+		}),
+	)
+}
+
+func (wv *walletsView) actionsLayout(gtx C, row *walletRow) D {
+	w := row.Wallet
+	if w.Open {
+		row.lockToggle.Text = "Lock"
+	} else {
+		row.lockToggle.Text = "Unlock"
+	}
+
+	button := func(btn decredmaterial.Button) layout.FlexChild {
+		return layout.Rigid(func(gtx C) D {
+			return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, btn.Layout)
+		})
+	}
+
+	buttons := make([]layout.FlexChild, 0)
+	if !w.Running {
+		buttons = append(buttons, button(row.connect))
+	} else {
+		buttons = append(buttons, button(row.deposit), button(row.withdraw), button(row.lockToggle))
+	}
+	buttons = append(buttons, button(row.reconfigure))
+
+	return layout.Inset{Top: values.MarginPadding16}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{}.Layout(gtx, buttons...)
+	})
+}
+
+func (wv *walletsView) detailRow(label, value string) layout.Widget {
+	return func(gtx C) D {
+		return layout.Inset{Bottom: values.MarginPadding4}.Layout(gtx, func(gtx C) D {
+			return layout.Flex{Spacing: layout.SpaceBetween}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					lbl := wv.Theme.Body2(label)
+					lbl.Color = wv.Theme.Color.GrayText2
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(wv.Theme.Body2(value).Layout),
+			)
+		})
+	}
+}
+
+// walletStatus describes the connection and lock state of a DEX wallet.
+func walletStatus(w *core.WalletState) string {
+	switch {
+	case w == nil:
+		return "No wallet"
+	case !w.Running:
+		return "Not connected"
+	case !w.Open:
+		return "Locked"
+	default:
+		return "Unlocked"
+	}
+}
+
+func syncStatus(w *core.WalletState) string {
+	if w.Synced {
+		return fmt.Sprintf("Synced, %d peers", w.PeerCount)
+	}
+	return fmt.Sprintf("Syncing %.1f%%, %d peers", w.SyncProgress*100, w.PeerCount)
+}
+
+// unitSymbol returns the conventional unit of the asset, e.g. DCR.
+func unitSymbol(a *core.SupportedAsset) string {
+	return a.Info.UnitInfo.Conventional.Unit
+}
@@ -0,0 +1,185 @@
+package dexclient
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"decred.org/dcrdex/client/core"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/values"
+)
+
+const dexWithdrawModalID = "dex_withdraw_modal"
+
+// withdrawModal sends funds from a DEX wallet to an address.
+type withdrawModal struct {
+	*load.Load
+
+	asset *core.SupportedAsset
+
+	modal          *decredmaterial.Modal
+	addressEditor  decredmaterial.Editor
+	amountEditor   decredmaterial.Editor
+	appPassword    decredmaterial.Editor
+	maxBtn         decredmaterial.Button
+	submit         decredmaterial.Button
+	cancel         decredmaterial.Button
+	materialLoader material.LoaderStyle
+	isSending      bool
+	withdrawError  string
+}
+
+func newWithdrawModal(l *load.Load, a *core.SupportedAsset) *withdrawModal {
+	md := &withdrawModal{
+		Load:           l,
+		asset:          a,
+		modal:          l.Theme.ModalFloatTitle(),
+		addressEditor:  l.Theme.Editor(new(widget.Editor), "Address"),
+		amountEditor:   l.Theme.Editor(new(widget.Editor), fmt.Sprintf("Amount (%s)", unitSymbol(a))),
+		appPassword:    l.Theme.EditorPassword(new(widget.Editor), "App Password"),
+		maxBtn:         l.Theme.OutlineButton("Max"),
+		submit:         l.Theme.Button("Withdraw"),
+		cancel:         l.Theme.OutlineButton("Cancel"),
+		materialLoader: material.Loader(l.Theme.Base),
+	}
+
+	md.addressEditor.Editor.SingleLine = true
+	md.amountEditor.Editor.SingleLine = true
+	md.appPassword.Editor.SingleLine = true
+
+	return md
+}
+
+func (md *withdrawModal) ModalID() string {
+	return dexWithdrawModalID
+}
+
+func (md *withdrawModal) Show() {
+	md.ShowModal(md)
+}
+
+func (md *withdrawModal) Dismiss() {
+	md.DismissModal(md)
+}
+
+func (md *withdrawModal) OnDismiss() {}
+
+func (md *withdrawModal) OnResume() {}
+
+func (md *withdrawModal) conversionFactor() uint64 {
+	return md.asset.Info.UnitInfo.Conventional.ConversionFactor
+}
+
+func (md *withdrawModal) Handle() {
+	if md.cancel.Clicked() && !md.isSending {
+		md.Dismiss()
+	}
+
+	_, changed := decredmaterial.HandleEditorEvents(md.addressEditor.Editor, md.amountEditor.Editor, md.appPassword.Editor)
+	if changed {
+		md.withdrawError = ""
+	}
+
+	if md.maxBtn.Clicked() {
+		if balance := md.asset.Wallet.Balance; balance != nil && balance.Balance != nil {
+			md.amountEditor.Editor.SetText(formatAtoms(balance.Available, md.conversionFactor()))
+		}
+	}
+
+	if md.submit.Clicked() && !md.isSending {
+		address := md.addressEditor.Editor.Text()
+		if address == "" {
+			md.withdrawError = "enter an address"
+			return
+		}
+
+		amount, err := strconv.ParseFloat(md.amountEditor.Editor.Text(), 64)
+		if err != nil || amount <= 0 {
+			md.withdrawError = "enter a valid amount"
+			return
+		}
+		value := uint64(math.Round(amount * float64(md.conversionFactor())))
+
+		appPass := []byte(md.appPassword.Editor.Text())
+		if len(appPass) == 0 {
+			md.withdrawError = "enter the app password"
+			return
+		}
+
+		md.isSending = true
+		md.modal.SetDisabled(true)
+		go func() {
+			defer func() {
+				md.isSending = false
+				md.modal.SetDisabled(false)
+			}()
+
+			coin, err := md.Dexc().Core().Withdraw(appPass, md.asset.ID, value, address)
+			if err != nil {
+				md.withdrawError = err.Error()
+				md.RefreshWindow()
+				return
+			}
+
+			md.Toast.Notify(fmt.Sprintf("Withdrawal sent: %s", coin))
+			md.Dismiss()
+		}()
+	}
+}
+
+func (md *withdrawModal) Layout(gtx layout.Context) D {
+	w := []layout.Widget{
+		func(gtx C) D {
+			t := md.Theme.H6(fmt.Sprintf("Withdraw %s", md.asset.Info.Name))
+			t.Font.Weight = text.SemiBold
+			return t.Layout(gtx)
+		},
+		func(gtx C) D {
+			available := "-"
+			if balance := md.asset.Wallet.Balance; balance != nil && balance.Balance != nil {
+				available = fmt.Sprintf("%s %s", formatAtoms(balance.Available, md.conversionFactor()), unitSymbol(md.asset))
+			}
+			lbl := md.Theme.Body2(fmt.Sprintf("Available: %s", available))
+			lbl.Color = md.Theme.Color.GrayText2
+			return lbl.Layout(gtx)
+		},
+		md.addressEditor.Layout,
+		func(gtx C) D {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1, md.amountEditor.Layout),
+				layout.Rigid(func(gtx C) D {
+					return layout.Inset{Left: values.MarginPadding8}.Layout(gtx, md.maxBtn.Layout)
+				}),
+			)
+		},
+		md.appPassword.Layout,
+		func(gtx C) D {
+			if md.withdrawError == "" {
+				return D{}
+			}
+			return md.Theme.ErrorLabel(md.withdrawError).Layout(gtx)
+		},
+		func(gtx C) D {
+			return layout.E.Layout(gtx, func(gtx C) D {
+				if md.isSending {
+					return md.materialLoader.Layout(gtx)
+				}
+				return layout.Flex{}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, md.cancel.Layout)
+					}),
+					layout.Rigid(md.submit.Layout),
+				)
+			})
+		},
+	}
+
+	return md.modal.Layout(gtx, w)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/planetdecred/godcr/ui/load"
 	"github.com/planetdecred/godcr/ui/page/components"
 	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
 )
 
 const GovernancePageID = "Governance"
@@ -28,12 +29,13 @@ type Page struct {
 
 	proposalsPage *ProposalsPage
 	consensusPage *ConsensusPage
+	treasuryPage  *TreasuryPage
 
 	selectedCategoryIndex int
 	changed               bool
 }
 
-var governanceTabTitles = []string{"Proposals", "Consensus Changes"}
+var governanceTabTitles = []string{"Proposals", "Consensus Changes", "Treasury Spending"}
 
 func NewGovernancePage(l *load.Load) *Page {
 	pg := &Page{
@@ -42,6 +44,7 @@ func NewGovernancePage(l *load.Load) *Page {
 		selectedCategoryIndex: -1,
 		proposalsPage:         NewProposalsPage(l),
 		consensusPage:         NewConsensusPage(l),
+		treasuryPage:          NewTreasuryPage(l),
 		tabCategoryList:       l.Theme.NewClickableList(layout.Horizontal),
 	}
 
@@ -61,11 +64,7 @@ func (pg *Page) OnNavigatedTo() {
 		pg.selectedCategoryIndex = 0
 	}
 
-	if pg.selectedCategoryIndex == 1 {
-		pg.consensusPage.OnNavigatedTo()
-	} else {
-		pg.proposalsPage.OnNavigatedTo()
-	}
+	pg.selectedPage().OnNavigatedTo()
 }
 
 // OnNavigatedFrom is called when the page is about to be removed from
@@ -78,6 +77,7 @@ func (pg *Page) OnNavigatedTo() {
 func (pg *Page) OnNavigatedFrom() {
 	pg.consensusPage.OnNavigatedFrom()
 	pg.proposalsPage.OnNavigatedFrom()
+	pg.treasuryPage.OnNavigatedFrom()
 }
 
 func (pg *Page) ID() string {
@@ -86,10 +86,13 @@ func (pg *Page) ID() string {
 
 func (pg *Page) HandleUserInteractions() {
 	for pg.enableGovernanceBtn.Clicked() {
+		pg.WL.Wallet.SaveConfigValueForKey(load.FetchProposalConfigKey, true)
+		if err := pg.WL.Wallet.CheckConnection(wallet.ProxySubsystemGovernance); err != nil {
+			pg.Toast.NotifyError(err.Error())
+		}
 		go pg.consensusPage.FetchAgendas()
-		go pg.WL.MultiWallet.Politeia.Sync()
+		go pg.WL.Wallet.SyncPoliteia()
 		pg.proposalsPage.isSyncing = pg.multiWallet.Politeia.IsSyncing()
-		pg.WL.Wallet.SaveConfigValueForKey(load.FetchProposalConfigKey, true)
 	}
 
 	if clicked, selectedItem := pg.tabCategoryList.ItemClicked(); clicked {
@@ -99,19 +102,25 @@ func (pg *Page) HandleUserInteractions() {
 		}
 
 		// call selected page OnNavigatedTo() only once
-		if pg.changed && pg.selectedCategoryIndex == 0 {
-			pg.proposalsPage.OnNavigatedTo()
-		} else if pg.changed && pg.selectedCategoryIndex == 1 {
-			pg.consensusPage.OnNavigatedTo()
+		if pg.changed {
+			pg.selectedPage().OnNavigatedTo()
 		}
 		pg.changed = false
 	}
 
 	// handle individual page user interactions
-	if pg.selectedCategoryIndex == 0 {
-		pg.proposalsPage.HandleUserInteractions()
-	} else {
-		pg.consensusPage.HandleUserInteractions()
+	pg.selectedPage().HandleUserInteractions()
+}
+
+// selectedPage returns the page of the selected governance tab.
+func (pg *Page) selectedPage() load.Page {
+	switch pg.selectedCategoryIndex {
+	case 1:
+		return pg.consensusPage
+	case 2:
+		return pg.treasuryPage
+	default:
+		return pg.proposalsPage
 	}
 }
 
@@ -134,11 +143,14 @@ func (pg *Page) Layout(gtx C) D {
 }
 
 func (pg *Page) switchTab(gtx C, selectedCategoryIndex int) D {
-	if selectedCategoryIndex == 0 {
+	switch selectedCategoryIndex {
+	case 1:
+		return pg.consensusPage.Layout(gtx)
+	case 2:
+		return pg.treasuryPage.Layout(gtx)
+	default:
 		return pg.proposalsPage.Layout(gtx)
 	}
-
-	return pg.consensusPage.Layout(gtx)
 }
 
 func (pg *Page) layoutTabs(gtx C) D {
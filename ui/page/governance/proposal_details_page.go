@@ -52,13 +52,27 @@ type ProposalDetails struct {
 
 	viewInPoliteiaBtn *decredmaterial.Clickable
 	copyRedirectURL   *decredmaterial.Clickable
+	ticketVotesBtn    *decredmaterial.Clickable
 
 	descriptionCard decredmaterial.Card
 	vote            decredmaterial.Button
+	bookmarkBtn     decredmaterial.Button
 	backButton      decredmaterial.IconButton
 
 	voteBar            *components.VoteBar
 	loadingDescription bool
+	// offlineDescription is set when the latest proposal description could
+	// not be downloaded and the cached copy is displayed instead.
+	offlineDescription bool
+	// descriptionErr is set when Politeia may not be contacted and there is
+	// no cached copy of the description.
+	descriptionErr string
+
+	// voteBreakdown is the vote of the tickets of each wallet on the proposal.
+	voteBreakdown []*wallet.WalletProposalVotes
+	// recordedVotes is set when Politeia may not be contacted and the vote
+	// breakdown is taken from the vote history.
+	recordedVotes bool
 }
 
 func NewProposalDetailsPage(l *load.Load, proposal *dcrlibwallet.Proposal) *ProposalDetails {
@@ -79,6 +93,7 @@ func NewProposalDetailsPage(l *load.Load, proposal *dcrlibwallet.Proposal) *Prop
 		successIcon:       l.Icons.ActionCheckCircle,
 		viewInPoliteiaBtn: l.Theme.NewClickable(false),
 		copyRedirectURL:   l.Theme.NewClickable(false),
+		ticketVotesBtn:    l.Theme.NewClickable(false),
 		voteBar:           components.NewVoteBar(l),
 	}
 
@@ -95,9 +110,21 @@ func NewProposalDetailsPage(l *load.Load, proposal *dcrlibwallet.Proposal) *Prop
 		Right:  values.MarginPadding12,
 	}
 
+	pg.bookmarkBtn = l.Theme.OutlineButton("")
+	pg.bookmarkBtn.TextSize = values.TextSize14
+	pg.updateBookmarkBtn()
+
 	return pg
 }
 
+func (pg *ProposalDetails) updateBookmarkBtn() {
+	if pg.WL.Wallet.IsProposalBookmarked(pg.proposal.Token) {
+		pg.bookmarkBtn.Text = "Remove bookmark"
+	} else {
+		pg.bookmarkBtn.Text = "Bookmark"
+	}
+}
+
 // ID is a unique string that identifies the page and may be used
 // to differentiate this page from other pages.
 // Part of the load.Page interface.
@@ -112,6 +139,29 @@ func (pg *ProposalDetails) ID() string {
 func (pg *ProposalDetails) OnNavigatedTo() {
 	pg.ctx, pg.ctxCancel = context.WithCancel(context.TODO())
 	pg.listenForSyncNotifications()
+	pg.fetchVoteBreakdown()
+}
+
+func (pg *ProposalDetails) fetchVoteBreakdown() {
+	if pg.proposal.Category == dcrlibwallet.ProposalCategoryPre || pg.proposal.Category == dcrlibwallet.ProposalCategoryAbandoned {
+		return
+	}
+
+	if err := pg.WL.Wallet.CheckConnection(wallet.ProxySubsystemGovernance); err != nil {
+		pg.voteBreakdown = pg.WL.Wallet.RecordedProposalVotes(pg.proposal.Token)
+		pg.recordedVotes = true
+		return
+	}
+
+	go func() {
+		breakdown, err := pg.WL.Wallet.ProposalVoteBreakdown(pg.proposal)
+		if err != nil {
+			log.Errorf("Error fetching proposal vote breakdown: %v", err)
+			return
+		}
+		pg.voteBreakdown = breakdown
+		pg.RefreshWindow()
+	}()
 }
 
 // HandleUserInteractions is called just before Layout() to determine
@@ -132,6 +182,16 @@ func (pg *ProposalDetails) HandleUserInteractions() {
 		newVoteModal(pg.Load, pg.proposal).Show()
 	}
 
+	for pg.ticketVotesBtn.Clicked() {
+		showTicketVotes(pg.Load, pg.voteBreakdown)
+	}
+
+	for pg.bookmarkBtn.Clicked() {
+		bookmarked := !pg.WL.Wallet.IsProposalBookmarked(pg.proposal.Token)
+		pg.WL.Wallet.SetProposalBookmarked(pg.proposal.Token, bookmarked)
+		pg.updateBookmarkBtn()
+	}
+
 	for pg.viewInPoliteiaBtn.Clicked() {
 		host := "https://proposals.decred.org/record/" + pg.proposal.Token
 		if pg.WL.MultiWallet.NetType() == dcrlibwallet.Testnet3 {
@@ -382,6 +442,7 @@ func (pg *ProposalDetails) layoutNormalTitle(gtx C) D {
 		}),
 		layout.Rigid(pg.lineSeparator(layout.Inset{Top: values.MarginPadding10, Bottom: values.MarginPadding10})),
 		layout.Rigid(pg.layoutProposalVoteBar),
+		layout.Rigid(pg.layoutVoteBreakdown),
 		layout.Rigid(func(gtx C) D {
 			if proposal.Category != dcrlibwallet.ProposalCategoryActive {
 				return D{}
@@ -394,6 +455,50 @@ func (pg *ProposalDetails) layoutNormalTitle(gtx C) D {
 	)
 }
 
+func (pg *ProposalDetails) layoutVoteBreakdown(gtx C) D {
+	breakdown := pg.voteBreakdown
+	if len(breakdown) == 0 {
+		return D{}
+	}
+
+	rows := []layout.FlexChild{
+		layout.Rigid(pg.lineSeparator(layout.Inset{Top: values.MarginPadding10, Bottom: values.MarginPadding10})),
+		layout.Rigid(func(gtx C) D {
+			lbl := pg.Theme.Body1("My votes")
+			lbl.Font.Weight = text.SemiBold
+			return layout.Inset{Bottom: values.MarginPadding4}.Layout(gtx, lbl.Layout)
+		}),
+	}
+	if pg.recordedVotes {
+		rows = append(rows, layout.Rigid(func(gtx C) D {
+			lbl := pg.Theme.Caption("Votes recorded by this app, governance is off or blocked by the proxy")
+			lbl.Color = pg.Theme.Color.GrayText2
+			return layout.Inset{Bottom: values.MarginPadding4}.Layout(gtx, lbl.Layout)
+		}))
+	}
+	for _, walletVotes := range breakdown {
+		walletVotes := walletVotes
+		rows = append(rows, layout.Rigid(func(gtx C) D {
+			return layout.Flex{Spacing: layout.SpaceBetween}.Layout(gtx,
+				layout.Rigid(pg.Theme.Body2(walletVotes.WalletName).Layout),
+				layout.Rigid(func(gtx C) D {
+					summary := fmt.Sprintf("%d eligible · %d yes · %d no · %d unvoted",
+						walletVotes.Eligible(), walletVotes.Yes, walletVotes.No, walletVotes.Unvoted)
+					if pg.recordedVotes {
+						summary = fmt.Sprintf("%d yes · %d no", walletVotes.Yes, walletVotes.No)
+					}
+					lbl := pg.Theme.Body2(summary)
+					lbl.Color = pg.Theme.Color.GrayText2
+					return lbl.Layout(gtx)
+				}),
+			)
+		}))
+	}
+	rows = append(rows, layout.Rigid(pg.layoutRedirect("View ticket votes", pg.redirectIcon, pg.ticketVotesBtn)))
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
+}
+
 func (pg *ProposalDetails) layoutTitle(gtx C) D {
 	proposal := pg.proposal
 
@@ -428,9 +533,16 @@ func (pg *ProposalDetails) layoutDescription(gtx C) D {
 
 	w := []layout.Widget{
 		func(gtx C) D {
-			lbl := pg.Theme.H5(proposal.Name)
-			lbl.Font.Weight = text.SemiBold
-			return lbl.Layout(gtx)
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1, func(gtx C) D {
+					lbl := pg.Theme.H5(proposal.Name)
+					lbl.Font.Weight = text.SemiBold
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx C) D {
+					return layout.Inset{Left: values.MarginPadding10}.Layout(gtx, pg.bookmarkBtn.Layout)
+				}),
+			)
 		},
 		pg.lineSeparator(layout.Inset{Top: values.MarginPadding16, Bottom: values.MarginPadding16}),
 		func(gtx C) D {
@@ -455,7 +567,20 @@ func (pg *ProposalDetails) layoutDescription(gtx C) D {
 
 	_, ok := pg.proposalItems[proposal.Token]
 	if ok {
+		if pg.offlineDescription {
+			w = append(w, func(gtx C) D {
+				lbl := pg.Theme.Body2(fmt.Sprintf("Offline copy of version %s, the latest version could not be downloaded.", proposal.IndexFileVersion))
+				lbl.Color = pg.Theme.Color.GrayText2
+				return layout.Inset{Bottom: values.MarginPadding16}.Layout(gtx, lbl.Layout)
+			})
+		}
 		w = append(w, pg.proposalItems[proposal.Token].widgets...)
+	} else if pg.descriptionErr != "" {
+		w = append(w, func(gtx C) D {
+			lbl := pg.Theme.Body2(pg.descriptionErr + ", the proposal was not downloaded for offline reading.")
+			lbl.Color = pg.Theme.Color.GrayText2
+			return lbl.Layout(gtx)
+		})
 	} else {
 		loading := func(gtx C) D {
 			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, layout.Flexed(1, func(gtx C) D {
@@ -522,10 +647,28 @@ func (pg *ProposalDetails) Layout(gtx C) D {
 			var proposalDescription string
 			if proposal.IndexFile != "" && proposal.IndexFileVersion == proposal.Version {
 				proposalDescription = proposal.IndexFile
+			} else if err := pg.WL.Wallet.CheckConnection(wallet.ProxySubsystemGovernance); err != nil {
+				// Politeia may not be contacted, the description is not
+				// loaded again until the page is opened again.
+				if proposal.IndexFile == "" {
+					pg.descriptionErr = err.Error()
+					pg.RefreshWindow()
+					return
+				}
+				proposalDescription = proposal.IndexFile
+				pg.offlineDescription = true
 			} else {
 				var err error
 				proposalDescription, err = pg.WL.MultiWallet.Politeia.FetchProposalDescription(proposal.Token)
-				if err != nil {
+				switch {
+				case err == nil:
+					pg.offlineDescription = false
+				case proposal.IndexFile != "":
+					// fallback to the outdated copy kept for offline reading.
+					log.Errorf("Error loading proposal description: %v", err)
+					proposalDescription = proposal.IndexFile
+					pg.offlineDescription = true
+				default:
 					fmt.Printf("Error loading proposal description: %v", err)
 					time.Sleep(7 * time.Second)
 					pg.loadingDescription = false
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"gioui.org/layout"
 	"gioui.org/text"
@@ -15,6 +16,7 @@ import (
 	"github.com/planetdecred/godcr/ui/modal"
 	"github.com/planetdecred/godcr/ui/page/components"
 	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
 )
 
 const ModalInputVote = "input_vote_modal"
@@ -70,11 +72,15 @@ func newVoteModal(l *load.Load, proposal *dcrlibwallet.Proposal) *voteModal {
 			vm.detailsCancel = cancel
 
 			vm.voteDetails = nil
-			vm.voteDetailsErr = nil
+			vm.voteDetailsErr = vm.WL.Wallet.CheckConnection(wallet.ProxySubsystemGovernance)
+			blocked := vm.voteDetailsErr != nil
 
 			vm.detailsMu.Unlock()
 
 			vm.RefreshWindow()
+			if blocked {
+				return
+			}
 
 			go func() {
 				voteDetails, err := vm.WL.MultiWallet.Politeia.ProposalVoteDetailsRaw(w.ID, vm.proposal.Token)
@@ -168,15 +174,27 @@ func (vm *voteModal) sendVotes() {
 		}).
 		PositiveButton("Confirm", func(password string, pm *modal.PasswordModal) bool {
 			go func() {
-				err := vm.WL.MultiWallet.Politeia.CastVotes(vm.walletSelector.selectedWallet.ID, votes, vm.proposal.Token, password)
+				if err := vm.WL.Wallet.CheckConnection(wallet.ProxySubsystemGovernance); err != nil {
+					pm.SetError(err.Error())
+					pm.SetLoading(false)
+					return
+				}
+				walletID := vm.walletSelector.selectedWallet.ID
+				err := vm.WL.MultiWallet.Politeia.CastVotes(walletID, votes, vm.proposal.Token, password)
 				if err != nil {
 					pm.SetError(err.Error())
 					pm.SetLoading(false)
 					return
 				}
+				// The votes were just received by politeia, its time replaces
+				// this one when the votes are fetched again.
+				for _, vote := range votes {
+					vote.Timestamp = time.Now().Unix()
+				}
+				vm.WL.Wallet.RecordProposalVotes(vm.proposal, walletID, votes)
 				pm.Dismiss()
 				vm.Toast.Notify("Vote sent successfully, refreshing proposals!")
-				go vm.WL.MultiWallet.Politeia.Sync()
+				go vm.WL.Wallet.SyncPoliteia()
 				vm.Dismiss()
 			}()
 
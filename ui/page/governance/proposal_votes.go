@@ -0,0 +1,114 @@
+package governance
+
+import (
+	"fmt"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/modal"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+// showTicketVotes lists how each ticket of the wallets voted on a proposal.
+func showTicketVotes(l *load.Load, breakdown []*wallet.WalletProposalVotes) {
+	list := &widget.List{List: layout.List{Axis: layout.Vertical}}
+
+	type row struct {
+		walletName string
+		vote       wallet.TicketProposalVote
+	}
+	rows := make([]row, 0)
+	for _, walletVotes := range breakdown {
+		for _, vote := range walletVotes.Tickets {
+			rows = append(rows, row{walletVotes.WalletName, vote})
+		}
+	}
+
+	modal.NewInfoModal(l).
+		Title("Ticket votes").
+		UseCustomWidget(func(gtx C) D {
+			if len(rows) == 0 {
+				return l.Theme.Body2("No eligible tickets").Layout(gtx)
+			}
+
+			gtx.Constraints.Max.Y = gtx.Px(values.MarginPadding280)
+			return l.Theme.List(list).Layout(gtx, len(rows), func(gtx C, i int) D {
+				r := rows[i]
+				return layout.Inset{Bottom: values.MarginPadding8}.Layout(gtx, func(gtx C) D {
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(func(gtx C) D {
+							return layout.Flex{Spacing: layout.SpaceBetween}.Layout(gtx,
+								layout.Rigid(l.Theme.Label(values.TextSize14, r.walletName).Layout),
+								layout.Rigid(l.Theme.Label(values.TextSize14, r.vote.Vote).Layout),
+							)
+						}),
+						layout.Rigid(func(gtx C) D {
+							txt := l.Theme.Label(values.TextSize12, r.vote.Ticket)
+							txt.Color = l.Theme.Color.GrayText3
+							return txt.Layout(gtx)
+						}),
+					)
+				})
+			})
+		}).
+		PositiveButton("Close", func() {}).
+		Show()
+}
+
+// showProposalVoteHistory lists the votes cast on all proposals, and exports
+// them to a CSV file on request.
+func showProposalVoteHistory(l *load.Load) {
+	history := l.WL.Wallet.ProposalVoteHistory()
+	list := &widget.List{List: layout.List{Axis: layout.Vertical}}
+
+	infoModal := modal.NewInfoModal(l).
+		Title("My votes").
+		UseCustomWidget(func(gtx C) D {
+			if len(history) == 0 {
+				return l.Theme.Body2("No votes cast yet").Layout(gtx)
+			}
+
+			gtx.Constraints.Max.Y = gtx.Px(values.MarginPadding280)
+			return l.Theme.List(list).Layout(gtx, len(history), func(gtx C, i int) D {
+				r := history[i]
+				return layout.Inset{Bottom: values.MarginPadding8}.Layout(gtx, func(gtx C) D {
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(l.Theme.Label(values.TextSize14, r.ProposalName).Layout),
+						layout.Rigid(func(gtx C) D {
+							date := "Date unknown"
+							if r.Timestamp != 0 {
+								date = time.Unix(r.Timestamp, 0).Format("Jan 2, 2006")
+							}
+							txt := l.Theme.Label(values.TextSize12, fmt.Sprintf("%s · %s · voted %s", date, r.WalletName, r.Vote))
+							txt.Color = l.Theme.Color.GrayText2
+							return txt.Layout(gtx)
+						}),
+						layout.Rigid(func(gtx C) D {
+							txt := l.Theme.Label(values.TextSize12, r.Ticket)
+							txt.Color = l.Theme.Color.GrayText3
+							return txt.Layout(gtx)
+						}),
+					)
+				})
+			})
+		}).
+		NegativeButton("Close", func() {})
+
+	if len(history) > 0 {
+		infoModal.PositiveButton("Export CSV", func() {
+			path, err := l.WL.Wallet.ExportProposalVoteHistory()
+			if err != nil {
+				l.Toast.NotifyError(err.Error())
+				return
+			}
+			l.Toast.Notify("Votes exported to " + path)
+		})
+	} else {
+		infoModal.PositiveButton("Got it", func() {})
+	}
+	infoModal.Show()
+}
@@ -2,6 +2,7 @@ package governance
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"time"
 
@@ -21,6 +22,10 @@ import (
 
 const ProposalsPageID = "Proposals"
 
+// bookmarkedCategoryIndex is the position of the bookmarked proposals in the
+// category dropdown.
+const bookmarkedCategoryIndex = 4
+
 type (
 	C = layout.Context
 	D = layout.Dimensions
@@ -40,6 +45,7 @@ type ProposalsPage struct {
 	categoryDropDown *decredmaterial.DropDown
 	proposalsList    *decredmaterial.ClickableList
 	syncButton       *widget.Clickable
+	myVotesBtn       decredmaterial.Button
 	searchEditor     decredmaterial.Editor
 
 	infoButton decredmaterial.IconButton
@@ -67,6 +73,8 @@ func NewProposalsPage(l *load.Load) *ProposalsPage {
 	pg.updatedIcon.Color = pg.Theme.Color.Success
 
 	pg.syncButton = new(widget.Clickable)
+	pg.myVotesBtn = l.Theme.OutlineButton("My votes")
+	pg.myVotesBtn.TextSize = values.TextSize14
 
 	pg.proposalsList = pg.Theme.NewClickableList(layout.Vertical)
 	pg.proposalsList.IsShadowEnabled = true
@@ -90,6 +98,9 @@ func NewProposalsPage(l *load.Load) *ProposalsPage {
 		{
 			Text: "Abandoned",
 		},
+		{
+			Text: "Bookmarked",
+		},
 	}, values.ProposalDropdownGroup, 1)
 
 	return pg
@@ -127,21 +138,31 @@ func (pg *ProposalsPage) fetchProposals() {
 	}
 
 	proposalItems := components.LoadProposals(proposalFilter, newestFirst, pg.Load)
+	bookmarked := pg.WL.Wallet.BookmarkedProposals()
+	searchQuery := pg.searchEditor.Editor.Text()
 
-	// group 'In discussion' and 'Active' proposals into under review
 	listItems := make([]*components.ProposalItem, 0)
 	for _, item := range proposalItems {
-		if item.Proposal.Category == dcrlibwallet.ProposalCategoryPre ||
-			item.Proposal.Category == dcrlibwallet.ProposalCategoryActive {
+		switch pg.categoryDropDown.SelectedIndex() {
+		case 0:
+			// group 'In discussion' and 'Active' proposals into under review
+			if item.Proposal.Category != dcrlibwallet.ProposalCategoryPre &&
+				item.Proposal.Category != dcrlibwallet.ProposalCategoryActive {
+				continue
+			}
+		case bookmarkedCategoryIndex:
+			if !containsToken(bookmarked, item.Proposal.Token) {
+				continue
+			}
+		}
+
+		if wallet.ProposalMatchesSearch(&item.Proposal, searchQuery) {
 			listItems = append(listItems, item)
 		}
 	}
 
 	pg.proposalMu.Lock()
-	pg.proposalItems = proposalItems
-	if proposalFilter == dcrlibwallet.ProposalCategoryAll {
-		pg.proposalItems = listItems
-	}
+	pg.proposalItems = listItems
 	pg.proposalMu.Unlock()
 }
 
@@ -160,7 +181,12 @@ func (pg *ProposalsPage) HandleUserInteractions() {
 	}
 
 	pg.searchEditor.EditorIconButtonEvent = func() {
-		//TODO: Proposals search functionality
+		pg.fetchProposals()
+	}
+
+	submitted, changed := decredmaterial.HandleEditorEvents(pg.searchEditor.Editor)
+	if submitted || changed {
+		pg.fetchProposals()
 	}
 
 	if clicked, selectedItem := pg.proposalsList.ItemClicked(); clicked {
@@ -171,8 +197,16 @@ func (pg *ProposalsPage) HandleUserInteractions() {
 		pg.ChangeFragment(NewProposalDetailsPage(pg.Load, &selectedProposal))
 	}
 
+	for pg.myVotesBtn.Clicked() {
+		showProposalVoteHistory(pg.Load)
+	}
+
 	for pg.syncButton.Clicked() {
-		go pg.multiWallet.Politeia.Sync()
+		if err := pg.WL.Wallet.CheckConnection(wallet.ProxySubsystemGovernance); err != nil {
+			pg.Toast.NotifyError(err.Error())
+			continue
+		}
+		go pg.WL.Wallet.SyncPoliteia()
 		pg.isSyncing = true
 
 		//Todo: check after 1min if sync does not start, set isSyncing to false and cancel sync
@@ -271,7 +305,7 @@ func (pg *ProposalsPage) layoutContent(gtx C) D {
 				return layout.Inset{Right: values.MarginPadding2}.Layout(gtx, func(gtx C) D {
 					return pg.Theme.Card().Layout(gtx, func(gtx C) D {
 						if len(proposalItems) == 0 {
-							return components.LayoutNoProposalsFound(gtx, pg.Load, pg.isSyncing, int32(pg.categoryDropDown.SelectedIndex()))
+							return pg.layoutNoProposalsFound(gtx)
 						}
 						return pg.proposalsList.Layout(gtx, len(proposalItems), func(gtx C, i int) D {
 							return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
@@ -290,6 +324,30 @@ func (pg *ProposalsPage) layoutContent(gtx C) D {
 	)
 }
 
+func (pg *ProposalsPage) layoutNoProposalsFound(gtx C) D {
+	var text string
+	switch {
+	case pg.isSyncing:
+		text = "Fetching proposals..."
+	case strings.TrimSpace(pg.searchEditor.Editor.Text()) != "":
+		text = "No proposals match your search"
+	case pg.categoryDropDown.SelectedIndex() == bookmarkedCategoryIndex:
+		text = "No bookmarked proposals"
+	default:
+		return components.LayoutNoProposalsFound(gtx, pg.Load, pg.isSyncing, int32(pg.categoryDropDown.SelectedIndex()))
+	}
+
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	lbl := pg.Theme.Body1(text)
+	lbl.Color = pg.Theme.Color.GrayText3
+	return layout.Center.Layout(gtx, func(gtx C) D {
+		return layout.Inset{
+			Top:    values.MarginPadding10,
+			Bottom: values.MarginPadding10,
+		}.Layout(gtx, lbl.Layout)
+	})
+}
+
 func (pg *ProposalsPage) layoutSyncSection(gtx C) D {
 	if pg.isSyncing {
 		return pg.layoutIsSyncingSection(gtx)
@@ -318,6 +376,9 @@ func (pg *ProposalsPage) layoutSectionHeader(gtx C) D {
 			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
 				layout.Rigid(pg.Theme.Label(values.TextSize20, "Proposals").Layout), // Do we really need to display the title? nav is proposals already
 				layout.Rigid(pg.infoButton.Layout),
+				layout.Rigid(func(gtx C) D {
+					return layout.Inset{Left: values.MarginPadding10}.Layout(gtx, pg.myVotesBtn.Layout)
+				}),
 			)
 		}),
 		layout.Flexed(1, func(gtx C) D {
@@ -380,3 +441,12 @@ func (pg *ProposalsPage) listenForSyncNotifications() {
 		}
 	}()
 }
+
+func containsToken(tokens []string, token string) bool {
+	for _, t := range tokens {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,312 @@
+package governance
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+
+	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/planetdecred/dcrlibwallet"
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/modal"
+	"github.com/planetdecred/godcr/ui/page/components"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+const TreasuryPageID = "Treasury"
+
+type treasuryItem struct {
+	tspend  *wallet.TreasurySpend
+	voteBtn decredmaterial.Button
+
+	// tally and tallyErr are set once the votes cast on chain are counted,
+	// they are guarded by the tallyMu of the page.
+	tally    *wallet.TreasurySpendTally
+	tallyErr error
+}
+
+type TreasuryPage struct {
+	*load.Load
+
+	ctx       context.Context // page context
+	ctxCancel context.CancelFunc
+
+	multiWallet   *dcrlibwallet.MultiWallet
+	wallets       []*dcrlibwallet.Wallet
+	treasuryItems []*treasuryItem
+
+	listContainer  *widget.List
+	walletDropDown *decredmaterial.DropDown
+	addTSpendBtn   decredmaterial.Button
+	infoButton     decredmaterial.IconButton
+
+	isLoading bool
+	tallyMu   sync.Mutex
+}
+
+func NewTreasuryPage(l *load.Load) *TreasuryPage {
+	pg := &TreasuryPage{
+		Load:        l,
+		multiWallet: l.WL.MultiWallet,
+		wallets:     l.WL.SortedWalletList(),
+		listContainer: &widget.List{
+			List: layout.List{Axis: layout.Vertical},
+		},
+		addTSpendBtn: l.Theme.OutlineButton("Add treasury spend"),
+	}
+
+	_, pg.infoButton = components.SubpageHeaderButtons(l)
+	pg.infoButton.Size = values.MarginPadding20
+
+	pg.walletDropDown = components.CreateOrUpdateWalletDropDown(pg.Load, &pg.walletDropDown, pg.wallets, values.TreasuryDropdownGroup, 0)
+
+	return pg
+}
+
+func (pg *TreasuryPage) ID() string {
+	return TreasuryPageID
+}
+
+func (pg *TreasuryPage) OnNavigatedTo() {
+	pg.ctx, pg.ctxCancel = context.WithCancel(context.TODO())
+	pg.FetchTreasurySpends()
+}
+
+func (pg *TreasuryPage) OnNavigatedFrom() {
+	if pg.ctxCancel != nil {
+		pg.ctxCancel()
+	}
+}
+
+func (pg *TreasuryPage) HandleUserInteractions() {
+	for pg.walletDropDown.Changed() {
+		pg.FetchTreasurySpends()
+	}
+
+	for _, item := range pg.treasuryItems {
+		if item.voteBtn.Clicked() {
+			newTreasuryVoteModal(pg.Load, item.tspend, func() {
+				pg.FetchTreasurySpends() // re-fetch tspends when the policy is updated
+			}).Show()
+		}
+	}
+
+	for pg.addTSpendBtn.Clicked() {
+		textModal := modal.NewTextInputModal(pg.Load).
+			Hint("Treasury spend hash").
+			PositiveButtonStyle(pg.Theme.Color.Primary, pg.Theme.Color.InvText).
+			PositiveButton("Add", func(hash string, tim *modal.TextInputModal) bool {
+				go func() {
+					err := pg.WL.Wallet.AddTreasurySpend(hash)
+					if err != nil {
+						tim.SetError(err.Error())
+						tim.SetLoading(false)
+						return
+					}
+					pg.Toast.Notify("Treasury spend added")
+					tim.Dismiss()
+					pg.FetchTreasurySpends()
+				}()
+				return false
+			})
+
+		textModal.Title("Add treasury spend").
+			NegativeButton(values.String(values.StrCancel), func() {})
+		textModal.Show()
+	}
+
+	if pg.infoButton.Button.Clicked() {
+		modal.NewInfoModal(pg.Load).
+			Title("Treasury spends").
+			Body("Spends from the decentralized treasury are approved by ticket holders. "+
+				"Set a policy for a single treasury spend, or for all spends signed by a Pi key. "+
+				"The wallets learn about pending treasury spends from their peers while synced, "+
+				"the ones that are not listed yet can be fetched by their hash from the block explorers set in the settings. "+
+				"The network votes are counted from the blocks of the voting window, fetched from the peers.").
+			SetCancelable(true).
+			PositiveButton("Got it", func() {}).Show()
+	}
+}
+
+func (pg *TreasuryPage) FetchTreasurySpends() {
+	if len(pg.wallets) == 0 {
+		return
+	}
+	selectedWallet := pg.wallets[pg.walletDropDown.SelectedIndex()]
+
+	pg.isLoading = true
+	go func() {
+		tspends, err := pg.WL.Wallet.TreasurySpends(selectedWallet.ID)
+		pg.isLoading = false
+		if err != nil {
+			pg.Toast.NotifyError(err.Error())
+			return
+		}
+
+		items := make([]*treasuryItem, len(tspends))
+		for i := range tspends {
+			voteBtn := pg.Theme.Button("Set policy")
+			voteBtn.Font.Weight = text.Medium
+			voteBtn.TextSize = values.TextSize14
+			if selectedWallet.IsWatchingOnlyWallet() {
+				voteBtn.SetEnabled(false)
+			}
+			items[i] = &treasuryItem{
+				tspend:  tspends[i],
+				voteBtn: voteBtn,
+			}
+		}
+		pg.treasuryItems = items
+		pg.RefreshWindow()
+
+		for _, item := range items {
+			tally, err := pg.WL.Wallet.TreasurySpendTally(selectedWallet.ID, item.tspend)
+			pg.tallyMu.Lock()
+			item.tally, item.tallyErr = tally, err
+			pg.tallyMu.Unlock()
+			pg.RefreshWindow()
+		}
+	}()
+	pg.RefreshWindow()
+}
+
+// tallyText returns the votes cast on chain for the treasury spend of the
+// item.
+func (pg *TreasuryPage) tallyText(item *treasuryItem) string {
+	pg.tallyMu.Lock()
+	tally, err := item.tally, item.tallyErr
+	pg.tallyMu.Unlock()
+
+	var txt string
+	switch {
+	case tally == nil && err == nil:
+		return "Counting the votes..."
+	case tally == nil:
+		return fmt.Sprintf("The votes cannot be counted: %v", err)
+	case !tally.Started():
+		txt = fmt.Sprintf("Voting starts at block %d", tally.Start)
+	default:
+		txt = fmt.Sprintf("%d yes, %d no up to block %d of %d (quorum %d of %d votes)",
+			tally.Yes, tally.No, tally.Height, tally.End-1, tally.Quorum, tally.MaxVotes)
+	}
+	if err != nil {
+		txt += fmt.Sprintf(", the newer blocks cannot be fetched: %v", err)
+	}
+	return txt
+}
+
+func (pg *TreasuryPage) Layout(gtx C) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+						layout.Rigid(pg.Theme.Label(values.TextSize20, "Treasury Spending").Layout),
+						layout.Rigid(pg.infoButton.Layout),
+					)
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					return layout.E.Layout(gtx, pg.addTSpendBtn.Layout)
+				}),
+			)
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Inset{Top: values.MarginPadding10}.Layout(gtx, func(gtx C) D {
+				return layout.Stack{}.Layout(gtx,
+					layout.Expanded(func(gtx C) D {
+						return layout.Inset{
+							Top: values.MarginPadding60,
+						}.Layout(gtx, pg.layoutContent)
+					}),
+					layout.Expanded(func(gtx C) D {
+						return pg.walletDropDown.Layout(gtx, 0, true)
+					}),
+				)
+			})
+		}),
+	)
+}
+
+func (pg *TreasuryPage) layoutContent(gtx C) D {
+	if len(pg.treasuryItems) == 0 {
+		text := "No pending treasury spends"
+		if pg.isLoading {
+			text = "Fetching treasury spends..."
+		}
+		lbl := pg.Theme.Body1(text)
+		lbl.Color = pg.Theme.Color.GrayText3
+		return layout.Center.Layout(gtx, lbl.Layout)
+	}
+
+	return pg.Theme.List(pg.listContainer).Layout(gtx, len(pg.treasuryItems), func(gtx C, i int) D {
+		return decredmaterial.LinearLayout{
+			Orientation: layout.Vertical,
+			Width:       decredmaterial.MatchParent,
+			Height:      decredmaterial.WrapContent,
+			Background:  pg.Theme.Color.Surface,
+			Direction:   layout.W,
+			Border:      decredmaterial.Border{Radius: decredmaterial.Radius(14)},
+			Padding:     layout.UniformInset(values.MarginPadding15),
+			Margin:      layout.Inset{Bottom: values.MarginPadding4, Top: values.MarginPadding4, Right: values.MarginPadding2}}.
+			Layout2(gtx, func(gtx C) D {
+				return pg.layoutTreasuryItem(gtx, pg.treasuryItems[i])
+			})
+	})
+}
+
+func (pg *TreasuryPage) layoutTreasuryItem(gtx C, item *treasuryItem) D {
+	tspend := item.tspend
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{Spacing: layout.SpaceBetween, Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					lbl := pg.Theme.H6(dcrutil.Amount(tspend.Amount).String())
+					lbl.Font.Weight = text.SemiBold
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(item.voteBtn.Layout),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{Top: values.MarginPadding8}.Layout(gtx, func(gtx C) D {
+				return pg.layoutTreasuryRow(gtx, "Hash", tspend.Hash)
+			})
+		}),
+		layout.Rigid(func(gtx C) D {
+			return pg.layoutTreasuryRow(gtx, "Pi key", tspend.PiKey)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return pg.layoutTreasuryRow(gtx, "Expiry", fmt.Sprintf("Block %d", tspend.Expiry))
+		}),
+		layout.Rigid(func(gtx C) D {
+			return pg.layoutTreasuryRow(gtx, "Policy", fmt.Sprintf("%s (Pi key: %s)", tspend.Policy, tspend.KeyPolicy))
+		}),
+		layout.Rigid(func(gtx C) D {
+			return pg.layoutTreasuryRow(gtx, "Network votes", pg.tallyText(item))
+		}),
+		layout.Rigid(func(gtx C) D {
+			policies := fmt.Sprintf("%d tickets yes, %d no, %d abstain", tspend.PolicyYes, tspend.PolicyNo, tspend.PolicyAbstain)
+			return pg.layoutTreasuryRow(gtx, "Your policies", policies)
+		}),
+	)
+}
+
+func (pg *TreasuryPage) layoutTreasuryRow(gtx C, title, value string) D {
+	return layout.Inset{Top: values.MarginPadding4}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				gtx.Constraints.Min.X = gtx.Px(values.MarginPadding100)
+				lbl := pg.Theme.Label(values.TextSize14, title)
+				lbl.Color = pg.Theme.Color.GrayText2
+				return lbl.Layout(gtx)
+			}),
+			layout.Flexed(1, pg.Theme.Label(values.TextSize14, value).Layout),
+		)
+	})
+}
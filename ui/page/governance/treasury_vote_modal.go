@@ -0,0 +1,262 @@
+package governance
+
+import (
+	"fmt"
+	"sort"
+
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/planetdecred/dcrlibwallet"
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+const (
+	treasuryScopeTSpend = "tspend"
+	treasuryScopePiKey  = "pikey"
+)
+
+var treasuryVoteChoices = []string{wallet.TreasuryVoteYes, wallet.TreasuryVoteNo, wallet.TreasuryVoteAbstain}
+
+type treasuryVoteModal struct {
+	*load.Load
+	modal decredmaterial.Modal
+
+	// tickets that have not been spent by a vote or revocation (unspent) and that
+	// have not expired (unexpired).
+	votableTickets []*dcrlibwallet.Transaction
+
+	tspend           *wallet.TreasurySpend
+	isVoting         bool
+	modalUpdateCount int // this keeps track of the number of times the modal has been updated.
+
+	onPolicyUpdated func()
+
+	walletSelector    *WalletSelector
+	ticketSelector    *ticketSelector
+	spendingPassword  decredmaterial.Editor
+	materialLoader    material.LoaderStyle
+	scopeRadioGroup   *widget.Enum
+	optionsRadioGroup *widget.Enum
+	voteBtn           decredmaterial.Button
+	cancelBtn         decredmaterial.Button
+}
+
+func newTreasuryVoteModal(l *load.Load, tspend *wallet.TreasurySpend, onPolicyUpdated func()) *treasuryVoteModal {
+	tvm := &treasuryVoteModal{
+		Load:              l,
+		modal:             *l.Theme.ModalFloatTitle(),
+		tspend:            tspend,
+		onPolicyUpdated:   onPolicyUpdated,
+		materialLoader:    material.Loader(material.NewTheme(gofont.Collection())),
+		scopeRadioGroup:   &widget.Enum{Value: treasuryScopeTSpend},
+		optionsRadioGroup: &widget.Enum{Value: tspend.Policy},
+		spendingPassword:  l.Theme.EditorPassword(new(widget.Editor), "Spending password"),
+		voteBtn:           l.Theme.Button("Update Policy"),
+		cancelBtn:         l.Theme.OutlineButton("Cancel"),
+	}
+
+	tvm.voteBtn.Background = l.Theme.Color.Gray3
+	tvm.voteBtn.Color = l.Theme.Color.Surface
+
+	tvm.walletSelector = NewWalletSelector(l).
+		Title("Select wallet").
+		WalletSelected(func(w *dcrlibwallet.Wallet) {
+			tvm.modalUpdateCount = 0 // modal just opened.
+			tvm.ticketSelector = nil
+
+			tvm.FetchUnspentUnexpiredTickets(w.ID)
+			tvm.modalUpdateCount++
+		}).
+		WalletValidator(func(w *dcrlibwallet.Wallet) bool {
+			return !w.IsWatchingOnlyWallet()
+		})
+
+	return tvm
+}
+
+func (tvm *treasuryVoteModal) FetchUnspentUnexpiredTickets(walletID int) {
+	go func() {
+		wallet := tvm.WL.MultiWallet.WalletWithID(walletID)
+		tickets, err := wallet.UnspentUnexpiredTickets()
+		if err != nil {
+			tvm.Toast.NotifyError(err.Error())
+			return
+		}
+
+		// sort by newest first
+		sort.Slice(tickets[:], func(i, j int) bool {
+			return tickets[i].Timestamp > tickets[j].Timestamp
+		})
+		tvm.votableTickets = make([]*dcrlibwallet.Transaction, len(tickets))
+		for i := range tickets {
+			tvm.votableTickets[i] = &tickets[i]
+		}
+		tvm.RefreshWindow()
+	}()
+}
+
+func (tvm *treasuryVoteModal) ModalID() string {
+	return ModalInputVote
+}
+
+func (tvm *treasuryVoteModal) OnResume() {
+	tvm.walletSelector.SelectFirstValidWallet()
+}
+
+func (tvm *treasuryVoteModal) OnDismiss() {}
+
+func (tvm *treasuryVoteModal) Show() {
+	tvm.ShowModal(tvm)
+}
+
+func (tvm *treasuryVoteModal) Dismiss() {
+	tvm.DismissModal(tvm)
+}
+
+func (tvm *treasuryVoteModal) Handle() {
+	for tvm.cancelBtn.Clicked() {
+		if tvm.isVoting {
+			continue
+		}
+		tvm.Dismiss()
+	}
+
+	_, isChanged := decredmaterial.HandleEditorEvents(tvm.spendingPassword.Editor)
+	if isChanged {
+		tvm.spendingPassword.SetError("")
+	}
+
+	if len(tvm.votableTickets) != 0 {
+		if tvm.modalUpdateCount == 1 { // modal window has been updated once.
+			tvm.modalUpdateCount++
+			tvm.ticketSelector = newTicketSelector(tvm.Load, tvm.votableTickets).Title("Select a ticket")
+		}
+	}
+
+	validToVote := tvm.optionsRadioGroup.Value != "" && tvm.spendingPassword.Editor.Text() != ""
+	tvm.voteBtn.SetEnabled(validToVote)
+	tvm.voteBtn.Background = tvm.Theme.Color.Gray3
+	if tvm.voteBtn.Enabled() {
+		tvm.voteBtn.Background = tvm.Theme.Color.Primary
+	}
+
+	for tvm.voteBtn.Clicked() {
+		if tvm.isVoting || !validToVote {
+			break
+		}
+
+		tvm.isVoting = true
+		tvm.sendPolicy()
+	}
+
+	if tvm.modal.BackdropClicked(true) {
+		tvm.Dismiss()
+	}
+}
+
+// - Layout
+
+func (tvm *treasuryVoteModal) Layout(gtx layout.Context) D {
+	w := []layout.Widget{
+		func(gtx C) D {
+			t := tvm.Theme.H6("Update Treasury Policy")
+			t.Font.Weight = text.SemiBold
+			return t.Layout(gtx)
+		},
+		tvm.walletSelector.Layout,
+		func(gtx C) D {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(tvm.Theme.RadioButton(tvm.scopeRadioGroup, treasuryScopeTSpend, "This treasury spend", tvm.Theme.Color.DeepBlue, tvm.Theme.Color.Primary).Layout),
+				layout.Rigid(tvm.Theme.RadioButton(tvm.scopeRadioGroup, treasuryScopePiKey, fmt.Sprintf("All spends signed by Pi key %s", tvm.tspend.PiKey), tvm.Theme.Color.DeepBlue, tvm.Theme.Color.Primary).Layout),
+			)
+		},
+		func(gtx C) D {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, tvm.layoutItems()...)
+		},
+		func(gtx C) D {
+			if tvm.ticketSelector == nil {
+				return D{}
+			}
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					lbl := tvm.Theme.Body2("Optionally select a ticket, the policy is set for all tickets otherwise.")
+					lbl.Color = tvm.Theme.Color.GrayText2
+					return layout.Inset{Bottom: values.MarginPadding8}.Layout(gtx, lbl.Layout)
+				}),
+				layout.Rigid(tvm.ticketSelector.Layout),
+			)
+		},
+		tvm.spendingPassword.Layout,
+		func(gtx C) D {
+			return layout.E.Layout(gtx, func(gtx C) D {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, tvm.cancelBtn.Layout)
+					}),
+					layout.Rigid(func(gtx C) D {
+						if tvm.isVoting {
+							return tvm.materialLoader.Layout(gtx)
+						}
+						return tvm.voteBtn.Layout(gtx)
+					}),
+				)
+			})
+		},
+	}
+
+	return tvm.modal.Layout(gtx, w)
+}
+
+func (tvm *treasuryVoteModal) layoutItems() []layout.FlexChild {
+	items := make([]layout.FlexChild, 0, len(treasuryVoteChoices))
+	for _, voteChoice := range treasuryVoteChoices {
+		radioBtn := tvm.Theme.RadioButton(tvm.optionsRadioGroup, voteChoice, voteChoice, tvm.Theme.Color.DeepBlue, tvm.Theme.Color.Primary)
+		items = append(items, layout.Rigid(radioBtn.Layout))
+	}
+
+	return items
+}
+
+func (tvm *treasuryVoteModal) sendPolicy() {
+	go func() {
+		password := []byte(tvm.spendingPassword.Editor.Text())
+
+		defer func() {
+			tvm.isVoting = false
+		}()
+
+		var ticketHash string
+		if tvm.ticketSelector != nil && tvm.ticketSelector.SelectedTicket() != nil {
+			ticketHash = tvm.ticketSelector.SelectedTicket().Hash
+		}
+
+		walletID := tvm.walletSelector.SelectedWallet().ID
+		policy := tvm.optionsRadioGroup.Value
+
+		var err error
+		if tvm.scopeRadioGroup.Value == treasuryScopePiKey {
+			err = tvm.WL.Wallet.SetTreasuryKeyPolicy(walletID, tvm.tspend.PiKey, policy, ticketHash, password)
+		} else {
+			err = tvm.WL.Wallet.SetTreasurySpendPolicy(walletID, tvm.tspend.Hash, policy, ticketHash, password)
+		}
+		if err != nil {
+			if err.Error() == dcrlibwallet.ErrInvalidPassphrase {
+				tvm.spendingPassword.SetError("Invalid password")
+			} else {
+				tvm.Toast.NotifyError(err.Error())
+			}
+			return
+		}
+		tvm.Toast.Notify("Treasury policy updated successfully")
+
+		tvm.Dismiss()
+		tvm.onPolicyUpdated()
+	}()
+}
@@ -0,0 +1,156 @@
+package page
+
+import (
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/values"
+)
+
+const LockPageID = "lock_page"
+
+// lockPage covers the window after a period without user activity. The
+// pages and modals underneath are kept as they were and are shown again
+// once the password is entered.
+type lockPage struct {
+	*load.Load
+
+	// unlocked is called from a goroutine once the password is verified.
+	unlocked func()
+
+	decredSymbol   *decredmaterial.Image
+	passwordEditor decredmaterial.Editor
+	unlockButton   decredmaterial.Button
+	materialLoader material.LoaderStyle
+
+	isUnlocking bool
+}
+
+func NewLockPage(l *load.Load, unlocked func()) load.Page {
+	pg := &lockPage{
+		Load:     l,
+		unlocked: unlocked,
+
+		decredSymbol:   l.Icons.DecredSymbolIcon,
+		passwordEditor: l.Theme.EditorPassword(new(widget.Editor), "Password"),
+		unlockButton:   l.Theme.Button("Unlock"),
+		materialLoader: material.Loader(l.Theme.Base),
+	}
+	pg.passwordEditor.Editor.SingleLine, pg.passwordEditor.Editor.Submit = true, true
+
+	return pg
+}
+
+// ID is a unique string that identifies the page and may be used
+// to differentiate this page from other pages.
+// Part of the load.Page interface.
+func (pg *lockPage) ID() string {
+	return LockPageID
+}
+
+// OnNavigatedTo is called when the page is about to be displayed and
+// may be used to initialize page features that are only relevant when
+// the page is displayed.
+// Part of the load.Page interface.
+func (pg *lockPage) OnNavigatedTo() {
+	pg.passwordEditor.Editor.SetText("")
+	pg.passwordEditor.SetError("")
+	pg.passwordEditor.Editor.Focus()
+
+	if pg.WL.MultiWallet.IsStartupSecuritySet() {
+		pg.passwordEditor.Hint = "Startup password"
+	} else {
+		pg.passwordEditor.Hint = "Spending password"
+	}
+}
+
+// HandleUserInteractions is called just before Layout() to determine
+// if any user interaction recently occurred on the page and may be
+// used to update the page's UI components shortly before they are
+// displayed.
+// Part of the load.Page interface.
+func (pg *lockPage) HandleUserInteractions() {
+	submitted, changed := decredmaterial.HandleEditorEvents(pg.passwordEditor.Editor)
+	if changed {
+		pg.passwordEditor.SetError("")
+	}
+
+	password := pg.passwordEditor.Editor.Text()
+	pg.unlockButton.SetEnabled(password != "" && !pg.isUnlocking)
+
+	if (pg.unlockButton.Clicked() || submitted) && password != "" && !pg.isUnlocking {
+		pg.isUnlocking = true
+		go func() {
+			defer func() {
+				pg.isUnlocking = false
+				pg.RefreshWindow()
+			}()
+
+			err := pg.WL.Wallet.VerifyUnlockPassphrase([]byte(password))
+			if err != nil {
+				pg.passwordEditor.SetError(translateErr(err))
+				return
+			}
+
+			pg.passwordEditor.Editor.SetText("")
+			pg.unlocked()
+		}()
+	}
+}
+
+// OnNavigatedFrom is called when the page is about to be removed from
+// the displayed window. This method should ideally be used to disable
+// features that are irrelevant when the page is NOT displayed.
+// NOTE: The page may be re-displayed on the app's window, in which case
+// OnNavigatedTo() will be called again. This method should not destroy UI
+// components unless they'll be recreated in the OnNavigatedTo() method.
+// Part of the load.Page interface.
+func (pg *lockPage) OnNavigatedFrom() {}
+
+// Layout draws the page UI components into the provided layout context
+// to be eventually drawn on screen.
+// Part of the load.Page interface.
+func (pg *lockPage) Layout(gtx C) D {
+	gtx.Constraints.Min = gtx.Constraints.Max // use maximum height & width
+	description := values.StrUnlockWithSpend
+	if pg.WL.MultiWallet.IsStartupSecuritySet() {
+		description = values.StrUnlockWithStartup
+	}
+
+	return layout.Center.Layout(gtx, func(gtx C) D {
+		gtx.Constraints.Max.X = gtx.Px(values.MarginPadding350)
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return pg.decredSymbol.LayoutSize(gtx, values.MarginPadding150)
+			}),
+			layout.Rigid(func(gtx C) D {
+				title := pg.Theme.Label(values.TextSize20, values.String(values.StrWalletLocked))
+				title.Font.Weight = text.Medium
+				return layout.Inset{Top: values.MarginPadding24}.Layout(gtx, title.Layout)
+			}),
+			layout.Rigid(func(gtx C) D {
+				lbl := pg.Theme.Body2(values.String(description))
+				lbl.Color = pg.Theme.Color.GrayText2
+				lbl.Alignment = text.Middle
+				return layout.Inset{Top: values.MarginPadding8}.Layout(gtx, lbl.Layout)
+			}),
+			layout.Rigid(func(gtx C) D {
+				return layout.Inset{Top: values.MarginPadding16}.Layout(gtx, pg.passwordEditor.Layout)
+			}),
+			layout.Rigid(func(gtx C) D {
+				return layout.Inset{Top: values.MarginPadding16}.Layout(gtx, func(gtx C) D {
+					if pg.isUnlocking {
+						return pg.materialLoader.Layout(gtx)
+					}
+					gtx.Constraints.Min.X = gtx.Constraints.Max.X
+					return pg.unlockButton.Layout(gtx)
+				})
+			}),
+		)
+	})
+}
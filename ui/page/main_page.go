@@ -2,7 +2,9 @@ package page
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"image/color"
 	"path/filepath"
 	"strconv"
 	"time"
@@ -19,6 +21,7 @@ import (
 	"github.com/planetdecred/godcr/ui/decredmaterial"
 	"github.com/planetdecred/godcr/ui/load"
 	"github.com/planetdecred/godcr/ui/modal"
+	"github.com/planetdecred/godcr/ui/notification"
 	"github.com/planetdecred/godcr/ui/page/components"
 	"github.com/planetdecred/godcr/ui/page/dexclient"
 	"github.com/planetdecred/godcr/ui/page/governance"
@@ -33,6 +36,14 @@ import (
 
 const (
 	MainPageID = "Main"
+
+	// ticketNotificationListenerID identifies the ticket notification
+	// listener, which is registered as a second tx and block listener.
+	ticketNotificationListenerID = MainPageID + "-tickets"
+
+	// mixerScheduleInterval is how often the account mixer schedules are
+	// checked.
+	mixerScheduleInterval = time.Minute
 )
 
 var (
@@ -59,6 +70,7 @@ type MainPage struct {
 	*listeners.SyncProgressListener
 	*listeners.TxAndBlockNotificationListener
 	*listeners.ProposalNotificationListener
+	*listeners.TicketNotificationListener
 	ctx       context.Context
 	ctxCancel context.CancelFunc
 	appBarNav components.NavDrawer
@@ -80,6 +92,9 @@ type MainPage struct {
 	isBalanceHidden        bool
 	totalBalance           dcrutil.Amount
 	totalBalanceUSD        string
+	ticketBuyersResumed    bool
+	seedReminderShown      bool
+	autoSyncStarted        bool
 }
 
 func NewMainPage(l *load.Load) *MainPage {
@@ -95,8 +110,11 @@ func NewMainPage(l *load.Load) *MainPage {
 	// init shared page functions
 	toggleSync := func() {
 		if mp.WL.MultiWallet.IsConnectedToDecredNetwork() {
+			mp.WL.Wallet.CancelSyncRetry()
+			mp.WL.Wallet.RecordSyncEvent("Disconnected by user")
 			mp.WL.MultiWallet.CancelSync()
 		} else {
+			mp.WL.Wallet.CancelSyncRetry()
 			mp.StartSyncing()
 		}
 	}
@@ -210,6 +228,7 @@ func (mp *MainPage) initNavItems() {
 // Part of the load.Page interface.
 func (mp *MainPage) OnNavigatedTo() {
 	mp.setLanguageSetting()
+	load.MigrateTicketNotifications(mp.WL.Wallet)
 
 	mp.ctx, mp.ctxCancel = context.WithCancel(context.TODO())
 	mp.listenForNotifications()
@@ -226,16 +245,44 @@ func (mp *MainPage) OnNavigatedTo() {
 		mp.receivePage.OnNavigatedTo()
 	}
 
-	if mp.WL.Wallet.ReadBoolConfigValueForKey(load.AutoSyncConfigKey) {
+	// Auto sync starts the sync once, when the app starts. The sync is not
+	// restarted on navigating back here after the user disconnected.
+	if !mp.autoSyncStarted && mp.WL.Wallet.ReadBoolConfigValueForKey(load.AutoSyncConfigKey) {
+		mp.autoSyncStarted = true
+		mp.WL.Wallet.RecordSyncEvent("Auto sync on startup")
 		mp.StartSyncing()
 		if mp.WL.Wallet.ReadBoolConfigValueForKey(load.FetchProposalConfigKey) {
-			go mp.WL.MultiWallet.Politeia.Sync()
+			go mp.WL.Wallet.SyncPoliteia()
 		}
 	}
 
+	// Resume the ticket buyers that were running when the app was last closed.
+	if !mp.ticketBuyersResumed {
+		mp.ticketBuyersResumed = true
+		staking.ResumeTicketBuyers(mp.Load)
+	}
+
+	// Remind once per session of the seed backups to verify again.
+	if !mp.seedReminderShown {
+		mp.seedReminderShown = true
+		mp.remindSeedVerification()
+	}
+
 	mp.updateBalance()
 }
 
+func (mp *MainPage) remindSeedVerification() {
+	stale := mp.WL.Wallet.WalletsWithStaleSeedBackup()
+	switch len(stale) {
+	case 0:
+		return
+	case 1:
+		mp.Toast.Notify(fmt.Sprintf("The seed backup of %s is due for verification, see the Wallets page", stale[0].Name))
+	default:
+		mp.Toast.Notify(fmt.Sprintf("%d seed backups are due for verification, see the Wallets page", len(stale)))
+	}
+}
+
 func (mp *MainPage) setLanguageSetting() {
 	langPre := mp.WL.Wallet.ReadStringConfigValueForKey(load.LanguagePreferenceKey)
 	if langPre == "" {
@@ -264,12 +311,16 @@ func (mp *MainPage) fetchExchangeRate() {
 	if mp.isFetchingExchangeRate {
 		return
 	}
+	if err := mp.WL.Wallet.CheckConnection(wallet.ProxySubsystemExchange); err != nil {
+		log.Info(err)
+		return
+	}
 	maxAttempts := 5
 	delayBtwAttempts := 2 * time.Second
 	mp.isFetchingExchangeRate = true
 	desc := "for getting dcrUsdtBittrex exchange rate value"
 	attempts, err := components.RetryFunc(maxAttempts, delayBtwAttempts, desc, func() error {
-		return load.GetUSDExchangeValue(&mp.dcrUsdtBittrex)
+		return load.GetUSDExchangeValue(mp.WL.Wallet.HTTPClient(wallet.ProxySubsystemExchange), &mp.dcrUsdtBittrex)
 	})
 	if err != nil {
 		log.Errorf("error fetching usd exchange rate value after %d attempts: %v", attempts, err)
@@ -306,10 +357,13 @@ func (mp *MainPage) StartSyncing() {
 		}
 	}
 
-	err := mp.WL.MultiWallet.SpvSync()
+	err := mp.WL.Wallet.StartSync()
 	if err != nil {
-		// show error dialog
 		log.Info("Error starting sync:", err)
+		mp.WL.Wallet.RecordSyncEvent("Error starting sync: " + err.Error())
+		if errors.Is(err, wallet.ErrDirectConnection) {
+			mp.Toast.NotifyError(err.Error())
+		}
 	}
 }
 
@@ -401,10 +455,14 @@ func (mp *MainPage) HandleUserInteractions() {
 			case governance.GovernancePageID:
 				pg = governance.NewGovernancePage(mp.Load)
 			case dexclient.MarketPageID:
-				_, err := mp.WL.MultiWallet.StartDexClient() // does nothing if already started
+				err := mp.WL.Wallet.CheckConnection(wallet.ProxySubsystemDEX)
+				if err == nil {
+					_, err = mp.WL.MultiWallet.StartDexClient() // does nothing if already started
+				}
 				if err != nil {
 					mp.Toast.NotifyError(fmt.Sprintf("Unable to start DEX client: %v", err))
 				} else {
+					dexclient.ListenForNotifications(mp.Load)
 					pg = dexclient.NewMarketPage(mp.Load)
 				}
 			case MorePageID:
@@ -644,6 +702,75 @@ func (mp *MainPage) totalDCRBalance(gtx layout.Context) layout.Dimensions {
 	return components.LayoutBalance(gtx, mp.Load, mp.totalBalance.String())
 }
 
+// proxyIndicator shows the proxy address while the outbound connections go
+// through the proxy.
+func (mp *MainPage) proxyIndicator(gtx layout.Context) layout.Dimensions {
+	if !mp.WL.Wallet.ProxyEnabled() {
+		return layout.Dimensions{}
+	}
+
+	return layout.Inset{Left: values.MarginPadding16}.Layout(gtx, func(gtx C) D {
+		return decredmaterial.LinearLayout{
+			Width:      decredmaterial.WrapContent,
+			Height:     decredmaterial.WrapContent,
+			Background: mp.Theme.Color.Success2,
+			Padding: layout.Inset{
+				Top:    values.MarginPadding4,
+				Bottom: values.MarginPadding4,
+				Left:   values.MarginPadding8,
+				Right:  values.MarginPadding8,
+			},
+			Border: decredmaterial.Border{Radius: decredmaterial.Radius(8)},
+		}.Layout2(gtx, func(gtx C) D {
+			txt := mp.Theme.Caption("Proxy on: " + mp.WL.Wallet.ProxyAddress())
+			txt.Color = mp.Theme.Color.Success
+			return txt.Layout(gtx)
+		})
+	})
+}
+
+// networkTint returns the name and the colors of the network the wallets
+// run on, so the test networks are not mistaken for mainnet. ok is false on
+// mainnet.
+func (mp *MainPage) networkTint() (name string, fg, bg color.NRGBA, ok bool) {
+	switch mp.WL.Wallet.Net {
+	case dcrlibwallet.Testnet3:
+		return "Testnet", mp.Theme.Color.Orange, mp.Theme.Color.Orange2, true
+	case wallet.Simnet:
+		return "Simnet", mp.Theme.Color.Turquoise800, mp.Theme.Color.Turquoise100, true
+	case wallet.Regnet:
+		return "Regnet", mp.Theme.Color.PrimaryHighlight, mp.Theme.Color.LightBlue3, true
+	}
+	return "", color.NRGBA{}, color.NRGBA{}, false
+}
+
+// networkIndicator shows the network the wallets run on, except mainnet.
+func (mp *MainPage) networkIndicator(gtx layout.Context) layout.Dimensions {
+	name, fg, bg, ok := mp.networkTint()
+	if !ok {
+		return layout.Dimensions{}
+	}
+
+	return layout.Inset{Left: values.MarginPadding16}.Layout(gtx, func(gtx C) D {
+		return decredmaterial.LinearLayout{
+			Width:      decredmaterial.WrapContent,
+			Height:     decredmaterial.WrapContent,
+			Background: bg,
+			Padding: layout.Inset{
+				Top:    values.MarginPadding4,
+				Bottom: values.MarginPadding4,
+				Left:   values.MarginPadding8,
+				Right:  values.MarginPadding8,
+			},
+			Border: decredmaterial.Border{Radius: decredmaterial.Radius(8)},
+		}.Layout2(gtx, func(gtx C) D {
+			txt := mp.Theme.Caption(name)
+			txt.Color = fg
+			return txt.Layout(gtx)
+		})
+	})
+}
+
 func (mp *MainPage) LayoutTopBar(gtx layout.Context) layout.Dimensions {
 	return decredmaterial.LinearLayout{
 		Width:       decredmaterial.MatchParent,
@@ -703,6 +830,8 @@ func (mp *MainPage) LayoutTopBar(gtx layout.Context) layout.Dimensions {
 											Left: values.MarginPadding9,
 										}.Layout(gtx, mp.hideBalanceItem.hideBalanceButton.Layout)
 									}),
+									layout.Rigid(mp.networkIndicator),
+									layout.Rigid(mp.proxyIndicator),
 								)
 							})
 					})
@@ -715,6 +844,12 @@ func (mp *MainPage) LayoutTopBar(gtx layout.Context) layout.Dimensions {
 		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			// The test networks tint the bottom edge of the top bar.
+			if _, fg, _, ok := mp.networkTint(); ok {
+				line := mp.Theme.Line(gtx.Px(values.MarginPadding4), 0)
+				line.Color = fg
+				return line.Layout(gtx)
+			}
 			return mp.Theme.Separator().Layout(gtx)
 		}),
 	)
@@ -734,11 +869,6 @@ func (mp *MainPage) postDesktopNotification(notifier interface{}) {
 			// remove trailing zeros from amount and convert to string
 			amount := strconv.FormatFloat(dcrlibwallet.AmountCoin(t.Transaction.Amount), 'f', -1, 64)
 			notification = fmt.Sprintf("You have received %s DCR", amount)
-		case dcrlibwallet.TxTypeVote:
-			reward := strconv.FormatFloat(dcrlibwallet.AmountCoin(t.Transaction.VoteReward), 'f', -1, 64)
-			notification = fmt.Sprintf("A ticket just voted\nVote reward: %s DCR", reward)
-		case dcrlibwallet.TxTypeRevocation:
-			notification = "A ticket was revoked"
 		default:
 			return
 		}
@@ -772,6 +902,69 @@ func (mp *MainPage) postDesktopNotification(notifier interface{}) {
 	}
 }
 
+// postTicketNotification shows a ticket lifecycle notification as a toast
+// and/or a desktop notification, as configured for the notification type.
+func (mp *MainPage) postTicketNotification(n listeners.TicketNotification) {
+	var configKey, message string
+	switch n.Type {
+	case listeners.TicketMatured:
+		configKey = load.TicketMaturedNotificationConfigKey
+		message = "The stake of a voted or revoked ticket is now spendable"
+	case listeners.TicketLive:
+		configKey = load.TicketLiveNotificationConfigKey
+		message = "A ticket is now live"
+	case listeners.TicketVoted:
+		configKey = load.TicketVotedNotificationConfigKey
+		message = "A ticket just voted"
+		if n.Transaction != nil {
+			reward := strconv.FormatFloat(dcrlibwallet.AmountCoin(n.Transaction.VoteReward), 'f', -1, 64)
+			message = fmt.Sprintf("A ticket just voted\nVote reward: %s DCR", reward)
+		}
+	case listeners.TicketRevoked:
+		configKey = load.TicketRevokedNotificationConfigKey
+		message = "A ticket was revoked"
+	case listeners.TicketExpired:
+		configKey = load.TicketExpiredNotificationConfigKey
+		message = "A ticket expired without voting"
+	case listeners.TicketMissed:
+		configKey = load.TicketMissedNotificationConfigKey
+		message = "A ticket missed its vote"
+	default:
+		return
+	}
+
+	delivery := mp.WL.Wallet.ReadStringConfigValueForKey(configKey)
+	if delivery == "" || delivery == values.TicketNotificationOff {
+		return
+	}
+
+	if mp.WL.MultiWallet.OpenedWalletsCount() > 1 {
+		wallet := mp.WL.MultiWallet.WalletWithID(n.WalletID)
+		if wallet == nil {
+			return
+		}
+
+		message = fmt.Sprintf("[%s] %s", wallet.Name, message)
+	}
+
+	if delivery == values.TicketNotificationToast || delivery == values.TicketNotificationBoth {
+		mp.Toast.Notify(message)
+	}
+
+	if delivery == values.TicketNotificationSystem || delivery == values.TicketNotificationBoth {
+		systemNotification, err := notification.NewSystemNotification()
+		if err != nil {
+			log.Errorf("could not initiate desktop notification: %v", err)
+			return
+		}
+
+		err = systemNotification.Notify(message)
+		if err != nil {
+			log.Info("could not initiate desktop notification, reason:", err.Error())
+		}
+	}
+}
+
 func initializeBeepNotification(n string) {
 	absoluteWdPath, err := GetAbsolutePath()
 	if err != nil {
@@ -795,6 +988,8 @@ func (mp *MainPage) listenForNotifications() {
 		return
 	case mp.ProposalNotificationListener != nil:
 		return
+	case mp.TicketNotificationListener != nil:
+		return
 	}
 
 	mp.SyncProgressListener = listeners.NewSyncProgress()
@@ -818,9 +1013,26 @@ func (mp *MainPage) listenForNotifications() {
 		return
 	}
 
+	mp.TicketNotificationListener = listeners.NewTicketNotificationListener(mp.WL.MultiWallet)
+	err = mp.WL.MultiWallet.AddTxAndBlockNotificationListener(mp.TicketNotificationListener, true, ticketNotificationListenerID)
+	if err != nil {
+		log.Errorf("Error adding ticket notification listener: %v", err)
+		return
+	}
+
+	err = mp.WL.Wallet.RecordMixerSessions(MainPageID)
+	if err != nil {
+		log.Errorf("Error adding account mixer session recorder: %v", err)
+		return
+	}
+
+	mixerScheduleTicker := time.NewTicker(mixerScheduleInterval)
+
 	go func() {
 		for {
 			select {
+			case <-mixerScheduleTicker.C:
+				go mp.WL.Wallet.RunMixerSchedules()
 			case n := <-mp.TxAndBlockNotifChan:
 				switch n.Type {
 				case listeners.NewTransaction:
@@ -853,24 +1065,51 @@ func (mp *MainPage) listenForNotifications() {
 				// Post desktop notification for all events except the synced event.
 				if notification.ProposalStatus != wallet.Synced {
 					mp.postDesktopNotification(notification)
+				} else {
+					// keep a copy of the proposal bodies for offline reading.
+					go func() {
+						if err := mp.WL.Wallet.CacheProposalDescriptions(); err != nil {
+							log.Errorf("Error caching proposal descriptions: %v", err)
+						}
+					}()
 				}
+			case n := <-mp.TicketNotifChan:
+				mp.postTicketNotification(n)
 			case n := <-mp.SyncStatusChan:
-				if n.Stage == wallet.SyncCompleted {
+				switch n.Stage {
+				case wallet.SyncStarted:
+					mp.WL.Wallet.RecordSyncEvent("Sync started")
+				case wallet.SyncCanceled:
+					mp.WL.Wallet.RecordSyncEvent("Sync canceled")
+				case wallet.SyncCompleted:
+					mp.WL.Wallet.SyncCompleted()
+					mp.WL.Wallet.RecordSyncEvent("Sync completed")
+					go mp.WL.Wallet.RunMixerSchedules()
+					go mp.WL.Wallet.ApplyRestoredAccountNames()
 					mp.updateBalance()
 					mp.RefreshWindow()
+				case wallet.SyncEndedWithError:
+					syncErr := mp.WL.Wallet.HandleSyncError(n.Error, mp.StartSyncing)
+					mp.Toast.NotifyError(syncErr.Kind.Title() + ": " + syncErr.Kind.Explanation())
+					mp.RefreshWindow()
 				}
 			case <-mp.ctx.Done():
 				mp.WL.MultiWallet.RemoveSyncProgressListener(MainPageID)
 				mp.WL.MultiWallet.RemoveTxAndBlockNotificationListener(MainPageID)
 				mp.WL.MultiWallet.Politeia.RemoveNotificationListener(MainPageID)
+				mp.WL.MultiWallet.RemoveTxAndBlockNotificationListener(ticketNotificationListenerID)
+				mp.WL.MultiWallet.RemoveAccountMixerNotificationListener(MainPageID)
+				mixerScheduleTicker.Stop()
 
 				close(mp.SyncStatusChan)
 				close(mp.TxAndBlockNotifChan)
 				close(mp.ProposalNotifChan)
+				close(mp.TicketNotifChan)
 
 				mp.SyncProgressListener = nil
 				mp.TxAndBlockNotificationListener = nil
 				mp.ProposalNotificationListener = nil
+				mp.TicketNotificationListener = nil
 
 				return
 			}
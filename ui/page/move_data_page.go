@@ -0,0 +1,407 @@
+package page
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/godcr/appconfig"
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/modal"
+	"github.com/planetdecred/godcr/ui/page/components"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+const MoveDataPageID = "MoveData"
+
+// MoveDataPage moves the app directory, with the wallets and the config
+// file, to another directory such as an encrypted volume. The copy is
+// verified before the profile is set to the new directory.
+type MoveDataPage struct {
+	*load.Load
+
+	container    *widget.List
+	backButton   decredmaterial.IconButton
+	destination  decredmaterial.Editor
+	removeSource *widget.Bool
+	move         decredmaterial.Button
+	moveError    string
+	dataSize     string
+}
+
+func NewMoveDataPage(l *load.Load) *MoveDataPage {
+	pg := &MoveDataPage{
+		Load:         l,
+		container:    &widget.List{List: layout.List{Axis: layout.Vertical}},
+		destination:  l.Theme.Editor(new(widget.Editor), "New app directory"),
+		removeSource: new(widget.Bool),
+		move:         l.Theme.Button("Move wallet data"),
+	}
+	pg.destination.Editor.SingleLine = true
+	pg.backButton, _ = components.SubpageHeaderButtons(l)
+
+	return pg
+}
+
+// ID is a unique string that identifies the page and may be used
+// to differentiate this page from other pages.
+// Part of the load.Page interface.
+func (pg *MoveDataPage) ID() string {
+	return MoveDataPageID
+}
+
+// OnNavigatedTo is called when the page is about to be displayed and
+// may be used to initialize page features that are only relevant when
+// the page is displayed.
+// Part of the load.Page interface.
+func (pg *MoveDataPage) OnNavigatedTo() {
+	pg.dataSize = pg.WL.DataSize()
+}
+
+// skippedEntries are the paths of the app directory that are not moved: the
+// log directory, which is written to while the data is copied, the list of
+// profiles, which stays in the default app directory, and the app
+// directories of the other profiles inside it.
+func (pg *MoveDataPage) skippedEntries(profiles *appconfig.Profiles) []string {
+	root := filepath.Clean(pg.WL.Wallet.Root)
+	skip := []string{appconfig.DefaultProfilesFileName}
+	if logDir := filepath.Dir(pg.WL.Wallet.LogFile()); filepath.Dir(logDir) == root {
+		skip = append(skip, filepath.Base(logDir))
+	}
+	for _, profile := range profiles.Profiles {
+		if profile.HomeDir == root || !appconfig.IsInsideDir(root, profile.HomeDir) {
+			continue
+		}
+		if rel, err := filepath.Rel(root, profile.HomeDir); err == nil {
+			skip = append(skip, rel)
+		}
+	}
+	return skip
+}
+
+// HandleUserInteractions is called just before Layout() to determine
+// if any user interaction recently occurred on the page and may be
+// used to update the page's UI components shortly before they are
+// displayed.
+// Part of the load.Page interface.
+func (pg *MoveDataPage) HandleUserInteractions() {
+	_, changed := decredmaterial.HandleEditorEvents(pg.destination.Editor)
+	if changed {
+		pg.moveError = ""
+	}
+
+	for pg.move.Clicked() {
+		dst := appconfig.CleanAndExpandPath(strings.TrimSpace(pg.destination.Editor.Text()))
+		if err := wallet.CheckDataDirDestination(pg.WL.Wallet.Root, dst); err != nil {
+			pg.moveError = err.Error()
+			continue
+		}
+		profiles, err := appconfig.LoadProfiles(appconfig.DefaultProfilesFile)
+		if err != nil {
+			pg.moveError = err.Error()
+			continue
+		}
+		if _, err := profiles.CheckHomeDir(pg.WL.Wallet.Profile(), dst); err != nil {
+			pg.moveError = err.Error()
+			continue
+		}
+		skip := pg.skippedEntries(profiles)
+
+		removeSource := pg.removeSource.Value
+		body := fmt.Sprintf("The wallets are shut down and the app directory is copied to %s, then godcr closes. "+
+			"Start godcr again to open the wallets from the new directory.", dst)
+		if removeSource {
+			body += " The old files are deleted once the copy is verified and the profile is set to the new directory."
+		}
+		modal.NewInfoModal(pg.Load).
+			Title("Move wallet data").
+			Body(body).
+			NegativeButton(values.String(values.StrCancel), func() {}).
+			PositiveButton("Move", func() {
+				newMoveDataModal(pg.Load, dst, skip, removeSource).Show()
+			}).Show()
+	}
+}
+
+// OnNavigatedFrom is called when the page is about to be removed from
+// the displayed window. This method should ideally be used to disable
+// features that are irrelevant when the page is NOT displayed.
+// NOTE: The page may be re-displayed on the app's window, in which case
+// OnNavigatedTo() will be called again. This method should not destroy UI
+// components unless they'll be recreated in the OnNavigatedTo() method.
+// Part of the load.Page interface.
+func (pg *MoveDataPage) OnNavigatedFrom() {}
+
+// Layout draws the page UI components into the provided layout context
+// to be eventually drawn on screen.
+// Part of the load.Page interface.
+func (pg *MoveDataPage) Layout(gtx C) D {
+	container := func(gtx C) D {
+		sp := components.SubPage{
+			Load:       pg.Load,
+			Title:      "Wallet data directory",
+			BackButton: pg.backButton,
+			Back: func() {
+				pg.PopFragment()
+			},
+			Body: func(gtx C) D {
+				return pg.Theme.List(pg.container).Layout(gtx, 1, func(gtx C, i int) D {
+					return pg.moveSection(gtx)
+				})
+			},
+		}
+		return sp.Layout(gtx)
+	}
+	return components.UniformPadding(gtx, container)
+}
+
+func (pg *MoveDataPage) moveSection(gtx C) D {
+	card := pg.Theme.Card()
+	card.Radius = decredmaterial.Radius(14)
+	return card.Layout(gtx, func(gtx C) D {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		return layout.UniformInset(values.MarginPadding15).Layout(gtx, func(gtx C) D {
+			caption := func(txt string) layout.FlexChild {
+				return layout.Rigid(func(gtx C) D {
+					lbl := pg.Theme.Body2(txt)
+					lbl.Color = pg.Theme.Color.GrayText2
+					return layout.Inset{Top: values.MarginPadding10}.Layout(gtx, lbl.Layout)
+				})
+			}
+			inset := func(w layout.Widget) layout.FlexChild {
+				return layout.Rigid(func(gtx C) D {
+					return layout.Inset{Top: values.MarginPadding10}.Layout(gtx, w)
+				})
+			}
+
+			profile := pg.WL.Wallet.Profile()
+			if profile == "" {
+				profile = "none, the app directory is set with --appdata"
+			}
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					lbl := pg.Theme.Body1("Move wallet data")
+					lbl.Font.Weight = text.SemiBold
+					return lbl.Layout(gtx)
+				}),
+				caption("Move the wallets and the config file to another directory, such as an encrypted volume. "+
+					"The copied files are read again and compared to the originals before the profile is set to the new directory."),
+				inset(pg.Theme.Body2("App directory: "+pg.WL.Wallet.Root).Layout),
+				inset(pg.Theme.Body2("Profile: "+profile).Layout),
+				inset(pg.Theme.Body2("Wallet data: "+pg.dataSize).Layout),
+				inset(pg.destination.Layout),
+				inset(pg.Theme.CheckBox(pg.removeSource, "Delete the old files once the copy is verified").Layout),
+				layout.Rigid(func(gtx C) D {
+					if pg.moveError == "" {
+						return D{}
+					}
+					return layout.Inset{Top: values.MarginPadding10}.Layout(gtx, pg.Theme.ErrorLabel(pg.moveError).Layout)
+				}),
+				inset(func(gtx C) D {
+					return layout.E.Layout(gtx, pg.move.Layout)
+				}),
+			)
+		})
+	})
+}
+
+const moveDataModalID = "move_data_modal"
+
+// moveDataModal moves the wallet data and shows the progress. It cannot be
+// dismissed, the wallets are shut down and godcr must be closed once the
+// move ends, unless the copy failed and the wallets were opened again.
+type moveDataModal struct {
+	*load.Load
+
+	dst       string
+	skip      []string
+	removeOld bool
+
+	modal *decredmaterial.Modal
+	close decredmaterial.Button
+
+	mu       sync.Mutex
+	started  bool
+	progress wallet.MoveProgress
+	done     bool
+	result   string
+	failed   bool
+	reopened bool
+}
+
+func newMoveDataModal(l *load.Load, dst string, skip []string, removeOld bool) *moveDataModal {
+	return &moveDataModal{
+		Load:      l,
+		dst:       dst,
+		skip:      skip,
+		removeOld: removeOld,
+		modal:     l.Theme.ModalFloatTitle(),
+		close:     l.Theme.Button("Close godcr"),
+	}
+}
+
+func (md *moveDataModal) ModalID() string {
+	return moveDataModalID
+}
+
+func (md *moveDataModal) Show() {
+	md.ShowModal(md)
+}
+
+func (md *moveDataModal) Dismiss() {
+	md.DismissModal(md)
+}
+
+func (md *moveDataModal) OnDismiss() {}
+
+func (md *moveDataModal) OnResume() {
+	md.mu.Lock()
+	started := md.started
+	md.started = true
+	md.mu.Unlock()
+	if !started {
+		go md.moveData()
+	}
+}
+
+// moveData moves the wallet data, sets the profile to the new directory and
+// only then removes the old files. If the copy fails the wallets are opened
+// again and the app is reloaded from Handle.
+func (md *moveDataModal) moveData() {
+	wal := md.WL.Wallet
+	err := wal.MoveDataDir(md.dst, md.skip, func(p wallet.MoveProgress) {
+		md.mu.Lock()
+		md.progress = p
+		md.mu.Unlock()
+		md.RefreshWindow()
+	})
+
+	var result string
+	reopened := false
+	switch {
+	case errors.Is(err, wallet.ErrWalletsClosed):
+		result = fmt.Sprintf("The wallet data was not moved: %v. The wallets are still in %s, start godcr again to open them.", err, wal.Root)
+	case err != nil:
+		result = fmt.Sprintf("The wallet data was not moved: %v", err)
+		reopened = true
+	case wal.Profile() == "":
+		result = fmt.Sprintf("The wallet data was moved to %s. Start godcr with --appdata=%s to open the wallets.", md.dst, md.dst)
+		result = md.removeOldFiles(result)
+	default:
+		if err := md.setProfileHomeDir(wal.Profile()); err != nil {
+			result = fmt.Sprintf("The wallet data was copied to %s but the profile was not updated: %v. The old files were kept in %s, start godcr again to open them.",
+				md.dst, err, wal.Root)
+			break
+		}
+		result = md.removeOldFiles(fmt.Sprintf("The wallet data was moved to %s. Start godcr again to open the wallets.", md.dst))
+	}
+
+	md.mu.Lock()
+	md.done, md.result, md.failed, md.reopened = true, result, err != nil, reopened
+	md.mu.Unlock()
+	md.RefreshWindow()
+}
+
+// removeOldFiles removes the old files if they are to be deleted once the
+// new directory is in use, and returns the result with the removal error.
+func (md *moveDataModal) removeOldFiles(result string) string {
+	if !md.removeOld {
+		return result
+	}
+	if err := md.WL.Wallet.RemoveMovedData(md.skip); err != nil {
+		return fmt.Sprintf("%s The old files were not all deleted from %s: %v", result, md.WL.Wallet.Root, err)
+	}
+	return result
+}
+
+// setProfileHomeDir sets the app directory of the profile to the new
+// directory.
+func (md *moveDataModal) setProfileHomeDir(name string) error {
+	profiles, err := appconfig.LoadProfiles(appconfig.DefaultProfilesFile)
+	if err != nil {
+		return err
+	}
+	if err := profiles.SetHomeDir(name, md.dst); err != nil {
+		return err
+	}
+	return profiles.Save()
+}
+
+func (md *moveDataModal) Handle() {
+	md.mu.Lock()
+	reopened, result := md.reopened, md.result
+	md.reopened = false
+	md.mu.Unlock()
+	if reopened {
+		// The wallets were opened again, the pages still hold the multiwallet
+		// that was shut down so the app is reloaded on the new one.
+		md.Toast.NotifyError(result)
+		md.Dismiss()
+		md.ReloadApp()
+		return
+	}
+
+	if md.close.Clicked() {
+		md.CloseWindow()
+	}
+}
+
+func (md *moveDataModal) Layout(gtx layout.Context) D {
+	md.mu.Lock()
+	p, done, result, failed := md.progress, md.done, md.result, md.failed
+	md.mu.Unlock()
+
+	w := []layout.Widget{
+		func(gtx C) D {
+			t := md.Theme.H6("Moving wallet data")
+			t.Font.Weight = text.SemiBold
+			return t.Layout(gtx)
+		},
+	}
+
+	if done {
+		w = append(w,
+			func(gtx C) D {
+				if failed {
+					return md.Theme.ErrorLabel(result).Layout(gtx)
+				}
+				return md.Theme.Body2(result).Layout(gtx)
+			},
+			func(gtx C) D {
+				return layout.E.Layout(gtx, md.close.Layout)
+			},
+		)
+		return md.modal.Layout(gtx, w)
+	}
+
+	step := "Copying"
+	if p.Verifying {
+		step = "Verifying"
+	}
+	var percent int
+	if p.TotalBytes > 0 {
+		percent = int(p.Bytes * 100 / p.TotalBytes)
+	}
+	w = append(w,
+		func(gtx C) D {
+			return md.Theme.Body2(fmt.Sprintf("%s %d of %d files", step, p.Files, p.TotalFiles)).Layout(gtx)
+		},
+		func(gtx C) D {
+			return md.Theme.ProgressBar(percent).Layout(gtx)
+		},
+		func(gtx C) D {
+			lbl := md.Theme.Caption("Do not close godcr until the move is done.")
+			lbl.Color = md.Theme.Color.GrayText2
+			return lbl.Layout(gtx)
+		},
+	)
+	return md.modal.Layout(gtx, w)
+}
@@ -0,0 +1,209 @@
+package page
+
+import (
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/page/components"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+const NetworkPrivacyPageID = "NetworkPrivacy"
+
+// privacyFeature is a network feature that can be turned off.
+type privacyFeature struct {
+	feature     string
+	title       string
+	description string
+	enabled     *decredmaterial.Switch
+}
+
+var privacyFeatureText = map[string][2]string{
+	wallet.ProxySubsystemExchange:   {"Exchange rate", "Fetch the DCR-USDT rate from Bittrex to show the balances in USD."},
+	wallet.ProxySubsystemVSPList:    {"VSP list", "Fetch the list of VSPs from api.decred.org."},
+	wallet.ProxySubsystemGovernance: {"Governance", "Fetch the Politeia proposals."},
+	wallet.ProxySubsystemDEX:        {"DEX", "Connect to the DEX servers."},
+	wallet.ProxySubsystemExplorer:   {"Block explorer", "Open transactions, addresses, blocks and tickets on the block explorers and fetch treasury spends from dcrdata."},
+}
+
+// NetworkPrivacyPage turns off the network features that make requests to
+// third parties, one by one or all at once. The SPV sync with the Decred
+// peers is always on.
+type NetworkPrivacyPage struct {
+	*load.Load
+
+	container    *widget.List
+	backButton   decredmaterial.IconButton
+	noThirdParty *decredmaterial.Switch
+	features     []*privacyFeature
+}
+
+func NewNetworkPrivacyPage(l *load.Load) *NetworkPrivacyPage {
+	pg := &NetworkPrivacyPage{
+		Load:         l,
+		container:    &widget.List{List: layout.List{Axis: layout.Vertical}},
+		noThirdParty: l.Theme.Switch(),
+	}
+	pg.backButton, _ = components.SubpageHeaderButtons(l)
+
+	for _, feature := range wallet.PrivacyFeatures {
+		txt := privacyFeatureText[feature]
+		pg.features = append(pg.features, &privacyFeature{
+			feature:     feature,
+			title:       txt[0],
+			description: txt[1],
+			enabled:     l.Theme.Switch(),
+		})
+	}
+
+	return pg
+}
+
+// ID is a unique string that identifies the page and may be used
+// to differentiate this page from other pages.
+// Part of the load.Page interface.
+func (pg *NetworkPrivacyPage) ID() string {
+	return NetworkPrivacyPageID
+}
+
+// OnNavigatedTo is called when the page is about to be displayed and
+// may be used to initialize page features that are only relevant when
+// the page is displayed.
+// Part of the load.Page interface.
+func (pg *NetworkPrivacyPage) OnNavigatedTo() {
+	pg.loadSwitches()
+}
+
+func (pg *NetworkPrivacyPage) loadSwitches() {
+	noThirdParty := pg.WL.Wallet.NoThirdPartyRequests()
+	pg.noThirdParty.SetChecked(noThirdParty)
+	for _, f := range pg.features {
+		f.enabled.SetChecked(!pg.WL.Wallet.FeatureDisabled(f.feature))
+		f.enabled.SetEnabled(!noThirdParty)
+	}
+}
+
+// HandleUserInteractions is called just before Layout() to determine
+// if any user interaction recently occurred on the page and may be
+// used to update the page's UI components shortly before they are
+// displayed.
+// Part of the load.Page interface.
+func (pg *NetworkPrivacyPage) HandleUserInteractions() {
+	if pg.noThirdParty.Changed() {
+		pg.WL.Wallet.SetNoThirdPartyRequests(pg.noThirdParty.IsChecked())
+		if pg.noThirdParty.IsChecked() {
+			pg.stopPoliteiaSync()
+		}
+		pg.loadSwitches()
+	}
+
+	for _, f := range pg.features {
+		if f.enabled.Changed() {
+			pg.WL.Wallet.SetFeatureDisabled(f.feature, !f.enabled.IsChecked())
+			if f.feature == wallet.ProxySubsystemGovernance && !f.enabled.IsChecked() {
+				pg.stopPoliteiaSync()
+			}
+		}
+	}
+}
+
+func (pg *NetworkPrivacyPage) stopPoliteiaSync() {
+	if pg.WL.MultiWallet.Politeia.IsSyncing() {
+		go pg.WL.MultiWallet.Politeia.StopSync()
+	}
+}
+
+// OnNavigatedFrom is called when the page is about to be removed from
+// the displayed window. This method should ideally be used to disable
+// features that are irrelevant when the page is NOT displayed.
+// NOTE: The page may be re-displayed on the app's window, in which case
+// OnNavigatedTo() will be called again. This method should not destroy UI
+// components unless they'll be recreated in the OnNavigatedTo() method.
+// Part of the load.Page interface.
+func (pg *NetworkPrivacyPage) OnNavigatedFrom() {}
+
+// Layout draws the page UI components into the provided layout context
+// to be eventually drawn on screen.
+// Part of the load.Page interface.
+func (pg *NetworkPrivacyPage) Layout(gtx C) D {
+	container := func(gtx C) D {
+		sp := components.SubPage{
+			Load:       pg.Load,
+			Title:      "Network privacy",
+			BackButton: pg.backButton,
+			Back: func() {
+				pg.PopFragment()
+			},
+			Body: func(gtx C) D {
+				sections := []layout.Widget{
+					pg.thirdPartySection,
+					pg.featuresSection,
+				}
+				return pg.Theme.List(pg.container).Layout(gtx, len(sections), func(gtx C, i int) D {
+					return layout.Inset{Bottom: values.MarginPadding10}.Layout(gtx, sections[i])
+				})
+			},
+		}
+		return sp.Layout(gtx)
+	}
+	return components.UniformPadding(gtx, container)
+}
+
+func (pg *NetworkPrivacyPage) section(gtx C, title string, rows ...layout.FlexChild) D {
+	card := pg.Theme.Card()
+	card.Radius = decredmaterial.Radius(14)
+	return card.Layout(gtx, func(gtx C) D {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		return layout.UniformInset(values.MarginPadding15).Layout(gtx, func(gtx C) D {
+			children := []layout.FlexChild{
+				layout.Rigid(func(gtx C) D {
+					lbl := pg.Theme.Body1(title)
+					lbl.Font.Weight = text.SemiBold
+					return lbl.Layout(gtx)
+				}),
+			}
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx, append(children, rows...)...)
+		})
+	})
+}
+
+func (pg *NetworkPrivacyPage) switchRow(title, description string, option *decredmaterial.Switch) layout.FlexChild {
+	return layout.Rigid(func(gtx C) D {
+		return layout.Inset{Top: values.MarginPadding10}.Layout(gtx, func(gtx C) D {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1, func(gtx C) D {
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(pg.Theme.Body1(title).Layout),
+						layout.Rigid(func(gtx C) D {
+							lbl := pg.Theme.Body2(description)
+							lbl.Color = pg.Theme.Color.GrayText2
+							return lbl.Layout(gtx)
+						}),
+					)
+				}),
+				layout.Rigid(func(gtx C) D {
+					return layout.Inset{Left: values.MarginPadding8}.Layout(gtx, option.Layout)
+				}),
+			)
+		})
+	})
+}
+
+func (pg *NetworkPrivacyPage) thirdPartySection(gtx C) D {
+	return pg.section(gtx, "Third-party requests",
+		pg.switchRow("No third-party requests",
+			"Only the wallet sync connects to the Decred network. The features below, VSPs and the account mixer are turned off.",
+			pg.noThirdParty))
+}
+
+func (pg *NetworkPrivacyPage) featuresSection(gtx C) D {
+	rows := make([]layout.FlexChild, 0, len(pg.features))
+	for _, f := range pg.features {
+		rows = append(rows, pg.switchRow(f.title, f.description, f.enabled))
+	}
+	return pg.section(gtx, "Network features", rows...)
+}
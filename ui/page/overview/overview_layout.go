@@ -80,6 +80,7 @@ type AppOverviewPage struct {
 
 	sync              decredmaterial.Label
 	toggleSyncDetails decredmaterial.Button
+	syncHistory       decredmaterial.Button
 	checkBox          decredmaterial.CheckBoxStyle
 
 	isBackupModalOpened   bool
@@ -290,6 +291,10 @@ func (pg *AppOverviewPage) HandleUserInteractions() {
 		pg.ChangeFragment(tPage.NewTransactionDetailsPage(pg.Load, &pg.transactions[selectedItem]))
 	}
 
+	for pg.syncHistory.Clicked() {
+		newSyncHistoryModal(pg.Load).Show()
+	}
+
 	if pg.toggleSyncDetails.Clicked() {
 		pg.syncDetailsVisibility = !pg.syncDetailsVisibility
 		if pg.syncDetailsVisibility {
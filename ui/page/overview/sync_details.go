@@ -2,6 +2,7 @@ package overview
 
 import (
 	"fmt"
+	"image/color"
 	"time"
 
 	"gioui.org/layout"
@@ -19,6 +20,49 @@ func (pg *AppOverviewPage) initSyncDetailsWidgets() {
 	pg.sync = pg.Theme.Label(values.MarginPadding14, values.String(values.StrReconnect))
 	pg.sync.TextSize = values.TextSize14
 	pg.sync.Color = pg.Theme.Color.Text
+
+	pg.syncHistory = pg.Theme.Button("Sync history")
+	pg.syncHistory.TextSize = values.TextSize14
+	pg.syncHistory.Background = color.NRGBA{}
+	pg.syncHistory.Color = pg.Theme.Color.Primary
+	pg.syncHistory.Inset = layout.Inset{}
+}
+
+// syncErrorRow lays out the error of the last failed sync with an
+// explanation, and when the sync restarts.
+func (pg *AppOverviewPage) syncErrorRow(gtx C) D {
+	syncErr, nextRetry := pg.WL.Wallet.LastSyncError()
+	if syncErr == nil {
+		return layout.Inset{Top: values.MarginPadding8}.Layout(gtx, pg.syncHistory.Layout)
+	}
+
+	retryText := "The sync will not restart on its own."
+	if !nextRetry.IsZero() {
+		retryText = "Retrying in " + components.TimeFormat(int(time.Until(nextRetry).Seconds()), true) + "."
+	}
+
+	return layout.Inset{Top: values.MarginPadding12}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				lbl := pg.Theme.Body1(syncErr.Kind.Title())
+				lbl.Color = pg.Theme.Color.Danger
+				return lbl.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx C) D {
+				lbl := pg.Theme.Body2(syncErr.Kind.Explanation())
+				lbl.Color = pg.Theme.Color.GrayText2
+				return layout.Inset{Top: values.MarginPadding4}.Layout(gtx, lbl.Layout)
+			}),
+			layout.Rigid(func(gtx C) D {
+				lbl := pg.Theme.Caption(retryText)
+				lbl.Color = pg.Theme.Color.GrayText2
+				return layout.Inset{Top: values.MarginPadding4}.Layout(gtx, lbl.Layout)
+			}),
+			layout.Rigid(func(gtx C) D {
+				return layout.Inset{Top: values.MarginPadding8}.Layout(gtx, pg.syncHistory.Layout)
+			}),
+		)
+	})
 }
 
 // syncDetail returns a walletSyncDetails object containing data of a single wallet sync box
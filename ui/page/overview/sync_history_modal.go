@@ -0,0 +1,109 @@
+package overview
+
+import (
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+const syncHistoryModalID = "sync_history_modal"
+
+// syncHistoryModal lists the sync events of this session, the most recent
+// first.
+type syncHistoryModal struct {
+	*load.Load
+
+	modal     *decredmaterial.Modal
+	container *widget.List
+	close     decredmaterial.Button
+	events    []wallet.SyncEvent
+}
+
+func newSyncHistoryModal(l *load.Load) *syncHistoryModal {
+	return &syncHistoryModal{
+		Load:      l,
+		modal:     l.Theme.ModalFloatTitle(),
+		container: &widget.List{List: layout.List{Axis: layout.Vertical}},
+		close:     l.Theme.OutlineButton("Close"),
+	}
+}
+
+func (md *syncHistoryModal) ModalID() string {
+	return syncHistoryModalID
+}
+
+func (md *syncHistoryModal) Show() {
+	md.ShowModal(md)
+}
+
+func (md *syncHistoryModal) Dismiss() {
+	md.DismissModal(md)
+}
+
+func (md *syncHistoryModal) OnDismiss() {}
+
+func (md *syncHistoryModal) OnResume() {
+	md.events = md.WL.Wallet.SyncEvents()
+}
+
+func (md *syncHistoryModal) Handle() {
+	if md.close.Clicked() {
+		md.Dismiss()
+	}
+}
+
+func (md *syncHistoryModal) Layout(gtx layout.Context) D {
+	w := []layout.Widget{
+		func(gtx C) D {
+			t := md.Theme.H6("Sync history")
+			t.Font.Weight = text.SemiBold
+			return t.Layout(gtx)
+		},
+		func(gtx C) D {
+			if len(md.events) == 0 {
+				lbl := md.Theme.Body2("No sync events yet.")
+				lbl.Color = md.Theme.Color.GrayText2
+				return lbl.Layout(gtx)
+			}
+
+			gtx.Constraints.Max.Y = gtx.Px(values.MarginPadding350)
+			return md.Theme.List(md.container).Layout(gtx, len(md.events), func(gtx C, i int) D {
+				event := md.events[i]
+				return layout.Inset{Bottom: values.MarginPadding8}.Layout(gtx, func(gtx C) D {
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(func(gtx C) D {
+							lbl := md.Theme.Body2(event.Description)
+							if event.Err != nil {
+								lbl.Color = md.Theme.Color.Danger
+							}
+							return lbl.Layout(gtx)
+						}),
+						layout.Rigid(func(gtx C) D {
+							if event.Err == nil {
+								return D{}
+							}
+							lbl := md.Theme.Caption(event.Err.Err.Error())
+							lbl.Color = md.Theme.Color.GrayText2
+							return lbl.Layout(gtx)
+						}),
+						layout.Rigid(func(gtx C) D {
+							lbl := md.Theme.Caption(event.Time.Format("Jan 2, 2006 15:04:05"))
+							lbl.Color = md.Theme.Color.GrayText2
+							return lbl.Layout(gtx)
+						}),
+					)
+				})
+			})
+		},
+		func(gtx C) D {
+			return layout.E.Layout(gtx, md.close.Layout)
+		},
+	}
+
+	return md.modal.Layout(gtx, w)
+}
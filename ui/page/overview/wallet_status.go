@@ -191,6 +191,7 @@ func (pg *AppOverviewPage) syncDormantContent(gtx layout.Context, uniform layout
 				latestBlockTitleLabel.Color = pg.Theme.Color.GrayText2
 				return latestBlockTitleLabel.Layout(gtx)
 			}),
+			layout.Rigid(pg.syncErrorRow),
 		)
 	})
 }
@@ -0,0 +1,517 @@
+package page
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/modal"
+	"github.com/planetdecred/godcr/ui/page/components"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+const PeerManagerPageID = "PeerManager"
+
+// peerStatsRefreshInterval is how often the connected peers are listed
+// again while the page is displayed.
+const peerStatsRefreshInterval = 10 * time.Second
+
+// peerRow is a connected peer with its actions.
+type peerRow struct {
+	stats      wallet.PeerStats
+	disconnect decredmaterial.Button
+	ban        decredmaterial.Button
+}
+
+// addressRow is a persistent or banned peer address with its action.
+type addressRow struct {
+	address string
+	action  decredmaterial.Button
+}
+
+// profileRow is a saved peer profile with its actions.
+type profileRow struct {
+	profile wallet.PeerProfile
+	apply   decredmaterial.Button
+	remove  decredmaterial.Button
+}
+
+// PeerManagerPage lists the connected peers and manages the persistent
+// peers, the banned peers and the peer profiles. A discovered peer is
+// disconnected on its own, a persistent peer is removed from the persistent
+// peers and the sync restarts without it.
+type PeerManagerPage struct {
+	*load.Load
+
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+
+	container   *widget.List
+	backButton  decredmaterial.IconButton
+	addPeers    decredmaterial.Button
+	saveProfile decredmaterial.Button
+
+	rowsMu        sync.Mutex
+	peers         []*peerRow
+	peersErr      string
+	persistent    []*addressRow
+	banned        []*addressRow
+	profiles      []*profileRow
+	lastRefreshed time.Time
+}
+
+func NewPeerManagerPage(l *load.Load) *PeerManagerPage {
+	pg := &PeerManagerPage{
+		Load:        l,
+		container:   &widget.List{List: layout.List{Axis: layout.Vertical}},
+		addPeers:    l.Theme.OutlineButton("Add peers"),
+		saveProfile: l.Theme.OutlineButton("Save as profile"),
+	}
+	pg.backButton, _ = components.SubpageHeaderButtons(l)
+
+	return pg
+}
+
+// ID is a unique string that identifies the page and may be used
+// to differentiate this page from other pages.
+// Part of the load.Page interface.
+func (pg *PeerManagerPage) ID() string {
+	return PeerManagerPageID
+}
+
+// OnNavigatedTo is called when the page is about to be displayed and
+// may be used to initialize page features that are only relevant when
+// the page is displayed.
+// Part of the load.Page interface.
+func (pg *PeerManagerPage) OnNavigatedTo() {
+	pg.ctx, pg.ctxCancel = context.WithCancel(context.TODO())
+	pg.loadAddresses()
+
+	go func() {
+		ticker := time.NewTicker(peerStatsRefreshInterval)
+		defer ticker.Stop()
+		for {
+			pg.loadPeers()
+			pg.RefreshWindow()
+
+			select {
+			case <-ticker.C:
+			case <-pg.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// loadPeers lists the connected peers, measuring their latency.
+func (pg *PeerManagerPage) loadPeers() {
+	var rows []*peerRow
+	var peersErr string
+	stats, err := pg.WL.Wallet.PeerStats()
+	if err != nil {
+		peersErr = err.Error()
+	}
+	for _, stat := range stats {
+		row := &peerRow{
+			stats:      stat,
+			disconnect: pg.Theme.OutlineButton("Disconnect"),
+			ban:        pg.Theme.DangerButton("Ban"),
+		}
+		row.disconnect.Inset = layout.UniformInset(values.MarginPadding8)
+		row.ban.Inset = layout.UniformInset(values.MarginPadding8)
+		rows = append(rows, row)
+	}
+
+	pg.rowsMu.Lock()
+	pg.peers, pg.peersErr, pg.lastRefreshed = rows, peersErr, time.Now()
+	pg.rowsMu.Unlock()
+}
+
+// loadAddresses lists the persistent peers, banned peers and profiles.
+func (pg *PeerManagerPage) loadAddresses() {
+	newAddressRows := func(addresses []string, action string) []*addressRow {
+		rows := make([]*addressRow, 0, len(addresses))
+		for _, addr := range addresses {
+			row := &addressRow{address: addr, action: pg.Theme.OutlineButton(action)}
+			row.action.Inset = layout.UniformInset(values.MarginPadding8)
+			rows = append(rows, row)
+		}
+		return rows
+	}
+
+	var profiles []*profileRow
+	for _, profile := range pg.WL.Wallet.PeerProfiles() {
+		row := &profileRow{
+			profile: profile,
+			apply:   pg.Theme.OutlineButton("Apply"),
+			remove:  pg.Theme.OutlineButton("Delete"),
+		}
+		row.apply.Inset = layout.UniformInset(values.MarginPadding8)
+		row.remove.Inset = layout.UniformInset(values.MarginPadding8)
+		profiles = append(profiles, row)
+	}
+
+	pg.rowsMu.Lock()
+	pg.persistent = newAddressRows(pg.WL.Wallet.PersistentPeers(), "Remove")
+	pg.banned = newAddressRows(pg.WL.Wallet.BannedPeers(), "Unban")
+	pg.profiles = profiles
+	pg.rowsMu.Unlock()
+}
+
+// peersChanged reloads the addresses and restarts the sync so the change
+// takes effect.
+func (pg *PeerManagerPage) peersChanged() {
+	pg.loadAddresses()
+	go func() {
+		if err := pg.WL.Wallet.RestartSyncForPeers(); err != nil {
+			pg.Toast.NotifyError(err.Error())
+			return
+		}
+		if pg.WL.MultiWallet.IsConnectedToDecredNetwork() {
+			pg.Toast.Notify("Sync restarted with the new peers")
+		}
+	}()
+}
+
+// HandleUserInteractions is called just before Layout() to determine
+// if any user interaction recently occurred on the page and may be
+// used to update the page's UI components shortly before they are
+// displayed.
+// Part of the load.Page interface.
+func (pg *PeerManagerPage) HandleUserInteractions() {
+	for pg.addPeers.Clicked() {
+		modal.NewTextInputModal(pg.Load).
+			Hint("Peer addresses, separated by commas").
+			PositiveButtonStyle(pg.Theme.Color.Primary, pg.Theme.Color.InvText).
+			PositiveButton("Add", func(input string, tim *modal.TextInputModal) bool {
+				if err := pg.WL.Wallet.AddPersistentPeers(input); err != nil {
+					tim.SetError(err.Error())
+					tim.SetLoading(false)
+					return false
+				}
+				pg.peersChanged()
+				return true
+			}).
+			Title("Add persistent peers").
+			NegativeButton(values.String(values.StrCancel), func() {}).
+			Show()
+	}
+
+	for pg.saveProfile.Clicked() {
+		newPeerProfileModal(pg.Load, pg.WL.Wallet.PersistentPeers()).
+			Saved(pg.loadAddresses).
+			Show()
+	}
+
+	pg.rowsMu.Lock()
+	peers, persistent, banned, profiles := pg.peers, pg.persistent, pg.banned, pg.profiles
+	pg.rowsMu.Unlock()
+
+	for _, row := range peers {
+		for row.disconnect.Clicked() {
+			if row.stats.Persistent {
+				pg.WL.Wallet.RemovePersistentPeer(row.stats.Addr)
+				pg.peersChanged()
+				continue
+			}
+			if err := pg.WL.Wallet.DisconnectPeer(row.stats.ID); err != nil {
+				pg.Toast.NotifyError(err.Error())
+				continue
+			}
+			go pg.loadPeers()
+		}
+		for row.ban.Clicked() {
+			addr, persistent := row.stats.Addr, row.stats.Persistent
+			modal.NewInfoModal(pg.Load).
+				Title("Ban peer").
+				Body(fmt.Sprintf("Ban %s? The peer is disconnected and not connected to again.", addr)).
+				NegativeButton(values.String(values.StrCancel), func() {}).
+				PositiveButtonStyle(pg.Theme.Color.Surface, pg.Theme.Color.Danger).
+				PositiveButton("Ban", func() {
+					pg.WL.Wallet.BanPeer(addr)
+					if persistent {
+						pg.peersChanged()
+						return
+					}
+					pg.loadAddresses()
+					go pg.loadPeers()
+				}).Show()
+		}
+	}
+
+	for _, row := range persistent {
+		for row.action.Clicked() {
+			pg.WL.Wallet.RemovePersistentPeer(row.address)
+			pg.peersChanged()
+		}
+	}
+
+	for _, row := range banned {
+		for row.action.Clicked() {
+			pg.WL.Wallet.UnbanPeer(row.address)
+			pg.loadAddresses()
+		}
+	}
+
+	for _, row := range profiles {
+		for row.apply.Clicked() {
+			if err := pg.WL.Wallet.ApplyPeerProfile(row.profile.Name); err != nil {
+				pg.Toast.NotifyError(err.Error())
+				continue
+			}
+			pg.Toast.Notify("Applied peer profile " + row.profile.Name)
+			pg.peersChanged()
+		}
+		for row.remove.Clicked() {
+			pg.WL.Wallet.RemovePeerProfile(row.profile.Name)
+			pg.loadAddresses()
+		}
+	}
+}
+
+// OnNavigatedFrom is called when the page is about to be removed from
+// the displayed window. This method should ideally be used to disable
+// features that are irrelevant when the page is NOT displayed.
+// NOTE: The page may be re-displayed on the app's window, in which case
+// OnNavigatedTo() will be called again. This method should not destroy UI
+// components unless they'll be recreated in the OnNavigatedTo() method.
+// Part of the load.Page interface.
+func (pg *PeerManagerPage) OnNavigatedFrom() {
+	pg.ctxCancel()
+}
+
+// Layout draws the page UI components into the provided layout context
+// to be eventually drawn on screen.
+// Part of the load.Page interface.
+func (pg *PeerManagerPage) Layout(gtx C) D {
+	container := func(gtx C) D {
+		sp := components.SubPage{
+			Load:       pg.Load,
+			Title:      "Peer manager",
+			BackButton: pg.backButton,
+			Back: func() {
+				pg.PopFragment()
+			},
+			Body: func(gtx C) D {
+				sections := []layout.Widget{
+					pg.connectedPeersSection,
+					pg.persistentPeersSection,
+					pg.profilesSection,
+					pg.bannedPeersSection,
+				}
+				return pg.Theme.List(pg.container).Layout(gtx, len(sections), func(gtx C, i int) D {
+					return layout.Inset{Bottom: values.MarginPadding10}.Layout(gtx, sections[i])
+				})
+			},
+		}
+		return sp.Layout(gtx)
+	}
+	return components.UniformPadding(gtx, container)
+}
+
+func (pg *PeerManagerPage) section(gtx C, title string, action layout.Widget, rows ...layout.FlexChild) D {
+	card := pg.Theme.Card()
+	card.Radius = decredmaterial.Radius(14)
+	return card.Layout(gtx, func(gtx C) D {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		return layout.UniformInset(values.MarginPadding15).Layout(gtx, func(gtx C) D {
+			children := []layout.FlexChild{
+				layout.Rigid(func(gtx C) D {
+					return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+						layout.Rigid(func(gtx C) D {
+							lbl := pg.Theme.Body1(title)
+							lbl.Font.Weight = text.SemiBold
+							return lbl.Layout(gtx)
+						}),
+						layout.Flexed(1, func(gtx C) D {
+							if action == nil {
+								return D{}
+							}
+							return layout.E.Layout(gtx, action)
+						}),
+					)
+				}),
+			}
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx, append(children, rows...)...)
+		})
+	})
+}
+
+func (pg *PeerManagerPage) emptyRow(message string) layout.FlexChild {
+	return layout.Rigid(func(gtx C) D {
+		lbl := pg.Theme.Body2(message)
+		lbl.Color = pg.Theme.Color.GrayText2
+		return layout.Inset{Top: values.MarginPadding10}.Layout(gtx, lbl.Layout)
+	})
+}
+
+func (pg *PeerManagerPage) connectedPeersSection(gtx C) D {
+	pg.rowsMu.Lock()
+	peers, peersErr, lastRefreshed := pg.peers, pg.peersErr, pg.lastRefreshed
+	pg.rowsMu.Unlock()
+
+	var rows []layout.FlexChild
+	if len(peers) == 0 {
+		message := "No connected peers."
+		if peersErr != "" {
+			message = "Not connected to the Decred network."
+		}
+		rows = append(rows, pg.emptyRow(message))
+	}
+	for _, row := range peers {
+		row := row
+		rows = append(rows, layout.Rigid(func(gtx C) D {
+			return layout.Inset{Top: values.MarginPadding10}.Layout(gtx, func(gtx C) D {
+				return pg.peerRowLayout(gtx, row)
+			})
+		}))
+	}
+
+	refreshed := func(gtx C) D {
+		if lastRefreshed.IsZero() {
+			return D{}
+		}
+		lbl := pg.Theme.Caption("Updated " + lastRefreshed.Format("15:04:05"))
+		lbl.Color = pg.Theme.Color.GrayText2
+		return lbl.Layout(gtx)
+	}
+	return pg.section(gtx, "Connected peers", refreshed, rows...)
+}
+
+func (pg *PeerManagerPage) peerRowLayout(gtx C, row *peerRow) D {
+	stats := row.stats
+	latency := "not measured yet"
+	if stats.Latency > 0 {
+		latency = fmt.Sprintf("%d ms", stats.Latency.Milliseconds())
+	}
+	details := fmt.Sprintf("%s, protocol %d, height at connect %d, latency %s, ban score %d, sent %s, received %s",
+		stats.SubVer, stats.Version, stats.StartingHeight, latency, stats.BanScore,
+		formatBytes(stats.BytesSent), formatBytes(stats.BytesReceived))
+
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					addr := stats.Addr
+					if stats.Persistent {
+						addr += " (persistent)"
+					}
+					lbl := pg.Theme.Body2(addr)
+					if stats.Banned {
+						lbl.Text += " (banned)"
+						lbl.Color = pg.Theme.Color.Danger
+					}
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx C) D {
+					lbl := pg.Theme.Caption(details)
+					lbl.Color = pg.Theme.Color.GrayText2
+					return lbl.Layout(gtx)
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{Left: values.MarginPadding8}.Layout(gtx, row.disconnect.Layout)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{Left: values.MarginPadding8}.Layout(gtx, row.ban.Layout)
+		}),
+	)
+}
+
+func (pg *PeerManagerPage) addressRows(rows []*addressRow, empty string) []layout.FlexChild {
+	if len(rows) == 0 {
+		return []layout.FlexChild{pg.emptyRow(empty)}
+	}
+
+	children := make([]layout.FlexChild, 0, len(rows))
+	for _, row := range rows {
+		row := row
+		children = append(children, layout.Rigid(func(gtx C) D {
+			return layout.Inset{Top: values.MarginPadding10}.Layout(gtx, func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, pg.Theme.Body2(row.address).Layout),
+					layout.Rigid(row.action.Layout),
+				)
+			})
+		}))
+	}
+	return children
+}
+
+func (pg *PeerManagerPage) persistentPeersSection(gtx C) D {
+	pg.rowsMu.Lock()
+	persistent := pg.persistent
+	pg.rowsMu.Unlock()
+
+	rows := pg.addressRows(persistent, "No persistent peers, the sync discovers peers on the network.")
+	return pg.section(gtx, "Persistent peers", pg.addPeers.Layout, rows...)
+}
+
+func (pg *PeerManagerPage) bannedPeersSection(gtx C) D {
+	pg.rowsMu.Lock()
+	banned := pg.banned
+	pg.rowsMu.Unlock()
+
+	rows := pg.addressRows(banned, "No banned peers.")
+	return pg.section(gtx, "Banned peers", nil, rows...)
+}
+
+func (pg *PeerManagerPage) profilesSection(gtx C) D {
+	pg.rowsMu.Lock()
+	profiles := pg.profiles
+	pg.rowsMu.Unlock()
+
+	var rows []layout.FlexChild
+	if len(profiles) == 0 {
+		rows = append(rows, pg.emptyRow("No peer profiles. Save a list of peers, such as your own nodes, to switch to it at once."))
+	}
+	for _, row := range profiles {
+		row := row
+		rows = append(rows, layout.Rigid(func(gtx C) D {
+			return layout.Inset{Top: values.MarginPadding10}.Layout(gtx, func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, func(gtx C) D {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(pg.Theme.Body2(row.profile.Name).Layout),
+							layout.Rigid(func(gtx C) D {
+								lbl := pg.Theme.Caption(fmt.Sprintf("%d peers", len(row.profile.Addresses)))
+								lbl.Color = pg.Theme.Color.GrayText2
+								return lbl.Layout(gtx)
+							}),
+						)
+					}),
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Left: values.MarginPadding8}.Layout(gtx, row.apply.Layout)
+					}),
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Left: values.MarginPadding8}.Layout(gtx, row.remove.Layout)
+					}),
+				)
+			})
+		}))
+	}
+	return pg.section(gtx, "Peer profiles", pg.saveProfile.Layout, rows...)
+}
+
+// formatBytes formats a byte count with a binary unit, such as 1.5 MiB.
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,130 @@
+package page
+
+import (
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/values"
+)
+
+const peerProfileModalID = "peer_profile_modal"
+
+// peerProfileModal saves a named list of peers.
+type peerProfileModal struct {
+	*load.Load
+
+	saved func()
+
+	modal           *decredmaterial.Modal
+	nameEditor      decredmaterial.Editor
+	addressesEditor decredmaterial.Editor
+	save            decredmaterial.Button
+	cancel          decredmaterial.Button
+	saveError       string
+}
+
+func newPeerProfileModal(l *load.Load, addresses []string) *peerProfileModal {
+	md := &peerProfileModal{
+		Load:            l,
+		modal:           l.Theme.ModalFloatTitle(),
+		nameEditor:      l.Theme.Editor(new(widget.Editor), "Profile name"),
+		addressesEditor: l.Theme.Editor(new(widget.Editor), "Peer addresses, one per line"),
+		save:            l.Theme.Button("Save"),
+		cancel:          l.Theme.OutlineButton(values.String(values.StrCancel)),
+	}
+	md.nameEditor.Editor.SingleLine = true
+	md.addressesEditor.Editor.SetText(strings.Join(addresses, "\n"))
+
+	return md
+}
+
+// Saved sets the function called once the profile is saved.
+func (md *peerProfileModal) Saved(saved func()) *peerProfileModal {
+	md.saved = saved
+	return md
+}
+
+func (md *peerProfileModal) ModalID() string {
+	return peerProfileModalID
+}
+
+func (md *peerProfileModal) Show() {
+	md.ShowModal(md)
+}
+
+func (md *peerProfileModal) Dismiss() {
+	md.DismissModal(md)
+}
+
+func (md *peerProfileModal) OnDismiss() {}
+
+func (md *peerProfileModal) OnResume() {
+	md.nameEditor.Editor.Focus()
+}
+
+func (md *peerProfileModal) Handle() {
+	if md.cancel.Clicked() {
+		md.Dismiss()
+	}
+
+	_, changed := decredmaterial.HandleEditorEvents(md.nameEditor.Editor, md.addressesEditor.Editor)
+	if changed {
+		md.saveError = ""
+	}
+
+	if md.save.Clicked() {
+		err := md.WL.Wallet.SavePeerProfile(md.nameEditor.Editor.Text(), md.addressesEditor.Editor.Text())
+		if err != nil {
+			md.saveError = err.Error()
+			return
+		}
+
+		md.Dismiss()
+		if md.saved != nil {
+			md.saved()
+		}
+	}
+}
+
+func (md *peerProfileModal) Layout(gtx layout.Context) D {
+	w := []layout.Widget{
+		func(gtx C) D {
+			t := md.Theme.H6("Save peer profile")
+			t.Font.Weight = text.SemiBold
+			return t.Layout(gtx)
+		},
+		func(gtx C) D {
+			lbl := md.Theme.Body2("A profile with the same name is replaced.")
+			lbl.Color = md.Theme.Color.GrayText2
+			return lbl.Layout(gtx)
+		},
+		md.nameEditor.Layout,
+		func(gtx C) D {
+			gtx.Constraints.Max.Y = gtx.Px(values.MarginPadding150)
+			return md.addressesEditor.Layout(gtx)
+		},
+		func(gtx C) D {
+			if md.saveError == "" {
+				return D{}
+			}
+			return md.Theme.ErrorLabel(md.saveError).Layout(gtx)
+		},
+		func(gtx C) D {
+			return layout.E.Layout(gtx, func(gtx C) D {
+				return layout.Flex{}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, md.cancel.Layout)
+					}),
+					layout.Rigid(md.save.Layout),
+				)
+			})
+		},
+	}
+
+	return md.modal.Layout(gtx, w)
+}
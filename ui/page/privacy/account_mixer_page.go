@@ -3,6 +3,7 @@ package privacy
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"gioui.org/layout"
 
@@ -19,6 +20,10 @@ import (
 
 const AccountMixerPageID = "AccountMixer"
 
+// maxDisplayedMixerSessions is the number of most recent mixer sessions
+// shown on the page.
+const maxDisplayedMixerSessions = 10
+
 type AccountMixerPage struct {
 	*load.Load
 
@@ -35,8 +40,13 @@ type AccountMixerPage struct {
 	infoButton              decredmaterial.IconButton
 	toggleMixer             *decredmaterial.Switch
 	allowUnspendUnmixedAcct *decredmaterial.Switch
+	autoStartMixer          *decredmaterial.Switch
+	scheduleRow             *decredmaterial.Clickable
 
 	mixerCompleted bool
+
+	mixerSessions []*wallet.MixerSession
+	mixerProgress *wallet.MixerProgress
 }
 
 func NewAccountMixerPage(l *load.Load, wallet *dcrlibwallet.Wallet) *AccountMixerPage {
@@ -46,6 +56,8 @@ func NewAccountMixerPage(l *load.Load, wallet *dcrlibwallet.Wallet) *AccountMixe
 		pageContainer:           layout.List{Axis: layout.Vertical},
 		toggleMixer:             l.Theme.Switch(),
 		allowUnspendUnmixedAcct: l.Theme.Switch(),
+		autoStartMixer:          l.Theme.Switch(),
+		scheduleRow:             l.Theme.NewClickable(true),
 		dangerZoneCollapsible:   l.Theme.Collapsible(),
 	}
 	pg.backButton, pg.infoButton = components.SubpageHeaderButtons(l)
@@ -69,6 +81,8 @@ func (pg *AccountMixerPage) OnNavigatedTo() {
 
 	pg.listenForMixerNotifications()
 	pg.toggleMixer.SetChecked(pg.wallet.IsAccountMixerActive())
+	pg.autoStartMixer.SetChecked(pg.WL.Wallet.MixerAutoStartEnabled(pg.wallet.ID))
+	pg.loadMixerStats()
 
 	if pg.wallet.AccountMixerConfigIsSet() {
 		pg.allowUnspendUnmixedAcct.SetChecked(false)
@@ -141,6 +155,15 @@ func (pg *AccountMixerPage) Layout(gtx layout.Context) layout.Dimensions {
 								})
 							})
 					},
+					func(gtx C) D {
+						return pg.mixerProgressLayout(gtx)
+					},
+					func(gtx C) D {
+						return pg.mixerSessionsLayout(gtx)
+					},
+					func(gtx C) D {
+						return pg.mixerScheduleLayout(gtx)
+					},
 					func(gtx C) D {
 						return pg.mixerSettingsLayout(gtx)
 					},
@@ -200,6 +223,186 @@ func (pg *AccountMixerPage) mixerSettingsLayout(gtx layout.Context) layout.Dimen
 	})
 }
 
+func (pg *AccountMixerPage) mixerScheduleLayout(gtx layout.Context) layout.Dimensions {
+	return pg.Theme.Card().Layout(gtx, func(gtx C) D {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		inset := layout.Inset{
+			Left:   values.MarginPadding15,
+			Right:  values.MarginPadding15,
+			Top:    values.MarginPadding10,
+			Bottom: values.MarginPadding10,
+		}
+
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return layout.UniformInset(values.MarginPadding15).Layout(gtx, pg.Theme.Body2("Mixing Schedule").Layout)
+			}),
+			layout.Rigid(func(gtx C) D {
+				return pg.scheduleRow.Layout(gtx, func(gtx C) D {
+					return inset.Layout(gtx, func(gtx C) D {
+						return layout.Flex{Spacing: layout.SpaceBetween, Alignment: layout.Middle}.Layout(gtx,
+							layout.Rigid(pg.Theme.Label(values.TextSize16, "Schedule").Layout),
+							layout.Rigid(pg.Theme.Body2(pg.WL.Wallet.MixerSchedule(pg.wallet.ID).String()).Layout),
+						)
+					})
+				})
+			}),
+			layout.Rigid(pg.Theme.Separator().Layout),
+			layout.Rigid(func(gtx C) D {
+				return inset.Layout(gtx, func(gtx C) D {
+					return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+						layout.Flexed(1, func(gtx C) D {
+							return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+								layout.Rigid(pg.Theme.Label(values.TextSize16, "Start on unlock").Layout),
+								layout.Rigid(func(gtx C) D {
+									txt := pg.Theme.Label(values.TextSize14, "Store the spending password encrypted with the startup password")
+									txt.Color = pg.Theme.Color.GrayText2
+									return txt.Layout(gtx)
+								}),
+							)
+						}),
+						layout.Rigid(pg.autoStartMixer.Layout),
+					)
+				})
+			}),
+		)
+	})
+}
+
+func (pg *AccountMixerPage) loadMixerStats() {
+	go func() {
+		progress, err := pg.WL.Wallet.MixerProgress(pg.wallet.ID)
+		if err != nil {
+			log.Errorf("Error estimating mixer progress: %v", err)
+			return
+		}
+
+		sessions := pg.WL.Wallet.MixerSessions(pg.wallet.ID)
+		// newest first
+		for i, j := 0, len(sessions)-1; i < j; i, j = i+1, j-1 {
+			sessions[i], sessions[j] = sessions[j], sessions[i]
+		}
+
+		if len(sessions) > maxDisplayedMixerSessions {
+			sessions = sessions[:maxDisplayedMixerSessions]
+		}
+
+		pg.mixerProgress = progress
+		pg.mixerSessions = sessions
+		pg.RefreshWindow()
+	}()
+}
+
+func (pg *AccountMixerPage) mixerProgressLayout(gtx layout.Context) layout.Dimensions {
+	progress := pg.mixerProgress
+	if progress == nil {
+		return D{}
+	}
+
+	return pg.Theme.Card().Layout(gtx, func(gtx C) D {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		return layout.UniformInset(values.MarginPadding15).Layout(gtx, func(gtx C) D {
+			remaining := "Not enough mixing history for an estimate"
+			if progress.Unmixed == 0 {
+				remaining = "All funds are mixed"
+			} else if progress.Remaining > 0 {
+				remaining = fmt.Sprintf("About %s left to mix the unmixed balance", progress.Remaining.Round(time.Minute))
+			}
+
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(pg.Theme.Body2("Mixing progress").Layout),
+				layout.Rigid(func(gtx C) D {
+					return layout.Inset{Top: values.MarginPadding10, Bottom: values.MarginPadding10}.Layout(gtx, func(gtx C) D {
+						return pg.Theme.ProgressBar(int(progress.Percent)).Layout(gtx)
+					})
+				}),
+				layout.Rigid(func(gtx C) D {
+					txt := fmt.Sprintf("%.1f%% mixed, %s unmixed", progress.Percent, dcrutil.Amount(progress.Unmixed))
+					return pg.Theme.Label(values.TextSize14, txt).Layout(gtx)
+				}),
+				layout.Rigid(func(gtx C) D {
+					txt := pg.Theme.Label(values.TextSize14, remaining)
+					txt.Color = pg.Theme.Color.GrayText2
+					return txt.Layout(gtx)
+				}),
+			)
+		})
+	})
+}
+
+func (pg *AccountMixerPage) mixerSessionsLayout(gtx layout.Context) layout.Dimensions {
+	return pg.Theme.Card().Layout(gtx, func(gtx C) D {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		return layout.UniformInset(values.MarginPadding15).Layout(gtx, func(gtx C) D {
+			rows := []layout.FlexChild{
+				layout.Rigid(pg.Theme.Body2("Mixing sessions").Layout),
+			}
+			if len(pg.mixerSessions) == 0 {
+				rows = append(rows, layout.Rigid(func(gtx C) D {
+					txt := pg.Theme.Label(values.TextSize14, "The mixer has not run yet")
+					txt.Color = pg.Theme.Color.GrayText2
+					return layout.Inset{Top: values.MarginPadding10}.Layout(gtx, txt.Layout)
+				}))
+			}
+
+			for _, session := range pg.mixerSessions {
+				session := session
+				rows = append(rows, layout.Rigid(func(gtx C) D {
+					return layout.Inset{Top: values.MarginPadding10}.Layout(gtx, func(gtx C) D {
+						return pg.mixerSessionLayout(gtx, session)
+					})
+				}))
+			}
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
+		})
+	})
+}
+
+func (pg *AccountMixerPage) mixerSessionLayout(gtx layout.Context, session *wallet.MixerSession) layout.Dimensions {
+	start := time.Unix(session.StartTime, 0).Format("Jan 2, 2006 15:04")
+	status := "Ended " + time.Unix(session.EndTime, 0).Format("Jan 2, 2006 15:04")
+	if session.Active() {
+		status = "Running"
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{Spacing: layout.SpaceBetween}.Layout(gtx,
+				layout.Rigid(pg.Theme.Label(values.TextSize14, start).Layout),
+				layout.Rigid(func(gtx C) D {
+					txt := pg.Theme.Label(values.TextSize14, status)
+					txt.Color = pg.Theme.Color.GrayText2
+					return txt.Layout(gtx)
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			txt := fmt.Sprintf("%d mixes, %s mixed, %s fees, ran for %s", session.Mixes,
+				dcrutil.Amount(session.MixedAmount), dcrutil.Amount(session.Fees), session.Duration().Round(time.Second))
+			lbl := pg.Theme.Label(values.TextSize12, txt)
+			lbl.Color = pg.Theme.Color.GrayText2
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx C) D {
+			if session.Active() {
+				return D{}
+			}
+			txt := fmt.Sprintf("Unmixed %s → %s", dcrutil.Amount(session.UnmixedAtStart), dcrutil.Amount(session.UnmixedAtEnd))
+			lbl := pg.Theme.Label(values.TextSize12, txt)
+			lbl.Color = pg.Theme.Color.GrayText2
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx C) D {
+			if session.Error == "" {
+				return D{}
+			}
+			lbl := pg.Theme.Label(values.TextSize12, session.Error)
+			lbl.Color = pg.Theme.Color.Danger
+			return lbl.Layout(gtx)
+		}),
+	)
+}
+
 func (pg *AccountMixerPage) shufflePortForCurrentNet() string {
 	if pg.WL.Wallet.Net == dcrlibwallet.Testnet3 {
 		return dcrlibwallet.TestnetShufflePort
@@ -248,7 +451,8 @@ func (pg *AccountMixerPage) HandleUserInteractions() {
 				NegativeButton("No", func() {}).
 				PositiveButton("Yes", func() {
 					pg.toggleMixer.SetChecked(false)
-					go pg.WL.MultiWallet.StopAccountMixer(pg.wallet.ID)
+					pg.WL.Wallet.SetMixerRunState(pg.wallet.ID, false)
+					go pg.WL.Wallet.StopAccountMixer(pg.wallet.ID)
 				})
 			pg.ShowModal(info)
 		}
@@ -292,11 +496,63 @@ func (pg *AccountMixerPage) HandleUserInteractions() {
 		}
 	}
 
+	for pg.scheduleRow.Clicked() {
+		newMixerScheduleModal(pg.Load, pg.wallet.ID).
+			ScheduleSaved(pg.RefreshWindow).
+			Show()
+	}
+
+	if pg.autoStartMixer.Changed() {
+		if pg.autoStartMixer.IsChecked() {
+			pg.autoStartMixer.SetChecked(false)
+			if !pg.WL.MultiWallet.IsStartupSecuritySet() {
+				pg.Toast.NotifyError(wallet.ErrNoStartupPassword.Error())
+			} else {
+				pg.enableMixerAutoStart()
+			}
+		} else {
+			pg.WL.Wallet.DisableMixerAutoStart(pg.wallet.ID)
+		}
+	}
+
 	if pg.backButton.Button.Clicked() {
 		pg.PopToFragment(components.WalletsPageID)
 	}
 }
 
+// enableMixerAutoStart asks for the spending password of the wallet, then
+// for the startup password it is encrypted with.
+func (pg *AccountMixerPage) enableMixerAutoStart() {
+	modal.NewPasswordModal(pg.Load).
+		Title("Start mixer on unlock").
+		Description("The spending password is stored encrypted with the startup password, "+
+			"and the mixer starts according to its schedule when godcr is unlocked.").
+		Hint("Spending password").
+		NegativeButton("Cancel", func() {}).
+		PositiveButton("Next", func(spendingPassword string, spm *modal.PasswordModal) bool {
+			spm.Dismiss()
+			modal.NewPasswordModal(pg.Load).
+				Title("Start mixer on unlock").
+				Hint("Startup password").
+				NegativeButton("Cancel", func() {}).
+				PositiveButton("Confirm", func(startupPassword string, pm *modal.PasswordModal) bool {
+					go func() {
+						err := pg.WL.Wallet.EnableMixerAutoStart(pg.wallet.ID, []byte(startupPassword), []byte(spendingPassword))
+						if err != nil {
+							pm.SetError(err.Error())
+							pm.SetLoading(false)
+							return
+						}
+						pg.autoStartMixer.SetChecked(true)
+						pg.Toast.Notify("Mixer will start on unlock")
+						pm.Dismiss()
+					}()
+					return false
+				}).Show()
+			return false
+		}).Show()
+}
+
 func (pg *AccountMixerPage) showModalPasswordStartAccountMixer() {
 	modal.NewPasswordModal(pg.Load).
 		Title("Confirm to mix account").
@@ -305,12 +561,20 @@ func (pg *AccountMixerPage) showModalPasswordStartAccountMixer() {
 		}).
 		PositiveButton("Confirm", func(password string, pm *modal.PasswordModal) bool {
 			go func() {
-				err := pg.WL.MultiWallet.StartAccountMixer(pg.wallet.ID, password)
+				err := pg.WL.Wallet.CheckConnection(wallet.ProxySubsystemMixer)
+				if err == nil {
+					err = pg.WL.MultiWallet.StartAccountMixer(pg.wallet.ID, password)
+				}
 				if err != nil {
+					if err.Error() != dcrlibwallet.ErrInvalidPassphrase {
+						pg.WL.Wallet.RecordMixerError(pg.wallet.ID, err)
+						pg.loadMixerStats()
+					}
 					pm.SetError(err.Error())
 					pm.SetLoading(false)
 					return
 				}
+				pg.WL.Wallet.SetMixerRunState(pg.wallet.ID, true)
 				pm.Dismiss()
 			}()
 
@@ -343,6 +607,7 @@ func (pg *AccountMixerPage) listenForMixerNotifications() {
 					pg.mixerCompleted = true
 					pg.RefreshWindow()
 				}
+				pg.loadMixerStats()
 
 			case <-pg.ctx.Done():
 				pg.WL.MultiWallet.RemoveAccountMixerNotificationListener(AccountMixerPageID)
@@ -0,0 +1,163 @@
+package privacy
+
+import (
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+const mixerScheduleModalID = "mixer_schedule_modal"
+
+type mixerScheduleModal struct {
+	*load.Load
+
+	walletID      int
+	scheduleSaved func()
+
+	modal         decredmaterial.Modal
+	modeGroup     *widget.Enum
+	startEditor   decredmaterial.Editor
+	endEditor     decredmaterial.Editor
+	cancelBtn     decredmaterial.Button
+	saveButton    decredmaterial.Button
+	errorLabel    decredmaterial.Label
+	scheduleError string
+}
+
+func newMixerScheduleModal(l *load.Load, walletID int) *mixerScheduleModal {
+	schedule := l.WL.Wallet.MixerSchedule(walletID)
+
+	sm := &mixerScheduleModal{
+		Load:       l,
+		walletID:   walletID,
+		modal:      *l.Theme.ModalFloatTitle(),
+		modeGroup:  &widget.Enum{Value: schedule.Mode},
+		cancelBtn:  l.Theme.OutlineButton("Cancel"),
+		saveButton: l.Theme.Button("Save"),
+	}
+
+	sm.startEditor = l.Theme.Editor(new(widget.Editor), "Start (HH:MM)")
+	sm.startEditor.Editor.SingleLine = true
+	sm.startEditor.Editor.SetText(schedule.Start)
+
+	sm.endEditor = l.Theme.Editor(new(widget.Editor), "End (HH:MM)")
+	sm.endEditor.Editor.SingleLine = true
+	sm.endEditor.Editor.SetText(schedule.End)
+
+	sm.errorLabel = l.Theme.Body2("")
+	sm.errorLabel.Color = l.Theme.Color.Danger
+
+	return sm
+}
+
+func (sm *mixerScheduleModal) ScheduleSaved(scheduleSaved func()) *mixerScheduleModal {
+	sm.scheduleSaved = scheduleSaved
+	return sm
+}
+
+func (sm *mixerScheduleModal) ModalID() string {
+	return mixerScheduleModalID
+}
+
+func (sm *mixerScheduleModal) OnResume() {}
+
+func (sm *mixerScheduleModal) OnDismiss() {}
+
+func (sm *mixerScheduleModal) Show() {
+	sm.ShowModal(sm)
+}
+
+func (sm *mixerScheduleModal) Dismiss() {
+	sm.DismissModal(sm)
+}
+
+func (sm *mixerScheduleModal) Handle() {
+	if sm.cancelBtn.Clicked() || sm.modal.BackdropClicked(true) {
+		sm.Dismiss()
+	}
+
+	_, changed := decredmaterial.HandleEditorEvents(sm.startEditor.Editor, sm.endEditor.Editor)
+	if changed {
+		sm.scheduleError = ""
+	}
+
+	if sm.saveButton.Clicked() {
+		schedule := &wallet.MixerSchedule{Mode: sm.modeGroup.Value}
+		if schedule.Mode == wallet.MixerScheduleWindow {
+			schedule.Start = sm.startEditor.Editor.Text()
+			schedule.End = sm.endEditor.Editor.Text()
+		}
+
+		err := sm.WL.Wallet.SetMixerSchedule(sm.walletID, schedule)
+		if err != nil {
+			sm.scheduleError = err.Error()
+			return
+		}
+
+		if sm.scheduleSaved != nil {
+			sm.scheduleSaved()
+		}
+		sm.Dismiss()
+		go sm.WL.Wallet.RunMixerSchedules()
+	}
+}
+
+func (sm *mixerScheduleModal) Layout(gtx layout.Context) D {
+	radio := func(mode, label string) layout.Widget {
+		return sm.Theme.RadioButton(sm.modeGroup, mode, label, sm.Theme.Color.DeepBlue, sm.Theme.Color.Primary).Layout
+	}
+
+	w := []layout.Widget{
+		func(gtx C) D {
+			t := sm.Theme.H6("Mixing schedule")
+			t.Font.Weight = text.SemiBold
+			return t.Layout(gtx)
+		},
+		func(gtx C) D {
+			lbl := sm.Theme.Body2("Scheduled mixing needs the spending password to be stored with the startup password.")
+			lbl.Color = sm.Theme.Color.GrayText2
+			return lbl.Layout(gtx)
+		},
+		func(gtx C) D {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(radio(wallet.MixerScheduleManual, "Manual")),
+				layout.Rigid(radio(wallet.MixerScheduleAlways, "Always while synced")),
+				layout.Rigid(radio(wallet.MixerScheduleWindow, "Between set hours")),
+			)
+		},
+		func(gtx C) D {
+			if sm.modeGroup.Value != wallet.MixerScheduleWindow {
+				return D{}
+			}
+			return layout.Flex{}.Layout(gtx,
+				layout.Flexed(1, sm.startEditor.Layout),
+				layout.Rigid(layout.Spacer{Width: values.MarginPadding10}.Layout),
+				layout.Flexed(1, sm.endEditor.Layout),
+			)
+		},
+		func(gtx C) D {
+			if sm.scheduleError == "" {
+				return D{}
+			}
+			sm.errorLabel.Text = sm.scheduleError
+			return sm.errorLabel.Layout(gtx)
+		},
+		func(gtx C) D {
+			return layout.E.Layout(gtx, func(gtx C) D {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, sm.cancelBtn.Layout)
+					}),
+					layout.Rigid(sm.saveButton.Layout),
+				)
+			})
+		},
+	}
+
+	return sm.modal.Layout(gtx, w)
+}
@@ -0,0 +1,144 @@
+package page
+
+import (
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/godcr/appconfig"
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/values"
+)
+
+const profileModalID = "profile_modal"
+
+// profileModal adds a profile.
+type profileModal struct {
+	*load.Load
+
+	profiles *appconfig.Profiles
+	saved    func()
+
+	modal         *decredmaterial.Modal
+	nameEditor    decredmaterial.Editor
+	homeDirEditor decredmaterial.Editor
+	networkEditor decredmaterial.Editor
+	save          decredmaterial.Button
+	cancel        decredmaterial.Button
+	saveError     string
+}
+
+func newProfileModal(l *load.Load, profiles *appconfig.Profiles) *profileModal {
+	md := &profileModal{
+		Load:          l,
+		profiles:      profiles,
+		modal:         l.Theme.ModalFloatTitle(),
+		nameEditor:    l.Theme.Editor(new(widget.Editor), "Profile name"),
+		homeDirEditor: l.Theme.Editor(new(widget.Editor), "App directory"),
+		networkEditor: l.Theme.Editor(new(widget.Editor), "Network: mainnet, testnet, simnet or regnet"),
+		save:          l.Theme.Button("Add"),
+		cancel:        l.Theme.OutlineButton(values.String(values.StrCancel)),
+	}
+	md.nameEditor.Editor.SingleLine = true
+	md.homeDirEditor.Editor.SingleLine = true
+	md.networkEditor.Editor.SingleLine = true
+
+	return md
+}
+
+// Saved sets the function called once the profile is added.
+func (md *profileModal) Saved(saved func()) *profileModal {
+	md.saved = saved
+	return md
+}
+
+func (md *profileModal) ModalID() string {
+	return profileModalID
+}
+
+func (md *profileModal) Show() {
+	md.ShowModal(md)
+}
+
+func (md *profileModal) Dismiss() {
+	md.DismissModal(md)
+}
+
+func (md *profileModal) OnDismiss() {}
+
+func (md *profileModal) OnResume() {
+	md.nameEditor.Editor.Focus()
+}
+
+func (md *profileModal) Handle() {
+	if md.cancel.Clicked() {
+		md.Dismiss()
+	}
+
+	_, changed := decredmaterial.HandleEditorEvents(md.nameEditor.Editor, md.homeDirEditor.Editor, md.networkEditor.Editor)
+	if changed {
+		md.saveError = ""
+	}
+
+	if md.save.Clicked() {
+		name := strings.TrimSpace(md.nameEditor.Editor.Text())
+		err := md.profiles.Add(appconfig.Profile{
+			Name:    name,
+			HomeDir: md.homeDirEditor.Editor.Text(),
+			Network: strings.TrimSpace(md.networkEditor.Editor.Text()),
+		})
+		if err == nil {
+			if err = md.profiles.Save(); err != nil {
+				md.profiles.Remove(name)
+			}
+		}
+		if err != nil {
+			md.saveError = err.Error()
+			return
+		}
+
+		md.Dismiss()
+		if md.saved != nil {
+			md.saved()
+		}
+	}
+}
+
+func (md *profileModal) Layout(gtx layout.Context) D {
+	w := []layout.Widget{
+		func(gtx C) D {
+			t := md.Theme.H6("Add profile")
+			t.Font.Weight = text.SemiBold
+			return t.Layout(gtx)
+		},
+		func(gtx C) D {
+			lbl := md.Theme.Body2("The app directory is created the first time the profile is used. Leave the network empty to use the network of the config file in the app directory.")
+			lbl.Color = md.Theme.Color.GrayText2
+			return lbl.Layout(gtx)
+		},
+		md.nameEditor.Layout,
+		md.homeDirEditor.Layout,
+		md.networkEditor.Layout,
+		func(gtx C) D {
+			if md.saveError == "" {
+				return D{}
+			}
+			return md.Theme.ErrorLabel(md.saveError).Layout(gtx)
+		},
+		func(gtx C) D {
+			return layout.E.Layout(gtx, func(gtx C) D {
+				return layout.Flex{}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, md.cancel.Layout)
+					}),
+					layout.Rigid(md.save.Layout),
+				)
+			})
+		},
+	}
+
+	return md.modal.Layout(gtx, w)
+}
@@ -0,0 +1,256 @@
+package page
+
+import (
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/godcr/appconfig"
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/page/components"
+	"github.com/planetdecred/godcr/ui/values"
+)
+
+const ProfilesPageID = "Profiles"
+
+// profileListRow is a profile with its actions.
+type profileListRow struct {
+	profile    appconfig.Profile
+	setDefault decredmaterial.Button
+	remove     decredmaterial.Button
+}
+
+// ProfilesPage lists the profiles, each with its own app directory, network
+// and config file, and adds and removes them. The changes take effect the
+// next time godcr starts.
+type ProfilesPage struct {
+	*load.Load
+
+	container       *widget.List
+	backButton      decredmaterial.IconButton
+	add             decredmaterial.Button
+	chooseOnStartup *decredmaterial.Switch
+
+	profiles *appconfig.Profiles
+	rows     []*profileListRow
+	loadErr  string
+}
+
+func NewProfilesPage(l *load.Load) *ProfilesPage {
+	pg := &ProfilesPage{
+		Load:            l,
+		container:       &widget.List{List: layout.List{Axis: layout.Vertical}},
+		add:             l.Theme.OutlineButton("Add profile"),
+		chooseOnStartup: l.Theme.Switch(),
+	}
+	pg.backButton, _ = components.SubpageHeaderButtons(l)
+
+	return pg
+}
+
+// ID is a unique string that identifies the page and may be used
+// to differentiate this page from other pages.
+// Part of the load.Page interface.
+func (pg *ProfilesPage) ID() string {
+	return ProfilesPageID
+}
+
+// OnNavigatedTo is called when the page is about to be displayed and
+// may be used to initialize page features that are only relevant when
+// the page is displayed.
+// Part of the load.Page interface.
+func (pg *ProfilesPage) OnNavigatedTo() {
+	pg.loadProfiles()
+}
+
+// loadProfiles reads the profiles listed in the default app directory.
+func (pg *ProfilesPage) loadProfiles() {
+	profiles, err := appconfig.LoadProfiles(appconfig.DefaultProfilesFile)
+	if err != nil {
+		pg.profiles, pg.rows, pg.loadErr = nil, nil, err.Error()
+		return
+	}
+
+	var rows []*profileListRow
+	for _, profile := range profiles.Profiles {
+		row := &profileListRow{
+			profile:    profile,
+			setDefault: pg.Theme.OutlineButton("Use by default"),
+			remove:     pg.Theme.OutlineButton("Remove"),
+		}
+		row.setDefault.Inset = layout.UniformInset(values.MarginPadding8)
+		row.remove.Inset = layout.UniformInset(values.MarginPadding8)
+		rows = append(rows, row)
+	}
+	pg.profiles, pg.rows, pg.loadErr = profiles, rows, ""
+	pg.chooseOnStartup.SetChecked(profiles.ChooseOnStartup)
+}
+
+// saveProfiles saves the changed profiles and lists them again.
+func (pg *ProfilesPage) saveProfiles() {
+	if err := pg.profiles.Save(); err != nil {
+		pg.Toast.NotifyError(err.Error())
+	}
+	pg.loadProfiles()
+}
+
+// HandleUserInteractions is called just before Layout() to determine
+// if any user interaction recently occurred on the page and may be
+// used to update the page's UI components shortly before they are
+// displayed.
+// Part of the load.Page interface.
+func (pg *ProfilesPage) HandleUserInteractions() {
+	if pg.profiles == nil {
+		return
+	}
+
+	for pg.add.Clicked() {
+		newProfileModal(pg.Load, pg.profiles).
+			Saved(pg.loadProfiles).
+			Show()
+	}
+
+	if pg.chooseOnStartup.Changed() {
+		pg.profiles.ChooseOnStartup = pg.chooseOnStartup.IsChecked()
+		pg.saveProfiles()
+	}
+
+	for _, row := range pg.rows {
+		for row.setDefault.Clicked() {
+			if err := pg.profiles.SetDefault(row.profile.Name); err != nil {
+				pg.Toast.NotifyError(err.Error())
+				continue
+			}
+			pg.saveProfiles()
+		}
+		for row.remove.Clicked() {
+			if err := pg.profiles.Remove(row.profile.Name); err != nil {
+				pg.Toast.NotifyError(err.Error())
+				continue
+			}
+			pg.saveProfiles()
+			pg.Toast.Notify("Removed the profile " + row.profile.Name + ", its app directory was left as is")
+		}
+	}
+}
+
+// OnNavigatedFrom is called when the page is about to be removed from
+// the displayed window. This method should ideally be used to disable
+// features that are irrelevant when the page is NOT displayed.
+// NOTE: The page may be re-displayed on the app's window, in which case
+// OnNavigatedTo() will be called again. This method should not destroy UI
+// components unless they'll be recreated in the OnNavigatedTo() method.
+// Part of the load.Page interface.
+func (pg *ProfilesPage) OnNavigatedFrom() {}
+
+// Layout draws the page UI components into the provided layout context
+// to be eventually drawn on screen.
+// Part of the load.Page interface.
+func (pg *ProfilesPage) Layout(gtx C) D {
+	container := func(gtx C) D {
+		sp := components.SubPage{
+			Load:       pg.Load,
+			Title:      "Profiles",
+			BackButton: pg.backButton,
+			Back: func() {
+				pg.PopFragment()
+			},
+			Body: func(gtx C) D {
+				return pg.Theme.List(pg.container).Layout(gtx, 1, func(gtx C, i int) D {
+					return pg.profilesSection(gtx)
+				})
+			},
+		}
+		return sp.Layout(gtx)
+	}
+	return components.UniformPadding(gtx, container)
+}
+
+func (pg *ProfilesPage) profilesSection(gtx C) D {
+	card := pg.Theme.Card()
+	card.Radius = decredmaterial.Radius(14)
+	return card.Layout(gtx, func(gtx C) D {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		return layout.UniformInset(values.MarginPadding15).Layout(gtx, func(gtx C) D {
+			if pg.loadErr != "" {
+				return pg.Theme.ErrorLabel(pg.loadErr).Layout(gtx)
+			}
+
+			children := []layout.FlexChild{
+				layout.Rigid(func(gtx C) D {
+					return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+						layout.Flexed(1, func(gtx C) D {
+							lbl := pg.Theme.Body1("Profiles")
+							lbl.Font.Weight = text.SemiBold
+							return lbl.Layout(gtx)
+						}),
+						layout.Rigid(pg.add.Layout),
+					)
+				}),
+				layout.Rigid(func(gtx C) D {
+					lbl := pg.Theme.Body2("Each profile has its own app directory, with its wallets, config file and logs. The changes take effect the next time godcr starts.")
+					lbl.Color = pg.Theme.Color.GrayText2
+					return layout.Inset{Top: values.MarginPadding10}.Layout(gtx, lbl.Layout)
+				}),
+				layout.Rigid(func(gtx C) D {
+					return layout.Inset{Top: values.MarginPadding10}.Layout(gtx, func(gtx C) D {
+						return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+							layout.Flexed(1, pg.Theme.Body2("Ask for the profile at startup").Layout),
+							layout.Rigid(pg.chooseOnStartup.Layout),
+						)
+					})
+				}),
+			}
+			for _, row := range pg.rows {
+				row := row
+				children = append(children, layout.Rigid(func(gtx C) D {
+					return layout.Inset{Top: values.MarginPadding10}.Layout(gtx, func(gtx C) D {
+						return pg.profileRowLayout(gtx, row)
+					})
+				}))
+			}
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+		})
+	})
+}
+
+func (pg *ProfilesPage) profileRowLayout(gtx C, row *profileListRow) D {
+	title := row.profile.Name
+	if row.profile.Name == pg.profiles.Default {
+		title += " (default)"
+	}
+	current := row.profile.Name == pg.WL.Wallet.Profile()
+	if current {
+		title += " (in use)"
+	}
+	network := row.profile.Network
+	if network == "" {
+		network = "network of the config file"
+	}
+
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(pg.Theme.Body2(title).Layout),
+				layout.Rigid(func(gtx C) D {
+					lbl := pg.Theme.Caption(network + ", " + row.profile.HomeDir)
+					lbl.Color = pg.Theme.Color.GrayText2
+					return lbl.Layout(gtx)
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			if row.profile.Name == pg.profiles.Default {
+				return D{}
+			}
+			return layout.Inset{Left: values.MarginPadding8}.Layout(gtx, row.setDefault.Layout)
+		}),
+		layout.Rigid(func(gtx C) D {
+			if row.profile.Name == pg.profiles.Default || current {
+				return D{}
+			}
+			return layout.Inset{Left: values.MarginPadding8}.Layout(gtx, row.remove.Layout)
+		}),
+	)
+}
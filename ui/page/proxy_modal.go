@@ -0,0 +1,188 @@
+package page
+
+import (
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+const proxyModalID = "proxy_modal"
+
+// proxyModal sets the SOCKS5 proxy of the outbound connections.
+type proxyModal struct {
+	*load.Load
+
+	modal        *decredmaterial.Modal
+	addressInput decredmaterial.Editor
+	isolation    *decredmaterial.Switch
+	allowDirect  *decredmaterial.Switch
+	remove       decredmaterial.Button
+	cancel       decredmaterial.Button
+	save         decredmaterial.Button
+	saveError    string
+}
+
+func newProxyModal(l *load.Load) *proxyModal {
+	md := &proxyModal{
+		Load:         l,
+		modal:        l.Theme.ModalFloatTitle(),
+		addressInput: l.Theme.Editor(new(widget.Editor), "Proxy address, eg. 127.0.0.1:9050"),
+		isolation:    l.Theme.Switch(),
+		allowDirect:  l.Theme.Switch(),
+		remove:       l.Theme.OutlineButton("Turn off"),
+		cancel:       l.Theme.OutlineButton(values.String(values.StrCancel)),
+		save:         l.Theme.Button("Save"),
+	}
+	md.addressInput.Editor.SingleLine = true
+	md.remove.Color = l.Theme.Color.Danger
+
+	wal := l.WL.Wallet
+	md.addressInput.Editor.SetText(wal.ReadStringConfigValueForKey(wallet.ProxyAddressConfigKey))
+	md.isolation.SetChecked(wal.ProxyIsolation())
+	md.allowDirect.SetChecked(wal.ProxyAllowDirect())
+
+	return md
+}
+
+func (md *proxyModal) ModalID() string {
+	return proxyModalID
+}
+
+func (md *proxyModal) Show() {
+	md.ShowModal(md)
+}
+
+func (md *proxyModal) Dismiss() {
+	md.DismissModal(md)
+}
+
+func (md *proxyModal) OnDismiss() {}
+
+func (md *proxyModal) OnResume() {
+	md.addressInput.Editor.Focus()
+}
+
+func (md *proxyModal) Handle() {
+	if md.cancel.Clicked() {
+		md.Dismiss()
+	}
+
+	_, changed := decredmaterial.HandleEditorEvents(md.addressInput.Editor)
+	if changed {
+		md.saveError = ""
+	}
+
+	if md.remove.Clicked() {
+		md.setProxy("")
+	}
+
+	if md.save.Clicked() {
+		md.setProxy(md.addressInput.Editor.Text())
+	}
+}
+
+func (md *proxyModal) setProxy(addr string) {
+	err := md.WL.Wallet.SetProxy(addr, md.isolation.IsChecked(), md.allowDirect.IsChecked())
+	if err != nil {
+		md.saveError = err.Error()
+		return
+	}
+
+	md.Dismiss()
+	if addr == "" {
+		md.Toast.Notify("Proxy turned off")
+	} else {
+		md.Toast.Notify("Proxy saved")
+	}
+	if err := md.WL.Wallet.CheckDirectConnection(wallet.ProxySubsystemDEX); err != nil {
+		md.Toast.NotifyError(err.Error())
+	}
+	go func() {
+		if err := md.WL.Wallet.RestartSyncForProxy(); err != nil {
+			md.Toast.NotifyError(err.Error())
+		}
+	}()
+}
+
+func (md *proxyModal) switchRow(gtx C, title, description string, option *decredmaterial.Switch) D {
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(md.Theme.Body1(title).Layout),
+				layout.Rigid(func(gtx C) D {
+					lbl := md.Theme.Caption(description)
+					lbl.Color = md.Theme.Color.GrayText2
+					return lbl.Layout(gtx)
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{Left: values.MarginPadding8}.Layout(gtx, option.Layout)
+		}),
+	)
+}
+
+func (md *proxyModal) Layout(gtx layout.Context) D {
+	w := []layout.Widget{
+		func(gtx C) D {
+			t := md.Theme.H6("SOCKS5 proxy")
+			t.Font.Weight = text.SemiBold
+			return t.Layout(gtx)
+		},
+		func(gtx C) D {
+			lbl := md.Theme.Body2("Outbound connections go through the proxy, such as Tor. The Tor port 9050 is used when no port is set.")
+			lbl.Color = md.Theme.Color.GrayText2
+			return lbl.Layout(gtx)
+		},
+		func(gtx C) D {
+			if !md.WL.Wallet.ProxyOverridden() {
+				return D{}
+			}
+			lbl := md.Theme.Body2("The proxy " + md.WL.Wallet.ProxyAddress() + " is set with --proxy, this setting applies when the app starts without it.")
+			lbl.Color = md.Theme.Color.Danger
+			return lbl.Layout(gtx)
+		},
+		md.addressInput.Layout,
+		func(gtx C) D {
+			return md.switchRow(gtx, "Stream isolation",
+				"Each subsystem uses its own proxy credentials, Tor routes them over separate circuits.", md.isolation)
+		},
+		func(gtx C) D {
+			return md.switchRow(gtx, "Allow direct connections",
+				"The Bitcoin SPV wallet of the DEX cannot use the proxy. It is blocked unless direct connections are allowed.", md.allowDirect)
+		},
+		func(gtx C) D {
+			if md.saveError == "" {
+				return D{}
+			}
+			return md.Theme.ErrorLabel(md.saveError).Layout(gtx)
+		},
+		func(gtx C) D {
+			return layout.Flex{}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					if md.WL.Wallet.ReadStringConfigValueForKey(wallet.ProxyAddressConfigKey) == "" {
+						return D{}
+					}
+					return md.remove.Layout(gtx)
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					return layout.E.Layout(gtx, func(gtx C) D {
+						return layout.Flex{}.Layout(gtx,
+							layout.Rigid(func(gtx C) D {
+								return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, md.cancel.Layout)
+							}),
+							layout.Rigid(md.save.Layout),
+						)
+					})
+				}),
+			)
+		},
+	}
+
+	return md.modal.Layout(gtx, w)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/planetdecred/godcr/ui/modal"
 	"github.com/planetdecred/godcr/ui/page/components"
 	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
 	qrcode "github.com/yeqown/go-qrcode"
 	"golang.org/x/exp/shiny/materialdesign/icons"
 )
@@ -46,7 +47,12 @@ type ReceivePage struct {
 
 	backdrop   *widget.Clickable
 	backButton decredmaterial.IconButton
-	infoButton decredmaterial.IconButton
+
+	// explorer opens the address on the block explorers that have
+	// address pages, hasExplorer is false if there are none.
+	explorer    *decredmaterial.Clickable
+	hasExplorer bool
+	infoButton  decredmaterial.IconButton
 }
 
 func NewReceivePage(l *load.Load) *ReceivePage {
@@ -66,6 +72,7 @@ func NewReceivePage(l *load.Load) *ReceivePage {
 		receiveAddress: l.Theme.Label(values.TextSize20, ""),
 		card:           l.Theme.Card(),
 		backdrop:       new(widget.Clickable),
+		explorer:       l.Theme.NewClickable(true),
 	}
 
 	pg.info.Inset, pg.info.Size = layout.UniformInset(values.MarginPadding5), values.MarginPadding20
@@ -137,6 +144,7 @@ func (pg *ReceivePage) OnNavigatedTo() {
 	pg.selector.ListenForTxNotifications(pg.ctx)
 	pg.selector.SelectFirstWalletValidAccount(nil) // Want to reset the user's selection everytime this page appears?
 	// might be better to track the last selection in a variable and reselect it.
+	pg.hasExplorer = len(pg.WL.Wallet.BlockExplorersFor(wallet.ExplorerAddress)) > 0
 }
 
 func (pg *ReceivePage) generateQRForAddress() {
@@ -326,6 +334,14 @@ func (pg *ReceivePage) addressLayout(gtx layout.Context) layout.Dimensions {
 				card.Radius = decredmaterial.CornerRadius{TopRight: 8, TopLeft: 0, BottomRight: 8, BottomLeft: 0}
 				return card.Layout(gtx, pg.copy.Layout)
 			}),
+			layout.Rigid(func(gtx C) D {
+				if !pg.hasExplorer {
+					return D{}
+				}
+				return layout.Inset{Left: values.MarginPadding10}.Layout(gtx, func(gtx C) D {
+					return pg.explorer.Layout(gtx, pg.Icons.RedirectIcon.Layout24dp)
+				})
+			}),
 		)
 	})
 }
@@ -340,6 +356,10 @@ func (pg *ReceivePage) HandleUserInteractions() {
 		pg.isNewAddr = false
 	}
 
+	for pg.explorer.Clicked() {
+		components.OpenInExplorer(pg.Load, wallet.ExplorerAddress, pg.currentAddress)
+	}
+
 	if pg.more.Button.Clicked() {
 		pg.isNewAddr = !pg.isNewAddr
 		if pg.isInfo {
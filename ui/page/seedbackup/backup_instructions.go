@@ -23,10 +23,11 @@ type BackupInstructionsPage struct {
 	*load.Load
 	wallet *dcrlibwallet.Wallet
 
-	backButton  decredmaterial.IconButton
-	viewSeedBtn decredmaterial.Button
-	checkBoxes  []decredmaterial.CheckBoxStyle
-	infoList    *layout.List
+	backButton   decredmaterial.IconButton
+	viewSeedBtn  decredmaterial.Button
+	splitSeedBtn decredmaterial.Button
+	checkBoxes   []decredmaterial.CheckBoxStyle
+	infoList     *layout.List
 }
 
 func NewBackupInstructionsPage(l *load.Load, wallet *dcrlibwallet.Wallet) *BackupInstructionsPage {
@@ -34,10 +35,12 @@ func NewBackupInstructionsPage(l *load.Load, wallet *dcrlibwallet.Wallet) *Backu
 		Load:   l,
 		wallet: wallet,
 
-		viewSeedBtn: l.Theme.Button("View seed phrase"),
+		viewSeedBtn:  l.Theme.Button("View seed phrase"),
+		splitSeedBtn: l.Theme.OutlineButton("Split seed into shares instead"),
 	}
 
 	bi.viewSeedBtn.Font.Weight = text.Medium
+	bi.splitSeedBtn.Font.Weight = text.Medium
 
 	bi.backButton, _ = components.SubpageHeaderButtons(l)
 	bi.backButton.Icon = l.Icons.ContentClear
@@ -87,6 +90,12 @@ func (pg *BackupInstructionsPage) HandleUserInteractions() {
 		}
 	}
 
+	for pg.splitSeedBtn.Clicked() {
+		if pg.verifyCheckBoxes() {
+			pg.ChangeFragment(NewSplitSeedPage(pg.Load, pg.wallet))
+		}
+	}
+
 }
 func promptToExit(load *load.Load) {
 	modal.NewInfoModal(load).
@@ -121,13 +130,19 @@ func (pg *BackupInstructionsPage) Layout(gtx layout.Context) layout.Dimensions {
 			promptToExit(pg.Load)
 		},
 		Body: func(gtx C) D {
-			return pg.infoList.Layout(gtx, len(pg.checkBoxes), func(gtx C, i int) D {
+			return pg.infoList.Layout(gtx, len(pg.checkBoxes)+1, func(gtx C, i int) D {
+				if i == len(pg.checkBoxes) {
+					// The split seed option, for seeds that must not be
+					// stored in a single location.
+					return pg.splitSeedBtn.Layout(gtx)
+				}
 				return layout.Inset{Bottom: values.MarginPadding20}.Layout(gtx, pg.checkBoxes[i].Layout)
 			})
 		},
 	}
 
 	pg.viewSeedBtn.SetEnabled(pg.verifyCheckBoxes())
+	pg.splitSeedBtn.SetEnabled(pg.verifyCheckBoxes())
 
 	return container(gtx, *pg.Theme, sp.Layout, "", pg.viewSeedBtn)
 }
@@ -0,0 +1,147 @@
+package seedbackup
+
+import (
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/planetdecred/dcrlibwallet"
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/values"
+)
+
+const ReverifySeedModalID = "reverify_seed_modal"
+
+// ReverifySeedModal checks a seed backup again. The user enters the seed
+// from the backup, it is checked against the keys of the wallet so the seed
+// is never shown.
+type ReverifySeedModal struct {
+	*load.Load
+	wallet *dcrlibwallet.Wallet
+
+	verified func()
+
+	modal          *decredmaterial.Modal
+	seedEditor     decredmaterial.Editor
+	verify         decredmaterial.Button
+	cancel         decredmaterial.Button
+	materialLoader material.LoaderStyle
+	isSending      bool
+	verifyError    string
+}
+
+func NewReverifySeedModal(l *load.Load, wallet *dcrlibwallet.Wallet) *ReverifySeedModal {
+	return &ReverifySeedModal{
+		Load:           l,
+		wallet:         wallet,
+		modal:          l.Theme.ModalFloatTitle(),
+		seedEditor:     l.Theme.Editor(new(widget.Editor), "Seed words or hex seed"),
+		verify:         l.Theme.Button("Verify"),
+		cancel:         l.Theme.OutlineButton("Cancel"),
+		materialLoader: material.Loader(l.Theme.Base),
+	}
+}
+
+// Verified sets the function called once the seed is verified.
+func (md *ReverifySeedModal) Verified(verified func()) *ReverifySeedModal {
+	md.verified = verified
+	return md
+}
+
+func (md *ReverifySeedModal) ModalID() string {
+	return ReverifySeedModalID
+}
+
+func (md *ReverifySeedModal) Show() {
+	md.ShowModal(md)
+}
+
+func (md *ReverifySeedModal) Dismiss() {
+	md.DismissModal(md)
+}
+
+func (md *ReverifySeedModal) OnDismiss() {}
+
+func (md *ReverifySeedModal) OnResume() {}
+
+func (md *ReverifySeedModal) Handle() {
+	if md.cancel.Clicked() && !md.isSending {
+		md.Dismiss()
+	}
+
+	_, changed := decredmaterial.HandleEditorEvents(md.seedEditor.Editor)
+	if changed {
+		md.verifyError = ""
+	}
+
+	if md.verify.Clicked() && !md.isSending {
+		seed := md.seedEditor.Editor.Text()
+		if seed == "" {
+			md.verifyError = "enter the seed from your backup"
+			return
+		}
+
+		md.isSending = true
+		md.modal.SetDisabled(true)
+		go func() {
+			defer func() {
+				md.isSending = false
+				md.modal.SetDisabled(false)
+			}()
+
+			if err := md.WL.Wallet.ReverifySeed(md.wallet.ID, seed); err != nil {
+				md.verifyError = err.Error()
+				md.RefreshWindow()
+				return
+			}
+
+			md.Toast.Notify("Seed backup verified")
+			md.Dismiss()
+			if md.verified != nil {
+				md.verified()
+			}
+		}()
+	}
+}
+
+func (md *ReverifySeedModal) Layout(gtx layout.Context) D {
+	w := []layout.Widget{
+		func(gtx C) D {
+			t := md.Theme.H6("Verify seed backup")
+			t.Font.Weight = text.SemiBold
+			return t.Layout(gtx)
+		},
+		func(gtx C) D {
+			lbl := md.Theme.Body2("Enter the seed of " + md.wallet.Name + " from your backup to check that it still restores the wallet.")
+			lbl.Color = md.Theme.Color.GrayText2
+			return lbl.Layout(gtx)
+		},
+		func(gtx C) D {
+			gtx.Constraints.Max.Y = gtx.Px(values.MarginPadding150)
+			return md.seedEditor.Layout(gtx)
+		},
+		func(gtx C) D {
+			if md.verifyError == "" {
+				return D{}
+			}
+			return md.Theme.ErrorLabel(md.verifyError).Layout(gtx)
+		},
+		func(gtx C) D {
+			return layout.E.Layout(gtx, func(gtx C) D {
+				if md.isSending {
+					return md.materialLoader.Layout(gtx)
+				}
+				return layout.Flex{}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, md.cancel.Layout)
+					}),
+					layout.Rigid(md.verify.Layout),
+				)
+			})
+		},
+	}
+
+	return md.modal.Layout(gtx, w)
+}
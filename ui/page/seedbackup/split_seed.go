@@ -0,0 +1,315 @@
+package seedbackup
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/dcrlibwallet"
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/modal"
+	"github.com/planetdecred/godcr/ui/page/components"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+const SplitSeedPageID = "split_seed"
+
+// splitSeedStep is a step of the split seed backup.
+type splitSeedStep int
+
+const (
+	splitSeedSetup splitSeedStep = iota
+	splitSeedWriteShare
+	splitSeedVerifyShare
+)
+
+// SplitSeedPage splits the seed of a wallet into shares, any threshold of
+// which restore the seed. Each share is written down then verified.
+type SplitSeedPage struct {
+	*load.Load
+	wallet *dcrlibwallet.Wallet
+
+	backButton      decredmaterial.IconButton
+	actionButton    decredmaterial.Button
+	sharesEditor    decredmaterial.Editor
+	thresholdEditor decredmaterial.Editor
+	verifyEditor    decredmaterial.Editor
+	shareList       *widget.List
+
+	seed   string
+	shares []*wallet.SeedShare
+	words  []string
+
+	step       splitSeedStep
+	shareIndex int
+}
+
+func NewSplitSeedPage(l *load.Load, wallet *dcrlibwallet.Wallet) *SplitSeedPage {
+	pg := &SplitSeedPage{
+		Load:            l,
+		wallet:          wallet,
+		sharesEditor:    l.Theme.Editor(new(widget.Editor), "Number of shares"),
+		thresholdEditor: l.Theme.Editor(new(widget.Editor), "Shares needed to restore"),
+		verifyEditor:    l.Theme.Editor(new(widget.Editor), "Share words"),
+		shareList: &widget.List{
+			List: layout.List{Axis: layout.Vertical},
+		},
+	}
+
+	pg.sharesEditor.Editor.SingleLine = true
+	pg.sharesEditor.Editor.SetText("3")
+	pg.thresholdEditor.Editor.SingleLine = true
+	pg.thresholdEditor.Editor.SetText("2")
+
+	pg.backButton, _ = components.SubpageHeaderButtons(l)
+	pg.backButton.Icon = l.Icons.ContentClear
+
+	pg.setStep(splitSeedSetup)
+
+	return pg
+}
+
+// ID is a unique string that identifies the page and may be used
+// to differentiate this page from other pages.
+// Part of the load.Page interface.
+func (pg *SplitSeedPage) ID() string {
+	return SplitSeedPageID
+}
+
+// OnNavigatedTo is called when the page is about to be displayed and
+// may be used to initialize page features that are only relevant when
+// the page is displayed.
+// Part of the load.Page interface.
+func (pg *SplitSeedPage) OnNavigatedTo() {
+	if pg.seed != "" {
+		return
+	}
+
+	modal.NewPasswordModal(pg.Load).
+		Title("Confirm to split seed").
+		PositiveButton("Confirm", func(password string, m *modal.PasswordModal) bool {
+			go func() {
+				seed, err := pg.wallet.DecryptSeed([]byte(password))
+				if err != nil {
+					m.SetLoading(false)
+					m.SetError(err.Error())
+					return
+				}
+
+				m.Dismiss()
+				pg.seed = seed
+			}()
+
+			return false
+		}).
+		NegativeButton("Cancel", func() {
+			pg.PopToFragment(components.WalletsPageID)
+		}).Show()
+}
+
+func (pg *SplitSeedPage) setStep(step splitSeedStep) {
+	pg.step = step
+	switch step {
+	case splitSeedSetup:
+		pg.actionButton = pg.Theme.Button("Split seed")
+	case splitSeedWriteShare:
+		pg.words = strings.Split(pg.shares[pg.shareIndex].String(), " ")
+		pg.actionButton = pg.Theme.Button(fmt.Sprintf("I have written down share %d", pg.shareIndex+1))
+	case splitSeedVerifyShare:
+		pg.words = nil
+		pg.verifyEditor.Editor.SetText("")
+		pg.actionButton = pg.Theme.Button(fmt.Sprintf("Verify share %d", pg.shareIndex+1))
+	}
+	pg.actionButton.Font.Weight = text.Medium
+}
+
+func (pg *SplitSeedPage) splitSeed() {
+	n, err := strconv.Atoi(pg.sharesEditor.Editor.Text())
+	if err != nil {
+		pg.sharesEditor.SetError("enter a number")
+		return
+	}
+	threshold, err := strconv.Atoi(pg.thresholdEditor.Editor.Text())
+	if err != nil {
+		pg.thresholdEditor.SetError("enter a number")
+		return
+	}
+
+	shares, err := wallet.SplitSeed(pg.seed, threshold, n)
+	if err != nil {
+		pg.Toast.NotifyError(err.Error())
+		return
+	}
+
+	pg.shares = shares
+	pg.shareIndex = 0
+	pg.setStep(splitSeedWriteShare)
+}
+
+func (pg *SplitSeedPage) verifyShare() {
+	share, err := wallet.DecodeSeedShare(pg.verifyEditor.Editor.Text())
+	if err != nil || !wallet.SameShare(share, pg.shares[pg.shareIndex]) {
+		pg.verifyEditor.SetError(fmt.Sprintf("This is not share %d. Check every word and try again.", pg.shareIndex+1))
+		return
+	}
+
+	if pg.shareIndex < len(pg.shares)-1 {
+		pg.shareIndex++
+		pg.setStep(splitSeedWriteShare)
+		return
+	}
+
+	// All the shares are written down, the seed is not needed anymore.
+	modal.NewPasswordModal(pg.Load).
+		Title("Confirm to verify seed").
+		PositiveButton("Confirm", func(password string, m *modal.PasswordModal) bool {
+			go func() {
+				_, err := pg.WL.MultiWallet.VerifySeedForWallet(pg.wallet.ID, pg.seed, []byte(password))
+				if err != nil {
+					m.SetLoading(false)
+					m.SetError(err.Error())
+					return
+				}
+				m.Dismiss()
+
+				pg.WL.Wallet.MarkSeedVerified(pg.wallet.ID)
+				pg.ChangeFragment(NewBackupSuccessPage(pg.Load))
+			}()
+
+			return false
+		}).
+		NegativeButton("Cancel", func() {}).Show()
+}
+
+// HandleUserInteractions is called just before Layout() to determine
+// if any user interaction recently occurred on the page and may be
+// used to update the page's UI components shortly before they are
+// displayed.
+// Part of the load.Page interface.
+func (pg *SplitSeedPage) HandleUserInteractions() {
+	_, changed := decredmaterial.HandleEditorEvents(pg.sharesEditor.Editor, pg.thresholdEditor.Editor, pg.verifyEditor.Editor)
+	if changed {
+		pg.sharesEditor.SetError("")
+		pg.thresholdEditor.SetError("")
+		pg.verifyEditor.SetError("")
+	}
+
+	for pg.actionButton.Clicked() {
+		switch pg.step {
+		case splitSeedSetup:
+			if pg.seed != "" {
+				pg.splitSeed()
+			}
+		case splitSeedWriteShare:
+			pg.setStep(splitSeedVerifyShare)
+		case splitSeedVerifyShare:
+			pg.verifyShare()
+		}
+	}
+}
+
+// OnNavigatedFrom is called when the page is about to be removed from
+// the displayed window. This method should ideally be used to disable
+// features that are irrelevant when the page is NOT displayed.
+// NOTE: The page may be re-displayed on the app's window, in which case
+// OnNavigatedTo() will be called again. This method should not destroy UI
+// components unless they'll be recreated in the OnNavigatedTo() method.
+// Part of the load.Page interface.
+func (pg *SplitSeedPage) OnNavigatedFrom() {}
+
+// Layout draws the page UI components into the provided layout context
+// to be eventually drawn on screen.
+// Part of the load.Page interface.
+func (pg *SplitSeedPage) Layout(gtx C) D {
+	sp := components.SubPage{
+		Load:       pg.Load,
+		Title:      "Split seed into shares",
+		WalletName: pg.wallet.Name,
+		BackButton: pg.backButton,
+		Back: func() {
+			promptToExit(pg.Load)
+		},
+		Body: func(gtx C) D {
+			switch pg.step {
+			case splitSeedWriteShare:
+				return pg.shareLayout(gtx)
+			case splitSeedVerifyShare:
+				return pg.bodyLayout(gtx, fmt.Sprintf("Enter share %d of %d to verify it was written down correctly.", pg.shareIndex+1, len(pg.shares)),
+					pg.verifyEditor.Layout)
+			}
+			return pg.bodyLayout(gtx, "Any of the shares needed to restore give access to the wallet, fewer reveal nothing about the seed. Keep each share in a separate location.",
+				pg.sharesEditor.Layout, pg.thresholdEditor.Layout)
+		},
+	}
+
+	var infoText string
+	if pg.step != splitSeedSetup {
+		infoText = fmt.Sprintf("Share %d of %d, %d shares restore the wallet.", pg.shareIndex+1, len(pg.shares), pg.shares[0].Threshold)
+	}
+	pg.actionButton.SetEnabled(pg.seed != "")
+	return container(gtx, *pg.Theme, sp.Layout, infoText, pg.actionButton)
+}
+
+func (pg *SplitSeedPage) bodyLayout(gtx C, description string, widgets ...layout.Widget) D {
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx C) D {
+			label := pg.Theme.Label(values.TextSize16, description)
+			label.Color = pg.Theme.Color.GrayText1
+			return label.Layout(gtx)
+		}),
+	}
+	for _, w := range widgets {
+		w := w
+		children = append(children, layout.Rigid(func(gtx C) D {
+			return layout.Inset{Top: values.MarginPadding16}.Layout(gtx, w)
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
+func (pg *SplitSeedPage) shareLayout(gtx C) D {
+	rows := (len(pg.words) + 2) / 3
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			label := pg.Theme.Label(values.TextSize16, fmt.Sprintf("Write down all %d words of share %d in the correct order.", len(pg.words), pg.shareIndex+1))
+			label.Color = pg.Theme.Color.GrayText1
+			return label.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return decredmaterial.LinearLayout{
+				Width:       decredmaterial.MatchParent,
+				Height:      decredmaterial.WrapContent,
+				Orientation: layout.Vertical,
+				Background:  pg.Theme.Color.Surface,
+				Border:      decredmaterial.Border{Radius: decredmaterial.Radius(8)},
+				Margin:      layout.Inset{Top: values.MarginPadding16, Bottom: values.MarginPadding120},
+				Padding:     layout.Inset{Top: values.MarginPadding8, Right: values.MarginPadding16, Bottom: values.MarginPadding8, Left: values.MarginPadding16},
+			}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					return pg.Theme.List(pg.shareList).Layout(gtx, rows, func(gtx C, row int) D {
+						itemWidth := gtx.Constraints.Max.X / 3
+						children := make([]layout.FlexChild, 0, 3)
+						for col := 0; col < 3; col++ {
+							i := col*rows + row
+							if i >= len(pg.words) {
+								break
+							}
+							children = append(children, layout.Rigid(func(gtx C) D {
+								return seedItem(pg.Theme, gtx, itemWidth, i+1, pg.words[i])
+							}))
+						}
+						return layout.Inset{Top: values.MarginPadding8}.Layout(gtx, func(gtx C) D {
+							return layout.Flex{}.Layout(gtx, children...)
+						})
+					})
+				}),
+			)
+		}),
+	)
+}
@@ -172,6 +172,7 @@ func (pg *VerifySeedPage) verifySeed() {
 				}
 				m.Dismiss()
 
+				pg.WL.Wallet.MarkSeedVerified(pg.wallet.ID)
 				pg.ChangeFragment(NewBackupSuccessPage(pg.Load))
 			}()
 
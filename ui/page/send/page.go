@@ -18,6 +18,7 @@ import (
 	"github.com/planetdecred/godcr/ui/modal"
 	"github.com/planetdecred/godcr/ui/page/components"
 	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
 )
 
 const (
@@ -177,6 +178,10 @@ func (pg *Page) fetchExchangeRate() {
 	if pg.isFetchingExchangeRate {
 		return
 	}
+	if err := pg.WL.Wallet.CheckConnection(wallet.ProxySubsystemExchange); err != nil {
+		pg.exchangeRateMessage = err.Error()
+		return
+	}
 	maxAttempts := 5
 	delayBtwAttempts := 2 * time.Second
 	pg.isFetchingExchangeRate = true
@@ -185,7 +190,7 @@ func (pg *Page) fetchExchangeRate() {
 
 	var dcrUsdtBittrex load.DCRUSDTBittrex
 	attempts, err := components.RetryFunc(maxAttempts, delayBtwAttempts, desc, func() error {
-		return load.GetUSDExchangeValue(&dcrUsdtBittrex)
+		return load.GetUSDExchangeValue(pg.WL.Wallet.HTTPClient(wallet.ProxySubsystemExchange), &dcrUsdtBittrex)
 	})
 	if err != nil {
 		pg.exchangeRateMessage = "Exchange rate not fetched. Kindly check internet connection."
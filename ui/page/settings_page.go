@@ -1,6 +1,8 @@
 package page
 
 import (
+	"fmt"
+
 	"gioui.org/layout"
 	"gioui.org/widget"
 
@@ -9,6 +11,7 @@ import (
 	"github.com/planetdecred/godcr/ui/load"
 	"github.com/planetdecred/godcr/ui/modal"
 	"github.com/planetdecred/godcr/ui/page/components"
+	"github.com/planetdecred/godcr/ui/page/dexclient"
 	"github.com/planetdecred/godcr/ui/preference"
 	"github.com/planetdecred/godcr/ui/values"
 	"github.com/planetdecred/godcr/wallet"
@@ -23,6 +26,21 @@ type row struct {
 	label     decredmaterial.Label
 }
 
+// ticketNotification is a ticket lifecycle event whose notification
+// delivery can be configured.
+type ticketNotification struct {
+	title     string // str-key
+	configKey string
+	clickable *decredmaterial.Clickable
+}
+
+// dexNotification is a DEX notification category that can be switched on
+// or off.
+type dexNotification struct {
+	category *dexclient.NotificationCategory
+	toggle   *decredmaterial.Switch
+}
+
 type SettingsPage struct {
 	*load.Load
 
@@ -34,6 +52,18 @@ type SettingsPage struct {
 	changeStartupPass   *decredmaterial.Clickable
 	language            *decredmaterial.Clickable
 	currency            *decredmaterial.Clickable
+	seedVerification    *decredmaterial.Clickable
+	autoLock            *decredmaterial.Clickable
+	syncRetry           *decredmaterial.Clickable
+	peerManager         *decredmaterial.Clickable
+	proxy               *decredmaterial.Clickable
+	networkPrivacy      *decredmaterial.Clickable
+	blockExplorers      *decredmaterial.Clickable
+	profiles            *decredmaterial.Clickable
+	dataDirectory       *decredmaterial.Clickable
+
+	ticketNotifications []ticketNotification
+	dexNotifications    []dexNotification
 
 	chevronRightIcon *decredmaterial.Icon
 	backButton       decredmaterial.IconButton
@@ -86,6 +116,34 @@ func NewSettingsPage(l *load.Load) *SettingsPage {
 		changeStartupPass:   l.Theme.NewClickable(false),
 		language:            l.Theme.NewClickable(false),
 		currency:            l.Theme.NewClickable(false),
+		seedVerification:    l.Theme.NewClickable(false),
+		autoLock:            l.Theme.NewClickable(false),
+		syncRetry:           l.Theme.NewClickable(false),
+		peerManager:         l.Theme.NewClickable(false),
+		proxy:               l.Theme.NewClickable(false),
+		networkPrivacy:      l.Theme.NewClickable(false),
+		blockExplorers:      l.Theme.NewClickable(false),
+		profiles:            l.Theme.NewClickable(false),
+		dataDirectory:       l.Theme.NewClickable(false),
+	}
+
+	pg.ticketNotifications = []ticketNotification{
+		{title: values.StrTicketLive, configKey: load.TicketLiveNotificationConfigKey},
+		{title: values.StrTicketVoted, configKey: load.TicketVotedNotificationConfigKey},
+		{title: values.StrTicketRevoked, configKey: load.TicketRevokedNotificationConfigKey},
+		{title: values.StrTicketMissed, configKey: load.TicketMissedNotificationConfigKey},
+		{title: values.StrTicketExpired, configKey: load.TicketExpiredNotificationConfigKey},
+		{title: values.StrTicketMatured, configKey: load.TicketMaturedNotificationConfigKey},
+	}
+	for i := range pg.ticketNotifications {
+		pg.ticketNotifications[i].clickable = l.Theme.NewClickable(false)
+	}
+
+	for _, category := range dexclient.NotificationCategories {
+		pg.dexNotifications = append(pg.dexNotifications, dexNotification{
+			category: category,
+			toggle:   l.Theme.Switch(),
+		})
 	}
 
 	pg.backButton, pg.infoButton = components.SubpageHeaderButtons(l)
@@ -174,6 +232,34 @@ func (pg *SettingsPage) general() layout.Widget {
 					}
 					return pg.clickableRow(gtx, languageRow)
 				}),
+				layout.Rigid(pg.lineSeparator()),
+				layout.Rigid(func(gtx C) D {
+					profile := pg.wal.Profile()
+					if profile == "" {
+						profile = "--appdata"
+					}
+					label := pg.Theme.Body2(profile)
+					label.Color = pg.Theme.Color.GrayText2
+					profilesRow := row{
+						title:     "Profiles",
+						clickable: pg.profiles,
+						icon:      pg.chevronRightIcon,
+						label:     label,
+					}
+					return pg.clickableRow(gtx, profilesRow)
+				}),
+				layout.Rigid(pg.lineSeparator()),
+				layout.Rigid(func(gtx C) D {
+					label := pg.Theme.Body2(pg.wal.Root)
+					label.Color = pg.Theme.Color.GrayText2
+					dataDirectoryRow := row{
+						title:     "Wallet data directory",
+						clickable: pg.dataDirectory,
+						icon:      pg.chevronRightIcon,
+						label:     label,
+					}
+					return pg.clickableRow(gtx, dataDirectoryRow)
+				}),
 			)
 		})
 	}
@@ -192,11 +278,51 @@ func (pg *SettingsPage) notification() layout.Widget {
 				layout.Rigid(func(gtx C) D {
 					return pg.subSectionSwitch(gtx, "Proposal notification", pg.proposalNotification)
 				}),
+				layout.Rigid(pg.ticketNotificationRows()),
+				layout.Rigid(pg.dexNotificationRows()),
 			)
 		})
 	}
 }
 
+func (pg *SettingsPage) ticketNotificationRows() layout.Widget {
+	return func(gtx C) D {
+		rows := make([]layout.FlexChild, 0, len(pg.ticketNotifications)*2)
+		for _, tn := range pg.ticketNotifications {
+			tn := tn
+			rows = append(rows, layout.Rigid(pg.lineSeparator()), layout.Rigid(func(gtx C) D {
+				value := pg.wal.ReadStringConfigValueForKey(tn.configKey)
+				if value == "" {
+					value = values.TicketNotificationOff
+				}
+				label := pg.Theme.Body2(values.String(values.ArrTicketNotification[value]))
+				label.Color = pg.Theme.Color.GrayText2
+				ticketNotificationRow := row{
+					title:     values.String(tn.title),
+					clickable: tn.clickable,
+					icon:      pg.chevronRightIcon,
+					label:     label,
+				}
+				return pg.clickableRow(gtx, ticketNotificationRow)
+			}))
+		}
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
+	}
+}
+
+func (pg *SettingsPage) dexNotificationRows() layout.Widget {
+	return func(gtx C) D {
+		rows := make([]layout.FlexChild, 0, len(pg.dexNotifications)*2)
+		for _, dn := range pg.dexNotifications {
+			dn := dn
+			rows = append(rows, layout.Rigid(pg.lineSeparator()), layout.Rigid(func(gtx C) D {
+				return pg.subSectionSwitch(gtx, dn.category.Title, dn.toggle)
+			}))
+		}
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
+	}
+}
+
 func (pg *SettingsPage) security() layout.Widget {
 	return func(gtx C) D {
 		return pg.mainSection(gtx, values.String(values.StrSecurity), func(gtx C) D {
@@ -215,6 +341,38 @@ func (pg *SettingsPage) security() layout.Widget {
 						return pg.clickableRow(gtx, changeStartupPassRow)
 					})
 				}),
+				layout.Rigid(pg.lineSeparator()),
+				layout.Rigid(func(gtx C) D {
+					value := pg.wal.ReadStringConfigValueForKey(wallet.SeedVerificationIntervalConfigKey)
+					if value == "" {
+						value = wallet.DefaultSeedVerificationInterval
+					}
+					label := pg.Theme.Body2(values.String(values.ArrSeedVerificationInterval[value]))
+					label.Color = pg.Theme.Color.GrayText2
+					seedVerificationRow := row{
+						title:     values.String(values.StrSeedVerificationReminder),
+						clickable: pg.seedVerification,
+						icon:      pg.chevronRightIcon,
+						label:     label,
+					}
+					return pg.clickableRow(gtx, seedVerificationRow)
+				}),
+				layout.Rigid(pg.lineSeparator()),
+				layout.Rigid(func(gtx C) D {
+					value := pg.wal.ReadStringConfigValueForKey(wallet.AutoLockConfigKey)
+					if value == "" || !pg.wal.CanAutoLock() {
+						value = wallet.DefaultAutoLock
+					}
+					label := pg.Theme.Body2(values.String(values.ArrAutoLock[value]))
+					label.Color = pg.Theme.Color.GrayText2
+					autoLockRow := row{
+						title:     values.String(values.StrAutoLock),
+						clickable: pg.autoLock,
+						icon:      pg.chevronRightIcon,
+						label:     label,
+					}
+					return pg.clickableRow(gtx, autoLockRow)
+				}),
 			)
 		})
 	}
@@ -241,12 +399,102 @@ func (pg *SettingsPage) connection() layout.Widget {
 					})
 				}),
 				layout.Rigid(pg.lineSeparator()),
+				layout.Rigid(func(gtx C) D {
+					peerManagerRow := row{
+						title:     "Peer manager",
+						clickable: pg.peerManager,
+						icon:      pg.chevronRightIcon,
+						label:     pg.Theme.Body1(""),
+					}
+					return pg.clickableRow(gtx, peerManagerRow)
+				}),
+				layout.Rigid(pg.lineSeparator()),
+				layout.Rigid(func(gtx C) D {
+					proxyValue := "Off"
+					if pg.wal.ProxyEnabled() {
+						proxyValue = pg.wal.ProxyAddress()
+						if pg.wal.ProxyOverridden() {
+							proxyValue += " (--proxy)"
+						}
+					}
+					label := pg.Theme.Body2(proxyValue)
+					label.Color = pg.Theme.Color.GrayText2
+					proxyRow := row{
+						title:     "SOCKS5 proxy",
+						clickable: pg.proxy,
+						icon:      pg.chevronRightIcon,
+						label:     label,
+					}
+					return pg.clickableRow(gtx, proxyRow)
+				}),
+				layout.Rigid(pg.lineSeparator()),
+				layout.Rigid(func(gtx C) D {
+					label := pg.Theme.Body2(pg.networkPrivacyValue())
+					label.Color = pg.Theme.Color.GrayText2
+					networkPrivacyRow := row{
+						title:     "Network privacy",
+						clickable: pg.networkPrivacy,
+						icon:      pg.chevronRightIcon,
+						label:     label,
+					}
+					return pg.clickableRow(gtx, networkPrivacyRow)
+				}),
+				layout.Rigid(pg.lineSeparator()),
+				layout.Rigid(func(gtx C) D {
+					value := "Off"
+					if !pg.wal.FeatureDisabled(wallet.ProxySubsystemExplorer) {
+						value = fmt.Sprintf("%d", len(pg.wal.BlockExplorers()))
+					}
+					label := pg.Theme.Body2(value)
+					label.Color = pg.Theme.Color.GrayText2
+					blockExplorersRow := row{
+						title:     "Block explorers",
+						clickable: pg.blockExplorers,
+						icon:      pg.chevronRightIcon,
+						label:     label,
+					}
+					return pg.clickableRow(gtx, blockExplorersRow)
+				}),
+				layout.Rigid(pg.lineSeparator()),
 				layout.Rigid(pg.agent()),
+				layout.Rigid(pg.lineSeparator()),
+				layout.Rigid(func(gtx C) D {
+					value := pg.wal.ReadStringConfigValueForKey(wallet.SyncRetryConfigKey)
+					if value == "" {
+						value = wallet.DefaultSyncRetry
+					}
+					label := pg.Theme.Body2(values.String(values.ArrSyncRetry[value]))
+					label.Color = pg.Theme.Color.GrayText2
+					syncRetryRow := row{
+						title:     values.String(values.StrSyncRetry),
+						clickable: pg.syncRetry,
+						icon:      pg.chevronRightIcon,
+						label:     label,
+					}
+					return pg.clickableRow(gtx, syncRetryRow)
+				}),
 			)
 		})
 	}
 }
 
+// networkPrivacyValue describes the network features turned off.
+func (pg *SettingsPage) networkPrivacyValue() string {
+	if pg.wal.NoThirdPartyRequests() {
+		return "No third-party requests"
+	}
+	off := 0
+	for _, feature := range wallet.PrivacyFeatures {
+		if pg.wal.FeatureDisabled(feature) {
+			off++
+		}
+	}
+	if off == 0 {
+		return "All features on"
+	}
+	return fmt.Sprintf("%d of %d features off", off, len(wallet.PrivacyFeatures))
+}
+
 func (pg *SettingsPage) agent() layout.Widget {
 	return func(gtx C) D {
 		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
@@ -387,6 +635,16 @@ func (pg *SettingsPage) showWarningModalDialog(title, msg, key string) {
 	pg.ShowModal(info)
 }
 
+// showAutoLockPreference lists the auto-lock timeouts.
+func (pg *SettingsPage) showAutoLockPreference() {
+	preference.NewListPreference(pg.WL.Wallet, pg.Load,
+		wallet.AutoLockConfigKey, wallet.DefaultAutoLock,
+		values.ArrAutoLock).
+		Title(values.StrAutoLock).
+		UpdateValues(func() {}).
+		Show()
+}
+
 // HandleUserInteractions is called just before Layout() to determine
 // if any user interaction recently occurred on the page and may be
 // used to update the page's UI components shortly before they are
@@ -414,6 +672,67 @@ func (pg *SettingsPage) HandleUserInteractions() {
 		break
 	}
 
+	for pg.seedVerification.Clicked() {
+		preference.NewListPreference(pg.WL.Wallet, pg.Load,
+			wallet.SeedVerificationIntervalConfigKey, wallet.DefaultSeedVerificationInterval,
+			values.ArrSeedVerificationInterval).
+			Title(values.StrSeedVerificationReminder).
+			UpdateValues(func() {}).
+			Show()
+		break
+	}
+
+	for pg.syncRetry.Clicked() {
+		preference.NewListPreference(pg.WL.Wallet, pg.Load,
+			wallet.SyncRetryConfigKey, wallet.DefaultSyncRetry,
+			values.ArrSyncRetry).
+			Title(values.StrSyncRetry).
+			UpdateValues(func() {}).
+			Show()
+		break
+	}
+
+	for pg.autoLock.Clicked() {
+		if pg.wal.CanAutoLock() {
+			pg.showAutoLockPreference()
+			break
+		}
+
+		// Without a startup password, a spending password is confirmed once
+		// and its verifier unlocks the window.
+		modal.NewPasswordModal(pg.Load).
+			Title(values.String(values.StrAutoLock)).
+			Description(values.String(values.StrAutoLockSpending)).
+			Hint("Spending password").
+			NegativeButton(values.String(values.StrCancel), func() {}).
+			PositiveButton(values.String(values.StrConfirm), func(password string, pm *modal.PasswordModal) bool {
+				go func() {
+					if err := pg.wal.SetAutoLockPassphrase([]byte(password)); err != nil {
+						pm.SetError(translateErr(err))
+						pm.SetLoading(false)
+						return
+					}
+					pm.Dismiss()
+					pg.showAutoLockPreference()
+				}()
+
+				return false
+			}).Show()
+		break
+	}
+
+	for _, tn := range pg.ticketNotifications {
+		for tn.clickable.Clicked() {
+			preference.NewListPreference(pg.WL.Wallet, pg.Load,
+				tn.configKey, values.TicketNotificationOff,
+				values.ArrTicketNotification).
+				Title(tn.title).
+				UpdateValues(func() {}).
+				Show()
+			break
+		}
+	}
+
 	if pg.isDarkModeOn.Changed() {
 		pg.wal.SaveConfigValueForKey(load.DarkModeConfigKey, pg.isDarkModeOn.IsChecked())
 		pg.RefreshTheme()
@@ -425,8 +744,8 @@ func (pg *SettingsPage) HandleUserInteractions() {
 
 	if pg.governance.Changed() {
 		if pg.governance.IsChecked() {
-			go pg.WL.MultiWallet.Politeia.Sync()
 			pg.WL.Wallet.SaveConfigValueForKey(load.FetchProposalConfigKey, pg.governance.IsChecked())
+			go pg.WL.Wallet.SyncPoliteia()
 			pg.Toast.Notify("Proposals fetching enabled. Check Governance page")
 		} else {
 			info := modal.NewInfoModal(pg.Load).
@@ -468,6 +787,17 @@ func (pg *SettingsPage) HandleUserInteractions() {
 		}
 	}
 
+	for _, dn := range pg.dexNotifications {
+		if dn.toggle.Changed() {
+			pg.wal.SaveConfigValueForKey(dn.category.ConfigKey, dn.toggle.IsChecked())
+			if dn.toggle.IsChecked() {
+				pg.Toast.Notify(dn.category.Title + " notifications enabled")
+			} else {
+				pg.Toast.Notify(dn.category.Title + " notifications disabled")
+			}
+		}
+	}
+
 	if pg.infoButton.Button.Clicked() {
 		info := modal.NewInfoModal(pg.Load).
 			Title("Set up startup password").
@@ -511,6 +841,7 @@ func (pg *SettingsPage) HandleUserInteractions() {
 									m.SetLoading(false)
 									return
 								}
+								pg.wal.RekeyMixerCredentials([]byte(password), []byte(newPassword))
 								pg.Toast.Notify("Startup password changed")
 								m.Dismiss()
 							}()
@@ -563,6 +894,7 @@ func (pg *SettingsPage) HandleUserInteractions() {
 							pm.SetLoading(false)
 							return
 						}
+						pg.wal.RekeyMixerCredentials([]byte(password), nil)
 						pg.Toast.Notify("Startup password disabled")
 						pm.Dismiss()
 					}()
@@ -589,6 +921,31 @@ func (pg *SettingsPage) HandleUserInteractions() {
 		break
 	}
 
+	for pg.peerManager.Clicked() {
+		pg.ChangeFragment(NewPeerManagerPage(pg.Load))
+	}
+
+	for pg.profiles.Clicked() {
+		pg.ChangeFragment(NewProfilesPage(pg.Load))
+	}
+
+	for pg.dataDirectory.Clicked() {
+		pg.ChangeFragment(NewMoveDataPage(pg.Load))
+	}
+
+	for pg.blockExplorers.Clicked() {
+		pg.ChangeFragment(NewBlockExplorersPage(pg.Load))
+	}
+
+	for pg.networkPrivacy.Clicked() {
+		pg.ChangeFragment(NewNetworkPrivacyPage(pg.Load))
+	}
+
+	for pg.proxy.Clicked() {
+		newProxyModal(pg.Load).Show()
+		break
+	}
+
 	userAgentKey := dcrlibwallet.UserAgentConfigKey
 	for pg.updateUserAgent.Clicked() {
 		pg.showUserAgentDialog()
@@ -620,11 +977,20 @@ func (pg *SettingsPage) HandleUserInteractions() {
 
 func (pg *SettingsPage) showSPVPeerDialog() {
 	textModal := modal.NewTextInputModal(pg.Load).
-		Hint("IP address").
+		Hint("IP addresses, separated by commas").
 		PositiveButtonStyle(pg.Load.Theme.Color.Primary, pg.Load.Theme.Color.InvText).
 		PositiveButton(values.String(values.StrConfirm), func(ipAddress string, tim *modal.TextInputModal) bool {
-			if ipAddress != "" {
-				pg.wal.SaveConfigValueForKey(dcrlibwallet.SpvPersistentPeerAddressesConfigKey, ipAddress)
+			if ipAddress == "" {
+				return true
+			}
+			addresses, err := wallet.NormalizePeerAddresses(ipAddress, pg.WL.Wallet.ChainParams())
+			if err == nil {
+				err = pg.WL.Wallet.SetPersistentPeers(addresses)
+			}
+			if err != nil {
+				tim.SetError(err.Error())
+				tim.SetLoading(false)
+				return false
 			}
 			return true
 		})
@@ -708,6 +1074,10 @@ func (pg *SettingsPage) updateSettingOptions() {
 	if transactionNotification {
 		pg.transactionNotification.SetChecked(transactionNotification)
 	}
+
+	for _, dn := range pg.dexNotifications {
+		dn.toggle.SetChecked(dn.category.Enabled(pg.Load))
+	}
 }
 
 // OnNavigatedFrom is called when the page is about to be removed from
@@ -72,7 +72,7 @@ func (tb *ticketBuyerModal) OnResume() {
 
 	if len(tb.WL.MultiWallet.KnownVSPs()) == 0 {
 		// TODO: Does this modal need this list?
-		go tb.WL.MultiWallet.ReloadVSPList(context.TODO())
+		go tb.WL.Wallet.ReloadVSPList(context.TODO())
 	}
 
 	// loop through all available wallets and select the one with ticket buyer config.
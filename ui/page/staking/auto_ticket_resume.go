@@ -0,0 +1,128 @@
+package staking
+
+import (
+	"fmt"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/widget"
+
+	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/planetdecred/dcrlibwallet"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/modal"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+// createUnlockPINModal asks for the unlock PIN used to encrypt the spending
+// password of the ticket buyer, so that it can be resumed after a restart.
+func (pg *Page) createUnlockPINModal(walletID int, password string) {
+	modal.NewCreatePasswordModal(pg.Load).
+		Title("Create unlock PIN").
+		SetDescription("The spending password is stored encrypted with this PIN. " +
+			"Enter the PIN when godcr starts to resume automatic ticket purchase.").
+		EnableName(false).
+		PasswordHint("Unlock PIN").
+		ConfirmPasswordHint("Confirm unlock PIN").
+		PasswordCreated(func(_, pin string, m *modal.CreatePasswordModal) bool {
+			go func() {
+				err := pg.WL.Wallet.StoreCredential(walletID, wallet.AutoBuyerCredentialConfigKey, []byte(pin), []byte(password))
+				if err != nil {
+					m.SetError(err.Error())
+					m.SetLoading(false)
+					return
+				}
+				pg.Toast.Notify("Automatic ticket purchase will resume on restart")
+				m.Dismiss()
+			}()
+			return false
+		}).Show()
+}
+
+// showAutoPurchaseHistory lists the tickets bought by the ticket buyer of
+// the selected wallet.
+func (pg *Page) showAutoPurchaseHistory() {
+	purchases := pg.autoPurchases
+	list := &widget.List{List: layout.List{Axis: layout.Vertical}}
+
+	modal.NewInfoModal(pg.Load).
+		Title("Automatic purchases").
+		UseCustomWidget(func(gtx C) D {
+			gtx.Constraints.Max.Y = gtx.Px(values.MarginPadding280)
+			return pg.Theme.List(list).Layout(gtx, len(purchases), func(gtx C, i int) D {
+				// newest first
+				p := purchases[len(purchases)-1-i]
+				return layout.Inset{Bottom: values.MarginPadding8}.Layout(gtx, func(gtx C) D {
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(func(gtx C) D {
+							return layout.Flex{Spacing: layout.SpaceBetween}.Layout(gtx,
+								layout.Rigid(pg.Theme.Label(values.TextSize14, dcrutil.Amount(p.Amount).String()).Layout),
+								layout.Rigid(func(gtx C) D {
+									date := time.Unix(p.Timestamp, 0).Format("Jan 2, 2006 15:04:05 PM")
+									txt := pg.Theme.Label(values.TextSize14, date)
+									txt.Color = pg.Theme.Color.GrayText2
+									return txt.Layout(gtx)
+								}),
+							)
+						}),
+						layout.Rigid(func(gtx C) D {
+							txt := pg.Theme.Label(values.TextSize12, p.Hash)
+							txt.Color = pg.Theme.Color.GrayText3
+							return txt.Layout(gtx)
+						}),
+					)
+				})
+			})
+		}).
+		PositiveButton("Close", func() {}).
+		Show()
+}
+
+// ResumeTicketBuyers prompts for the unlock PIN of every wallet whose ticket
+// buyer was set to resume on restart, and restarts the ticket buyer with the
+// decrypted spending password. Wallets are handled one after the other.
+func ResumeTicketBuyers(l *load.Load) {
+	resumeTicketBuyers(l, l.WL.Wallet.ResumableTicketBuyers())
+}
+
+func resumeTicketBuyers(l *load.Load, wallets []*dcrlibwallet.Wallet) {
+	if len(wallets) == 0 {
+		return
+	}
+
+	wal, remaining := wallets[0], wallets[1:]
+	modal.NewPasswordModal(l).
+		Title("Resume automatic ticket purchase").
+		Description(fmt.Sprintf("Enter the unlock PIN to resume automatic ticket purchase for %s.", wal.Name)).
+		Hint("Unlock PIN").
+		NegativeButton(values.String(values.StrCancel), func() {
+			resumeTicketBuyers(l, remaining)
+		}).
+		PositiveButton("Resume", func(pin string, pm *modal.PasswordModal) bool {
+			go func() {
+				password, err := l.WL.Wallet.ReadCredential(wal.ID, wallet.AutoBuyerCredentialConfigKey, []byte(pin))
+				if err != nil {
+					pm.SetError(err.Error())
+					pm.SetLoading(false)
+					return
+				}
+
+				err = l.WL.Wallet.CheckConnection(wallet.ProxySubsystemVSP)
+				if err == nil {
+					err = wal.StartTicketBuyer(password)
+				}
+				if err != nil {
+					pm.SetError(err.Error())
+					pm.SetLoading(false)
+					return
+				}
+
+				log.Infof("[%d] Automatic ticket purchase resumed", wal.ID)
+				l.Toast.Notify(fmt.Sprintf("Automatic ticket purchase resumed for %s", wal.Name))
+				pm.Dismiss()
+				resumeTicketBuyers(l, remaining)
+			}()
+			return false
+		}).Show()
+}
@@ -282,6 +282,8 @@ func (pg *ListPage) HandleUserInteractions() {
 		}
 	}
 
+	handleTicketExplorers(pg.Load, pg.tickets)
+
 	decredmaterial.DisplayOneDropdown(pg.ticketTypeDropDown, pg.orderDropDown, pg.walletDropDown)
 }
 
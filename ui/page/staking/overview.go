@@ -17,6 +17,7 @@ import (
 	"github.com/planetdecred/godcr/ui/page/overview"
 	tpage "github.com/planetdecred/godcr/ui/page/transaction"
 	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
 )
 
 type (
@@ -38,12 +39,15 @@ type Page struct {
 
 	autoPurchaseSettings *decredmaterial.Clickable
 	autoPurchase         *decredmaterial.Switch
+	autoPurchaseHistory  *decredmaterial.Clickable
+	resumeAutoPurchase   decredmaterial.CheckBoxStyle
 
 	stakeBtn  decredmaterial.Button
 	toTickets decredmaterial.TextAndIconButton
 
 	ticketOverview *dcrlibwallet.StakingOverview
 	liveTickets    []*transactionItem
+	autoPurchases  []wallet.AutoTicketPurchase
 
 	ticketPrice  string
 	totalRewards string
@@ -131,7 +135,7 @@ func (pg *Page) loadPageData() {
 		if len(pg.WL.MultiWallet.KnownVSPs()) == 0 {
 			// TODO: Does this page need this list?
 			if pg.ctx != nil {
-				pg.WL.MultiWallet.ReloadVSPList(pg.ctx)
+				pg.WL.Wallet.ReloadVSPList(pg.ctx)
 			}
 		}
 
@@ -149,6 +153,10 @@ func (pg *Page) loadPageData() {
 			pg.ticketOverview = overview
 		}
 
+		if pg.ticketBuyerWallet != nil {
+			pg.autoPurchases = pg.WL.Wallet.AutoTicketPurchases(pg.ticketBuyerWallet.ID)
+		}
+
 		pg.RefreshWindow()
 	}()
 
@@ -256,6 +264,8 @@ func (pg *Page) HandleUserInteractions() {
 		pg.ChangeFragment(tpage.NewTransactionDetailsPage(pg.Load, pg.liveTickets[selectedItem].transaction))
 	}
 
+	handleTicketExplorers(pg.Load, pg.liveTickets)
+
 	if pg.autoPurchase.Changed() {
 		if pg.autoPurchase.IsChecked() {
 			if pg.ticketBuyerWallet.TicketBuyerConfigIsSet() {
@@ -273,9 +283,15 @@ func (pg *Page) HandleUserInteractions() {
 			}
 		} else {
 			pg.WL.MultiWallet.StopAutoTicketsPurchase(pg.ticketBuyerWallet.ID)
+			// A manually stopped ticket buyer should not be resumed on the next start.
+			pg.WL.Wallet.DeleteCredential(pg.ticketBuyerWallet.ID, wallet.AutoBuyerCredentialConfigKey)
 		}
 	}
 
+	if pg.autoPurchaseHistory.Clicked() {
+		pg.showAutoPurchaseHistory()
+	}
+
 	if pg.autoPurchaseSettings.Clicked() {
 		if pg.ticketBuyerWallet.IsAutoTicketsPurchaseActive() {
 			pg.Toast.NotifyError("Settings can not be modified when ticket buyer is running.")
@@ -307,6 +323,8 @@ func (pg *Page) startTicketBuyerPasswordModal() {
 		return
 	}
 
+	pg.resumeAutoPurchase.CheckBox.Value = pg.WL.Wallet.HasCredential(pg.ticketBuyerWallet.ID, wallet.AutoBuyerCredentialConfigKey)
+
 	modal.NewPasswordModal(pg.Load).
 		Title("Confirm Automatic Ticket Purchase").
 		SetCancelable(false).
@@ -318,6 +336,9 @@ func (pg *Page) startTicketBuyerPasswordModal() {
 					label := pg.Theme.Label(values.TextSize14, fmt.Sprintf("VSP: %s", tbConfig.VspHost))
 					return layout.Inset{Bottom: values.MarginPadding12}.Layout(gtx, label.Layout)
 				}),
+				layout.Rigid(func(gtx C) D {
+					return layout.Inset{Bottom: values.MarginPadding12}.Layout(gtx, pg.resumeAutoPurchase.Layout)
+				}),
 				layout.Rigid(func(gtx C) D {
 					return decredmaterial.LinearLayout{
 						Width:      decredmaterial.MatchParent,
@@ -357,7 +378,10 @@ func (pg *Page) startTicketBuyerPasswordModal() {
 			}
 
 			go func() {
-				err := pg.ticketBuyerWallet.StartTicketBuyer([]byte(password))
+				err := pg.WL.Wallet.CheckConnection(wallet.ProxySubsystemVSP)
+				if err == nil {
+					err = pg.ticketBuyerWallet.StartTicketBuyer([]byte(password))
+				}
 				if err != nil {
 					pg.Toast.NotifyError(err.Error())
 					pm.SetLoading(false)
@@ -365,6 +389,11 @@ func (pg *Page) startTicketBuyerPasswordModal() {
 				}
 
 				pg.autoPurchase.SetChecked(pg.ticketBuyerWallet.IsAutoTicketsPurchaseActive())
+				if pg.resumeAutoPurchase.CheckBox.Value {
+					pg.createUnlockPINModal(pg.ticketBuyerWallet.ID, password)
+				} else {
+					pg.WL.Wallet.DeleteCredential(pg.ticketBuyerWallet.ID, wallet.AutoBuyerCredentialConfigKey)
+				}
 				pg.RefreshWindow()
 			}()
 			pm.Dismiss()
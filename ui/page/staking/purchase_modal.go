@@ -16,6 +16,7 @@ import (
 	"github.com/planetdecred/godcr/ui/load"
 	"github.com/planetdecred/godcr/ui/page/components"
 	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
 )
 
 const stakingModalID = "staking_modal"
@@ -100,7 +101,7 @@ func (tp *stakingModal) OnResume() {
 	lastUsedVSP := tp.WL.MultiWallet.LastUsedVSP()
 	if len(tp.WL.MultiWallet.KnownVSPs()) == 0 {
 		// TODO: Does this modal need this list?
-		go tp.WL.MultiWallet.ReloadVSPList(context.TODO())
+		go tp.WL.Wallet.ReloadVSPList(context.TODO())
 	} else if components.StringNotEmpty(lastUsedVSP) {
 		tp.vspSelector.SelectVSP(lastUsedVSP)
 	}
@@ -425,7 +426,10 @@ func (tp *stakingModal) purchaseTickets() {
 		}()
 
 		vspHost, vspPubKey := selectedVSP.Host, selectedVSP.PubKey
-		_, err := wal.PurchaseTickets(account.Number, int32(tp.ticketCount()), vspHost, vspPubKey, password)
+		err := tp.WL.Wallet.CheckConnection(wallet.ProxySubsystemVSP)
+		if err == nil {
+			_, err = wal.PurchaseTickets(account.Number, int32(tp.ticketCount()), vspHost, vspPubKey, password)
+		}
 		if err != nil {
 			if err.Error() == dcrlibwallet.ErrInvalidPassphrase {
 				tp.spendingPassword.SetError("Invalid password")
@@ -1,7 +1,10 @@
 package staking
 
 import (
+	"fmt"
+
 	"gioui.org/layout"
+	"gioui.org/widget"
 
 	"github.com/planetdecred/godcr/ui/load"
 	"github.com/planetdecred/godcr/ui/page/components"
@@ -12,6 +15,8 @@ func (pg *Page) initStakePriceWidget() *Page {
 	pg.stakeBtn = pg.Theme.Button("Stake")
 	pg.autoPurchaseSettings = pg.Theme.NewClickable(false)
 	pg.autoPurchase = pg.Theme.Switch()
+	pg.autoPurchaseHistory = pg.Theme.NewClickable(false)
+	pg.resumeAutoPurchase = pg.Theme.CheckBox(new(widget.Bool), "Resume automatically when godcr restarts")
 	return pg
 }
 
@@ -96,6 +101,20 @@ func (pg *Page) stakePriceSection(gtx C) D {
 					return pg.stakeBtn.Layout(gtx)
 				})
 			}),
+			layout.Rigid(func(gtx C) D {
+				count := len(pg.autoPurchases)
+				if count == 0 {
+					return D{}
+				}
+
+				return layout.Inset{Top: values.MarginPadding12}.Layout(gtx, func(gtx C) D {
+					return layout.Center.Layout(gtx, func(gtx C) D {
+						txt := pg.Theme.Label(values.TextSize14, fmt.Sprintf("Automatic purchases (%d)", count))
+						txt.Color = pg.Theme.Color.Primary
+						return pg.autoPurchaseHistory.Layout(gtx, txt.Layout)
+					})
+				})
+			}),
 		)
 	})
 }
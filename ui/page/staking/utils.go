@@ -17,6 +17,7 @@ import (
 	"github.com/planetdecred/godcr/ui/load"
 	"github.com/planetdecred/godcr/ui/page/components"
 	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
 )
 
 type transactionItem struct {
@@ -35,6 +36,9 @@ type transactionItem struct {
 	dateTooltip       *decredmaterial.Tooltip
 	daysBehindTooltip *decredmaterial.Tooltip
 	durationTooltip   *decredmaterial.Tooltip
+
+	explorer    *decredmaterial.Clickable
+	hasExplorer bool
 }
 
 type Stake struct {
@@ -59,6 +63,7 @@ const (
 func stakeToTransactionItems(l *load.Load, txs []dcrlibwallet.Transaction, newestFirst bool, hasFilter func(int32) bool) ([]*transactionItem, error) {
 	tickets := make([]*transactionItem, 0)
 	multiWallet := l.WL.MultiWallet
+	hasExplorer := len(l.WL.Wallet.BlockExplorersFor(wallet.ExplorerTicket)) > 0
 	for _, tx := range txs {
 		w := multiWallet.WalletWithID(tx.WalletID)
 
@@ -129,6 +134,8 @@ func stakeToTransactionItems(l *load.Load, txs []dcrlibwallet.Transaction, newes
 			showTime:      showTime,
 			purchaseTime:  time.Unix(tx.Timestamp, 0).Format("Jan 2, 2006 15:04:05 PM"),
 			ticketAge:     ticketAge,
+			explorer:      l.Theme.NewClickable(true),
+			hasExplorer:   hasExplorer,
 
 			statusTooltip:     l.Theme.Tooltip(),
 			walletNameTooltip: l.Theme.Tooltip(),
@@ -520,7 +527,7 @@ func ticketListLayout(gtx C, l *load.Load, ticket *transactionItem, i int, showW
 				)
 			})
 		}),
-		layout.Rigid(func(gtx C) D {
+		layout.Flexed(1, func(gtx C) D {
 			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 				layout.Rigid(func(gtx C) D {
 					if i == 0 {
@@ -667,9 +674,27 @@ func ticketListLayout(gtx C, l *load.Load, ticket *transactionItem, i int, showW
 				}),
 			)
 		}),
+		layout.Rigid(func(gtx C) D {
+			if !ticket.hasExplorer {
+				return D{}
+			}
+			return layout.Inset{Left: values.MarginPadding8}.Layout(gtx, func(gtx C) D {
+				return ticket.explorer.Layout(gtx, l.Icons.RedirectIcon.Layout24dp)
+			})
+		}),
 	)
 }
 
+// handleTicketExplorers opens the tickets whose explorer icon was clicked
+// on the block explorer.
+func handleTicketExplorers(l *load.Load, tickets []*transactionItem) {
+	for _, ticket := range tickets {
+		for ticket.explorer.Clicked() {
+			components.OpenInExplorer(l, wallet.ExplorerTicket, ticket.transaction.Hash)
+		}
+	}
+}
+
 // todo: cleanup
 func createOrderDropDown(th *decredmaterial.Theme) *decredmaterial.DropDown {
 	return th.DropDown([]decredmaterial.DropDownItem{{Text: values.String(values.StrNewest)},
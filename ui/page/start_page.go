@@ -100,6 +100,10 @@ func (sp *startPage) openWallets(password string) error {
 		return err
 	}
 
+	if password != "" {
+		sp.WL.Wallet.UnlockMixerCredentials([]byte(password))
+	}
+
 	sp.ChangeWindowPage(NewMainPage(sp.Load), false)
 	return nil
 }
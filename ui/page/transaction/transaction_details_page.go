@@ -16,6 +16,7 @@ import (
 	"github.com/planetdecred/godcr/ui/modal"
 	"github.com/planetdecred/godcr/ui/page/components"
 	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
 )
 
 const TransactionDetailsPageID = "TransactionDetails"
@@ -29,6 +30,12 @@ type transactionWdg struct {
 	copyTextButtons []decredmaterial.Button
 }
 
+// explorerRow is a block explorer the transaction can be opened on.
+type explorerRow struct {
+	explorer  wallet.BlockExplorer
+	clickable *decredmaterial.Clickable
+}
+
 type TxDetailsPage struct {
 	*load.Load
 
@@ -41,7 +48,8 @@ type TxDetailsPage struct {
 	hashClickable                   *widget.Clickable
 	destAddressClickable            *widget.Clickable
 	dot                             *decredmaterial.Icon
-	toDcrdata                       *decredmaterial.Clickable
+	blockClickable                  *widget.Clickable
+	explorers                       []*explorerRow
 	outputsCollapsible              *decredmaterial.Collapsible
 	inputsCollapsible               *decredmaterial.Collapsible
 	backButton                      decredmaterial.IconButton
@@ -86,7 +94,7 @@ func NewTransactionDetailsPage(l *load.Load, transaction *dcrlibwallet.Transacti
 		associatedTicketClickable: l.Theme.NewClickable(true),
 		hashClickable:             new(widget.Clickable),
 		destAddressClickable:      new(widget.Clickable),
-		toDcrdata:                 l.Theme.NewClickable(true),
+		blockClickable:            new(widget.Clickable),
 
 		transaction:          transaction,
 		wallet:               l.WL.MultiWallet.WalletWithID(transaction.WalletID),
@@ -149,6 +157,33 @@ func (pg *TxDetailsPage) OnNavigatedTo() {
 
 	pg.getTXSourceAccountAndDirection()
 	pg.txnWidgets = initTxnWidgets(pg.Load, pg.transaction)
+
+	pg.explorers = nil
+	for _, e := range pg.WL.Wallet.BlockExplorers() {
+		pg.explorers = append(pg.explorers, &explorerRow{
+			explorer:  e,
+			clickable: pg.Theme.NewClickable(true),
+		})
+	}
+}
+
+// explorerKind returns the kind of explorer page of the transaction, the
+// tickets open on the ticket page of the explorers that have one.
+func (pg *TxDetailsPage) explorerKind(e wallet.BlockExplorer) string {
+	if pg.transaction.Type == dcrlibwallet.TxTypeTicketPurchase && e.TicketURL != "" {
+		return wallet.ExplorerTicket
+	}
+	return wallet.ExplorerTransaction
+}
+
+// hasBlockExplorer returns true if one of the explorers has block pages.
+func (pg *TxDetailsPage) hasBlockExplorer() bool {
+	for _, row := range pg.explorers {
+		if row.explorer.BlockURL != "" {
+			return true
+		}
+	}
+	return false
 }
 
 // Layout draws the page UI components into the provided layout context
@@ -531,7 +566,11 @@ func (pg *TxDetailsPage) txnTypeAndID(gtx layout.Context) layout.Dimensions {
 		layout.Rigid(func(gtx C) D {
 			if transaction.BlockHeight != -1 {
 				return layout.Inset{Top: m}.Layout(gtx, func(gtx C) D {
-					return pg.txnInfoSection(gtx, values.String(values.StrIncludedInBlock), fmt.Sprintf("%d", transaction.BlockHeight), false, nil)
+					var clickable *widget.Clickable
+					if pg.hasBlockExplorer() {
+						clickable = pg.blockClickable
+					}
+					return pg.txnInfoSection(gtx, values.String(values.StrIncludedInBlock), fmt.Sprintf("%d", transaction.BlockHeight), false, clickable)
 				})
 			}
 			return layout.Dimensions{}
@@ -704,17 +743,28 @@ func (pg *TxDetailsPage) txnIORow(gtx layout.Context, amount int64, acctNum int3
 	})
 }
 
+// viewTxn lists the block explorers the transaction can be opened on.
 func (pg *TxDetailsPage) viewTxn(gtx layout.Context) layout.Dimensions {
 	gtx.Constraints.Min.X = gtx.Constraints.Max.X
-	return pg.pageSections(gtx, func(gtx C) D {
-		return layout.Flex{Spacing: layout.SpaceBetween}.Layout(gtx,
-			layout.Rigid(pg.Theme.Body1(values.String(values.StrViewOnDcrdata)).Layout),
-			layout.Rigid(func(gtx C) D {
-				redirect := pg.Icons.RedirectIcon
-				return pg.toDcrdata.Layout(gtx, redirect.Layout24dp)
-			}),
-		)
-	})
+	var rows []layout.FlexChild
+	for _, row := range pg.explorers {
+		row := row
+		if row.explorer.Template(pg.explorerKind(row.explorer)) == "" {
+			continue
+		}
+		rows = append(rows, layout.Rigid(func(gtx C) D {
+			return pg.pageSections(gtx, func(gtx C) D {
+				return layout.Flex{Spacing: layout.SpaceBetween}.Layout(gtx,
+					layout.Rigid(pg.Theme.Body1("View on "+row.explorer.Name).Layout),
+					layout.Rigid(func(gtx C) D {
+						redirect := pg.Icons.RedirectIcon
+						return row.clickable.Layout(gtx, redirect.Layout24dp)
+					}),
+				)
+			})
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
 }
 
 func (pg *TxDetailsPage) pageSections(gtx layout.Context, body layout.Widget) layout.Dimensions {
@@ -727,8 +777,19 @@ func (pg *TxDetailsPage) pageSections(gtx layout.Context, body layout.Widget) la
 // displayed.
 // Part of the load.Page interface.
 func (pg *TxDetailsPage) HandleUserInteractions() {
-	if pg.toDcrdata.Clicked() {
-		components.GoToURL(pg.WL.Wallet.GetBlockExplorerURL(pg.transaction.Hash))
+	for _, row := range pg.explorers {
+		for row.clickable.Clicked() {
+			if err := pg.WL.Wallet.CheckConnection(wallet.ProxySubsystemExplorer); err != nil {
+				pg.Toast.NotifyError(err.Error())
+				continue
+			}
+			kind := pg.explorerKind(row.explorer)
+			components.GoToURL(row.explorer.URL(kind, pg.transaction.Hash))
+		}
+	}
+
+	for pg.blockClickable.Clicked() {
+		components.OpenInExplorer(pg.Load, wallet.ExplorerBlock, fmt.Sprint(pg.transaction.BlockHeight))
 	}
 
 	for pg.associatedTicketClickable.Clicked() {
@@ -0,0 +1,146 @@
+package wallets
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/text"
+
+	"github.com/planetdecred/dcrlibwallet"
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/page/seedbackup"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+// backupHealthRow is a wallet whose seed backup is missing or stale.
+type backupHealthRow struct {
+	wal    *dcrlibwallet.Wallet
+	health wallet.BackupHealth
+	action decredmaterial.Button
+}
+
+// backupHealthPanel lists the wallets whose seed backup needs attention.
+type backupHealthPanel struct {
+	*load.Load
+
+	rowsMu sync.Mutex
+	rows   []*backupHealthRow
+}
+
+func newBackupHealthPanel(l *load.Load) *backupHealthPanel {
+	return &backupHealthPanel{Load: l}
+}
+
+// refresh checks the seed backups of the wallets again.
+func (bp *backupHealthPanel) refresh() {
+	var rows []*backupHealthRow
+	for _, wal := range bp.WL.SortedWalletList() {
+		health := bp.WL.Wallet.SeedBackupHealth(wal)
+		if health == wallet.BackupHealthy {
+			continue
+		}
+
+		row := &backupHealthRow{wal: wal, health: health}
+		if health == wallet.BackupMissing {
+			row.action = bp.Theme.OutlineButton("Back up")
+		} else {
+			row.action = bp.Theme.OutlineButton("Verify")
+		}
+		row.action.Inset = layout.UniformInset(values.MarginPadding8)
+		rows = append(rows, row)
+	}
+
+	bp.rowsMu.Lock()
+	bp.rows = rows
+	bp.rowsMu.Unlock()
+}
+
+func (bp *backupHealthPanel) healthRows() []*backupHealthRow {
+	bp.rowsMu.Lock()
+	defer bp.rowsMu.Unlock()
+	return bp.rows
+}
+
+func (bp *backupHealthPanel) handle() {
+	for _, row := range bp.healthRows() {
+		for row.action.Clicked() {
+			if row.health == wallet.BackupMissing {
+				bp.ChangeFragment(seedbackup.NewBackupInstructionsPage(bp.Load, row.wal))
+				continue
+			}
+			seedbackup.NewReverifySeedModal(bp.Load, row.wal).
+				Verified(func() {
+					bp.refresh()
+					bp.RefreshWindow()
+				}).
+				Show()
+		}
+	}
+}
+
+func (bp *backupHealthPanel) status(row *backupHealthRow) string {
+	if row.health == wallet.BackupMissing {
+		return values.String(values.StrNotBackedUp)
+	}
+
+	lastVerified := bp.WL.Wallet.SeedLastVerified(row.wal)
+	if lastVerified.IsZero() {
+		return "Backup never verified"
+	}
+	days := int(time.Since(lastVerified).Hours() / 24)
+	return fmt.Sprintf("Backup last verified %d days ago", days)
+}
+
+func (bp *backupHealthPanel) layout(gtx C) D {
+	rows := bp.healthRows()
+	if len(rows) == 0 {
+		return D{}
+	}
+
+	return layout.Inset{Bottom: values.MarginPadding10}.Layout(gtx, func(gtx C) D {
+		card := bp.Theme.Card()
+		card.Radius = decredmaterial.Radius(10)
+		return card.Layout(gtx, func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			return layout.UniformInset(values.MarginPadding15).Layout(gtx, func(gtx C) D {
+				children := []layout.FlexChild{
+					layout.Rigid(func(gtx C) D {
+						lbl := bp.Theme.Body1("Backup health")
+						lbl.Font.Weight = text.SemiBold
+						return lbl.Layout(gtx)
+					}),
+				}
+				for _, row := range rows {
+					row := row
+					children = append(children, layout.Rigid(func(gtx C) D {
+						return layout.Inset{Top: values.MarginPadding10}.Layout(gtx, func(gtx C) D {
+							return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+								layout.Rigid(func(gtx C) D {
+									return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+										layout.Rigid(bp.Theme.Body2(row.wal.Name).Layout),
+										layout.Rigid(func(gtx C) D {
+											lbl := bp.Theme.Caption(bp.status(row))
+											lbl.Color = bp.Theme.Color.Danger
+											if row.health == wallet.BackupStale {
+												lbl.Color = bp.Theme.Color.GrayText2
+											}
+											return lbl.Layout(gtx)
+										}),
+									)
+								}),
+								layout.Flexed(1, func(gtx C) D {
+									return layout.E.Layout(gtx, row.action.Layout)
+								}),
+							)
+						})
+					}))
+				}
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+			})
+		})
+	})
+}
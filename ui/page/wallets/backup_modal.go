@@ -0,0 +1,230 @@
+package wallets
+
+import (
+	"fmt"
+
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/planetdecred/dcrlibwallet"
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/page/components"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+const backupModalID = "backup_modal"
+
+// backupWallet is a wallet of the backup with the editor for the spending
+// password, or for the seed if the seed is not stored anymore.
+type backupWallet struct {
+	wal    *dcrlibwallet.Wallet
+	editor decredmaterial.Editor
+}
+
+// backupModal writes an encrypted backup file of one or all the wallets.
+type backupModal struct {
+	*load.Load
+
+	wallets []*backupWallet
+	// allWallets is true if the backup is of all the wallets, the app
+	// settings are saved with them.
+	allWallets bool
+
+	modal           *decredmaterial.Modal
+	container       *widget.List
+	password        decredmaterial.Editor
+	confirmPassword decredmaterial.Editor
+	export          decredmaterial.Button
+	cancel          decredmaterial.Button
+	materialLoader  material.LoaderStyle
+	isSending       bool
+	backupError     string
+}
+
+func newBackupModal(l *load.Load, wallets []*dcrlibwallet.Wallet, allWallets bool) *backupModal {
+	md := &backupModal{
+		Load:            l,
+		allWallets:      allWallets,
+		modal:           l.Theme.ModalFloatTitle(),
+		container:       &widget.List{List: layout.List{Axis: layout.Vertical}},
+		password:        l.Theme.EditorPassword(new(widget.Editor), "Backup password"),
+		confirmPassword: l.Theme.EditorPassword(new(widget.Editor), "Confirm backup password"),
+		export:          l.Theme.Button("Export"),
+		cancel:          l.Theme.OutlineButton(values.String(values.StrCancel)),
+		materialLoader:  material.Loader(l.Theme.Base),
+	}
+	md.password.Editor.SingleLine = true
+	md.confirmPassword.Editor.SingleLine = true
+
+	for _, wal := range wallets {
+		bw := &backupWallet{wal: wal}
+		switch {
+		case wal.IsWatchingOnlyWallet():
+		case wal.EncryptedSeed != nil:
+			bw.editor = l.Theme.EditorPassword(new(widget.Editor), fmt.Sprintf("Spending password of %s", wal.Name))
+			bw.editor.Editor.SingleLine = true
+		default:
+			// The seed was verified and deleted, it has to be entered.
+			bw.editor = l.Theme.Editor(new(widget.Editor), fmt.Sprintf("Seed words of %s", wal.Name))
+		}
+		md.wallets = append(md.wallets, bw)
+	}
+
+	return md
+}
+
+func (md *backupModal) ModalID() string {
+	return backupModalID
+}
+
+func (md *backupModal) Show() {
+	md.ShowModal(md)
+}
+
+func (md *backupModal) Dismiss() {
+	md.DismissModal(md)
+}
+
+func (md *backupModal) OnDismiss() {}
+
+func (md *backupModal) OnResume() {}
+
+func (md *backupModal) editors() []*widget.Editor {
+	editors := []*widget.Editor{md.password.Editor, md.confirmPassword.Editor}
+	for _, bw := range md.wallets {
+		if bw.editor.Editor != nil {
+			editors = append(editors, bw.editor.Editor)
+		}
+	}
+	return editors
+}
+
+func (md *backupModal) Handle() {
+	if md.cancel.Clicked() && !md.isSending {
+		md.Dismiss()
+	}
+
+	_, changed := decredmaterial.HandleEditorEvents(md.editors()...)
+	if changed {
+		md.backupError = ""
+	}
+
+	if md.export.Clicked() && !md.isSending {
+		password := md.password.Editor.Text()
+		if password == "" {
+			md.backupError = "enter a backup password"
+			return
+		}
+		if password != md.confirmPassword.Editor.Text() {
+			md.backupError = "passwords do not match"
+			return
+		}
+
+		md.isSending = true
+		md.modal.SetDisabled(true)
+		go func() {
+			defer func() {
+				md.isSending = false
+				md.modal.SetDisabled(false)
+			}()
+
+			path, err := md.writeBackup([]byte(password))
+			if err != nil {
+				md.backupError = err.Error()
+				md.RefreshWindow()
+				return
+			}
+
+			md.Toast.Notify("Backup saved to " + path)
+			md.Dismiss()
+		}()
+	}
+}
+
+func (md *backupModal) writeBackup(password []byte) (string, error) {
+	backups := make([]*wallet.WalletBackup, 0, len(md.wallets))
+	for _, bw := range md.wallets {
+		var seed string
+		switch {
+		case bw.wal.IsWatchingOnlyWallet():
+		case bw.wal.EncryptedSeed != nil:
+			var err error
+			seed, err = bw.wal.DecryptSeed([]byte(bw.editor.Editor.Text()))
+			if err != nil {
+				return "", fmt.Errorf("%s: %s", bw.wal.Name, components.TranslateErr(err))
+			}
+		default:
+			seed = bw.editor.Editor.Text()
+		}
+
+		backup, err := md.WL.Wallet.WalletBackup(bw.wal.ID, seed)
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", bw.wal.Name, err)
+		}
+		backups = append(backups, backup)
+	}
+
+	backup := md.WL.Wallet.NewBackup(backups, md.allWallets, load.BackupConfigKeys...)
+	return md.WL.Wallet.WriteBackup(backup, password)
+}
+
+func (md *backupModal) Layout(gtx layout.Context) D {
+	title := "Back up wallet"
+	if md.allWallets {
+		title = "Back up all wallets"
+	}
+
+	w := []layout.Widget{
+		func(gtx C) D {
+			t := md.Theme.H6(title)
+			t.Font.Weight = text.SemiBold
+			return t.Layout(gtx)
+		},
+		func(gtx C) D {
+			lbl := md.Theme.Body2("The backup file holds the seeds, account names and settings, encrypted with the backup password. Anyone with the file and the password can spend your funds.")
+			lbl.Color = md.Theme.Color.GrayText2
+			return lbl.Layout(gtx)
+		},
+		func(gtx C) D {
+			gtx.Constraints.Max.Y = gtx.Px(values.MarginPadding200)
+			return md.Theme.List(md.container).Layout(gtx, len(md.wallets), func(gtx C, i int) D {
+				bw := md.wallets[i]
+				return layout.Inset{Bottom: values.MarginPadding8}.Layout(gtx, func(gtx C) D {
+					if bw.editor.Editor == nil {
+						lbl := md.Theme.Body2(fmt.Sprintf("%s is watch-only, its extended public key is saved", bw.wal.Name))
+						lbl.Color = md.Theme.Color.GrayText2
+						return lbl.Layout(gtx)
+					}
+					return bw.editor.Layout(gtx)
+				})
+			})
+		},
+		md.password.Layout,
+		md.confirmPassword.Layout,
+		func(gtx C) D {
+			if md.backupError == "" {
+				return D{}
+			}
+			return md.Theme.ErrorLabel(md.backupError).Layout(gtx)
+		},
+		func(gtx C) D {
+			return layout.E.Layout(gtx, func(gtx C) D {
+				if md.isSending {
+					return md.materialLoader.Layout(gtx)
+				}
+				return layout.Flex{}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, md.cancel.Layout)
+					}),
+					layout.Rigid(md.export.Layout),
+				)
+			})
+		},
+	}
+
+	return md.modal.Layout(gtx, w)
+}
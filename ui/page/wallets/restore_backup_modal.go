@@ -0,0 +1,240 @@
+package wallets
+
+import (
+	"fmt"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+const restoreBackupModalID = "restore_backup_modal"
+
+// restoreBackupModal restores the wallets of a backup file. The backup is
+// opened first, then the wallets are restored with a new spending password.
+type restoreBackupModal struct {
+	*load.Load
+
+	restored func()
+
+	// backup is the opened backup, nil until the backup password is entered.
+	backup *wallet.Backup
+
+	modal                   *decredmaterial.Modal
+	pathEditor              decredmaterial.Editor
+	backupPassword          decredmaterial.Editor
+	spendingPassword        decredmaterial.Editor
+	confirmSpendingPassword decredmaterial.Editor
+	submit                  decredmaterial.Button
+	cancel                  decredmaterial.Button
+	materialLoader          material.LoaderStyle
+	isSending               bool
+	restoreError            string
+}
+
+func newRestoreBackupModal(l *load.Load, restored func()) *restoreBackupModal {
+	md := &restoreBackupModal{
+		Load:                    l,
+		restored:                restored,
+		modal:                   l.Theme.ModalFloatTitle(),
+		pathEditor:              l.Theme.Editor(new(widget.Editor), "Backup file path"),
+		backupPassword:          l.Theme.EditorPassword(new(widget.Editor), "Backup password"),
+		spendingPassword:        l.Theme.EditorPassword(new(widget.Editor), "Spending password"),
+		confirmSpendingPassword: l.Theme.EditorPassword(new(widget.Editor), "Confirm spending password"),
+		submit:                  l.Theme.Button("Open"),
+		cancel:                  l.Theme.OutlineButton(values.String(values.StrCancel)),
+		materialLoader:          material.Loader(l.Theme.Base),
+	}
+	md.pathEditor.Editor.SingleLine = true
+	md.backupPassword.Editor.SingleLine = true
+	md.spendingPassword.Editor.SingleLine = true
+	md.confirmSpendingPassword.Editor.SingleLine = true
+
+	return md
+}
+
+func (md *restoreBackupModal) ModalID() string {
+	return restoreBackupModalID
+}
+
+func (md *restoreBackupModal) Show() {
+	md.ShowModal(md)
+}
+
+func (md *restoreBackupModal) Dismiss() {
+	md.DismissModal(md)
+}
+
+func (md *restoreBackupModal) OnDismiss() {}
+
+func (md *restoreBackupModal) OnResume() {}
+
+// needsSpendingPassword checks if the opened backup has wallets that are not
+// watch-only, they are encrypted with a spending password.
+func (md *restoreBackupModal) needsSpendingPassword() bool {
+	for _, wb := range md.backup.Wallets {
+		if wb.ExtendedPubKey == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (md *restoreBackupModal) Handle() {
+	if md.cancel.Clicked() && !md.isSending {
+		md.Dismiss()
+	}
+
+	_, changed := decredmaterial.HandleEditorEvents(md.pathEditor.Editor, md.backupPassword.Editor,
+		md.spendingPassword.Editor, md.confirmSpendingPassword.Editor)
+	if changed {
+		md.restoreError = ""
+	}
+
+	if md.submit.Clicked() && !md.isSending {
+		if md.backup == nil {
+			md.openBackup()
+		} else {
+			md.restoreBackup()
+		}
+	}
+}
+
+func (md *restoreBackupModal) openBackup() {
+	path := strings.TrimSpace(md.pathEditor.Editor.Text())
+	if path == "" {
+		md.restoreError = "enter the path of the backup file"
+		return
+	}
+
+	md.isSending = true
+	md.modal.SetDisabled(true)
+	go func() {
+		defer func() {
+			md.isSending = false
+			md.modal.SetDisabled(false)
+			md.RefreshWindow()
+		}()
+
+		backup, err := wallet.ReadBackupFile(path, []byte(md.backupPassword.Editor.Text()))
+		if err != nil {
+			md.restoreError = err.Error()
+			return
+		}
+
+		md.backup = backup
+		md.submit.Text = "Restore"
+	}()
+}
+
+func (md *restoreBackupModal) restoreBackup() {
+	password := md.spendingPassword.Editor.Text()
+	if md.needsSpendingPassword() {
+		if password == "" {
+			md.restoreError = "enter a spending password"
+			return
+		}
+		if password != md.confirmSpendingPassword.Editor.Text() {
+			md.restoreError = "passwords do not match"
+			return
+		}
+	}
+
+	md.isSending = true
+	md.modal.SetDisabled(true)
+	go func() {
+		defer func() {
+			md.isSending = false
+			md.modal.SetDisabled(false)
+		}()
+
+		restored, err := md.WL.Wallet.RestoreBackup(md.backup, password, load.BackupConfigKeys...)
+		if err != nil {
+			if len(restored) > 0 {
+				md.Toast.NotifyError(fmt.Sprintf("%d of %d wallets restored", len(restored), len(md.backup.Wallets)))
+			}
+			md.restoreError = err.Error()
+			md.RefreshWindow()
+			return
+		}
+
+		md.Toast.Notify("Wallets restored")
+		md.Dismiss()
+		if md.restored != nil {
+			md.restored()
+		}
+	}()
+}
+
+func (md *restoreBackupModal) Layout(gtx layout.Context) D {
+	w := []layout.Widget{
+		func(gtx C) D {
+			t := md.Theme.H6("Restore from backup file")
+			t.Font.Weight = text.SemiBold
+			return t.Layout(gtx)
+		},
+	}
+
+	if md.backup == nil {
+		w = append(w, md.pathEditor.Layout, md.backupPassword.Layout)
+	} else {
+		w = append(w, func(gtx C) D {
+			lbl := md.Theme.Body2(fmt.Sprintf("Backup of %s", backupSummary(md.backup)))
+			lbl.Color = md.Theme.Color.GrayText2
+			return lbl.Layout(gtx)
+		})
+		if md.needsSpendingPassword() {
+			w = append(w, md.spendingPassword.Layout, md.confirmSpendingPassword.Layout)
+		}
+	}
+
+	w = append(w,
+		func(gtx C) D {
+			if md.restoreError == "" {
+				return D{}
+			}
+			return md.Theme.ErrorLabel(md.restoreError).Layout(gtx)
+		},
+		func(gtx C) D {
+			return layout.E.Layout(gtx, func(gtx C) D {
+				if md.isSending {
+					return md.materialLoader.Layout(gtx)
+				}
+				return layout.Flex{}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, md.cancel.Layout)
+					}),
+					layout.Rigid(md.submit.Layout),
+				)
+			})
+		},
+	)
+
+	return md.modal.Layout(gtx, w)
+}
+
+// backupSummary describes the wallets of a backup, e.g. "default, savings
+// (watch-only) and the app settings".
+func backupSummary(backup *wallet.Backup) string {
+	names := make([]string, 0, len(backup.Wallets))
+	for _, wb := range backup.Wallets {
+		if wb.ExtendedPubKey != "" {
+			names = append(names, wb.Name+" (watch-only)")
+		} else {
+			names = append(names, wb.Name)
+		}
+	}
+
+	summary := strings.Join(names, ", ")
+	if backup.Config != nil {
+		summary += " and the app settings"
+	}
+	return summary
+}
@@ -44,8 +44,13 @@ type Restore struct {
 	backButton      decredmaterial.IconButton
 	validateSeed    decredmaterial.Button
 	resetSeedFields decredmaterial.Button
+	restoreBackup   decredmaterial.Button
 	optionsMenuCard decredmaterial.Card
 
+	// other seed formats
+	restoreHexSeed    decredmaterial.Button
+	restoreSeedShares decredmaterial.Button
+
 	suggestions    []string
 	allSuggestions []string
 	focused        []int
@@ -86,6 +91,15 @@ func NewRestorePage(l *load.Load, onRestoreComplete func()) *Restore {
 	pg.resetSeedFields = l.Theme.OutlineButton("Clear all")
 	pg.resetSeedFields.Font.Weight = text.Medium
 
+	pg.restoreBackup = l.Theme.OutlineButton("Restore from backup file")
+	pg.restoreBackup.Font.Weight = text.Medium
+
+	pg.restoreHexSeed = l.Theme.OutlineButton("Hex seed")
+	pg.restoreHexSeed.Font.Weight = text.Medium
+
+	pg.restoreSeedShares = l.Theme.OutlineButton("Seed shares")
+	pg.restoreSeedShares.Font.Weight = text.Medium
+
 	pg.backButton, _ = components.SubpageHeaderButtons(l)
 	pg.backButton.Icon = pg.Icons.ContentClear
 
@@ -163,7 +177,20 @@ func (pg *Restore) restore(gtx layout.Context) layout.Dimensions {
 					}.Layout(gtx, pg.Theme.Body1("Enter your seed phrase").Layout)
 				}),
 				layout.Rigid(pg.seedEditorView),
-				layout.Rigid(pg.resetSeedFields.Layout),
+				layout.Rigid(func(gtx C) D {
+					return layout.Flex{}.Layout(gtx,
+						layout.Rigid(pg.resetSeedFields.Layout),
+						layout.Rigid(func(gtx C) D {
+							return layout.Inset{Left: values.MarginPadding10}.Layout(gtx, pg.restoreHexSeed.Layout)
+						}),
+						layout.Rigid(func(gtx C) D {
+							return layout.Inset{Left: values.MarginPadding10}.Layout(gtx, pg.restoreSeedShares.Layout)
+						}),
+						layout.Rigid(func(gtx C) D {
+							return layout.Inset{Left: values.MarginPadding10}.Layout(gtx, pg.restoreBackup.Layout)
+						}),
+					)
+				}),
 			)
 		}),
 		layout.Stacked(func(gtx C) D {
@@ -426,6 +453,42 @@ func (pg *Restore) resetSeeds() {
 	}
 }
 
+// restoreFromSeed asks for the wallet name and spending password then
+// restores the wallet with the seed.
+func (pg *Restore) restoreFromSeed(seed string) {
+	pg.Load.UnsubscribeKeyEvent(pg.ID())
+
+	modal.NewCreatePasswordModal(pg.Load).
+		Title("Enter wallet details").
+		EnableName(true).
+		ShowWalletInfoTip(true).
+		SetParent(pg).
+		PasswordCreated(func(walletName, password string, m *modal.CreatePasswordModal) bool {
+			go func() {
+				wal, err := pg.WL.MultiWallet.RestoreWallet(walletName, seed, password, dcrlibwallet.PassphraseTypePass)
+				if err != nil {
+					m.SetError(components.TranslateErr(err))
+					m.SetLoading(false)
+					return
+				}
+				// The seed was just entered, its backup is verified.
+				pg.WL.Wallet.MarkSeedVerified(wal.ID)
+
+				pg.Toast.Notify("Wallet restored")
+				pg.resetSeeds()
+				m.Dismiss()
+				// Close this page and return to the previous page (most likely wallets page)
+				// if there's no restoreComplete callback function.
+				if pg.restoreComplete == nil {
+					pg.PopWindowPage()
+				} else {
+					pg.restoreComplete()
+				}
+			}()
+			return false
+		}).Show()
+}
+
 func switchSeedEditors(editors []decredmaterial.RestoreEditor) {
 	for i := 0; i < len(editors); i++ {
 		if editors[i].Edit.Editor.Focused() {
@@ -453,35 +516,26 @@ func (pg *Restore) HandleUserInteractions() {
 			return
 		}
 
-		pg.Load.UnsubscribeKeyEvent(pg.ID())
-
-		modal.NewCreatePasswordModal(pg.Load).
-			Title("Enter wallet details").
-			EnableName(true).
-			ShowWalletInfoTip(true).
-			SetParent(pg).
-			PasswordCreated(func(walletName, password string, m *modal.CreatePasswordModal) bool {
-				go func() {
-					_, err := pg.WL.MultiWallet.RestoreWallet(walletName, pg.seedPhrase, password, dcrlibwallet.PassphraseTypePass)
-					if err != nil {
-						m.SetError(components.TranslateErr(err))
-						m.SetLoading(false)
-						return
-					}
-
-					pg.Toast.Notify("Wallet restored")
-					pg.resetSeeds()
-					m.Dismiss()
-					// Close this page and return to the previous page (most likely wallets page)
-					// if there's no restoreComplete callback function.
-					if pg.restoreComplete == nil {
-						pg.PopWindowPage()
-					} else {
-						pg.restoreComplete()
-					}
-				}()
-				return false
-			}).Show()
+		pg.restoreFromSeed(pg.seedPhrase)
+	}
+
+	for pg.restoreHexSeed.Clicked() {
+		newRestoreSeedModal(pg.Load, false, pg.restoreFromSeed).Show()
+	}
+
+	for pg.restoreSeedShares.Clicked() {
+		newRestoreSeedModal(pg.Load, true, pg.restoreFromSeed).Show()
+	}
+
+	for pg.restoreBackup.Clicked() {
+		newRestoreBackupModal(pg.Load, func() {
+			pg.resetSeeds()
+			if pg.restoreComplete == nil {
+				pg.PopWindowPage()
+			} else {
+				pg.restoreComplete()
+			}
+		}).Show()
 	}
 
 	for pg.resetSeedFields.Clicked() {
@@ -0,0 +1,258 @@
+package wallets
+
+import (
+	"fmt"
+
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/dcrlibwallet"
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/load"
+	"github.com/planetdecred/godcr/ui/values"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+const restoreSeedModalID = "restore_seed_modal"
+
+// shareEditor is the editor of a seed share with the status of the share.
+type shareEditor struct {
+	editor decredmaterial.Editor
+	share  *wallet.SeedShare
+	status string
+}
+
+// restoreSeedModal reads a seed entered in hex, or the shares of a split
+// seed, and passes the seed to the restore callback.
+type restoreSeedModal struct {
+	*load.Load
+
+	restore func(seed string)
+	// splitSeed is true if the seed is entered as shares.
+	splitSeed bool
+	seed      string
+
+	modal      *decredmaterial.Modal
+	container  *widget.List
+	seedEditor decredmaterial.Editor
+	shares     []*shareEditor
+	addShare   decredmaterial.Button
+	submit     decredmaterial.Button
+	cancel     decredmaterial.Button
+	seedError  string
+}
+
+func newRestoreSeedModal(l *load.Load, splitSeed bool, restore func(seed string)) *restoreSeedModal {
+	md := &restoreSeedModal{
+		Load:       l,
+		restore:    restore,
+		splitSeed:  splitSeed,
+		modal:      l.Theme.ModalFloatTitle(),
+		container:  &widget.List{List: layout.List{Axis: layout.Vertical}},
+		seedEditor: l.Theme.Editor(new(widget.Editor), "Hex seed"),
+		addShare:   l.Theme.OutlineButton("Add share"),
+		submit:     l.Theme.Button("Continue"),
+		cancel:     l.Theme.OutlineButton(values.String(values.StrCancel)),
+	}
+	md.seedEditor.Editor.SingleLine = true
+
+	if splitSeed {
+		// A split needs at least 2 shares.
+		md.newShareEditor()
+		md.newShareEditor()
+	}
+
+	return md
+}
+
+func (md *restoreSeedModal) newShareEditor() {
+	se := &shareEditor{
+		editor: md.Theme.Editor(new(widget.Editor), fmt.Sprintf("Share %d", len(md.shares)+1)),
+	}
+	md.shares = append(md.shares, se)
+}
+
+func (md *restoreSeedModal) ModalID() string {
+	return restoreSeedModalID
+}
+
+func (md *restoreSeedModal) Show() {
+	md.ShowModal(md)
+}
+
+func (md *restoreSeedModal) Dismiss() {
+	md.DismissModal(md)
+}
+
+func (md *restoreSeedModal) OnDismiss() {}
+
+func (md *restoreSeedModal) OnResume() {}
+
+func (md *restoreSeedModal) Handle() {
+	if md.cancel.Clicked() {
+		md.Dismiss()
+	}
+
+	if md.addShare.Clicked() && len(md.shares) < wallet.MaxSeedShares {
+		md.newShareEditor()
+	}
+
+	if md.splitSeed {
+		md.handleShares()
+	} else {
+		md.handleHexSeed()
+	}
+	md.submit.SetEnabled(md.seed != "")
+
+	if md.submit.Clicked() && md.seed != "" {
+		md.Dismiss()
+		md.restore(md.seed)
+	}
+}
+
+func (md *restoreSeedModal) handleHexSeed() {
+	for _, evt := range md.seedEditor.Editor.Events() {
+		if _, ok := evt.(widget.ChangeEvent); !ok {
+			continue
+		}
+
+		md.seed, md.seedError = "", ""
+		seed := md.seedEditor.Editor.Text()
+		if seed == "" {
+			continue
+		}
+		if !dcrlibwallet.VerifySeed(seed) {
+			md.seedError = "invalid hex seed"
+			continue
+		}
+		md.seed = seed
+	}
+}
+
+func (md *restoreSeedModal) handleShares() {
+	changed := false
+	for _, se := range md.shares {
+		for _, evt := range se.editor.Editor.Events() {
+			if _, ok := evt.(widget.ChangeEvent); !ok {
+				continue
+			}
+			changed = true
+
+			se.share, se.status = nil, ""
+			input := se.editor.Editor.Text()
+			if input == "" {
+				continue
+			}
+			share, err := wallet.DecodeSeedShare(input)
+			if err != nil {
+				se.status = "invalid share"
+				continue
+			}
+			se.share = share
+			se.status = fmt.Sprintf("Share %d, %d shares needed", share.Index, share.Threshold)
+		}
+	}
+	if !changed {
+		return
+	}
+
+	md.seed, md.seedError = "", ""
+	var shares []*wallet.SeedShare
+	for _, se := range md.shares {
+		if se.share != nil {
+			shares = append(shares, se.share)
+		}
+	}
+	if len(shares) == 0 {
+		return
+	}
+
+	seed, err := wallet.CombineSeedShares(shares)
+	if err != nil {
+		if err != wallet.ErrNotEnoughShares {
+			md.seedError = err.Error()
+		}
+		return
+	}
+	md.seed = seed
+}
+
+func (md *restoreSeedModal) Layout(gtx layout.Context) D {
+	title, description := "Restore with a hex seed", "Enter the seed in hexadecimal."
+	if md.splitSeed {
+		title = "Restore from seed shares"
+		description = "Enter the shares of the seed, as words or in hex. The seed is restored once enough shares are entered."
+	}
+
+	w := []layout.Widget{
+		func(gtx C) D {
+			t := md.Theme.H6(title)
+			t.Font.Weight = text.SemiBold
+			return t.Layout(gtx)
+		},
+		func(gtx C) D {
+			lbl := md.Theme.Body2(description)
+			lbl.Color = md.Theme.Color.GrayText2
+			return lbl.Layout(gtx)
+		},
+	}
+
+	if md.splitSeed {
+		w = append(w, func(gtx C) D {
+			gtx.Constraints.Max.Y = gtx.Px(values.MarginPadding200)
+			return md.Theme.List(md.container).Layout(gtx, len(md.shares), func(gtx C, i int) D {
+				se := md.shares[i]
+				return layout.Inset{Bottom: values.MarginPadding8}.Layout(gtx, func(gtx C) D {
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(se.editor.Layout),
+						layout.Rigid(func(gtx C) D {
+							if se.status == "" {
+								return D{}
+							}
+							lbl := md.Theme.Caption(se.status)
+							lbl.Color = md.Theme.Color.GrayText2
+							if se.share == nil {
+								lbl.Color = md.Theme.Color.Danger
+							}
+							return lbl.Layout(gtx)
+						}),
+					)
+				})
+			})
+		})
+	} else {
+		w = append(w, md.seedEditor.Layout)
+	}
+
+	w = append(w,
+		func(gtx C) D {
+			if md.seedError == "" {
+				return D{}
+			}
+			return md.Theme.ErrorLabel(md.seedError).Layout(gtx)
+		},
+		func(gtx C) D {
+			return layout.Flex{}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					if !md.splitSeed {
+						return D{}
+					}
+					return md.addShare.Layout(gtx)
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					return layout.E.Layout(gtx, func(gtx C) D {
+						return layout.Flex{}.Layout(gtx,
+							layout.Rigid(func(gtx C) D {
+								return layout.Inset{Right: values.MarginPadding8}.Layout(gtx, md.cancel.Layout)
+							}),
+							layout.Rigid(md.submit.Layout),
+						)
+					})
+				}),
+			)
+		},
+	)
+
+	return md.modal.Layout(gtx, w)
+}
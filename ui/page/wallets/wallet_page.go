@@ -75,6 +75,7 @@ type WalletPage struct {
 	listItems      []*walletListItem
 	badWalletsList []*badWalletListItem
 	addWalletMenu  []menuItem
+	backupHealth   *backupHealthPanel
 
 	container   *widget.List
 	backdrop    *widget.Clickable
@@ -121,6 +122,7 @@ func NewWalletPage(l *load.Load) *WalletPage {
 		separator:                l.Theme.Separator(),
 		addAcctIcon:              decredmaterial.NewIcon(l.Icons.ContentAdd),
 		backupAcctIcon:           decredmaterial.NewIcon(l.Icons.NavigationArrowForward),
+		backupHealth:             newBackupHealthPanel(l),
 	}
 
 	pg.openAddWalletPopupButton.Radius = decredmaterial.Radius(24)
@@ -233,6 +235,7 @@ func (pg *WalletPage) loadWalletAndAccounts() {
 	pg.listLock.Unlock()
 
 	pg.loadBadWallets()
+	pg.backupHealth.refresh()
 }
 
 func (pg *WalletPage) loadBadWallets() {
@@ -270,6 +273,20 @@ func (pg *WalletPage) initializeFloatingMenu() {
 			button: pg.Theme.NewClickable(true),
 			action: pg.showImportWatchOnlyWalletModal,
 		},
+		{
+			text:   "Restore from backup file",
+			button: pg.Theme.NewClickable(true),
+			action: func(l *load.Load) {
+				newRestoreBackupModal(l, pg.loadWalletAndAccounts).Show()
+			},
+		},
+		{
+			text:   "Back up all wallets",
+			button: pg.Theme.NewClickable(true),
+			action: func(l *load.Load) {
+				newBackupModal(l, l.WL.SortedWalletList(), true).Show()
+			},
+		},
 	}
 }
 
@@ -437,6 +454,7 @@ func (pg *WalletPage) moreOptionPositionEvent(gtx layout.Context) {
 func (pg *WalletPage) Layout(gtx layout.Context) layout.Dimensions {
 	pg.moreOptionPositionEvent(gtx)
 	pageContent := []func(gtx C) D{
+		pg.backupHealth.layout,
 		pg.walletSection,
 	}
 
@@ -1091,6 +1109,8 @@ func (pg *WalletPage) HandleUserInteractions() {
 		pg.closePopups()
 	}
 
+	pg.backupHealth.handle()
+
 	if ok, selectedItem := pg.watchWalletsList.ItemClicked(); ok {
 		pg.listLock.Lock()
 		listItem := pg.listItems[selectedItem]
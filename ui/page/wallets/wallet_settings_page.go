@@ -8,6 +8,7 @@ import (
 	"github.com/planetdecred/godcr/ui/load"
 	"github.com/planetdecred/godcr/ui/modal"
 	"github.com/planetdecred/godcr/ui/page/components"
+	"github.com/planetdecred/godcr/ui/page/seedbackup"
 	"github.com/planetdecred/godcr/ui/values"
 )
 
@@ -18,7 +19,7 @@ type WalletSettingsPage struct {
 
 	wallet *dcrlibwallet.Wallet
 
-	changePass, rescan, deleteWallet *decredmaterial.Clickable
+	changePass, backup, verifySeed, rescan, deleteWallet *decredmaterial.Clickable
 
 	chevronRightIcon *decredmaterial.Icon
 	backButton       decredmaterial.IconButton
@@ -30,6 +31,8 @@ func NewWalletSettingsPage(l *load.Load, wal *dcrlibwallet.Wallet) *WalletSettin
 		Load:         l,
 		wallet:       wal,
 		changePass:   l.Theme.NewClickable(false),
+		backup:       l.Theme.NewClickable(false),
+		verifySeed:   l.Theme.NewClickable(false),
 		rescan:       l.Theme.NewClickable(false),
 		deleteWallet: l.Theme.NewClickable(false),
 
@@ -78,6 +81,7 @@ func (pg *WalletSettingsPage) Layout(gtx layout.Context) layout.Dimensions {
 						}
 						return layout.Dimensions{}
 					}),
+					layout.Rigid(pg.backupSection()),
 					layout.Rigid(pg.debug()),
 					layout.Rigid(pg.dangerZone()),
 				)
@@ -95,6 +99,23 @@ func (pg *WalletSettingsPage) changePassphrase() layout.Widget {
 	}
 }
 
+func (pg *WalletSettingsPage) backupSection() layout.Widget {
+	return func(gtx C) D {
+		return pg.pageSections(gtx, "Backup", func(gtx C) D {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(pg.bottomSectionLabel(pg.backup, "Export backup file")),
+				layout.Rigid(func(gtx C) D {
+					// The seed can be verified again once it is backed up.
+					if pg.wallet.IsWatchingOnlyWallet() || pg.wallet.EncryptedSeed != nil {
+						return D{}
+					}
+					return pg.bottomSectionLabel(pg.verifySeed, "Verify seed backup")(gtx)
+				}),
+			)
+		})
+	}
+}
+
 func (pg *WalletSettingsPage) debug() layout.Widget {
 	return func(gtx C) D {
 		return pg.pageSections(gtx, values.String(values.StrDebug),
@@ -217,6 +238,14 @@ func (pg *WalletSettingsPage) HandleUserInteractions() {
 		break
 	}
 
+	for pg.backup.Clicked() {
+		newBackupModal(pg.Load, []*dcrlibwallet.Wallet{pg.wallet}, false).Show()
+	}
+
+	for pg.verifySeed.Clicked() {
+		seedbackup.NewReverifySeedModal(pg.Load, pg.wallet).Show()
+	}
+
 	for pg.rescan.Clicked() {
 		go func() {
 			info := modal.NewInfoModal(pg.Load).
@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"gioui.org/app"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+
+	"github.com/planetdecred/godcr/appconfig"
+	"github.com/planetdecred/godcr/ui/assets"
+	"github.com/planetdecred/godcr/ui/decredmaterial"
+	"github.com/planetdecred/godcr/ui/values"
+)
+
+// profileChooser is the window listing the profiles at startup.
+type profileChooser struct {
+	*app.Window
+
+	theme     *decredmaterial.Theme
+	profiles  *appconfig.Profiles
+	rows      []*decredmaterial.Clickable
+	list      *widget.List
+	askOnOpen *widget.Bool
+}
+
+// ChooseProfile opens a window listing the profiles and returns the name of
+// the profile clicked, ok is false if the window is closed instead. The
+// window is closed before the app window is created for the profile.
+func ChooseProfile(profiles *appconfig.Profiles) (name string, ok bool) {
+	th := decredmaterial.NewTheme(assets.FontCollection(), assets.DecredIcons, false)
+	pc := &profileChooser{
+		Window:    app.NewWindow(app.Size(unit.Dp(480), unit.Dp(560)), app.Title("godcr profiles")),
+		theme:     th,
+		profiles:  profiles,
+		list:      &widget.List{List: layout.List{Axis: layout.Vertical}},
+		askOnOpen: &widget.Bool{Value: profiles.ChooseOnStartup},
+	}
+	for range profiles.Profiles {
+		pc.rows = append(pc.rows, th.NewClickable(true))
+	}
+
+	for e := range pc.Events() {
+		switch evt := e.(type) {
+		case system.DestroyEvent:
+			return "", false
+
+		case system.FrameEvent:
+			for i, row := range pc.rows {
+				for row.Clicked() {
+					name, ok = profiles.Profiles[i].Name, true
+				}
+			}
+			if pc.askOnOpen.Changed() {
+				profiles.ChooseOnStartup = pc.askOnOpen.Value
+				if err := profiles.Save(); err != nil {
+					log.Errorf("Error saving the profiles: %v", err)
+				}
+			}
+			if ok {
+				pc.Close()
+				return name, ok
+			}
+
+			gtx := layout.NewContext(&op.Ops{}, evt)
+			pc.layout(gtx)
+			evt.Frame(gtx.Ops)
+		}
+	}
+	return "", false
+}
+
+func (pc *profileChooser) layout(gtx C) D {
+	decredmaterial.Fill(gtx, pc.theme.Color.Gray4)
+	return layout.UniformInset(values.MarginPadding24).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				lbl := pc.theme.H6("Choose a profile")
+				lbl.Font.Weight = text.SemiBold
+				return layout.Inset{Bottom: values.MarginPadding16}.Layout(gtx, lbl.Layout)
+			}),
+			layout.Flexed(1, func(gtx C) D {
+				return pc.theme.List(pc.list).Layout(gtx, len(pc.rows), func(gtx C, i int) D {
+					return layout.Inset{Bottom: values.MarginPadding8}.Layout(gtx, func(gtx C) D {
+						return pc.profileRow(gtx, i)
+					})
+				})
+			}),
+			layout.Rigid(func(gtx C) D {
+				return layout.Inset{Top: values.MarginPadding16}.Layout(gtx,
+					pc.theme.CheckBox(pc.askOnOpen, "Ask for the profile at startup").Layout)
+			}),
+		)
+	})
+}
+
+func (pc *profileChooser) profileRow(gtx C, i int) D {
+	profile := pc.profiles.Profiles[i]
+	network := profile.Network
+	if network == "" {
+		network = "network of the config file"
+	}
+	title := profile.Name
+	if profile.Name == pc.profiles.Default {
+		title += " (default)"
+	}
+
+	card := pc.theme.Card()
+	card.Radius = decredmaterial.Radius(14)
+	return card.Layout(gtx, func(gtx C) D {
+		return pc.rows[i].Layout(gtx, func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			return layout.UniformInset(values.MarginPadding15).Layout(gtx, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(pc.theme.Body1(title).Layout),
+					layout.Rigid(func(gtx C) D {
+						lbl := pc.theme.Caption(network + ", " + profile.HomeDir)
+						lbl.Color = pc.theme.Color.GrayText2
+						return lbl.Layout(gtx)
+					}),
+				)
+			})
+		})
+	})
+}
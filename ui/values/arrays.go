@@ -5,11 +5,21 @@ import "github.com/planetdecred/godcr/ui/values/localizable"
 var (
 	ArrLanguages          map[string]string
 	ArrExchangeCurrencies map[string]string
+	ArrTicketNotification map[string]string
+
+	ArrSeedVerificationInterval map[string]string
+	ArrAutoLock                 map[string]string
+	ArrSyncRetry                map[string]string
 )
 
 const (
 	DefaultExchangeValue = "none"
 	USDExchangeValue     = "USD (Bittrex)"
+
+	TicketNotificationOff    = "off"
+	TicketNotificationToast  = "toast"
+	TicketNotificationSystem = "system"
+	TicketNotificationBoth   = "both"
 )
 
 func init() {
@@ -21,4 +31,33 @@ func init() {
 	ArrExchangeCurrencies = make(map[string]string)
 	ArrExchangeCurrencies[DefaultExchangeValue] = StrNone
 	ArrExchangeCurrencies[USDExchangeValue] = StrUsdBittrex
+
+	ArrTicketNotification = make(map[string]string)
+	ArrTicketNotification[TicketNotificationOff] = StrNotificationOff
+	ArrTicketNotification[TicketNotificationToast] = StrNotificationToast
+	ArrTicketNotification[TicketNotificationSystem] = StrNotificationSystem
+	ArrTicketNotification[TicketNotificationBoth] = StrNotificationBoth
+
+	// the keys are wallet.SeedVerificationIntervalConfigKey values
+	ArrSeedVerificationInterval = make(map[string]string)
+	ArrSeedVerificationInterval["030"] = StrEvery30Days
+	ArrSeedVerificationInterval["090"] = StrEvery90Days
+	ArrSeedVerificationInterval["180"] = StrEvery180Days
+	ArrSeedVerificationInterval["365"] = StrEveryYear
+	ArrSeedVerificationInterval["off"] = StrNever
+
+	// the keys are wallet.AutoLockConfigKey values
+	ArrAutoLock = make(map[string]string)
+	ArrAutoLock["01"] = StrAfter1Minute
+	ArrAutoLock["05"] = StrAfter5Minutes
+	ArrAutoLock["15"] = StrAfter15Minutes
+	ArrAutoLock["30"] = StrAfter30Minutes
+	ArrAutoLock["60"] = StrAfter1Hour
+	ArrAutoLock["off"] = StrNever
+
+	// the keys are wallet.SyncRetryConfigKey values
+	ArrSyncRetry = make(map[string]string)
+	ArrSyncRetry["backoff"] = StrSyncRetryBackoff
+	ArrSyncRetry["fixed"] = StrSyncRetryFixed
+	ArrSyncRetry["off"] = StrNever
 }
@@ -5,4 +5,6 @@ const (
 	StakingDropdownGroup
 	ProposalDropdownGroup
 	ConsensusDropdownGroup
+	TreasuryDropdownGroup
+	DexMarketDropdownGroup
 )
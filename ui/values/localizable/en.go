@@ -119,4 +119,33 @@ const EN = `
 "none" = "None";
 "proposals" = "Proposals";
 "dex" = "Dex";
+"notificationOff" = "Off";
+"notificationToast" = "In app";
+"notificationSystem" = "Desktop";
+"notificationBoth" = "In app and desktop";
+"ticketMatured" = "Ticket stake matured";
+"ticketLive" = "Ticket live";
+"ticketVoted" = "Ticket voted";
+"ticketRevoked" = "Ticket revoked";
+"ticketExpired" = "Ticket expired";
+"ticketMissed" = "Ticket missed";
+"seedVerificationReminder" = "Seed verification reminder";
+"every30Days" = "Every 30 days";
+"every90Days" = "Every 90 days";
+"every180Days" = "Every 180 days";
+"everyYear" = "Every year";
+"never" = "Never";
+"autoLock" = "Lock when idle";
+"after1Minute" = "After 1 minute";
+"after5Minutes" = "After 5 minutes";
+"after15Minutes" = "After 15 minutes";
+"after30Minutes" = "After 30 minutes";
+"after1Hour" = "After 1 hour";
+"walletLocked" = "Locked";
+"unlockWithStartup" = "Enter your startup password to unlock. Sync, mixing and ticket buying keep running while locked.";
+"syncRetry" = "Retry failed sync";
+"syncRetryBackoff" = "Wait longer after each failure";
+"syncRetryFixed" = "Every minute";
+"unlockWithSpend" = "Enter the spending password you confirmed for the auto-lock to unlock. Sync, mixing and ticket buying keep running while locked.";
+"autoLockSpending" = "No startup password is set. Confirm the spending password of a wallet that is not mixing or buying tickets, it unlocks the window when idle.";
 `
@@ -229,4 +229,37 @@ const (
 	StrNone       = "none"
 	StrProposal   = "proposals"
 	StrDex        = "dex"
+
+	StrNotificationOff    = "notificationOff"
+	StrNotificationToast  = "notificationToast"
+	StrNotificationSystem = "notificationSystem"
+	StrNotificationBoth   = "notificationBoth"
+	StrTicketMatured      = "ticketMatured"
+	StrTicketLive         = "ticketLive"
+	StrTicketVoted        = "ticketVoted"
+	StrTicketRevoked      = "ticketRevoked"
+	StrTicketExpired      = "ticketExpired"
+	StrTicketMissed       = "ticketMissed"
+
+	StrSeedVerificationReminder = "seedVerificationReminder"
+	StrEvery30Days              = "every30Days"
+	StrEvery90Days              = "every90Days"
+	StrEvery180Days             = "every180Days"
+	StrEveryYear                = "everyYear"
+	StrNever                    = "never"
+
+	StrAutoLock          = "autoLock"
+	StrAfter1Minute      = "after1Minute"
+	StrAfter5Minutes     = "after5Minutes"
+	StrAfter15Minutes    = "after15Minutes"
+	StrAfter30Minutes    = "after30Minutes"
+	StrAfter1Hour        = "after1Hour"
+	StrWalletLocked      = "walletLocked"
+	StrUnlockWithStartup = "unlockWithStartup"
+	StrUnlockWithSpend   = "unlockWithSpend"
+	StrAutoLockSpending  = "autoLockSpending"
+
+	StrSyncRetry        = "syncRetry"
+	StrSyncRetryBackoff = "syncRetryBackoff"
+	StrSyncRetryFixed   = "syncRetryFixed"
 )
@@ -3,12 +3,15 @@ package ui
 import (
 	"errors"
 	"sync"
+	"time"
 
 	"gioui.org/app"
 	"gioui.org/io/key"
+	"gioui.org/io/pointer"
 	"gioui.org/io/system"
 	"gioui.org/layout"
 	"gioui.org/op"
+	"gioui.org/op/clip"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 
@@ -52,8 +55,22 @@ type Window struct {
 
 	keyEvents             map[string]chan *key.Event
 	walletAcctMixerStatus chan *wallet.AccountMixer
+
+	// lastActivity is the time of the last key or pointer event, the
+	// window is locked once the auto-lock timeout passes without any.
+	lastActivity time.Time
+	// lockPage is displayed on top of everything else while the window
+	// is locked, nil if the window is unlocked.
+	lockPage load.Page
+	// unlocked receives from the lock page once the password is verified,
+	// the lock page is removed by the event loop.
+	unlocked chan struct{}
 }
 
+// autoLockCheckInterval is how often the time since the last user activity
+// is checked against the auto-lock timeout.
+const autoLockCheckInterval = 15 * time.Second
+
 type (
 	C = layout.Context
 	D = layout.Dimensions
@@ -83,6 +100,8 @@ func CreateWindow(wal *wallet.Wallet) (*Window, error) {
 		walletAcctMixerStatus: make(chan *wallet.AccountMixer),
 		proposals:             new(wallet.Proposals),
 		keyEvents:             make(map[string]chan *key.Event),
+		lastActivity:          time.Now(),
+		unlocked:              make(chan struct{}, 1),
 	}
 
 	l, err := win.NewLoad()
@@ -121,7 +140,8 @@ func (win *Window) NewLoad() (*load.Load, error) {
 			KeyEvents: win.keyEvents,
 		},
 
-		Toast: notification.NewToast(th),
+		Toast:         notification.NewToast(th),
+		Notifications: notification.NewCenter(),
 
 		Printer: message.NewPrinter(language.English),
 	}
@@ -133,6 +153,7 @@ func (win *Window) NewLoad() (*load.Load, error) {
 	l.ChangeWindowPage = win.changePage
 	l.SubscribeKeyEvent = win.SubscribeKeyEvent
 	l.UnsubscribeKeyEvent = win.UnsubscribeKeyEvent
+	l.CloseWindow = win.Close
 
 	// ReloadApp closes the current page active on the
 	// app window. When the next FrameEvent is received,
@@ -164,8 +185,21 @@ func (win *Window) UnsubscribeKeyEvent(pageID string) error {
 
 // HandleEvents runs main event handling and page rendering loop.
 func (win *Window) HandleEvents() {
+	autoLockTicker := time.NewTicker(autoLockCheckInterval)
+	defer autoLockTicker.Stop()
+
 	for {
-		e := <-win.Events()
+		var e interface{}
+		select {
+		case e = <-win.Events():
+		case <-autoLockTicker.C:
+			win.checkAutoLock()
+			continue
+		case <-win.unlocked:
+			win.unlock()
+			continue
+		}
+
 		switch evt := e.(type) {
 
 		case system.DestroyEvent:
@@ -179,6 +213,12 @@ func (win *Window) HandleEvents() {
 			win.displayWindow(evt)
 
 		case key.Event:
+			win.lastActivity = time.Now()
+			if win.lockPage != nil {
+				// Pages do not receive key events while the window is
+				// locked, the lock page editor handles its own.
+				continue
+			}
 			go func() {
 				for _, c := range win.keyEvents {
 					c <- &evt
@@ -203,6 +243,18 @@ func (win *Window) displayWindow(evt system.FrameEvent) {
 		win.currentPage.OnNavigatedTo()
 	}
 
+	gtx := layout.NewContext(&op.Ops{}, evt)
+
+	// While locked, only the lock page is handled and drawn. The current
+	// page and modals are kept as they are for when the window is unlocked.
+	if win.lockPage != nil {
+		win.lockPage.HandleUserInteractions()
+		win.drawLockPageUI(gtx)
+		win.handleActivity(gtx)
+		evt.Frame(gtx.Ops)
+		return
+	}
+
 	// A FrameEvent may be generated because of a user interaction
 	// with the current page such as a button click. First handle
 	// any such user interaction before rendering the page.
@@ -212,8 +264,8 @@ func (win *Window) displayWindow(evt system.FrameEvent) {
 	}
 
 	// Draw the window's UI components into an op.Ops.
-	gtx := layout.NewContext(&op.Ops{}, evt)
 	win.drawWindowUI(gtx)
+	win.handleActivity(gtx)
 
 	// Render the window's UI components on screen.
 	evt.Frame(gtx.Ops)
@@ -259,6 +311,71 @@ func (win *Window) drawWindowUI(gtx C) {
 	)
 }
 
+// handleActivity resets the auto-lock timer on pointer events received since
+// the last frame, then registers for pointer events over the whole window.
+// It is called after the UI components are drawn so its area is on top,
+// the events pass through to the components underneath.
+func (win *Window) handleActivity(gtx C) {
+	for range gtx.Events(win) {
+		win.lastActivity = time.Now()
+	}
+
+	pass := pointer.PassOp{}.Push(gtx.Ops)
+	area := clip.Rect{Max: gtx.Constraints.Max}.Push(gtx.Ops)
+	pointer.InputOp{
+		Tag:   win,
+		Types: pointer.Press | pointer.Move | pointer.Scroll,
+	}.Add(gtx.Ops)
+	area.Pop()
+	pass.Pop()
+}
+
+// checkAutoLock locks the window if no user activity happened within the
+// configured auto-lock timeout. Only the window is locked, sync, mixing and
+// ticket buying keep running.
+func (win *Window) checkAutoLock() {
+	if win.lockPage != nil || win.currentPage == nil || win.currentPage.ID() == page.StartPageID {
+		return
+	}
+
+	timeout := win.wallet.AutoLockTimeout()
+	if timeout == 0 || time.Since(win.lastActivity) < timeout {
+		return
+	}
+
+	win.lockPage = page.NewLockPage(win.load, func() {
+		select {
+		case win.unlocked <- struct{}{}:
+		default:
+		}
+	})
+	win.lockPage.OnNavigatedTo()
+	win.Invalidate()
+}
+
+// unlock removes the lock page once the password is verified, it is called
+// by the event loop.
+func (win *Window) unlock() {
+	if win.lockPage == nil {
+		return
+	}
+	win.lockPage.OnNavigatedFrom()
+	win.lockPage = nil
+	win.lastActivity = time.Now()
+	win.Invalidate()
+}
+
+// drawLockPageUI draws the lock page in place of the window UI components.
+func (win *Window) drawLockPageUI(gtx C) {
+	layout.Stack{Alignment: layout.N}.Layout(
+		gtx,
+		layout.Expanded(func(gtx C) D {
+			return decredmaterial.Fill(gtx, win.load.Theme.Color.Gray4)
+		}),
+		layout.Stacked(win.lockPage.Layout),
+	)
+}
+
 // changePage displays the provided page on the window and optionally adds
 // the current page to the backstack. This automatically refreshes the display,
 // callers should not re-refresh the display.
@@ -0,0 +1,161 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/planetdecred/dcrlibwallet"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// AutoLockConfigKey is the multiwallet config key of the number of
+	// minutes without user activity after which the window is locked,
+	// AutoLockOff disables the auto-lock.
+	AutoLockConfigKey = "auto_lock_timeout"
+
+	// AutoLockVerifierConfigKey is the multiwallet config key of the scrypt
+	// verifier of the spending password that unlocks the window when no
+	// startup password is set.
+	AutoLockVerifierConfigKey = "auto_lock_verifier"
+
+	// AutoLockOff is the auto-lock timeout that disables the auto-lock.
+	AutoLockOff = "off"
+
+	// DefaultAutoLock is the auto-lock timeout unless configured otherwise.
+	DefaultAutoLock = AutoLockOff
+)
+
+// scrypt parameters of the auto-lock verifier.
+const (
+	verifierN      = 1 << 15
+	verifierR      = 8
+	verifierP      = 1
+	verifierKeyLen = 32
+)
+
+var (
+	// ErrAutoLockNeedsPassword is returned when the window is unlocked
+	// without a startup password or a confirmed spending password.
+	ErrAutoLockNeedsPassword = errors.New("set a startup password or confirm a spending password to lock the window when idle")
+	// ErrNoUnlockWallet is returned when no wallet can check the spending
+	// password without disturbing the wallets.
+	ErrNoUnlockWallet = errors.New("no wallet can check the password while mixing or buying tickets, set a startup password instead")
+)
+
+// CanAutoLock returns true if a locked window can be unlocked: a startup
+// password is set or a spending password was confirmed with
+// SetAutoLockPassphrase.
+func (wal *Wallet) CanAutoLock() bool {
+	return wal.multi.IsStartupSecuritySet() || wal.ReadStringConfigValueForKey(AutoLockVerifierConfigKey) != ""
+}
+
+// AutoLockTimeout returns the time without user activity after which the
+// window is locked, 0 if the auto-lock is disabled or the window could not
+// be unlocked again.
+func (wal *Wallet) AutoLockTimeout() time.Duration {
+	if !wal.CanAutoLock() {
+		return 0
+	}
+
+	value := wal.ReadStringConfigValueForKey(AutoLockConfigKey)
+	if value == "" {
+		value = DefaultAutoLock
+	}
+
+	minutes, err := strconv.Atoi(value)
+	if err != nil || minutes <= 0 {
+		return 0
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// SetAutoLockPassphrase checks the spending password against a wallet that
+// is locked and is not mixing or buying tickets, and stores a scrypt
+// verifier of it to unlock the window when no startup password is set. The
+// wallets are not touched again when the window is unlocked.
+func (wal *Wallet) SetAutoLockPassphrase(passphrase []byte) error {
+	err := ErrNoUnlockWallet
+	for _, wall := range wal.multi.AllWallets() {
+		if wall.IsWatchingOnlyWallet() || !wall.IsLocked() ||
+			wall.IsAccountMixerActive() || wall.IsAutoTicketsPurchaseActive() {
+			continue
+		}
+
+		err = wall.UnlockWallet(passphrase)
+		if err != nil {
+			// Wallets may have different spending passwords.
+			continue
+		}
+		wall.LockWallet()
+
+		verifier, err := newPassphraseVerifier(passphrase)
+		if err != nil {
+			return err
+		}
+		wal.SaveConfigValueForKey(AutoLockVerifierConfigKey, verifier)
+		return nil
+	}
+	return err
+}
+
+// VerifyUnlockPassphrase checks the password that unlocks a locked window:
+// the startup password if one is set, otherwise the spending password
+// confirmed with SetAutoLockPassphrase. The wallets are not touched, they
+// keep mixing and buying tickets.
+func (wal *Wallet) VerifyUnlockPassphrase(passphrase []byte) error {
+	if wal.multi.IsStartupSecuritySet() {
+		return wal.multi.VerifyStartupPassphrase(passphrase)
+	}
+
+	verifier := wal.ReadStringConfigValueForKey(AutoLockVerifierConfigKey)
+	if verifier == "" {
+		return ErrAutoLockNeedsPassword
+	}
+	return checkPassphraseVerifier(verifier, passphrase)
+}
+
+// newPassphraseVerifier returns the hex encoded random salt and scrypt key
+// of the passphrase, separated by a colon.
+func newPassphraseVerifier(passphrase []byte) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := scrypt.Key(passphrase, salt, verifierN, verifierR, verifierP, verifierKeyLen)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(salt) + ":" + hex.EncodeToString(key), nil
+}
+
+// checkPassphraseVerifier returns an invalid passphrase error if the
+// passphrase does not match the verifier.
+func checkPassphraseVerifier(verifier string, passphrase []byte) error {
+	parts := strings.Split(verifier, ":")
+	if len(parts) != 2 {
+		return errors.New("invalid auto-lock verifier")
+	}
+	salt, err := hex.DecodeString(parts[0])
+	if err != nil {
+		return err
+	}
+	want, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return err
+	}
+
+	key, err := scrypt.Key(passphrase, salt, verifierN, verifierR, verifierP, len(want))
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(key, want) != 1 {
+		return errors.New(dcrlibwallet.ErrInvalidPassphrase)
+	}
+	return nil
+}
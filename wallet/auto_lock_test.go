@@ -0,0 +1,60 @@
+package wallet_test
+
+import (
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/planetdecred/dcrlibwallet"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+var _ = Describe("Auto-lock", func() {
+	var (
+		wal  *wallet.Wallet
+		root string
+	)
+
+	BeforeEach(func() {
+		var err error
+		root, err = os.MkdirTemp("", "godcr-autolock")
+		Expect(err).ToNot(HaveOccurred())
+		wal, err = wallet.NewWallet(root, dcrlibwallet.Testnet3, "dev", "", time.Now())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(wal.InitMultiWallet()).To(Succeed())
+
+		seed, err := dcrlibwallet.GenerateSeed()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = wal.GetMultiWallet().RestoreWallet("spending", seed, "password", dcrlibwallet.PassphraseTypePass)
+		Expect(err).ToNot(HaveOccurred())
+		wal.SaveConfigValueForKey(wallet.AutoLockConfigKey, "05")
+	})
+
+	AfterEach(func() {
+		wal.Shutdown()
+		os.RemoveAll(root)
+	})
+
+	It("is off until a spending password is confirmed without a startup password", func() {
+		Expect(wal.CanAutoLock()).To(BeFalse())
+		Expect(wal.AutoLockTimeout()).To(BeZero())
+		Expect(wal.VerifyUnlockPassphrase([]byte("password"))).To(Equal(wallet.ErrAutoLockNeedsPassword))
+
+		Expect(wal.SetAutoLockPassphrase([]byte("wrong"))).ToNot(Succeed())
+		Expect(wal.CanAutoLock()).To(BeFalse())
+
+		Expect(wal.SetAutoLockPassphrase([]byte("password"))).To(Succeed())
+		Expect(wal.AutoLockTimeout()).To(Equal(5 * time.Minute))
+		Expect(wal.VerifyUnlockPassphrase([]byte("password"))).To(Succeed())
+		Expect(wal.VerifyUnlockPassphrase([]byte("wrong"))).To(MatchError(dcrlibwallet.ErrInvalidPassphrase))
+	})
+
+	It("unlocks with the startup password when one is set", func() {
+		Expect(wal.GetMultiWallet().SetStartupPassphrase([]byte("startup"), dcrlibwallet.PassphraseTypePass)).To(Succeed())
+		Expect(wal.AutoLockTimeout()).To(Equal(5 * time.Minute))
+		Expect(wal.VerifyUnlockPassphrase([]byte("startup"))).To(Succeed())
+		Expect(wal.VerifyUnlockPassphrase([]byte("password"))).ToNot(Succeed())
+	})
+})
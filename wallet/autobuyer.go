@@ -0,0 +1,56 @@
+package wallet
+
+import "github.com/planetdecred/dcrlibwallet"
+
+// AutoBuyerCredentialConfigKey is the wallet config key under which the PIN
+// encrypted spending passphrase of the ticket buyer is stored.
+const AutoBuyerCredentialConfigKey = "auto_ticket_buyer_credential"
+
+// AutoTicketPurchase is a ticket bought by the automatic ticket buyer.
+type AutoTicketPurchase struct {
+	Hash      string
+	Amount    int64
+	Fee       int64
+	Timestamp int64
+}
+
+// AutoTicketPurchases returns the tickets bought by the ticket buyer of the
+// wallet, oldest first. Only the tickets reported by the ticket buyer are
+// returned, not the ones bought by hand while it runs.
+func (wal *Wallet) AutoTicketPurchases(walletID int) []AutoTicketPurchase {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return nil
+	}
+
+	var purchases []AutoTicketPurchase
+	for _, hash := range wall.TicketBuyerPurchases() {
+		tx, err := wall.GetTransactionRaw(hash)
+		if err != nil {
+			// The ticket is no longer known to the wallet.
+			continue
+		}
+		purchases = append(purchases, AutoTicketPurchase{
+			Hash:      tx.Hash,
+			Amount:    tx.Amount,
+			Fee:       tx.Fee,
+			Timestamp: tx.Timestamp,
+		})
+	}
+	return purchases
+}
+
+// ResumableTicketBuyers returns the wallets whose ticket buyer was started
+// with the resume option, and is configured but not currently running.
+func (wal *Wallet) ResumableTicketBuyers() []*dcrlibwallet.Wallet {
+	var wallets []*dcrlibwallet.Wallet
+	for _, wall := range wal.multi.AllWallets() {
+		if !wall.TicketBuyerConfigIsSet() || wall.IsAutoTicketsPurchaseActive() {
+			continue
+		}
+		if wal.HasCredential(wall.ID, AutoBuyerCredentialConfigKey) {
+			wallets = append(wallets, wall)
+		}
+	}
+	return wallets
+}
@@ -0,0 +1,389 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"decred.org/dcrwallet/v2/walletseed"
+	"github.com/decred/dcrd/hdkeychain/v3"
+	"github.com/planetdecred/dcrlibwallet"
+)
+
+const (
+	// BackupVersion is the version of the backup format written by
+	// EncodeBackup. Backups of this or an older version can be decoded.
+	BackupVersion = 1
+
+	backupMagic = "godcr-backup"
+
+	// restoredAccountNamesConfigKey is the wallet config key under which the
+	// account names of a restored wallet are kept until its accounts are
+	// discovered.
+	restoredAccountNamesConfigKey = "restored_account_names"
+)
+
+var (
+	// ErrNotBackup is returned when decoding data that is not a godcr
+	// backup.
+	ErrNotBackup = errors.New("not a godcr backup file")
+
+	// ErrBadBackupPassword is returned when a backup cannot be opened with
+	// the provided password.
+	ErrBadBackupPassword = errors.New("invalid backup password")
+
+	// ErrUnsupportedBackup is returned when decoding a backup written by a
+	// newer version of godcr.
+	ErrUnsupportedBackup = errors.New("backup version not supported, update godcr to restore it")
+
+	// ErrSeedMismatch is returned when a seed does not belong to the wallet.
+	ErrSeedMismatch = errors.New("the seed does not belong to this wallet")
+)
+
+// walletBackupConfigKeys are the wallet config keys saved in a backup.
+var walletBackupConfigKeys = []string{
+	dcrlibwallet.AccountMixerConfigSet,
+	dcrlibwallet.AccountMixerMixedAccount,
+	dcrlibwallet.AccountMixerUnmixedAccount,
+	dcrlibwallet.AccountMixerMixTxChange,
+	dcrlibwallet.TicketBuyerVSPHostConfigKey,
+	dcrlibwallet.TicketBuyerAccountConfigKey,
+	dcrlibwallet.TicketBuyerATMConfigKey,
+	mixerScheduleConfigKey,
+}
+
+// multiWalletBackupConfigKeys are the multiwallet config keys saved in a
+// backup of all the wallets, in addition to the keys given by the caller.
+var multiWalletBackupConfigKeys = []string{
+	dcrlibwallet.SpendUnconfirmedConfigKey,
+	dcrlibwallet.CurrencyConversionConfigKey,
+	dcrlibwallet.BeepNewBlocksConfigKey,
+	dcrlibwallet.SpvPersistentPeerAddressesConfigKey,
+	dcrlibwallet.UserAgentConfigKey,
+	dcrlibwallet.KnownVSPsConfigKey,
+	bookmarkedProposalsConfigKey,
+	treasurySpendsConfigKey,
+	dexServersConfigKey,
+	NoThirdPartyRequestsConfigKey,
+	disabledFeaturesConfigKey,
+}
+
+// backupFile is the unencrypted envelope of a backup. The version is readable
+// without the password so newer formats can be told apart.
+type backupFile struct {
+	Magic   string `json:"magic"`
+	Version int    `json:"version"`
+	Payload []byte `json:"payload"`
+}
+
+// Backup holds the seeds and the local metadata of one or more wallets.
+type Backup struct {
+	Version int             `json:"version"`
+	Created int64           `json:"created"`
+	Network string          `json:"network"`
+	Wallets []*WalletBackup `json:"wallets"`
+	// Config holds multiwallet config values, it is only set in a backup of
+	// all the wallets.
+	Config map[string]json.RawMessage `json:"config,omitempty"`
+}
+
+// WalletBackup holds the seed and the local metadata of a wallet. Watch-only
+// wallets have no seed but the extended public key they were created with.
+type WalletBackup struct {
+	Name           string                     `json:"name"`
+	Seed           string                     `json:"seed,omitempty"`
+	ExtendedPubKey string                     `json:"xpub,omitempty"`
+	Accounts       map[int32]string           `json:"accounts,omitempty"`
+	Config         map[string]json.RawMessage `json:"config,omitempty"`
+}
+
+// EncodeBackup encrypts the backup with the password.
+func EncodeBackup(backup *Backup, password []byte) ([]byte, error) {
+	if len(password) == 0 {
+		return nil, errors.New("backup password is required")
+	}
+
+	backup.Version = BackupVersion
+	payload, err := json.Marshal(backup)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := SealCredential(password, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(&backupFile{
+		Magic:   backupMagic,
+		Version: BackupVersion,
+		Payload: sealed,
+	}, "", "  ")
+}
+
+// DecodeBackup decrypts a backup encoded with EncodeBackup.
+func DecodeBackup(data, password []byte) (*Backup, error) {
+	file := new(backupFile)
+	if err := json.Unmarshal(data, file); err != nil || file.Magic != backupMagic {
+		return nil, ErrNotBackup
+	}
+	if file.Version < 1 || file.Version > BackupVersion {
+		return nil, ErrUnsupportedBackup
+	}
+
+	payload, err := OpenCredential(password, file.Payload)
+	if err != nil {
+		return nil, ErrBadBackupPassword
+	}
+
+	backup := new(Backup)
+	if err = json.Unmarshal(payload, backup); err != nil {
+		return nil, fmt.Errorf("invalid backup: %v", err)
+	}
+	if backup.Version != file.Version {
+		return nil, fmt.Errorf("invalid backup: version %d in a version %d file", backup.Version, file.Version)
+	}
+	return backup, nil
+}
+
+// ReadBackupFile reads and decrypts the backup file at path.
+func ReadBackupFile(path string, password []byte) (*Backup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBackup(data, password)
+}
+
+// WriteBackup encrypts the backup with the password and writes it to a file
+// in the exports directory. It returns the path of the file.
+func (wal *Wallet) WriteBackup(backup *Backup, password []byte) (string, error) {
+	data, err := EncodeBackup(backup, password)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(wal.Root, "exports")
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	name := "all-wallets"
+	if len(backup.Wallets) == 1 && backup.Config == nil {
+		name = strings.NewReplacer(" ", "_", "/", "_", "\\", "_").Replace(backup.Wallets[0].Name)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("godcr-backup-%s-%s.json", name, time.Now().Format("20060102-150405")))
+	if err = os.WriteFile(path, data, 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// NewBackup creates a backup of the wallets. The multiwallet config is saved
+// too if includeConfig is true, configKeys are the keys saved in addition to
+// the ones managed by this package.
+func (wal *Wallet) NewBackup(wallets []*WalletBackup, includeConfig bool, configKeys ...string) *Backup {
+	backup := &Backup{
+		Version: BackupVersion,
+		Created: time.Now().Unix(),
+		Network: wal.Net,
+		Wallets: wallets,
+	}
+
+	if includeConfig {
+		backup.Config = make(map[string]json.RawMessage)
+		for _, key := range append(multiWalletBackupConfigKeys, configKeys...) {
+			var value json.RawMessage
+			if err := wal.multi.ReadUserConfigValue(key, &value); err == nil {
+				backup.Config[key] = value
+			}
+		}
+	}
+	return backup
+}
+
+// WalletBackup gathers the metadata of the wallet for a backup. The seed is
+// required unless the wallet is watch-only.
+func (wal *Wallet) WalletBackup(walletID int, seed string) (*WalletBackup, error) {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return nil, ErrIDNotExist
+	}
+
+	backup := &WalletBackup{
+		Name:     wall.Name,
+		Accounts: make(map[int32]string),
+		Config:   make(map[string]json.RawMessage),
+	}
+
+	if wall.IsWatchingOnlyWallet() {
+		xpub, err := wall.Internal().AccountXpub(context.Background(), dcrlibwallet.DefaultAccountNum)
+		if err != nil {
+			return nil, err
+		}
+		backup.ExtendedPubKey = xpub.String()
+	} else {
+		seed = strings.Join(strings.Fields(seed), " ")
+		if err := wal.VerifyWalletSeed(walletID, seed); err != nil {
+			return nil, err
+		}
+		backup.Seed = seed
+	}
+
+	accounts, err := wall.GetAccountsRaw()
+	if err != nil {
+		return nil, err
+	}
+	for _, acct := range accounts.Acc {
+		if acct.Number != dcrlibwallet.ImportedAccountNumber {
+			backup.Accounts[acct.Number] = acct.Name
+		}
+	}
+
+	for _, key := range walletBackupConfigKeys {
+		var value json.RawMessage
+		if err := wall.ReadUserConfigValue(key, &value); err == nil {
+			backup.Config[key] = value
+		}
+	}
+	return backup, nil
+}
+
+// VerifyWalletSeed checks that the seed derives the keys of the wallet.
+func (wal *Wallet) VerifyWalletSeed(walletID int, seed string) error {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return ErrIDNotExist
+	}
+
+	seedBytes, err := walletseed.DecodeUserInput(seed)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	w := wall.Internal()
+	coinType, err := w.CoinType(ctx)
+	if err != nil {
+		return err
+	}
+	xpub, err := w.AccountXpub(ctx, dcrlibwallet.DefaultAccountNum)
+	if err != nil {
+		return err
+	}
+
+	master, err := hdkeychain.NewMaster(seedBytes, w.ChainParams())
+	if err != nil {
+		return err
+	}
+	key := master
+	for _, i := range []uint32{44, coinType, dcrlibwallet.DefaultAccountNum} {
+		key, err = key.Child(i + hdkeychain.HardenedKeyStart)
+		if err != nil {
+			return err
+		}
+	}
+
+	if key.Neuter().String() != xpub.String() {
+		return ErrSeedMismatch
+	}
+	return nil
+}
+
+// RestoreBackup creates the wallets of the backup, encrypted with the
+// passphrase, and restores their metadata. Wallets whose name is taken are
+// restored under a new name. The account names are applied once the accounts
+// are discovered, see ApplyRestoredAccountNames. Only the config keys saved by
+// NewBackup and WalletBackup are restored, configKeys are the keys restored in
+// addition to the ones managed by this package.
+func (wal *Wallet) RestoreBackup(backup *Backup, passphrase string, configKeys ...string) ([]*dcrlibwallet.Wallet, error) {
+	if backup.Network != "" && backup.Network != wal.Net {
+		return nil, fmt.Errorf("the backup is for %s, not %s", backup.Network, wal.Net)
+	}
+
+	restored := make([]*dcrlibwallet.Wallet, 0, len(backup.Wallets))
+	for _, wb := range backup.Wallets {
+		name := wal.unusedWalletName(wb.Name)
+
+		var wall *dcrlibwallet.Wallet
+		var err error
+		if wb.ExtendedPubKey != "" {
+			wall, err = wal.multi.CreateWatchOnlyWallet(name, wb.ExtendedPubKey)
+		} else {
+			wall, err = wal.multi.RestoreWallet(name, wb.Seed, passphrase, dcrlibwallet.PassphraseTypePass)
+		}
+		if err != nil {
+			return restored, fmt.Errorf("error restoring %s: %v", wb.Name, err)
+		}
+
+		for _, key := range walletBackupConfigKeys {
+			if value, ok := wb.Config[key]; ok {
+				wall.SaveUserConfigValue(key, value)
+			}
+		}
+		if len(wb.Accounts) > 0 {
+			wall.SaveUserConfigValue(restoredAccountNamesConfigKey, wb.Accounts)
+		}
+		restored = append(restored, wall)
+	}
+
+	for _, key := range append(multiWalletBackupConfigKeys, configKeys...) {
+		if value, ok := backup.Config[key]; ok {
+			wal.multi.SaveUserConfigValue(key, value)
+		}
+	}
+	return restored, nil
+}
+
+// unusedWalletName returns name, or name with a number appended if a wallet
+// with that name exists.
+func (wal *Wallet) unusedWalletName(name string) string {
+	exists := func(name string) bool {
+		for _, w := range wal.multi.AllWallets() {
+			if w.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	unused := name
+	for i := 2; exists(unused); i++ {
+		unused = fmt.Sprintf("%s (%d)", name, i)
+	}
+	return unused
+}
+
+// ApplyRestoredAccountNames renames the discovered accounts of restored
+// wallets to their names in the backup. The names of accounts that are not
+// discovered yet are kept for a later call.
+func (wal *Wallet) ApplyRestoredAccountNames() {
+	for _, wall := range wal.multi.AllWallets() {
+		var names map[int32]string
+		err := wall.ReadUserConfigValue(restoredAccountNamesConfigKey, &names)
+		if err != nil || len(names) == 0 {
+			continue
+		}
+
+		for number, name := range names {
+			current, err := wall.AccountName(number)
+			if err != nil || current == "" {
+				continue
+			}
+			if current != name {
+				if err := wall.RenameAccount(number, name); err != nil {
+					log.Errorf("Error renaming restored account %d of %s: %v", number, wall.Name, err)
+					continue
+				}
+			}
+			delete(names, number)
+		}
+
+		wall.SaveUserConfigValue(restoredAccountNamesConfigKey, names)
+	}
+}
@@ -0,0 +1,132 @@
+package wallet_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/planetdecred/dcrlibwallet"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+var _ = Describe("Backup", func() {
+	password := []byte("backup-password")
+
+	newBackup := func() *wallet.Backup {
+		return &wallet.Backup{
+			Version: wallet.BackupVersion,
+			Created: 1650000000,
+			Network: "testnet3",
+			Wallets: []*wallet.WalletBackup{{
+				Name:     "default",
+				Seed:     "seed words",
+				Accounts: map[int32]string{0: "default", 1: "mixed"},
+			}},
+			Config: map[string]json.RawMessage{"dark_mode": json.RawMessage("true")},
+		}
+	}
+
+	It("decodes what it encodes", func() {
+		data, err := wallet.EncodeBackup(newBackup(), password)
+		Expect(err).ToNot(HaveOccurred())
+
+		backup, err := wallet.DecodeBackup(data, password)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(backup).To(Equal(newBackup()))
+	})
+
+	It("rejects a wrong password", func() {
+		data, err := wallet.EncodeBackup(newBackup(), password)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = wallet.DecodeBackup(data, []byte("wrong"))
+		Expect(err).To(Equal(wallet.ErrBadBackupPassword))
+	})
+
+	It("rejects data that is not a backup", func() {
+		_, err := wallet.DecodeBackup([]byte(`{"magic":"other","version":1}`), password)
+		Expect(err).To(Equal(wallet.ErrNotBackup))
+
+		_, err = wallet.DecodeBackup([]byte("not json"), password)
+		Expect(err).To(Equal(wallet.ErrNotBackup))
+	})
+
+	It("rejects a backup from a newer version", func() {
+		data := []byte(`{"magic":"godcr-backup","version":2,"payload":""}`)
+		_, err := wallet.DecodeBackup(data, password)
+		Expect(err).To(Equal(wallet.ErrUnsupportedBackup))
+	})
+
+	It("restores version 1 backups", func() {
+		backup, err := wallet.ReadBackupFile(filepath.Join("testdata", "backup_v1.json"), []byte("fixture-password"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(backup.Version).To(Equal(1))
+		Expect(backup.Network).To(Equal("testnet3"))
+		Expect(backup.Wallets).To(HaveLen(2))
+		Expect(backup.Wallets[0].Seed).To(Equal("seed words"))
+		Expect(backup.Wallets[0].Accounts).To(HaveKeyWithValue(int32(1), "mixed"))
+		Expect(backup.Wallets[1].ExtendedPubKey).To(Equal("tpubVpQ"))
+		Expect(backup.Config).To(HaveKeyWithValue("dark_mode", json.RawMessage("true")))
+	})
+
+	Context("restoring", func() {
+		var (
+			wal  *wallet.Wallet
+			root string
+		)
+
+		BeforeEach(func() {
+			var err error
+			root, err = os.MkdirTemp("", "godcr-backup")
+			Expect(err).ToNot(HaveOccurred())
+			wal, err = wallet.NewWallet(root, dcrlibwallet.Testnet3, "dev", "", time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(wal.InitMultiWallet()).To(Succeed())
+		})
+
+		AfterEach(func() {
+			wal.Shutdown()
+			os.RemoveAll(root)
+		})
+
+		It("restores only the config keys that are backed up", func() {
+			seed, err := dcrlibwallet.GenerateSeed()
+			Expect(err).ToNot(HaveOccurred())
+
+			backup := &wallet.Backup{
+				Version: wallet.BackupVersion,
+				Network: wal.Net,
+				Wallets: []*wallet.WalletBackup{{
+					Name: "restored",
+					Seed: seed,
+					Config: map[string]json.RawMessage{
+						dcrlibwallet.TicketBuyerVSPHostConfigKey: json.RawMessage(`"vsp.example.com"`),
+						wallet.AutoBuyerCredentialConfigKey:      json.RawMessage(`"injected"`),
+					},
+				}},
+				Config: map[string]json.RawMessage{
+					dcrlibwallet.BeepNewBlocksConfigKey: json.RawMessage("true"),
+					"dark_mode":                         json.RawMessage("true"),
+					wallet.AutoLockVerifierConfigKey:    json.RawMessage(`"injected"`),
+				},
+			}
+
+			restored, err := wal.RestoreBackup(backup, "password", "dark_mode")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restored).To(HaveLen(1))
+
+			wall := restored[0]
+			Expect(wall.ReadStringConfigValueForKey(dcrlibwallet.TicketBuyerVSPHostConfigKey, "")).To(Equal("vsp.example.com"))
+			Expect(wall.ReadStringConfigValueForKey(wallet.AutoBuyerCredentialConfigKey, "")).To(BeEmpty())
+
+			multi := wal.GetMultiWallet()
+			Expect(multi.ReadBoolConfigValueForKey(dcrlibwallet.BeepNewBlocksConfigKey, false)).To(BeTrue())
+			Expect(multi.ReadBoolConfigValueForKey("dark_mode", false)).To(BeTrue())
+			Expect(multi.ReadStringConfigValueForKey(wallet.AutoLockVerifierConfigKey)).To(BeEmpty())
+		})
+	})
+})
@@ -1,11 +1,13 @@
 package wallet
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"strconv"
 	"time"
 
+	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/planetdecred/dcrlibwallet"
 )
 
@@ -39,6 +41,67 @@ func (wal *Wallet) UnlockWallet(walletID int, password []byte) error {
 	return wal.multi.UnlockWallet(walletID, password)
 }
 
+// OpenWallets opens the wallets with the startup password, empty if none is
+// set, and unlocks the spending passphrases of the scheduled mixers.
+func (wal *Wallet) OpenWallets(startupPassphrase []byte) error {
+	if err := wal.multi.OpenWallets(startupPassphrase); err != nil {
+		return err
+	}
+	if len(startupPassphrase) > 0 {
+		wal.UnlockMixerCredentials(startupPassphrase)
+	}
+	return nil
+}
+
+// AccountBalances returns the accounts of all the wallets with their
+// balances.
+func (wal *Wallet) AccountBalances() ([]*dcrlibwallet.Account, error) {
+	var accounts []*dcrlibwallet.Account
+	for _, wall := range wal.multi.AllWallets() {
+		accts, err := wall.GetAccountsRaw()
+		if err != nil {
+			return nil, fmt.Errorf("wallet %d: %v", wall.ID, err)
+		}
+		accounts = append(accounts, accts.Acc...)
+	}
+	return accounts, nil
+}
+
+// SendToAddress sends atoms from the wallet account to the address and
+// returns the hash of the transaction. With sendMax the whole spendable
+// balance of the account is sent, less the fee.
+func (wal *Wallet) SendToAddress(walletID int, account int32, address string, atoms int64, sendMax bool, passphrase []byte) (string, error) {
+	if !wal.multi.IsAddressValid(address) {
+		return "", fmt.Errorf("invalid address %q", address)
+	}
+	txAuthor, err := wal.multi.NewUnsignedTx(walletID, account)
+	if err != nil {
+		return "", err
+	}
+	if err := txAuthor.AddSendDestination(address, atoms, sendMax); err != nil {
+		return "", err
+	}
+	hash, err := txAuthor.Broadcast(passphrase)
+	if err != nil {
+		return "", err
+	}
+	txHash, err := chainhash.NewHash(hash)
+	if err != nil {
+		return "", err
+	}
+	return txHash.String(), nil
+}
+
+// Transactions returns the transactions of the wallet, the most recent
+// first.
+func (wal *Wallet) Transactions(walletID int, offset, limit int32) ([]dcrlibwallet.Transaction, error) {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return nil, ErrIDNotExist
+	}
+	return wall.GetTransactionsRaw(offset, limit, dcrlibwallet.TxFilterAll, true)
+}
+
 // CurrentAddress returns the next address for the specified wallet account.
 func (wal *Wallet) CurrentAddress(walletID int, accountID int32) (string, error) {
 	wall := wal.multi.WalletWithID(walletID)
@@ -80,9 +143,30 @@ func (wal *Wallet) VerifyMessage(address string, message string, signature strin
 
 // StartSync starts the multiwallet SPV sync
 func (wal *Wallet) StartSync() error {
+	if err := wal.CheckConnection(ProxySubsystemSync); err != nil {
+		return err
+	}
 	return wal.multi.SpvSync()
 }
 
+// ReloadVSPList fetches the list of VSPs unless the VSP list is turned off
+// or the proxy blocks direct connections.
+func (wal *Wallet) ReloadVSPList(ctx context.Context) {
+	if err := wal.CheckConnection(ProxySubsystemVSPList); err != nil {
+		log.Info(err)
+		return
+	}
+	wal.multi.ReloadVSPList(ctx)
+}
+
+// SaveVSP fetches the info of the VSP at host and adds it to the known VSPs.
+func (wal *Wallet) SaveVSP(host string) error {
+	if err := wal.CheckConnection(ProxySubsystemVSP); err != nil {
+		return err
+	}
+	return wal.multi.SaveVSP(host)
+}
+
 // RescanBlocks rescans the multiwallet
 func (wal *Wallet) RescanBlocks(walletID int) error {
 	return wal.multi.RescanBlocks(walletID)
@@ -0,0 +1,140 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// scrypt parameters used to derive the secretbox key from an unlock PIN.
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+
+	saltSize  = 16
+	nonceSize = 24
+	keySize   = 32
+)
+
+var (
+	// ErrBadPIN is returned when a stored credential cannot be opened with
+	// the provided unlock PIN.
+	ErrBadPIN = errors.New("invalid unlock PIN")
+
+	// ErrNoCredential is returned when no credential is stored for a key.
+	ErrNoCredential = errors.New("no stored credential")
+)
+
+// SealCredential encrypts secret with a key derived from pin. The returned
+// value contains the scrypt salt and secretbox nonce needed to open it.
+func SealCredential(pin, secret []byte) ([]byte, error) {
+	var salt [saltSize]byte
+	if _, err := io.ReadFull(rand.Reader, salt[:]); err != nil {
+		return nil, err
+	}
+
+	var nonce [nonceSize]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return nil, err
+	}
+
+	key, err := deriveCredentialKey(pin, salt[:])
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := make([]byte, 0, saltSize+nonceSize+len(secret)+secretbox.Overhead)
+	sealed = append(sealed, salt[:]...)
+	sealed = append(sealed, nonce[:]...)
+	return secretbox.Seal(sealed, secret, &nonce, key), nil
+}
+
+// OpenCredential decrypts a value previously returned by SealCredential.
+func OpenCredential(pin, sealed []byte) ([]byte, error) {
+	if len(sealed) < saltSize+nonceSize+secretbox.Overhead {
+		return nil, ErrNoCredential
+	}
+
+	key, err := deriveCredentialKey(pin, sealed[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+
+	var nonce [nonceSize]byte
+	copy(nonce[:], sealed[saltSize:saltSize+nonceSize])
+
+	secret, ok := secretbox.Open(nil, sealed[saltSize+nonceSize:], &nonce, key)
+	if !ok {
+		return nil, ErrBadPIN
+	}
+	return secret, nil
+}
+
+func deriveCredentialKey(pin, salt []byte) (*[keySize]byte, error) {
+	k, err := scrypt.Key(pin, salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, err
+	}
+
+	var key [keySize]byte
+	copy(key[:], k)
+	return &key, nil
+}
+
+// StoreCredential encrypts the spending passphrase of the wallet with the
+// given unlock PIN and saves it in the wallet config under key.
+func (wal *Wallet) StoreCredential(walletID int, key string, pin, passphrase []byte) error {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return ErrIDNotExist
+	}
+
+	sealed, err := SealCredential(pin, passphrase)
+	if err != nil {
+		return err
+	}
+
+	wall.SaveUserConfigValue(key, sealed)
+	return nil
+}
+
+// ReadCredential returns the spending passphrase stored for the wallet under
+// key, decrypted with the given unlock PIN.
+func (wal *Wallet) ReadCredential(walletID int, key string, pin []byte) ([]byte, error) {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return nil, ErrIDNotExist
+	}
+
+	var sealed []byte
+	if err := wall.ReadUserConfigValue(key, &sealed); err != nil || len(sealed) == 0 {
+		return nil, ErrNoCredential
+	}
+
+	return OpenCredential(pin, sealed)
+}
+
+// HasCredential checks if a credential is stored for the wallet under key.
+func (wal *Wallet) HasCredential(walletID int, key string) bool {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return false
+	}
+
+	var sealed []byte
+	err := wall.ReadUserConfigValue(key, &sealed)
+	return err == nil && len(sealed) > 0
+}
+
+// DeleteCredential removes the credential stored for the wallet under key.
+func (wal *Wallet) DeleteCredential(walletID int, key string) {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return
+	}
+	wall.SaveUserConfigValue(key, []byte{})
+}
@@ -0,0 +1,64 @@
+package wallet_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/planetdecred/godcr/wallet"
+)
+
+var _ = Describe("Credentials", func() {
+	pin := []byte("1234")
+	passphrase := []byte("spending passphrase")
+
+	It("opens what it seals", func() {
+		sealed, err := wallet.SealCredential(pin, passphrase)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sealed).ToNot(ContainSubstring(string(passphrase)))
+
+		secret, err := wallet.OpenCredential(pin, sealed)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(secret).To(Equal(passphrase))
+	})
+
+	It("seals with a new salt and nonce each time", func() {
+		first, err := wallet.SealCredential(pin, passphrase)
+		Expect(err).ToNot(HaveOccurred())
+		second, err := wallet.SealCredential(pin, passphrase)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(first).ToNot(Equal(second))
+	})
+
+	It("rejects a wrong PIN", func() {
+		sealed, err := wallet.SealCredential(pin, passphrase)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = wallet.OpenCredential([]byte("4321"), sealed)
+		Expect(err).To(Equal(wallet.ErrBadPIN))
+	})
+
+	It("rejects a truncated credential", func() {
+		sealed, err := wallet.SealCredential(pin, passphrase)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = wallet.OpenCredential(pin, sealed[:len(sealed)-1])
+		Expect(err).To(Equal(wallet.ErrBadPIN))
+
+		_, err = wallet.OpenCredential(pin, sealed[:40])
+		Expect(err).To(Equal(wallet.ErrNoCredential))
+
+		_, err = wallet.OpenCredential(pin, nil)
+		Expect(err).To(Equal(wallet.ErrNoCredential))
+	})
+
+	It("rejects a corrupt credential", func() {
+		for _, i := range []int{0, 16, 40, 60} {
+			sealed, err := wallet.SealCredential(pin, passphrase)
+			Expect(err).ToNot(HaveOccurred())
+
+			sealed[i] ^= 0xff
+			_, err = wallet.OpenCredential(pin, sealed)
+			Expect(err).To(Equal(wallet.ErrBadPIN), "byte %d", i)
+		}
+	})
+})
@@ -0,0 +1,313 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrDataDirNotEmpty is returned when the wallet data is moved to a
+	// directory that has files in it.
+	ErrDataDirNotEmpty = errors.New("the destination directory is not empty")
+	// ErrDataDirInside is returned when the wallet data is moved to a
+	// directory inside the app directory or the other way around.
+	ErrDataDirInside = errors.New("the destination directory cannot be inside the app directory or contain it")
+	// ErrWalletsClosed is returned when a move of the wallet data failed
+	// and the wallets could not be opened again.
+	ErrWalletsClosed = errors.New("the wallets could not be opened again")
+)
+
+// MoveProgress is the progress of a move of the wallet data, the files are
+// copied and then read again to verify them.
+type MoveProgress struct {
+	Verifying  bool
+	Files      int
+	TotalFiles int
+	Bytes      int64
+	TotalBytes int64
+}
+
+// dataFile is a file of the app directory with the SHA-256 of its content.
+type dataFile struct {
+	path string
+	mode os.FileMode
+	size int64
+	hash []byte
+}
+
+// SetProfile sets the name of the profile of the app directory, empty if the
+// app directory was set on the command line.
+func (wal *Wallet) SetProfile(name string) {
+	wal.profile = name
+}
+
+// Profile returns the name of the profile of the app directory.
+func (wal *Wallet) Profile() string {
+	return wal.profile
+}
+
+// MoveDataDir shuts the wallets down and copies the app directory to dst,
+// without the skipped paths relative to it, such as the log directory and
+// the app directories of other profiles inside it. The copied files are read again and compared to the SHA-256 of the originals,
+// the copy is removed if they differ. The destination and the app directory
+// are checked before the wallets are shut down. If the copy fails the
+// multiwallet is opened again from the app directory, the returned error
+// wraps ErrWalletsClosed if that fails too. On success the wallets are not
+// opened again, the app must be restarted on the new directory and the old
+// files are only removed by RemoveMovedData.
+func (wal *Wallet) MoveDataDir(dst string, skip []string, progress func(MoveProgress)) error {
+	if err := CheckDataDirDestination(wal.Root, dst); err != nil {
+		return err
+	}
+	if _, err := listDataFiles(wal.Root, skip); err != nil {
+		return err
+	}
+
+	wal.Shutdown()
+
+	if err := CopyDataDir(wal.Root, dst, skip, progress); err != nil {
+		wal.shutdownOnce = sync.Once{}
+		if reopenErr := wal.InitMultiWallet(); reopenErr != nil {
+			log.Errorf("Error opening the wallets again after a failed move: %v", reopenErr)
+			return fmt.Errorf("%v; %w: %v", err, ErrWalletsClosed, reopenErr)
+		}
+		return err
+	}
+	log.Infof("Wallet data copied from %s to %s", wal.Root, dst)
+	return nil
+}
+
+// RemoveMovedData removes the copied entries from the app directory, the
+// skipped paths and the directories containing them are kept. It is called
+// once the profile points at the new directory.
+func (wal *Wallet) RemoveMovedData(skip []string) error {
+	if err := removeDataEntries(wal.Root, ".", skippedPaths(skip)); err != nil {
+		return err
+	}
+	log.Infof("Moved wallet data removed from %s", wal.Root)
+	return nil
+}
+
+// CheckDataDirDestination returns an error if the app directory src cannot
+// be copied to dst, which must not be inside src and must be empty if it
+// exists.
+func CheckDataDirDestination(src, dst string) error {
+	if !filepath.IsAbs(dst) {
+		return fmt.Errorf("the destination directory %s is not an absolute path", dst)
+	}
+	src, dst = filepath.Clean(src), filepath.Clean(dst)
+	if isInside(src, dst) || isInside(dst, src) {
+		return ErrDataDirInside
+	}
+
+	entries, err := os.ReadDir(dst)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		return ErrDataDirNotEmpty
+	}
+	return nil
+}
+
+// isInside returns true if path is dir or is inside it.
+func isInside(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// CopyDataDir copies the app directory src to dst without the skipped paths
+// and verifies the copy. dst is removed if the copy fails, unless it existed
+// before.
+func CopyDataDir(src, dst string, skip []string, progress func(MoveProgress)) (err error) {
+	if err := CheckDataDirDestination(src, dst); err != nil {
+		return err
+	}
+	if progress == nil {
+		progress = func(MoveProgress) {}
+	}
+
+	files, err := listDataFiles(src, skip)
+	if err != nil {
+		return err
+	}
+	var p MoveProgress
+	p.TotalFiles = len(files)
+	for _, f := range files {
+		p.TotalBytes += f.size
+	}
+
+	_, statErr := os.Stat(dst)
+	created := os.IsNotExist(statErr)
+	if err := os.MkdirAll(dst, 0700); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil && created {
+			os.RemoveAll(dst)
+		}
+	}()
+
+	for _, f := range files {
+		f.hash, err = copyDataFile(filepath.Join(src, f.path), filepath.Join(dst, f.path), f.mode)
+		if err != nil {
+			return fmt.Errorf("error copying %s: %v", f.path, err)
+		}
+		p.Files++
+		p.Bytes += f.size
+		progress(p)
+	}
+
+	p.Verifying, p.Files, p.Bytes = true, 0, 0
+	for _, f := range files {
+		hash, size, err := hashFile(filepath.Join(dst, f.path))
+		if err != nil {
+			return fmt.Errorf("error verifying %s: %v", f.path, err)
+		}
+		if size != f.size || !bytes.Equal(hash, f.hash) {
+			return fmt.Errorf("the copy of %s differs from the original", f.path)
+		}
+		p.Files++
+		p.Bytes += f.size
+		progress(p)
+	}
+	return nil
+}
+
+// skippedPaths returns the set of the skipped paths, relative to the app
+// directory.
+func skippedPaths(skip []string) map[string]bool {
+	skipped := make(map[string]bool, len(skip))
+	for _, path := range skip {
+		skipped[filepath.Clean(path)] = true
+	}
+	return skipped
+}
+
+// containsSkipped returns true if a skipped path is inside the directory
+// rel of the app directory.
+func containsSkipped(rel string, skipped map[string]bool) bool {
+	for path := range skipped {
+		if isInside(rel, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// listDataFiles lists the files of the app directory, with their path
+// relative to it, without the skipped paths.
+func listDataFiles(dir string, skip []string) ([]*dataFile, error) {
+	skipped := skippedPaths(skip)
+	var files []*dataFile
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if skipped[rel] {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			if info.IsDir() {
+				return nil
+			}
+			return fmt.Errorf("%s is not a regular file", path)
+		}
+		files = append(files, &dataFile{path: rel, mode: info.Mode().Perm(), size: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// removeDataEntries removes the entries of the directory rel of the app
+// directory root, without the skipped paths. The directories containing a
+// skipped path are emptied instead of removed.
+func removeDataEntries(root, rel string, skipped map[string]bool) error {
+	entries, err := os.ReadDir(filepath.Join(root, rel))
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		path := filepath.Join(rel, entry.Name())
+		switch {
+		case skipped[path]:
+		case entry.IsDir() && containsSkipped(path, skipped):
+			if err := removeDataEntries(root, path, skipped); err != nil {
+				return err
+			}
+		default:
+			if err := os.RemoveAll(filepath.Join(root, path)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// copyDataFile copies the file src to dst and returns the SHA-256 of the
+// data read from src.
+func copyDataFile(src, dst string, mode os.FileMode) ([]byte, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return nil, err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(out, io.TeeReader(in, h)); err != nil {
+		out.Close()
+		return nil, err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return nil, err
+	}
+	if err := out.Close(); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// hashFile returns the SHA-256 and the size of the file.
+func hashFile(path string) ([]byte, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return nil, 0, err
+	}
+	return h.Sum(nil), size, nil
+}
@@ -0,0 +1,100 @@
+package wallet_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/planetdecred/dcrlibwallet"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+var _ = Describe("Data directory", func() {
+	var src, dst string
+
+	BeforeEach(func() {
+		tmp, err := os.MkdirTemp("", "godcr-datadir")
+		Expect(err).ToNot(HaveOccurred())
+		src, dst = filepath.Join(tmp, "src"), filepath.Join(tmp, "dst")
+
+		Expect(os.MkdirAll(filepath.Join(src, "testnet3", "1"), 0700)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(src, "logs"), 0700)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(src, "godcr.conf"), []byte("network=testnet\n"), 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(src, "testnet3", "1", "wallet.db"), []byte("wallet data"), 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(src, "logs", "godcr.log"), []byte("log"), 0600)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(filepath.Dir(src))
+	})
+
+	It("copies and verifies the app directory without the skipped entries", func() {
+		var last wallet.MoveProgress
+		err := wallet.CopyDataDir(src, dst, []string{"logs"}, func(p wallet.MoveProgress) {
+			last = p
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(last.Verifying).To(BeTrue())
+		Expect(last.Files).To(Equal(2))
+		Expect(last.TotalBytes).To(Equal(int64(len("network=testnet\n") + len("wallet data"))))
+
+		data, err := os.ReadFile(filepath.Join(dst, "testnet3", "1", "wallet.db"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("wallet data"))
+		Expect(filepath.Join(dst, "logs")).ToNot(BeADirectory())
+	})
+
+	It("skips the app directories of other profiles inside the app directory", func() {
+		other := filepath.Join(src, "profiles", "other")
+		Expect(os.MkdirAll(filepath.Join(other, "testnet3"), 0700)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(other, "godcr.conf"), []byte("other"), 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(src, "profiles", "notes"), []byte("notes"), 0600)).To(Succeed())
+		skip := []string{"logs", filepath.Join("profiles", "other")}
+
+		wal, err := wallet.NewWallet(src, dcrlibwallet.Testnet3, "dev", "", time.Now())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(wal.InitMultiWallet()).To(Succeed())
+		Expect(wal.MoveDataDir(dst, skip, nil)).To(Succeed())
+		Expect(filepath.Join(dst, "profiles", "notes")).To(BeARegularFile())
+		Expect(filepath.Join(dst, "profiles", "other")).ToNot(BeADirectory())
+
+		Expect(wal.RemoveMovedData(skip)).To(Succeed())
+		Expect(filepath.Join(other, "godcr.conf")).To(BeARegularFile())
+		Expect(filepath.Join(other, "testnet3")).To(BeADirectory())
+		Expect(filepath.Join(src, "profiles", "notes")).ToNot(BeAnExistingFile())
+		Expect(filepath.Join(src, "godcr.conf")).ToNot(BeAnExistingFile())
+		Expect(filepath.Join(src, "logs")).To(BeADirectory())
+	})
+
+	It("rejects a destination that is not empty", func() {
+		Expect(os.MkdirAll(dst, 0700)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dst, "file"), nil, 0600)).To(Succeed())
+		Expect(wallet.CopyDataDir(src, dst, nil, nil)).To(Equal(wallet.ErrDataDirNotEmpty))
+	})
+
+	It("keeps the app directory until the moved data is removed", func() {
+		wal, err := wallet.NewWallet(src, dcrlibwallet.Testnet3, "dev", "", time.Now())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(wal.InitMultiWallet()).To(Succeed())
+
+		Expect(wal.MoveDataDir(dst, []string{"logs"}, nil)).To(Succeed())
+		Expect(filepath.Join(src, "godcr.conf")).To(BeARegularFile())
+		Expect(filepath.Join(dst, "godcr.conf")).To(BeARegularFile())
+
+		Expect(wal.RemoveMovedData([]string{"logs"})).To(Succeed())
+		entries, err := os.ReadDir(src)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Name()).To(Equal("logs"))
+	})
+
+	It("rejects a destination inside the app directory", func() {
+		Expect(wallet.CheckDataDirDestination(src, filepath.Join(src, "moved"))).To(Equal(wallet.ErrDataDirInside))
+		Expect(wallet.CheckDataDirDestination(src, filepath.Dir(src))).To(Equal(wallet.ErrDataDirInside))
+		Expect(wallet.CheckDataDirDestination(src, "relative")).To(HaveOccurred())
+		Expect(wallet.CheckDataDirDestination(src, dst)).To(Succeed())
+	})
+})
@@ -0,0 +1,91 @@
+package wallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"decred.org/dcrdex/client/core"
+)
+
+// dexServersConfigKey is the multiwallet config key under which the details
+// of the registered DEX servers are stored.
+const dexServersConfigKey = "dex_servers"
+
+// DexServer holds the details of a registered DEX server that the dcrdex core
+// does not report, i.e. the TLS certificate used to connect to it and the
+// asset the registration fee was paid with.
+type DexServer struct {
+	Host     string `json:"host"`
+	Cert     []byte `json:"cert,omitempty"`
+	FeeAsset string `json:"feeAsset,omitempty"`
+}
+
+// DexServers returns the saved DEX server details keyed by host.
+func (wal *Wallet) DexServers() map[string]*DexServer {
+	servers := make(map[string]*DexServer)
+	_ = wal.multi.ReadUserConfigValue(dexServersConfigKey, &servers)
+	return servers
+}
+
+// SaveDexServer saves the details of a DEX server, replacing any previously
+// saved details for the same host.
+func (wal *Wallet) SaveDexServer(server *DexServer) {
+	servers := wal.DexServers()
+	servers[server.Host] = server
+	wal.multi.SaveUserConfigValue(dexServersConfigKey, servers)
+}
+
+// RemoveDexServer deletes the saved details of a DEX server.
+func (wal *Wallet) RemoveDexServer(host string) {
+	servers := wal.DexServers()
+	delete(servers, host)
+	wal.multi.SaveUserConfigValue(dexServersConfigKey, servers)
+}
+
+// ExportDexAccount writes the DEX account to a JSON file in the exports
+// directory and returns the path of the file. The file holds the account
+// private key and should be kept safe.
+func (wal *Wallet) ExportDexAccount(acct *core.Account) (string, error) {
+	dir := filepath.Join(wal.Root, "exports")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(acct, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	host := strings.NewReplacer(":", "_", "/", "_").Replace(acct.Host)
+	path := filepath.Join(dir, fmt.Sprintf("dex-account-%s-%s.json", host, time.Now().Format("20060102-150405")))
+	if err = os.WriteFile(path, data, 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// ReadDexAccount parses a DEX account exported by ExportDexAccount. The input
+// is either the JSON of the account or the path of the exported file.
+func ReadDexAccount(input string) (*core.Account, error) {
+	data := []byte(strings.TrimSpace(input))
+	if len(data) > 0 && data[0] != '{' {
+		var err error
+		data, err = os.ReadFile(string(data))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	acct := new(core.Account)
+	if err := json.Unmarshal(data, acct); err != nil {
+		return nil, fmt.Errorf("invalid account backup: %v", err)
+	}
+	if acct.Host == "" || acct.PrivKey == "" {
+		return nil, fmt.Errorf("invalid account backup: missing host or key")
+	}
+	return acct, nil
+}
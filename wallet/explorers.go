@@ -0,0 +1,208 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/planetdecred/dcrlibwallet"
+)
+
+const blockExplorersConfigKey = "block_explorers"
+
+// The kinds of explorer pages, with the placeholder of their URL templates.
+const (
+	ExplorerTransaction = "transaction"
+	ExplorerAddress     = "address"
+	ExplorerBlock       = "block"
+	ExplorerTicket      = "ticket"
+)
+
+// explorerPlaceholders are replaced by the transaction hash, address, block
+// height or hash and ticket hash in the URL templates.
+var explorerPlaceholders = map[string]string{
+	ExplorerTransaction: explorerHashPlaceholder,
+	ExplorerAddress:     "{address}",
+	ExplorerBlock:       "{block}",
+	ExplorerTicket:      explorerHashPlaceholder,
+}
+
+var (
+	// ErrNoExplorerName is returned when a block explorer has no name.
+	ErrNoExplorerName = errors.New("enter a name for the block explorer")
+	// ErrNoExplorerURL is returned when a block explorer has no URL
+	// template.
+	ErrNoExplorerURL = errors.New("enter at least one URL of the block explorer")
+)
+
+// BlockExplorer is a block explorer with the URL templates of its pages,
+// an empty template hides the page of that kind. The explorers are saved
+// for the network of the wallets.
+type BlockExplorer struct {
+	Name       string `json:"name"`
+	TxURL      string `json:"txurl"`
+	AddressURL string `json:"addressurl"`
+	BlockURL   string `json:"blockurl"`
+	TicketURL  string `json:"ticketurl"`
+}
+
+// Template returns the URL template of the kind of page.
+func (e BlockExplorer) Template(kind string) string {
+	switch kind {
+	case ExplorerTransaction:
+		return e.TxURL
+	case ExplorerAddress:
+		return e.AddressURL
+	case ExplorerBlock:
+		return e.BlockURL
+	case ExplorerTicket:
+		return e.TicketURL
+	}
+	return ""
+}
+
+// URL returns the URL of the page of the transaction hash, address, block
+// or ticket hash, empty if the explorer has no page of that kind.
+func (e BlockExplorer) URL(kind, id string) string {
+	template := e.Template(kind)
+	if template == "" {
+		return ""
+	}
+	return strings.Replace(template, explorerPlaceholders[kind], url.PathEscape(id), -1)
+}
+
+// Validate checks the name and URL templates of the explorer.
+func (e BlockExplorer) Validate() error {
+	if strings.TrimSpace(e.Name) == "" {
+		return ErrNoExplorerName
+	}
+
+	hasURL := false
+	for _, kind := range []string{ExplorerTransaction, ExplorerAddress, ExplorerBlock, ExplorerTicket} {
+		template := e.Template(kind)
+		if template == "" {
+			continue
+		}
+		hasURL = true
+
+		placeholder := explorerPlaceholders[kind]
+		if !strings.Contains(template, placeholder) {
+			return fmt.Errorf("the %s URL must contain %s", kind, placeholder)
+		}
+		u, err := url.Parse(strings.Replace(template, placeholder, "x", -1))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("the %s URL is not a valid http or https URL", kind)
+		}
+	}
+	if !hasURL {
+		return ErrNoExplorerURL
+	}
+	return nil
+}
+
+// DefaultBlockExplorers returns the public explorers of the network, there
+// are none for the local networks.
+func DefaultBlockExplorers(net string) []BlockExplorer {
+	var host string
+	switch net {
+	case dcrlibwallet.Mainnet:
+		host = "https://explorer.dcrdata.org"
+	case dcrlibwallet.Testnet3:
+		host = "https://testnet.dcrdata.org"
+	default:
+		return nil
+	}
+	return []BlockExplorer{{
+		Name:       "dcrdata",
+		TxURL:      host + "/tx/{hash}",
+		AddressURL: host + "/address/{address}",
+		BlockURL:   host + "/block/{block}",
+		TicketURL:  host + "/tx/{hash}",
+	}}
+}
+
+// SavedBlockExplorers returns the explorers set in the settings, the
+// default explorers of the network if none were saved.
+func (wal *Wallet) SavedBlockExplorers() []BlockExplorer {
+	var explorers []BlockExplorer
+	if wal.multi == nil || wal.multi.ReadUserConfigValue(blockExplorersConfigKey, &explorers) != nil || explorers == nil {
+		return DefaultBlockExplorers(wal.Net)
+	}
+	return explorers
+}
+
+// BlockExplorers returns the explorers to open the pages on, with the one
+// set from the command line first. It is empty when the block explorer is
+// turned off in the privacy settings.
+func (wal *Wallet) BlockExplorers() []BlockExplorer {
+	if wal.FeatureDisabled(ProxySubsystemExplorer) {
+		return nil
+	}
+	var explorers []BlockExplorer
+	if wal.explorerURLOverride != "" {
+		explorers = append(explorers, BlockExplorer{Name: "--explorerurl", TxURL: wal.explorerURLOverride})
+	}
+	return append(explorers, wal.SavedBlockExplorers()...)
+}
+
+// BlockExplorersFor returns the explorers that have a page of the kind.
+func (wal *Wallet) BlockExplorersFor(kind string) []BlockExplorer {
+	var explorers []BlockExplorer
+	for _, e := range wal.BlockExplorers() {
+		if e.Template(kind) != "" {
+			explorers = append(explorers, e)
+		}
+	}
+	return explorers
+}
+
+// SaveBlockExplorer validates the explorer and saves it, replacing the
+// explorer named oldName. An empty oldName adds the explorer.
+func (wal *Wallet) SaveBlockExplorer(oldName string, explorer BlockExplorer) error {
+	explorer.Name = strings.TrimSpace(explorer.Name)
+	explorer.TxURL = strings.TrimSpace(explorer.TxURL)
+	explorer.AddressURL = strings.TrimSpace(explorer.AddressURL)
+	explorer.BlockURL = strings.TrimSpace(explorer.BlockURL)
+	explorer.TicketURL = strings.TrimSpace(explorer.TicketURL)
+	if err := explorer.Validate(); err != nil {
+		return err
+	}
+
+	explorers := wal.SavedBlockExplorers()
+	saved := make([]BlockExplorer, 0, len(explorers)+1)
+	replaced := false
+	for _, e := range explorers {
+		switch {
+		case oldName != "" && e.Name == oldName:
+			saved = append(saved, explorer)
+			replaced = true
+		case e.Name == explorer.Name:
+			return fmt.Errorf("there is already a block explorer named %s", explorer.Name)
+		default:
+			saved = append(saved, e)
+		}
+	}
+	if !replaced {
+		saved = append(saved, explorer)
+	}
+	wal.multi.SaveUserConfigValue(blockExplorersConfigKey, saved)
+	return nil
+}
+
+// RemoveBlockExplorer removes the explorer named name.
+func (wal *Wallet) RemoveBlockExplorer(name string) {
+	explorers := wal.SavedBlockExplorers()
+	saved := make([]BlockExplorer, 0, len(explorers))
+	for _, e := range explorers {
+		if e.Name != name {
+			saved = append(saved, e)
+		}
+	}
+	wal.multi.SaveUserConfigValue(blockExplorersConfigKey, saved)
+}
+
+// ResetBlockExplorers restores the default explorers of the network.
+func (wal *Wallet) ResetBlockExplorers() {
+	wal.multi.DeleteUserConfigValueForKey(blockExplorersConfigKey)
+}
@@ -0,0 +1,66 @@
+package wallet_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/planetdecred/dcrlibwallet"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+var _ = Describe("BlockExplorer", func() {
+	explorer := wallet.BlockExplorer{
+		Name:       "local",
+		TxURL:      "http://127.0.0.1:17779/tx/{hash}",
+		AddressURL: "http://127.0.0.1:17779/address/{address}",
+		BlockURL:   "http://127.0.0.1:17779/block/{block}",
+	}
+
+	It("fills in the URL templates", func() {
+		Expect(explorer.URL(wallet.ExplorerTransaction, "abcd")).To(Equal("http://127.0.0.1:17779/tx/abcd"))
+		Expect(explorer.URL(wallet.ExplorerAddress, "Ssabcd")).To(Equal("http://127.0.0.1:17779/address/Ssabcd"))
+		Expect(explorer.URL(wallet.ExplorerBlock, "42")).To(Equal("http://127.0.0.1:17779/block/42"))
+		Expect(explorer.URL(wallet.ExplorerTicket, "abcd")).To(BeEmpty())
+	})
+
+	It("validates the name and URL templates", func() {
+		Expect(explorer.Validate()).To(Succeed())
+
+		noName := explorer
+		noName.Name = " "
+		Expect(noName.Validate()).To(Equal(wallet.ErrNoExplorerName))
+
+		Expect(wallet.BlockExplorer{Name: "empty"}.Validate()).To(Equal(wallet.ErrNoExplorerURL))
+
+		noPlaceholder := explorer
+		noPlaceholder.BlockURL = "http://127.0.0.1:17779/block/{hash}"
+		Expect(noPlaceholder.Validate()).To(HaveOccurred())
+
+		notHTTP := explorer
+		notHTTP.TxURL = "file:///tmp/{hash}"
+		Expect(notHTTP.Validate()).To(HaveOccurred())
+	})
+
+	It("has dcrdata as the default explorer of the public networks", func() {
+		mainnet := wallet.DefaultBlockExplorers(dcrlibwallet.Mainnet)
+		Expect(mainnet).To(HaveLen(1))
+		Expect(mainnet[0].Validate()).To(Succeed())
+		Expect(mainnet[0].URL(wallet.ExplorerTransaction, "abcd")).To(Equal("https://explorer.dcrdata.org/tx/abcd"))
+
+		Expect(wallet.DefaultBlockExplorers(dcrlibwallet.Testnet3)).To(HaveLen(1))
+		Expect(wallet.DefaultBlockExplorers(wallet.Simnet)).To(BeEmpty())
+	})
+
+	It("opens the transactions on the explorer set from the command line first", func() {
+		wal, err := wallet.NewWallet("/tmp/godcr", dcrlibwallet.Testnet3, "dev", "", time.Now())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(wal.SetExplorerURLOverride("http://127.0.0.1:17779/tx/{hash}")).To(Succeed())
+
+		explorers := wal.BlockExplorersFor(wallet.ExplorerTransaction)
+		Expect(explorers).To(HaveLen(2))
+		Expect(explorers[0].TxURL).To(Equal("http://127.0.0.1:17779/tx/{hash}"))
+		Expect(wal.BlockExplorersFor(wallet.ExplorerAddress)).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,323 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// MixerScheduleManual runs the mixer only when started by the user. A
+	// mixer that was running when the app closed is resumed on restart.
+	MixerScheduleManual = "manual"
+	// MixerScheduleAlways runs the mixer whenever the wallet is synced.
+	MixerScheduleAlways = "always"
+	// MixerScheduleWindow runs the mixer between the start and end times
+	// of the schedule.
+	MixerScheduleWindow = "window"
+
+	// MixerCredentialConfigKey is the wallet config key under which the
+	// spending passphrase used by the scheduled mixer is stored, encrypted
+	// with the startup password.
+	MixerCredentialConfigKey = "account_mixer_credential"
+
+	mixerScheduleConfigKey = "account_mixer_schedule"
+	mixerRunStateConfigKey = "account_mixer_running"
+
+	// mixerScheduleTimeLayout is the layout of the start and end times of
+	// mixer schedules.
+	mixerScheduleTimeLayout = "15:04"
+
+	// mixerRetryInterval is the time to wait before starting a mixer again
+	// after it failed.
+	mixerRetryInterval = 10 * time.Minute
+)
+
+// ErrNoStartupPassword is returned when enabling the mixer auto-start
+// without a startup password to encrypt the spending passphrase with.
+var ErrNoStartupPassword = errors.New("a startup password is required to start the mixer automatically")
+
+// ErrWalletUnlocked is returned when the spending passphrase of a wallet
+// cannot be checked because the wallet is unlocked, a wrong passphrase would
+// lock it while it is mixing or buying tickets.
+var ErrWalletUnlocked = errors.New("stop the mixer and the ticket buyer of the wallet to check its spending password")
+
+// MixerSchedule is the time the account mixer of a wallet should run.
+type MixerSchedule struct {
+	Mode string
+	// Start and End are the times of the day, formatted as "15:04", between
+	// which the mixer runs for the MixerScheduleWindow mode. The window
+	// spans midnight if End is before Start.
+	Start string
+	End   string
+}
+
+// Validate checks that the schedule can be used.
+func (s *MixerSchedule) Validate() error {
+	switch s.Mode {
+	case MixerScheduleManual, MixerScheduleAlways:
+		return nil
+	case MixerScheduleWindow:
+		start, err := time.Parse(mixerScheduleTimeLayout, s.Start)
+		if err != nil {
+			return fmt.Errorf("invalid start time %q, use HH:MM", s.Start)
+		}
+		end, err := time.Parse(mixerScheduleTimeLayout, s.End)
+		if err != nil {
+			return fmt.Errorf("invalid end time %q, use HH:MM", s.End)
+		}
+		if start.Equal(end) {
+			return errors.New("start and end times must differ")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown mixer schedule %q", s.Mode)
+	}
+}
+
+// Includes checks if t is within the mixing window of the schedule. It is
+// always false for the manual schedule.
+func (s *MixerSchedule) Includes(t time.Time) bool {
+	switch s.Mode {
+	case MixerScheduleAlways:
+		return true
+	case MixerScheduleWindow:
+		start, err1 := time.Parse(mixerScheduleTimeLayout, s.Start)
+		end, err2 := time.Parse(mixerScheduleTimeLayout, s.End)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+
+		now := t.Hour()*60 + t.Minute()
+		from := start.Hour()*60 + start.Minute()
+		to := end.Hour()*60 + end.Minute()
+		if from < to {
+			return now >= from && now < to
+		}
+		return now >= from || now < to
+	default:
+		return false
+	}
+}
+
+// String describes the schedule.
+func (s *MixerSchedule) String() string {
+	switch s.Mode {
+	case MixerScheduleAlways:
+		return "Always while synced"
+	case MixerScheduleWindow:
+		return fmt.Sprintf("Between %s and %s", s.Start, s.End)
+	default:
+		return "Manual"
+	}
+}
+
+// MixerSchedule returns the mixer schedule of the wallet.
+func (wal *Wallet) MixerSchedule(walletID int) *MixerSchedule {
+	schedule := &MixerSchedule{Mode: MixerScheduleManual}
+	wall := wal.multi.WalletWithID(walletID)
+	if wall != nil {
+		_ = wall.ReadUserConfigValue(mixerScheduleConfigKey, schedule)
+	}
+	return schedule
+}
+
+// SetMixerSchedule saves the mixer schedule of the wallet.
+func (wal *Wallet) SetMixerSchedule(walletID int, schedule *MixerSchedule) error {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return ErrIDNotExist
+	}
+
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+	wall.SaveUserConfigValue(mixerScheduleConfigKey, schedule)
+	return nil
+}
+
+// SetMixerRunState records whether the user left the mixer of the wallet
+// running, so that it can be resumed after a restart.
+func (wal *Wallet) SetMixerRunState(walletID int, running bool) {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return
+	}
+	wall.SetBoolConfigValueForKey(mixerRunStateConfigKey, running)
+}
+
+// MixerRunState checks if the user left the mixer of the wallet running.
+func (wal *Wallet) MixerRunState(walletID int) bool {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return false
+	}
+	return wall.ReadBoolConfigValueForKey(mixerRunStateConfigKey, false)
+}
+
+// EnableMixerAutoStart stores the spending passphrase of the wallet,
+// encrypted with the startup password, so that the mixer can be started on
+// schedule without prompting for it.
+func (wal *Wallet) EnableMixerAutoStart(walletID int, startupPassphrase, spendingPassphrase []byte) error {
+	if !wal.multi.IsStartupSecuritySet() {
+		return ErrNoStartupPassword
+	}
+
+	if err := wal.multi.VerifyStartupPassphrase(startupPassphrase); err != nil {
+		return err
+	}
+
+	// Check the spending passphrase so that the scheduled runs don't fail.
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return ErrIDNotExist
+	}
+	if !wall.IsLocked() {
+		return ErrWalletUnlocked
+	}
+	if err := wall.UnlockWallet(spendingPassphrase); err != nil {
+		return err
+	}
+	wall.LockWallet()
+
+	err := wal.StoreCredential(walletID, MixerCredentialConfigKey, startupPassphrase, spendingPassphrase)
+	if err != nil {
+		return err
+	}
+
+	wal.mixerMu.Lock()
+	defer wal.mixerMu.Unlock()
+	if wal.mixerPassphrases == nil {
+		wal.mixerPassphrases = make(map[int][]byte)
+	}
+	wal.mixerPassphrases[walletID] = spendingPassphrase
+	return nil
+}
+
+// DisableMixerAutoStart deletes the stored spending passphrase of the
+// wallet. The mixer will only be started by the user.
+func (wal *Wallet) DisableMixerAutoStart(walletID int) {
+	wal.DeleteCredential(walletID, MixerCredentialConfigKey)
+
+	wal.mixerMu.Lock()
+	delete(wal.mixerPassphrases, walletID)
+	wal.mixerMu.Unlock()
+}
+
+// MixerAutoStartEnabled checks if the spending passphrase of the wallet is
+// stored for the scheduled mixer.
+func (wal *Wallet) MixerAutoStartEnabled(walletID int) bool {
+	return wal.HasCredential(walletID, MixerCredentialConfigKey)
+}
+
+// UnlockMixerCredentials decrypts the spending passphrases stored for the
+// scheduled mixers with the startup password the wallets were opened with.
+func (wal *Wallet) UnlockMixerCredentials(startupPassphrase []byte) {
+	wal.mixerMu.Lock()
+	defer wal.mixerMu.Unlock()
+
+	wal.mixerPassphrases = make(map[int][]byte)
+	for _, wall := range wal.multi.AllWallets() {
+		if !wal.HasCredential(wall.ID, MixerCredentialConfigKey) {
+			continue
+		}
+
+		passphrase, err := wal.ReadCredential(wall.ID, MixerCredentialConfigKey, startupPassphrase)
+		if err != nil {
+			log.Errorf("[%d] Error unlocking the mixer credential: %v", wall.ID, err)
+			continue
+		}
+		wal.mixerPassphrases[wall.ID] = passphrase
+	}
+}
+
+// RekeyMixerCredentials encrypts the stored mixer spending passphrases with
+// a new startup password. The passphrases are deleted if the startup
+// password was removed, as indicated by an empty newStartupPassphrase.
+func (wal *Wallet) RekeyMixerCredentials(oldStartupPassphrase, newStartupPassphrase []byte) {
+	for _, wall := range wal.multi.AllWallets() {
+		if !wal.HasCredential(wall.ID, MixerCredentialConfigKey) {
+			continue
+		}
+
+		if len(newStartupPassphrase) == 0 {
+			wal.DisableMixerAutoStart(wall.ID)
+			continue
+		}
+
+		passphrase, err := wal.ReadCredential(wall.ID, MixerCredentialConfigKey, oldStartupPassphrase)
+		if err == nil {
+			err = wal.StoreCredential(wall.ID, MixerCredentialConfigKey, newStartupPassphrase, passphrase)
+		}
+		if err != nil {
+			log.Errorf("[%d] Error updating the mixer credential: %v", wall.ID, err)
+		}
+	}
+}
+
+// RunMixerSchedules starts or stops the account mixers of the synced wallets
+// whose spending passphrase is unlocked, according to their schedules.
+func (wal *Wallet) RunMixerSchedules() {
+	if !wal.multi.IsSynced() {
+		return
+	}
+
+	wal.mixerRunMu.Lock()
+	defer wal.mixerRunMu.Unlock()
+
+	wal.mixerMu.Lock()
+	passphrases := make(map[int][]byte, len(wal.mixerPassphrases))
+	for id, passphrase := range wal.mixerPassphrases {
+		passphrases[id] = passphrase
+	}
+	wal.mixerMu.Unlock()
+
+	now := time.Now()
+	for walletID, passphrase := range passphrases {
+		wall := wal.multi.WalletWithID(walletID)
+		if wall == nil || !wall.AccountMixerConfigIsSet() {
+			continue
+		}
+
+		schedule := wal.MixerSchedule(walletID)
+		shouldRun := schedule.Includes(now) || (schedule.Mode == MixerScheduleManual && wal.MixerRunState(walletID))
+
+		switch {
+		case shouldRun && !wall.IsAccountMixerActive():
+			if wal.mixerFailedRecently(walletID, now) || wal.CheckConnection(ProxySubsystemMixer) != nil {
+				continue
+			}
+
+			ready, err := wal.multi.ReadyToMix(walletID)
+			if err != nil || !ready {
+				continue
+			}
+
+			log.Infof("[%d] Starting account mixer on schedule: %s", walletID, schedule)
+			if err := wal.multi.StartAccountMixer(walletID, string(passphrase)); err != nil {
+				log.Errorf("[%d] Error starting account mixer: %v", walletID, err)
+				wal.RecordMixerError(walletID, err)
+			}
+		case !shouldRun && wall.IsAccountMixerActive() && schedule.Mode == MixerScheduleWindow:
+			log.Infof("[%d] Stopping account mixer, outside of the mixing window", walletID)
+			if err := wal.StopAccountMixer(walletID); err != nil {
+				log.Errorf("[%d] Error stopping account mixer: %v", walletID, err)
+			}
+		}
+	}
+}
+
+// mixerFailedRecently checks if the last mixer session of the wallet ended
+// with an error less than mixerRetryInterval ago.
+func (wal *Wallet) mixerFailedRecently(walletID int, now time.Time) bool {
+	sessions := wal.MixerSessions(walletID)
+	if len(sessions) == 0 {
+		return false
+	}
+
+	last := sessions[len(sessions)-1]
+	if last.Active() || last.Error == "" {
+		return false
+	}
+	return now.Sub(time.Unix(last.EndTime, 0)) < mixerRetryInterval
+}
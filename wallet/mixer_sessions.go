@@ -0,0 +1,261 @@
+package wallet
+
+import (
+	"time"
+
+	"github.com/planetdecred/dcrlibwallet"
+)
+
+const (
+	// mixerSessionsConfigKey is the wallet config key under which the
+	// account mixer sessions of the wallet are recorded.
+	mixerSessionsConfigKey = "account_mixer_sessions"
+
+	// maxMixerSessions is the number of sessions kept in the history of a
+	// wallet, older sessions are dropped.
+	maxMixerSessions = 100
+
+	errMixerStoppedUnexpectedly = "mixer stopped unexpectedly, see the logs for details"
+	errMixerInterrupted         = "mixer was interrupted by an app shutdown"
+)
+
+// MixerSession is a single run of the account mixer of a wallet.
+type MixerSession struct {
+	StartTime int64
+	EndTime   int64
+
+	UnmixedAtStart int64
+	MixedAtStart   int64
+	UnmixedAtEnd   int64
+	MixedAtEnd     int64
+
+	// Mixes is the number of mix transactions created during the session,
+	// MixedAmount the sum of their mixed outputs and Fees the fees paid.
+	Mixes       int
+	MixedAmount int64
+	Fees        int64
+
+	StoppedByUser bool
+	Error         string
+}
+
+// Active checks if the mixer session is still running.
+func (s *MixerSession) Active() bool {
+	return s.EndTime == 0
+}
+
+// Duration returns how long the mixer ran for, up to now for an active
+// session.
+func (s *MixerSession) Duration() time.Duration {
+	end := s.EndTime
+	if s.Active() {
+		end = time.Now().Unix()
+	}
+	return time.Duration(end-s.StartTime) * time.Second
+}
+
+// MixerProgress is an estimate of how far the wallet is from having all of
+// its unmixed balance mixed.
+type MixerProgress struct {
+	Mixed   int64
+	Unmixed int64
+	// Percent is the share of the balance of the mixer accounts that is
+	// mixed.
+	Percent float64
+	// Remaining is the estimated time needed to mix the unmixed balance,
+	// based on the rate of the previous sessions. It is zero when no
+	// estimate is available.
+	Remaining time.Duration
+}
+
+// mixerSessionRecorder records the account mixer sessions of all wallets.
+// It satisfies the dcrlibwallet AccountMixerNotificationListener interface.
+type mixerSessionRecorder struct {
+	wal *Wallet
+}
+
+func (r *mixerSessionRecorder) OnAccountMixerStarted(walletID int) {
+	r.wal.startMixerSession(walletID)
+}
+
+func (r *mixerSessionRecorder) OnAccountMixerEnded(walletID int) {
+	r.wal.endMixerSession(walletID, "")
+}
+
+// RecordMixerSessions starts recording the account mixer sessions of all
+// wallets under the uniqueIdentifier. Sessions left open by a previous run
+// of the app are closed first.
+func (wal *Wallet) RecordMixerSessions(uniqueIdentifier string) error {
+	for _, wall := range wal.multi.AllWallets() {
+		if !wall.IsAccountMixerActive() {
+			wal.endMixerSession(wall.ID, errMixerInterrupted)
+		}
+	}
+
+	return wal.multi.AddAccountMixerNotificationListener(&mixerSessionRecorder{wal}, uniqueIdentifier)
+}
+
+// StopAccountMixer stops the account mixer of the wallet. The current
+// session is marked as stopped by the user.
+func (wal *Wallet) StopAccountMixer(walletID int) error {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return ErrIDNotExist
+	}
+
+	sessions := wal.MixerSessions(walletID)
+	if n := len(sessions); n > 0 && sessions[n-1].Active() {
+		sessions[n-1].StoppedByUser = true
+		wall.SaveUserConfigValue(mixerSessionsConfigKey, sessions)
+	}
+
+	return wal.multi.StopAccountMixer(walletID)
+}
+
+// RecordMixerError adds a failed session to the history of the wallet, for
+// a mixer that could not be started.
+func (wal *Wallet) RecordMixerError(walletID int, err error) {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return
+	}
+
+	now := time.Now().Unix()
+	mixed, unmixed := mixerBalances(wall)
+	wal.saveMixerSessions(wall, append(wal.MixerSessions(walletID), &MixerSession{
+		StartTime:      now,
+		EndTime:        now,
+		UnmixedAtStart: unmixed,
+		MixedAtStart:   mixed,
+		UnmixedAtEnd:   unmixed,
+		MixedAtEnd:     mixed,
+		Error:          err.Error(),
+	}))
+}
+
+// MixerSessions returns the account mixer sessions of the wallet, oldest
+// first.
+func (wal *Wallet) MixerSessions(walletID int) []*MixerSession {
+	var sessions []*MixerSession
+	wall := wal.multi.WalletWithID(walletID)
+	if wall != nil {
+		_ = wall.ReadUserConfigValue(mixerSessionsConfigKey, &sessions)
+	}
+	return sessions
+}
+
+// MixerProgress estimates how much of the unmixed balance of the wallet is
+// left to mix, and how long it will take.
+func (wal *Wallet) MixerProgress(walletID int) (*MixerProgress, error) {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return nil, ErrIDNotExist
+	}
+
+	mixed, unmixed := mixerBalances(wall)
+	progress := &MixerProgress{
+		Mixed:   mixed,
+		Unmixed: unmixed,
+	}
+	if mixed+unmixed > 0 {
+		progress.Percent = float64(mixed) / float64(mixed+unmixed) * 100
+	}
+
+	var mixedAmount int64
+	var duration time.Duration
+	for _, s := range wal.MixerSessions(walletID) {
+		if s.Active() {
+			s.Mixes, s.MixedAmount, s.Fees = mixStats(wall, s.StartTime, time.Now().Unix())
+		}
+		mixedAmount += s.MixedAmount
+		duration += s.Duration()
+	}
+	if mixedAmount > 0 && unmixed > 0 {
+		progress.Remaining = time.Duration(float64(duration) * float64(unmixed) / float64(mixedAmount))
+	}
+
+	return progress, nil
+}
+
+func (wal *Wallet) startMixerSession(walletID int) {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return
+	}
+
+	mixed, unmixed := mixerBalances(wall)
+	wal.saveMixerSessions(wall, append(wal.MixerSessions(walletID), &MixerSession{
+		StartTime:      time.Now().Unix(),
+		UnmixedAtStart: unmixed,
+		MixedAtStart:   mixed,
+	}))
+}
+
+// endMixerSession closes the active session of the wallet, if any. The
+// session is marked with errMsg, or as stopped unexpectedly when it was not
+// stopped by the user.
+func (wal *Wallet) endMixerSession(walletID int, errMsg string) {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return
+	}
+
+	sessions := wal.MixerSessions(walletID)
+	n := len(sessions)
+	if n == 0 || !sessions[n-1].Active() {
+		return
+	}
+
+	session := sessions[n-1]
+	session.EndTime = time.Now().Unix()
+	session.MixedAtEnd, session.UnmixedAtEnd = mixerBalances(wall)
+	session.Mixes, session.MixedAmount, session.Fees = mixStats(wall, session.StartTime, session.EndTime)
+
+	switch {
+	case errMsg != "":
+		session.Error = errMsg
+	case !session.StoppedByUser:
+		session.Error = errMixerStoppedUnexpectedly
+	}
+	wal.saveMixerSessions(wall, sessions)
+}
+
+func (wal *Wallet) saveMixerSessions(wall *dcrlibwallet.Wallet, sessions []*MixerSession) {
+	if len(sessions) > maxMixerSessions {
+		sessions = sessions[len(sessions)-maxMixerSessions:]
+	}
+	wall.SaveUserConfigValue(mixerSessionsConfigKey, sessions)
+}
+
+// mixerBalances returns the total balances of the mixed and unmixed
+// accounts of the wallet.
+func mixerBalances(wall *dcrlibwallet.Wallet) (mixed, unmixed int64) {
+	if balance, err := wall.GetAccountBalance(wall.MixedAccountNumber()); err == nil {
+		mixed = balance.Total
+	}
+	if balance, err := wall.GetAccountBalance(wall.UnmixedAccountNumber()); err == nil {
+		unmixed = balance.Total
+	}
+	return
+}
+
+// mixStats counts the mix transactions of the wallet created between start
+// and end, and sums their mixed outputs and fees.
+func mixStats(wall *dcrlibwallet.Wallet, start, end int64) (mixes int, mixedAmount, fees int64) {
+	txs, err := wall.GetTransactionsRaw(0, 0, dcrlibwallet.TxFilterMixed, true)
+	if err != nil {
+		log.Errorf("[%d] Error reading mixed transactions: %v", wall.ID, err)
+		return
+	}
+
+	for _, tx := range txs {
+		// unconfirmed txs have the time they were first seen as timestamp.
+		if tx.Timestamp < start || tx.Timestamp > end {
+			continue
+		}
+		mixes++
+		mixedAmount += tx.MixDenomination * int64(tx.MixCount)
+		fees += tx.Fee
+	}
+	return
+}
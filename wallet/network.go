@@ -0,0 +1,142 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/planetdecred/dcrlibwallet"
+)
+
+// The local networks of a dcrd harness, such as the one the integration
+// tests run against, in addition to dcrlibwallet.Mainnet and
+// dcrlibwallet.Testnet3.
+const (
+	Simnet = "simnet"
+	Regnet = "regnet"
+)
+
+// explorerHashPlaceholder is replaced by the transaction hash in the
+// explorer URL templates.
+const explorerHashPlaceholder = "{hash}"
+
+var (
+	// ErrUnknownNetwork is returned for a network that is not mainnet,
+	// testnet3, simnet or regnet.
+	ErrUnknownNetwork = errors.New("unknown network")
+	// ErrLocalNetwork is returned when a public service is used on a
+	// local network, where it has nothing for the wallets.
+	ErrLocalNetwork = errors.New("is not available on a local network")
+	// ErrInvalidExplorerURL is returned for an explorer URL template
+	// without the {hash} placeholder.
+	ErrInvalidExplorerURL = errors.New("the explorer URL must contain " + explorerHashPlaceholder)
+)
+
+// localNetworkSubsystems are the public services that have nothing for the
+// wallets of a local network. The DEX is not one of them, its servers are
+// added by address and may be a local dcrdex harness.
+var localNetworkSubsystems = map[string]bool{
+	ProxySubsystemGovernance: true,
+	ProxySubsystemVSPList:    true,
+	ProxySubsystemExchange:   true,
+}
+
+// NetworkParams returns the parameters of the network, nil if the network
+// is unknown.
+func NetworkParams(net string) *chaincfg.Params {
+	switch net {
+	case dcrlibwallet.Mainnet:
+		return chaincfg.MainNetParams()
+	case dcrlibwallet.Testnet3:
+		return chaincfg.TestNet3Params()
+	case Simnet:
+		return chaincfg.SimNetParams()
+	case Regnet:
+		return chaincfg.RegNetParams()
+	}
+	return nil
+}
+
+// ChainParams returns the parameters of the network of the wallets.
+func (wal *Wallet) ChainParams() *chaincfg.Params {
+	if params := NetworkParams(wal.Net); params != nil {
+		return params
+	}
+	return chaincfg.TestNet3Params()
+}
+
+// IsLocalNetwork returns true if the wallets run on simnet or regnet.
+func (wal *Wallet) IsLocalNetwork() bool {
+	return wal.Net == Simnet || wal.Net == Regnet
+}
+
+// HDPrefix returns the HD path of the accounts without the account number.
+// The test networks share the coin type of testnet.
+func (wal *Wallet) HDPrefix() string {
+	if wal.Net == dcrlibwallet.Mainnet {
+		return dcrlibwallet.MainnetHDPath
+	}
+	return dcrlibwallet.TestnetHDPath
+}
+
+// LocalPeer returns the address of a dcrd of the local network on this
+// host, on the default port of the network.
+func (wal *Wallet) LocalPeer() string {
+	return net.JoinHostPort("127.0.0.1", wal.ChainParams().DefaultPort)
+}
+
+// SetConnectPeers sets the peers from the command line, they are saved as
+// the persistent peers once the wallets are loaded so the sync connects to
+// them instead of discovering peers.
+func (wal *Wallet) SetConnectPeers(input string) error {
+	addresses, err := NormalizePeerAddresses(input, wal.ChainParams())
+	if err != nil {
+		return err
+	}
+	wal.connectPeers = addresses
+	return nil
+}
+
+// applyConnectPeers saves the peers from the command line as the persistent
+// peers. A local network without persistent peers connects to the dcrd on
+// this host, local networks have no seeders to discover peers.
+func (wal *Wallet) applyConnectPeers() {
+	peers := wal.connectPeers
+	if len(peers) == 0 {
+		if !wal.IsLocalNetwork() || len(wal.PersistentPeers()) > 0 {
+			return
+		}
+		peers = []string{wal.LocalPeer()}
+	}
+	log.Infof("Connecting to the %s peers %s", wal.Net, strings.Join(peers, ", "))
+	if err := wal.SetPersistentPeers(peers); err != nil {
+		log.Errorf("Error setting the persistent peers: %v", err)
+	}
+}
+
+// SetExplorerURLOverride sets the transaction URL template of the block
+// explorer from the command line, {hash} is replaced by the transaction
+// hash. The local networks have no explorer by default.
+func (wal *Wallet) SetExplorerURLOverride(template string) error {
+	if template != "" {
+		if !strings.Contains(template, explorerHashPlaceholder) {
+			return ErrInvalidExplorerURL
+		}
+		if err := (BlockExplorer{Name: "--explorerurl", TxURL: template}).Validate(); err != nil {
+			return err
+		}
+	}
+	wal.explorerURLOverride = template
+	return nil
+}
+
+// checkNetwork returns an error if the subsystem is a public service that
+// has nothing for the wallets of a local network.
+func (wal *Wallet) checkNetwork(subsystem string) error {
+	if wal.IsLocalNetwork() && localNetworkSubsystems[subsystem] {
+		return fmt.Errorf("%s %w", proxySubsystemName(subsystem), ErrLocalNetwork)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package wallet_test
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/planetdecred/dcrlibwallet"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+var _ = Describe("Network", func() {
+	newWallet := func(net string) *wallet.Wallet {
+		wal, err := wallet.NewWallet("/tmp/godcr", net, "dev", "", time.Now())
+		Expect(err).ToNot(HaveOccurred())
+		return wal
+	}
+
+	It("rejects unknown networks", func() {
+		_, err := wallet.NewWallet("/tmp/godcr", "testnet2", "dev", "", time.Now())
+		Expect(errors.Is(err, wallet.ErrUnknownNetwork)).To(BeTrue())
+	})
+
+	It("uses the testnet coin type on the test networks", func() {
+		Expect(newWallet(dcrlibwallet.Mainnet).HDPrefix()).To(Equal(dcrlibwallet.MainnetHDPath))
+		Expect(newWallet(dcrlibwallet.Testnet3).HDPrefix()).To(Equal(dcrlibwallet.TestnetHDPath))
+		Expect(newWallet(wallet.Simnet).HDPrefix()).To(Equal(dcrlibwallet.TestnetHDPath))
+		Expect(newWallet(wallet.Regnet).HDPrefix()).To(Equal(dcrlibwallet.TestnetHDPath))
+	})
+
+	It("connects to the dcrd on localhost on local networks", func() {
+		Expect(newWallet(wallet.Simnet).LocalPeer()).To(Equal("127.0.0.1:18555"))
+		Expect(newWallet(wallet.Regnet).LocalPeer()).To(Equal("127.0.0.1:18655"))
+	})
+
+	It("opens the wallets of the local networks", func() {
+		for _, net := range []string{wallet.Simnet, wallet.Regnet} {
+			root, err := os.MkdirTemp("", "godcr-"+net)
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(root)
+
+			wal, err := wallet.NewWallet(root, net, "dev", "", time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(wal.InitMultiWallet()).To(Succeed(), net)
+			Expect(wal.GetMultiWallet().NetType()).To(Equal(net))
+			Expect(wal.PersistentPeers()).To(Equal([]string{wal.LocalPeer()}))
+
+			seed, err := dcrlibwallet.GenerateSeed()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = wal.GetMultiWallet().RestoreWallet("local", seed, "password", dcrlibwallet.PassphraseTypePass)
+			Expect(err).ToNot(HaveOccurred(), net)
+			wal.Shutdown()
+		}
+	})
+
+	It("turns off the public services on local networks", func() {
+		wal := newWallet(wallet.Simnet)
+		err := wal.CheckConnection(wallet.ProxySubsystemGovernance)
+		Expect(errors.Is(err, wallet.ErrLocalNetwork)).To(BeTrue())
+
+		err = wal.CheckConnection(wallet.ProxySubsystemDEX)
+		Expect(errors.Is(err, wallet.ErrLocalNetwork)).To(BeFalse())
+	})
+
+	It("fills in the explorer URL template", func() {
+		wal := newWallet(wallet.Simnet)
+		Expect(wal.GetBlockExplorerURL("abcd")).To(BeEmpty())
+
+		Expect(wal.SetExplorerURLOverride("http://127.0.0.1:17779/tx/")).To(Equal(wallet.ErrInvalidExplorerURL))
+		Expect(wal.SetExplorerURLOverride("http://127.0.0.1:17779/tx/{hash}")).To(Succeed())
+		Expect(wal.GetBlockExplorerURL("abcd")).To(Equal("http://127.0.0.1:17779/tx/abcd"))
+	})
+})
@@ -0,0 +1,310 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/planetdecred/dcrlibwallet"
+)
+
+const (
+	peerProfilesConfigKey = "spv_peer_profiles"
+	bannedPeersConfigKey  = "spv_banned_peers"
+
+	// peerAddressSeparator separates the persistent peer addresses saved
+	// under dcrlibwallet.SpvPersistentPeerAddressesConfigKey.
+	peerAddressSeparator = ";"
+)
+
+var (
+	// ErrNoPeerAddress is returned when a peer list has no address.
+	ErrNoPeerAddress = errors.New("enter at least one peer address")
+	// ErrNoProfileName is returned when a peer profile has no name.
+	ErrNoProfileName = errors.New("enter a name for the peer profile")
+	// ErrPeerBanned is returned when a banned peer is added as a
+	// persistent peer.
+	ErrPeerBanned = errors.New("the peer is banned, unban it first")
+)
+
+// PeerProfile is a named list of persistent peers, such as the nodes of an
+// organization, that may be applied at once.
+type PeerProfile struct {
+	Name      string   `json:"name"`
+	Addresses []string `json:"addresses"`
+}
+
+// PeerStats is a connected peer with the latency of its connection.
+type PeerStats struct {
+	dcrlibwallet.PeerInfo
+	// Latency is the round trip time of the last ping sent on the sync
+	// connection to the peer, 0 until the peer answered one.
+	Latency    time.Duration
+	Persistent bool
+	Banned     bool
+}
+
+// NormalizePeerAddresses parses a list of peer addresses separated by
+// commas, semicolons, spaces or new lines. The default port of the network
+// is added to the addresses without one, duplicates are dropped.
+func NormalizePeerAddresses(input string, params *chaincfg.Params) ([]string, error) {
+	fields := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\n' || r == '\t'
+	})
+	if len(fields) == 0 {
+		return nil, ErrNoPeerAddress
+	}
+
+	seen := make(map[string]bool)
+	var addresses []string
+	for _, field := range fields {
+		// A bracketed IPv6 address without a port.
+		host := field
+		if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+			host = strings.Trim(host, "[]")
+		}
+		addr, err := dcrlibwallet.NormalizeAddress(host, params.DefaultPort)
+		if err != nil {
+			return nil, fmt.Errorf("invalid peer address %q: %v", field, err)
+		}
+		host, port, _ := net.SplitHostPort(addr)
+		switch {
+		case host == "":
+			return nil, fmt.Errorf("invalid peer address %q: missing host", field)
+		case strings.Contains(host, ":") && net.ParseIP(host) == nil:
+			return nil, fmt.Errorf("invalid peer address %q: invalid IPv6 address", field)
+		}
+		if p, err := strconv.ParseUint(port, 10, 16); err != nil || p == 0 {
+			return nil, fmt.Errorf("invalid peer address %q: invalid port", field)
+		}
+		if !seen[addr] {
+			seen[addr] = true
+			addresses = append(addresses, addr)
+		}
+	}
+	return addresses, nil
+}
+
+// PersistentPeers returns the addresses of the peers the sync connects to
+// instead of discovering peers.
+func (wal *Wallet) PersistentPeers() []string {
+	value := wal.ReadStringConfigValueForKey(dcrlibwallet.SpvPersistentPeerAddressesConfigKey)
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, peerAddressSeparator)
+}
+
+// SetPersistentPeers saves the persistent peers, no addresses clears them
+// and the sync discovers peers again. The peers are used once the sync
+// restarts.
+func (wal *Wallet) SetPersistentPeers(addresses []string) error {
+	for _, addr := range addresses {
+		if wal.IsPeerBanned(addr) {
+			return fmt.Errorf("%s: %w", addr, ErrPeerBanned)
+		}
+	}
+	wal.SaveConfigValueForKey(dcrlibwallet.SpvPersistentPeerAddressesConfigKey,
+		strings.Join(addresses, peerAddressSeparator))
+	return nil
+}
+
+// AddPersistentPeers validates the addresses and adds them to the
+// persistent peers.
+func (wal *Wallet) AddPersistentPeers(input string) error {
+	addresses, err := NormalizePeerAddresses(input, wal.ChainParams())
+	if err != nil {
+		return err
+	}
+
+	peers := wal.PersistentPeers()
+	for _, addr := range addresses {
+		if !containsString(peers, addr) {
+			peers = append(peers, addr)
+		}
+	}
+	return wal.SetPersistentPeers(peers)
+}
+
+// RemovePersistentPeer removes the address from the persistent peers.
+func (wal *Wallet) RemovePersistentPeer(addr string) {
+	var peers []string
+	for _, peer := range wal.PersistentPeers() {
+		if peer != addr {
+			peers = append(peers, peer)
+		}
+	}
+	_ = wal.SetPersistentPeers(peers)
+}
+
+// BannedPeers returns the addresses of the banned peers.
+func (wal *Wallet) BannedPeers() []string {
+	var banned []string
+	_ = wal.multi.ReadUserConfigValue(bannedPeersConfigKey, &banned)
+	return banned
+}
+
+// IsPeerBanned returns true if the peer at addr is banned. Peers are
+// matched by host, the port of discovered peers may differ.
+func (wal *Wallet) IsPeerBanned(addr string) bool {
+	host := peerHost(addr)
+	for _, banned := range wal.BannedPeers() {
+		if peerHost(banned) == host {
+			return true
+		}
+	}
+	return false
+}
+
+// BanPeer bans the peer, removes it from the persistent peers and
+// disconnects the connected peers on the same host. The sync does not
+// connect to banned peers it discovers.
+func (wal *Wallet) BanPeer(addr string) {
+	wal.RemovePersistentPeer(addr)
+	banned := wal.BannedPeers()
+	if !containsString(banned, addr) {
+		wal.SaveConfigValueForKey(bannedPeersConfigKey, append(banned, addr))
+	}
+
+	infos, err := wal.multi.PeerInfoRaw()
+	if err != nil {
+		return
+	}
+	host := peerHost(addr)
+	for _, info := range infos {
+		if peerHost(info.Addr) != host {
+			continue
+		}
+		if err := wal.DisconnectPeer(info.ID); err != nil {
+			log.Errorf("Error disconnecting the banned peer %s: %v", info.Addr, err)
+		}
+	}
+}
+
+// DisconnectPeer disconnects the connected peer with the id. A discovered
+// peer is replaced by another one, a persistent peer is connected to again.
+func (wal *Wallet) DisconnectPeer(id int32) error {
+	return wal.multi.DisconnectPeer(id)
+}
+
+// UnbanPeer removes the peer from the banned peers.
+func (wal *Wallet) UnbanPeer(addr string) {
+	var banned []string
+	for _, peer := range wal.BannedPeers() {
+		if peer != addr {
+			banned = append(banned, peer)
+		}
+	}
+	wal.SaveConfigValueForKey(bannedPeersConfigKey, banned)
+}
+
+// PeerProfiles returns the saved peer profiles sorted by name.
+func (wal *Wallet) PeerProfiles() []PeerProfile {
+	var profiles []PeerProfile
+	_ = wal.multi.ReadUserConfigValue(peerProfilesConfigKey, &profiles)
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles
+}
+
+// SavePeerProfile validates the addresses and saves the profile, replacing
+// the profile with the same name.
+func (wal *Wallet) SavePeerProfile(name, input string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrNoProfileName
+	}
+	addresses, err := NormalizePeerAddresses(input, wal.ChainParams())
+	if err != nil {
+		return err
+	}
+
+	profiles := []PeerProfile{{Name: name, Addresses: addresses}}
+	for _, profile := range wal.PeerProfiles() {
+		if profile.Name != name {
+			profiles = append(profiles, profile)
+		}
+	}
+	wal.SaveConfigValueForKey(peerProfilesConfigKey, profiles)
+	return nil
+}
+
+// RemovePeerProfile deletes the profile with the name.
+func (wal *Wallet) RemovePeerProfile(name string) {
+	var profiles []PeerProfile
+	for _, profile := range wal.PeerProfiles() {
+		if profile.Name != name {
+			profiles = append(profiles, profile)
+		}
+	}
+	wal.SaveConfigValueForKey(peerProfilesConfigKey, profiles)
+}
+
+// ApplyPeerProfile replaces the persistent peers with the peers of the
+// profile.
+func (wal *Wallet) ApplyPeerProfile(name string) error {
+	for _, profile := range wal.PeerProfiles() {
+		if profile.Name == name {
+			return wal.SetPersistentPeers(profile.Addresses)
+		}
+	}
+	return fmt.Errorf("no peer profile named %q", name)
+}
+
+// RestartSyncForPeers restarts an active sync so peer changes take effect,
+// all the peers are disconnected and the sync connects to the peers again.
+func (wal *Wallet) RestartSyncForPeers() error {
+	if !wal.multi.IsConnectedToDecredNetwork() {
+		return nil
+	}
+	if err := wal.CheckConnection(ProxySubsystemSync); err != nil {
+		return err
+	}
+	wal.RecordSyncEvent("Restarting sync to apply peer changes")
+	return wal.multi.RestartSpvSync()
+}
+
+// PeerStats returns the connected peers with their latency. The latency is
+// the ping round trip time on the sync connection, no other connection is
+// opened to the peers.
+func (wal *Wallet) PeerStats() ([]PeerStats, error) {
+	infos, err := wal.multi.PeerInfoRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	persistent := wal.PersistentPeers()
+	stats := make([]PeerStats, len(infos))
+	for i, info := range infos {
+		stats[i] = PeerStats{
+			PeerInfo:   info,
+			Latency:    time.Duration(info.PingTime) * time.Microsecond,
+			Persistent: containsString(persistent, info.Addr),
+			Banned:     wal.IsPeerBanned(info.Addr),
+		}
+	}
+	return stats, nil
+}
+
+func peerHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package wallet_test
+
+import (
+	"github.com/decred/dcrd/chaincfg/v3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/planetdecred/godcr/wallet"
+)
+
+var _ = Describe("Peer addresses", func() {
+	params := chaincfg.TestNet3Params()
+
+	It("adds the default port and drops duplicates", func() {
+		addresses, err := wallet.NormalizePeerAddresses("10.0.0.1, 10.0.0.2:9000;10.0.0.1\nnode.example.org ::1 [::2]", params)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(addresses).To(Equal([]string{"10.0.0.1:19108", "10.0.0.2:9000", "node.example.org:19108", "[::1]:19108", "[::2]:19108"}))
+	})
+
+	It("rejects an empty list", func() {
+		_, err := wallet.NormalizePeerAddresses(" ,; ", params)
+		Expect(err).To(Equal(wallet.ErrNoPeerAddress))
+	})
+
+	It("rejects invalid addresses", func() {
+		_, err := wallet.NormalizePeerAddresses("10.0.0.1:1:2", params)
+		Expect(err).To(HaveOccurred())
+
+		_, err = wallet.NormalizePeerAddresses(":9108", params)
+		Expect(err).To(HaveOccurred())
+
+		_, err = wallet.NormalizePeerAddresses("10.0.0.1:dcr", params)
+		Expect(err).To(HaveOccurred())
+	})
+})
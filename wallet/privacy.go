@@ -0,0 +1,98 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// FetchProposalConfigKey turns the Politeia proposals sync on.
+	FetchProposalConfigKey = "fetch_proposals"
+	// NoThirdPartyRequestsConfigKey turns off every network feature but the
+	// SPV sync with the Decred peers.
+	NoThirdPartyRequestsConfigKey = "no_third_party_requests"
+
+	disabledFeaturesConfigKey = "privacy_disabled_features"
+)
+
+// ErrFeatureDisabled is returned when a network feature turned off in the
+// privacy settings is used.
+var ErrFeatureDisabled = errors.New("is turned off in the privacy settings")
+
+// PrivacyFeatures are the network features that can be turned off one by
+// one. The SPV sync is always on, the VSPs and the mixer are only turned
+// off with the third-party requests.
+var PrivacyFeatures = []string{
+	ProxySubsystemExchange,
+	ProxySubsystemVSPList,
+	ProxySubsystemGovernance,
+	ProxySubsystemDEX,
+	ProxySubsystemExplorer,
+}
+
+// NoThirdPartyRequests returns true if only the SPV sync connects to the
+// network.
+func (wal *Wallet) NoThirdPartyRequests() bool {
+	return wal.multi != nil && wal.ReadBoolConfigValueForKey(NoThirdPartyRequestsConfigKey)
+}
+
+// SetNoThirdPartyRequests turns the third-party requests on or off, the
+// settings of the features are kept.
+func (wal *Wallet) SetNoThirdPartyRequests(off bool) {
+	wal.SaveConfigValueForKey(NoThirdPartyRequestsConfigKey, off)
+}
+
+// FeatureDisabled returns true if the network feature is turned off, on
+// its own or with the third-party requests. The governance feature is the
+// proposals sync, which is off until turned on.
+func (wal *Wallet) FeatureDisabled(feature string) bool {
+	if feature == ProxySubsystemSync || wal.multi == nil {
+		return false
+	}
+	if wal.NoThirdPartyRequests() {
+		return true
+	}
+	if feature == ProxySubsystemGovernance {
+		return !wal.ReadBoolConfigValueForKey(FetchProposalConfigKey)
+	}
+	return containsString(wal.disabledFeatures(), feature)
+}
+
+// SetFeatureDisabled turns the network feature off or on.
+func (wal *Wallet) SetFeatureDisabled(feature string, disabled bool) {
+	if feature == ProxySubsystemGovernance {
+		wal.SaveConfigValueForKey(FetchProposalConfigKey, !disabled)
+		return
+	}
+
+	var features []string
+	for _, f := range wal.disabledFeatures() {
+		if f != feature {
+			features = append(features, f)
+		}
+	}
+	if disabled {
+		features = append(features, feature)
+	}
+	wal.SaveConfigValueForKey(disabledFeaturesConfigKey, features)
+}
+
+func (wal *Wallet) disabledFeatures() []string {
+	var features []string
+	_ = wal.multi.ReadUserConfigValue(disabledFeaturesConfigKey, &features)
+	return features
+}
+
+// CheckConnection returns an error if the subsystem may not connect to
+// the network, because it is turned off in the privacy settings, it is a
+// public service on a local network or it cannot use the proxy. Every call
+// site of a third-party service checks it first.
+func (wal *Wallet) CheckConnection(subsystem string) error {
+	if wal.FeatureDisabled(subsystem) {
+		return fmt.Errorf("%s %w", proxySubsystemName(subsystem), ErrFeatureDisabled)
+	}
+	if err := wal.checkNetwork(subsystem); err != nil {
+		return err
+	}
+	return wal.CheckDirectConnection(subsystem)
+}
@@ -0,0 +1,241 @@
+package wallet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/planetdecred/dcrlibwallet"
+)
+
+const (
+	ProposalVoteYes     = "yes"
+	ProposalVoteNo      = "no"
+	ProposalVoteUnvoted = "unvoted"
+
+	// proposalVoteHistoryConfigKey is the multiwallet config key under which
+	// the votes cast by the wallets on proposals are stored.
+	proposalVoteHistoryConfigKey = "proposal_vote_history"
+)
+
+// TicketProposalVote is the vote of a single ticket on a proposal.
+type TicketProposalVote struct {
+	Ticket string
+	Vote   string
+}
+
+// WalletProposalVotes is the breakdown of the votes of the tickets of a
+// wallet on a proposal.
+type WalletProposalVotes struct {
+	WalletID   int
+	WalletName string
+
+	Yes, No, Unvoted int
+	Tickets          []TicketProposalVote
+}
+
+// Eligible returns the number of tickets of the wallet that are eligible to
+// vote on the proposal.
+func (v *WalletProposalVotes) Eligible() int {
+	return v.Yes + v.No + v.Unvoted
+}
+
+// ProposalVoteRecord is a vote cast by a ticket of one of the wallets on a
+// proposal.
+type ProposalVoteRecord struct {
+	Token        string `json:"token"`
+	ProposalName string `json:"proposal_name"`
+	WalletID     int    `json:"wallet_id"`
+	WalletName   string `json:"wallet_name"`
+	Ticket       string `json:"ticket"`
+	Vote         string `json:"vote"`
+	// Timestamp is the unix time at which politeia received the vote, zero
+	// if it is not known.
+	Timestamp int64 `json:"timestamp"`
+}
+
+// ProposalVoteBreakdown fetches the votes of the tickets of every wallet that
+// is not watching only on the proposal. The votes found are added to the vote
+// history, including the ones cast by other wallet software. It returns the
+// error of CheckConnection if Politeia may not be contacted.
+func (wal *Wallet) ProposalVoteBreakdown(proposal *dcrlibwallet.Proposal) ([]*WalletProposalVotes, error) {
+	if err := wal.CheckConnection(ProxySubsystemGovernance); err != nil {
+		return nil, err
+	}
+
+	breakdown := make([]*WalletProposalVotes, 0)
+	for _, wall := range wal.multi.AllWallets() {
+		if wall.IsWatchingOnlyWallet() {
+			continue
+		}
+
+		details, err := wal.multi.Politeia.ProposalVoteDetailsRaw(wall.ID, proposal.Token)
+		if err != nil {
+			return nil, err
+		}
+
+		walletVotes := &WalletProposalVotes{
+			WalletID:   wall.ID,
+			WalletName: wall.Name,
+			Yes:        int(details.YesVotes),
+			No:         int(details.NoVotes),
+			Unvoted:    len(details.EligibleTickets),
+		}
+		for _, vote := range details.Votes {
+			walletVotes.Tickets = append(walletVotes.Tickets, TicketProposalVote{
+				Ticket: vote.Ticket.Hash,
+				Vote:   proposalVoteString(vote.Bit),
+			})
+		}
+		for _, ticket := range details.EligibleTickets {
+			walletVotes.Tickets = append(walletVotes.Tickets, TicketProposalVote{
+				Ticket: ticket.Hash,
+				Vote:   ProposalVoteUnvoted,
+			})
+		}
+
+		wal.RecordProposalVotes(proposal, wall.ID, details.Votes)
+		breakdown = append(breakdown, walletVotes)
+	}
+
+	return breakdown, nil
+}
+
+// RecordedProposalVotes returns the breakdown of the votes on the proposal
+// in the vote history, for use without Politeia. The unvoted tickets are not
+// known.
+func (wal *Wallet) RecordedProposalVotes(token string) []*WalletProposalVotes {
+	breakdown := make([]*WalletProposalVotes, 0)
+	byWallet := make(map[int]*WalletProposalVotes)
+	for _, r := range wal.ProposalVoteHistory() {
+		if r.Token != token {
+			continue
+		}
+		walletVotes, ok := byWallet[r.WalletID]
+		if !ok {
+			walletVotes = &WalletProposalVotes{WalletID: r.WalletID, WalletName: r.WalletName}
+			byWallet[r.WalletID] = walletVotes
+			breakdown = append(breakdown, walletVotes)
+		}
+		switch r.Vote {
+		case ProposalVoteYes:
+			walletVotes.Yes++
+		case ProposalVoteNo:
+			walletVotes.No++
+		}
+		walletVotes.Tickets = append(walletVotes.Tickets, TicketProposalVote{Ticket: r.Ticket, Vote: r.Vote})
+	}
+	return breakdown
+}
+
+// RecordProposalVotes adds the votes cast by the tickets of the wallet to the
+// vote history, dated with the time politeia received them. Tickets already
+// in the history for the proposal only have their date corrected, history
+// recorded before politeia's time was used has the date the vote was found.
+func (wal *Wallet) RecordProposalVotes(proposal *dcrlibwallet.Proposal, walletID int, votes []*dcrlibwallet.ProposalVote) {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil || len(votes) == 0 {
+		return
+	}
+
+	history := wal.ProposalVoteHistory()
+	recorded := make(map[string]*ProposalVoteRecord, len(history))
+	for _, r := range history {
+		recorded[r.Token+r.Ticket] = r
+	}
+
+	updated := false
+	for _, vote := range votes {
+		if r, ok := recorded[proposal.Token+vote.Ticket.Hash]; ok {
+			if vote.Timestamp != 0 && r.Timestamp != vote.Timestamp {
+				r.Timestamp = vote.Timestamp
+				updated = true
+			}
+			continue
+		}
+
+		history = append(history, &ProposalVoteRecord{
+			Token:        proposal.Token,
+			ProposalName: proposal.Name,
+			WalletID:     wall.ID,
+			WalletName:   wall.Name,
+			Ticket:       vote.Ticket.Hash,
+			Vote:         proposalVoteString(vote.Bit),
+			Timestamp:    vote.Timestamp,
+		})
+		updated = true
+	}
+
+	if updated {
+		wal.multi.SaveUserConfigValue(proposalVoteHistoryConfigKey, history)
+	}
+}
+
+// ProposalVoteHistory returns the votes cast on all proposals, newest first.
+func (wal *Wallet) ProposalVoteHistory() []*ProposalVoteRecord {
+	var history []*ProposalVoteRecord
+	_ = wal.multi.ReadUserConfigValue(proposalVoteHistoryConfigKey, &history)
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Timestamp > history[j].Timestamp
+	})
+	return history
+}
+
+// ExportProposalVoteHistory writes the vote history to a CSV file in the
+// exports directory of the app and returns the path of the file.
+func (wal *Wallet) ExportProposalVoteHistory() (string, error) {
+	dir := filepath.Join(wal.Root, "exports")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("proposal-votes-%s.csv", time.Now().Format("20060102-150405")))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	err = w.Write([]string{"Date", "Proposal", "Token", "Wallet", "Ticket", "Vote"})
+	if err != nil {
+		return "", err
+	}
+
+	for _, r := range wal.ProposalVoteHistory() {
+		date := ""
+		if r.Timestamp != 0 {
+			date = time.Unix(r.Timestamp, 0).UTC().Format(time.RFC3339)
+		}
+		err = w.Write([]string{
+			date,
+			r.ProposalName,
+			r.Token,
+			r.WalletName + " (" + strconv.Itoa(r.WalletID) + ")",
+			r.Ticket,
+			r.Vote,
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	w.Flush()
+	return path, w.Error()
+}
+
+func proposalVoteString(bit string) string {
+	switch bit {
+	case dcrlibwallet.VoteBitYes:
+		return ProposalVoteYes
+	case dcrlibwallet.VoteBitNo:
+		return ProposalVoteNo
+	default:
+		return ProposalVoteUnvoted
+	}
+}
@@ -0,0 +1,65 @@
+package wallet_test
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/planetdecred/dcrlibwallet"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+var _ = Describe("Proposal votes", func() {
+	var (
+		wal  *wallet.Wallet
+		wall *dcrlibwallet.Wallet
+		root string
+	)
+	proposal := &dcrlibwallet.Proposal{Token: "token", Name: "proposal"}
+
+	BeforeEach(func() {
+		var err error
+		root, err = os.MkdirTemp("", "godcr-votes")
+		Expect(err).ToNot(HaveOccurred())
+		wal, err = wallet.NewWallet(root, dcrlibwallet.Testnet3, "dev", "", time.Now())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(wal.InitMultiWallet()).To(Succeed())
+
+		seed, err := dcrlibwallet.GenerateSeed()
+		Expect(err).ToNot(HaveOccurred())
+		wall, err = wal.GetMultiWallet().RestoreWallet("voter", seed, "password", dcrlibwallet.PassphraseTypePass)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		wal.Shutdown()
+		os.RemoveAll(root)
+	})
+
+	It("does not contact politeia while governance is off", func() {
+		Expect(wal.FeatureDisabled(wallet.ProxySubsystemGovernance)).To(BeTrue())
+		_, err := wal.ProposalVoteBreakdown(proposal)
+		Expect(errors.Is(err, wallet.ErrFeatureDisabled)).To(BeTrue())
+		Expect(errors.Is(wal.CacheProposalDescriptions(), wallet.ErrFeatureDisabled)).To(BeTrue())
+	})
+
+	It("breaks the recorded votes down by wallet", func() {
+		wal.RecordProposalVotes(proposal, wall.ID, []*dcrlibwallet.ProposalVote{
+			{Ticket: &dcrlibwallet.EligibleTicket{Hash: "a"}, Bit: dcrlibwallet.VoteBitYes, Timestamp: 1},
+			{Ticket: &dcrlibwallet.EligibleTicket{Hash: "b"}, Bit: dcrlibwallet.VoteBitYes, Timestamp: 1},
+			{Ticket: &dcrlibwallet.EligibleTicket{Hash: "c"}, Bit: dcrlibwallet.VoteBitNo, Timestamp: 1},
+		})
+
+		breakdown := wal.RecordedProposalVotes(proposal.Token)
+		Expect(breakdown).To(HaveLen(1))
+		Expect(breakdown[0].WalletName).To(Equal("voter"))
+		Expect(breakdown[0].Yes).To(Equal(2))
+		Expect(breakdown[0].No).To(Equal(1))
+		Expect(breakdown[0].Tickets).To(HaveLen(3))
+
+		Expect(wal.RecordedProposalVotes("other")).To(BeEmpty())
+	})
+})
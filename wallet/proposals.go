@@ -0,0 +1,88 @@
+package wallet
+
+import (
+	"strings"
+
+	"github.com/planetdecred/dcrlibwallet"
+)
+
+// bookmarkedProposalsConfigKey is the multiwallet config key under which the
+// tokens of the bookmarked proposals are stored.
+const bookmarkedProposalsConfigKey = "bookmarked_proposals"
+
+// BookmarkedProposals returns the tokens of the bookmarked proposals.
+func (wal *Wallet) BookmarkedProposals() []string {
+	var tokens []string
+	_ = wal.multi.ReadUserConfigValue(bookmarkedProposalsConfigKey, &tokens)
+	return tokens
+}
+
+// IsProposalBookmarked checks if the proposal with the given token is
+// bookmarked.
+func (wal *Wallet) IsProposalBookmarked(token string) bool {
+	for _, t := range wal.BookmarkedProposals() {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
+
+// SetProposalBookmarked adds the proposal with the given token to, or
+// removes it from the bookmarked proposals.
+func (wal *Wallet) SetProposalBookmarked(token string, bookmarked bool) {
+	tokens := make([]string, 0)
+	for _, t := range wal.BookmarkedProposals() {
+		if t != token {
+			tokens = append(tokens, t)
+		}
+	}
+
+	if bookmarked {
+		tokens = append(tokens, token)
+	}
+	wal.multi.SaveUserConfigValue(bookmarkedProposalsConfigKey, tokens)
+}
+
+// ProposalMatchesSearch checks if the proposal title, author, token or
+// cached body contains the query. The comparison is case insensitive.
+func ProposalMatchesSearch(proposal *dcrlibwallet.Proposal, query string) bool {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return true
+	}
+
+	for _, field := range []string{proposal.Name, proposal.Username, proposal.Token, proposal.IndexFile} {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// CacheProposalDescriptions downloads the body of every proposal whose
+// cached copy is missing or out of date, so that proposals can be read
+// without network access. It stops at the first error as the remaining
+// downloads are likely to fail as well.
+func (wal *Wallet) CacheProposalDescriptions() error {
+	if err := wal.CheckConnection(ProxySubsystemGovernance); err != nil {
+		return err
+	}
+
+	proposals, err := wal.multi.Politeia.GetProposalsRaw(dcrlibwallet.ProposalCategoryAll, 0, 0, true)
+	if err != nil {
+		return err
+	}
+
+	for _, proposal := range proposals {
+		if proposal.IndexFile != "" && proposal.IndexFileVersion == proposal.Version {
+			continue
+		}
+
+		_, err := wal.multi.Politeia.FetchProposalDescription(proposal.Token)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
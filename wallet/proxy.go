@@ -0,0 +1,342 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decred/go-socks/socks"
+	"github.com/planetdecred/dcrlibwallet"
+)
+
+// The subsystems that make outbound connections. With stream isolation on
+// each subsystem uses its own proxy credentials, which Tor routes over
+// separate circuits.
+const (
+	ProxySubsystemSync       = "sync"
+	ProxySubsystemGovernance = "governance"
+	ProxySubsystemVSP        = "vsp"
+	ProxySubsystemVSPList    = "vsplist"
+	ProxySubsystemExchange   = "exchange"
+	ProxySubsystemExplorer   = "explorer"
+	ProxySubsystemDEX        = "dex"
+	ProxySubsystemDEXSPV     = "dexspv"
+	ProxySubsystemMixer      = "mixer"
+)
+
+const (
+	ProxyAddressConfigKey     = "proxy_address"
+	ProxyIsolationConfigKey   = "proxy_stream_isolation"
+	ProxyAllowDirectConfigKey = "proxy_allow_direct"
+
+	// defaultProxyPort is the SOCKS port of Tor.
+	defaultProxyPort = "9050"
+
+	proxyHTTPTimeout = 30 * time.Second
+)
+
+var (
+	// ErrNoProxyAddress is returned when the proxy address is empty.
+	ErrNoProxyAddress = errors.New("enter the address of the proxy")
+	// ErrDirectConnection is returned when a subsystem that cannot use the
+	// proxy is started while the proxy is on.
+	ErrDirectConnection = errors.New("cannot use the proxy, allow direct connections in the proxy settings to use it")
+	// ErrDEXProxyChanged is returned when the DEX is used after the proxy
+	// settings changed, the DEX keeps the proxy it started with.
+	ErrDEXProxyChanged = errors.New("the DEX started with other proxy settings, restart the app to apply them")
+)
+
+// proxySubsystemNames are the names of the subsystems shown in errors.
+var proxySubsystemNames = map[string]string{
+	ProxySubsystemSync:       "Wallet sync",
+	ProxySubsystemGovernance: "Governance",
+	ProxySubsystemVSP:        "Staking with a VSP",
+	ProxySubsystemVSPList:    "VSP list",
+	ProxySubsystemExchange:   "Exchange rate",
+	ProxySubsystemExplorer:   "Block explorer",
+	ProxySubsystemDEX:        "DEX",
+	ProxySubsystemDEXSPV:     "The Bitcoin SPV wallet of the DEX",
+	ProxySubsystemMixer:      "Account mixer",
+}
+
+// directSubsystems are the subsystems that connect directly, the Bitcoin
+// SPV wallet of the DEX does not let the wallet set its dialer.
+var directSubsystems = map[string]bool{
+	ProxySubsystemDEXSPV: true,
+}
+
+// dialSubsystems are the subsystems of the kinds of connections dialed by
+// dcrlibwallet.
+var dialSubsystems = map[string]string{
+	dcrlibwallet.DialSync:     ProxySubsystemSync,
+	dcrlibwallet.DialPoliteia: ProxySubsystemGovernance,
+	dcrlibwallet.DialVSP:      ProxySubsystemVSP,
+	dcrlibwallet.DialVSPList:  ProxySubsystemVSPList,
+	dcrlibwallet.DialMixer:    ProxySubsystemMixer,
+	dcrlibwallet.DialAgendas:  ProxySubsystemGovernance,
+}
+
+func proxySubsystemName(subsystem string) string {
+	if name, ok := proxySubsystemNames[subsystem]; ok {
+		return name
+	}
+	return subsystem
+}
+
+// NormalizeProxyAddress validates a SOCKS5 proxy address, the Tor port is
+// added to an address without one.
+func NormalizeProxyAddress(addr string) (string, error) {
+	addr = strings.TrimSpace(addr)
+	addr = strings.TrimPrefix(addr, "socks5://")
+	if addr == "" {
+		return "", ErrNoProxyAddress
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		host, port = strings.Trim(addr, "[]"), defaultProxyPort
+	}
+	switch {
+	case host == "":
+		return "", fmt.Errorf("invalid proxy address %q: missing host", addr)
+	case strings.ContainsAny(host, ":[]") && net.ParseIP(host) == nil:
+		return "", fmt.Errorf("invalid proxy address %q: invalid host", addr)
+	}
+	if p, err := strconv.ParseUint(port, 10, 16); err != nil || p == 0 {
+		return "", fmt.Errorf("invalid proxy address %q: invalid port", addr)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// ProxyURL returns the URL of the proxy at addr for the subsystem. With
+// isolation the subsystem and session are sent as the proxy credentials,
+// so each subsystem gets its own circuit in every session.
+func ProxyURL(addr, subsystem string, isolation bool, session string) *url.URL {
+	proxyURL := &url.URL{Scheme: "socks5", Host: addr}
+	if isolation {
+		proxyURL.User = url.UserPassword(subsystem, session)
+	}
+	return proxyURL
+}
+
+// SetProxyOverride sets the proxy from the command line, it takes
+// precedence over the proxy set in the settings. isolation forces the
+// stream isolation on.
+func (wal *Wallet) SetProxyOverride(addr string, isolation bool) error {
+	if addr != "" {
+		var err error
+		if addr, err = NormalizeProxyAddress(addr); err != nil {
+			return err
+		}
+	}
+
+	wal.proxyMu.Lock()
+	wal.proxyOverride = addr
+	wal.proxyIsolationOverride = isolation
+	wal.proxyClients = nil
+	wal.proxyMu.Unlock()
+
+	wal.applyDEXProxy()
+	return nil
+}
+
+// ProxyOverridden returns true if the proxy is set from the command line.
+func (wal *Wallet) ProxyOverridden() bool {
+	wal.proxyMu.Lock()
+	defer wal.proxyMu.Unlock()
+	return wal.proxyOverride != ""
+}
+
+// ProxyAddress returns the address of the SOCKS5 proxy, empty if the
+// proxy is off.
+func (wal *Wallet) ProxyAddress() string {
+	wal.proxyMu.Lock()
+	override := wal.proxyOverride
+	wal.proxyMu.Unlock()
+	if override != "" {
+		return override
+	}
+	if wal.multi == nil {
+		return ""
+	}
+	return wal.ReadStringConfigValueForKey(ProxyAddressConfigKey)
+}
+
+// ProxyEnabled returns true if the outbound connections go through a proxy.
+func (wal *Wallet) ProxyEnabled() bool {
+	return wal.ProxyAddress() != ""
+}
+
+// ProxyIsolation returns true if each subsystem uses its own proxy
+// credentials.
+func (wal *Wallet) ProxyIsolation() bool {
+	wal.proxyMu.Lock()
+	override := wal.proxyIsolationOverride
+	wal.proxyMu.Unlock()
+	return override || (wal.multi != nil && wal.ReadBoolConfigValueForKey(ProxyIsolationConfigKey))
+}
+
+// ProxyAllowDirect returns true if the subsystems that cannot use the proxy,
+// the Bitcoin SPV wallet of the DEX, may connect directly while the proxy is
+// on.
+func (wal *Wallet) ProxyAllowDirect() bool {
+	return wal.multi != nil && wal.ReadBoolConfigValueForKey(ProxyAllowDirectConfigKey)
+}
+
+// SetProxy saves the proxy settings, an empty address turns the proxy off.
+// Connections already open are not moved to the proxy, RestartSyncForProxy
+// moves the SPV sync and the DEX keeps its proxy until the app restarts.
+func (wal *Wallet) SetProxy(addr string, isolation, allowDirect bool) error {
+	if addr != "" {
+		var err error
+		if addr, err = NormalizeProxyAddress(addr); err != nil {
+			return err
+		}
+	}
+
+	wal.SaveConfigValueForKey(ProxyAddressConfigKey, addr)
+	wal.SaveConfigValueForKey(ProxyIsolationConfigKey, isolation)
+	wal.SaveConfigValueForKey(ProxyAllowDirectConfigKey, allowDirect)
+
+	wal.proxyMu.Lock()
+	wal.proxyClients = nil
+	wal.proxyMu.Unlock()
+
+	wal.applyDEXProxy()
+	return nil
+}
+
+// proxySessionID returns the session of the proxy credentials, it is
+// created once per run.
+func (wal *Wallet) proxySessionID() string {
+	wal.proxyMu.Lock()
+	defer wal.proxyMu.Unlock()
+	if wal.proxySession == "" {
+		wal.proxySession = newProxySession()
+	}
+	return wal.proxySession
+}
+
+// dialer returns the dial function of a kind of connection dialed by
+// dcrlibwallet, nil to connect directly. Set with dcrlibwallet.SetDialer.
+func (wal *Wallet) dialer(kind string) dcrlibwallet.DialFunc {
+	addr := wal.ProxyAddress()
+	if addr == "" {
+		return nil
+	}
+	subsystem, ok := dialSubsystems[kind]
+	if !ok {
+		subsystem = kind
+	}
+
+	proxy := &socks.Proxy{Addr: addr}
+	if wal.ProxyIsolation() {
+		proxy.Username, proxy.Password = subsystem, wal.proxySessionID()
+	}
+	return proxy.DialContext
+}
+
+// applyDEXProxy sets the proxy the DEX client is created with. Stream
+// isolation gives each DEX connection its own proxy credentials.
+func (wal *Wallet) applyDEXProxy() {
+	if wal.multi == nil {
+		return
+	}
+	wal.multi.SetDexProxy(wal.ProxyAddress(), wal.ProxyIsolation())
+}
+
+// RestartSyncForProxy restarts an active sync so the peers are connected
+// to with the new proxy settings.
+func (wal *Wallet) RestartSyncForProxy() error {
+	if !wal.multi.IsConnectedToDecredNetwork() {
+		return nil
+	}
+	if err := wal.CheckConnection(ProxySubsystemSync); err != nil {
+		return err
+	}
+	wal.RecordSyncEvent("Restarting sync to apply the proxy settings")
+	return wal.multi.RestartSpvSync()
+}
+
+// HTTPClient returns the HTTP client of the subsystem, it connects through
+// the proxy when the proxy is on.
+func (wal *Wallet) HTTPClient(subsystem string) *http.Client {
+	addr := wal.ProxyAddress()
+	if addr == "" {
+		return &http.Client{Timeout: proxyHTTPTimeout}
+	}
+	isolation := wal.ProxyIsolation()
+	session := wal.proxySessionID()
+
+	wal.proxyMu.Lock()
+	defer wal.proxyMu.Unlock()
+	if wal.proxyClients == nil {
+		wal.proxyClients = make(map[string]*http.Client)
+	}
+	if client, ok := wal.proxyClients[subsystem]; ok {
+		return client
+	}
+
+	proxyURL := ProxyURL(addr, subsystem, isolation, session)
+	client := &http.Client{
+		Timeout: proxyHTTPTimeout,
+		Transport: &http.Transport{
+			Proxy: http.ProxyURL(proxyURL),
+		},
+	}
+	wal.proxyClients[subsystem] = client
+	return client
+}
+
+// CheckDirectConnection returns ErrDirectConnection if the proxy is on and
+// the subsystem connects directly, unless direct connections are allowed.
+// The DEX is checked to use the current proxy settings.
+func (wal *Wallet) CheckDirectConnection(subsystem string) error {
+	if subsystem == ProxySubsystemDEX {
+		return wal.checkDEXProxy()
+	}
+	if !directSubsystems[subsystem] || !wal.ProxyEnabled() || wal.ProxyAllowDirect() {
+		return nil
+	}
+	return fmt.Errorf("%s %w", proxySubsystemName(subsystem), ErrDirectConnection)
+}
+
+// checkDEXProxy returns ErrDEXProxyChanged if the DEX client was created
+// with another proxy than the current one.
+func (wal *Wallet) checkDEXProxy() error {
+	if wal.multi == nil || wal.multi.DexClient() == nil {
+		return nil
+	}
+	addr, created := wal.multi.DexClient().DexProxy()
+	if created && addr != wal.ProxyAddress() {
+		return ErrDEXProxyChanged
+	}
+	return nil
+}
+
+// SyncPoliteia syncs the proposals unless the proposals sync is turned off
+// or the proxy blocks direct connections.
+func (wal *Wallet) SyncPoliteia() {
+	if err := wal.CheckConnection(ProxySubsystemGovernance); err != nil {
+		log.Info(err)
+		return
+	}
+	if err := wal.multi.Politeia.Sync(); err != nil {
+		log.Error(err)
+	}
+}
+
+func newProxySession() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
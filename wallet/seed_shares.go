@@ -0,0 +1,204 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"decred.org/dcrwallet/v2/walletseed"
+	"github.com/decred/dcrd/hdkeychain/v3"
+)
+
+// MaxSeedShares is the maximum number of shares a seed can be split into.
+const MaxSeedShares = 16
+
+// seedShareHeaderLen is the length of the share header: the 2 bytes
+// identifier of the split, the threshold and the share index.
+const seedShareHeaderLen = 4
+
+// MaxSplitSeedBytes is the length of the longest seed that can be split, the
+// encoded shares are decoded like seeds and cannot be longer than the
+// longest seed.
+const MaxSplitSeedBytes = hdkeychain.MaxSeedBytes - seedShareHeaderLen
+
+var (
+	// ErrNotEnoughShares is returned when combining fewer shares than the
+	// threshold of the split.
+	ErrNotEnoughShares = errors.New("not enough shares to restore the seed")
+
+	// ErrMixedShares is returned when combining shares of different splits.
+	ErrMixedShares = errors.New("the shares are not from the same split")
+
+	// ErrDuplicateShare is returned when combining a share twice.
+	ErrDuplicateShare = errors.New("the same share was entered twice")
+)
+
+// SeedShare is a share of a seed split with SplitSeed. Any Threshold shares
+// of the split restore the seed, fewer reveal nothing about it.
+type SeedShare struct {
+	// ID identifies the split, the shares of a split have the same ID.
+	ID        uint16
+	Threshold int
+	// Index is the x coordinate of the share, from 1 to the number of
+	// shares of the split.
+	Index int
+	data  []byte
+}
+
+// String encodes the share as a PGP word list with a checksum word, like a
+// seed.
+func (share *SeedShare) String() string {
+	b := make([]byte, 0, seedShareHeaderLen+len(share.data))
+	b = append(b, byte(share.ID>>8), byte(share.ID), byte(share.Threshold), byte(share.Index))
+	b = append(b, share.data...)
+	return walletseed.EncodeMnemonic(b)
+}
+
+// DecodeSeedShare decodes a share encoded as a word list or in hex.
+func DecodeSeedShare(input string) (*SeedShare, error) {
+	b, err := walletseed.DecodeUserInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	share := &SeedShare{
+		ID:        uint16(b[0])<<8 | uint16(b[1]),
+		Threshold: int(b[2]),
+		Index:     int(b[3]),
+		data:      b[seedShareHeaderLen:],
+	}
+	if share.Threshold < 2 || share.Threshold > MaxSeedShares || share.Index < 1 || share.Index > MaxSeedShares {
+		return nil, errors.New("not a seed share")
+	}
+	return share, nil
+}
+
+// SplitSeed splits the seed, as a word list or in hex, into n shares, any
+// threshold of them restore the seed.
+func SplitSeed(seed string, threshold, n int) ([]*SeedShare, error) {
+	if threshold < 2 || threshold > n || n > MaxSeedShares {
+		return nil, fmt.Errorf("invalid split: %d of %d shares, 2 to %d shares are needed", threshold, n, MaxSeedShares)
+	}
+
+	secret, err := walletseed.DecodeUserInput(seed)
+	if err != nil {
+		return nil, err
+	}
+	if len(secret) > MaxSplitSeedBytes {
+		return nil, fmt.Errorf("the seed is %d bytes long, seeds of up to %d bytes can be split", len(secret), MaxSplitSeedBytes)
+	}
+
+	var id [2]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+
+	shares := make([]*SeedShare, n)
+	for i := range shares {
+		shares[i] = &SeedShare{
+			ID:        uint16(id[0])<<8 | uint16(id[1]),
+			Threshold: threshold,
+			Index:     i + 1,
+			data:      make([]byte, len(secret)),
+		}
+	}
+
+	// Each byte of the seed is the constant term of a random polynomial of
+	// degree threshold-1, the shares are points of the polynomials.
+	coefficients := make([]byte, threshold)
+	for i, s := range secret {
+		coefficients[0] = s
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, err
+		}
+		for _, share := range shares {
+			share.data[i] = gfEval(coefficients, byte(share.Index))
+		}
+	}
+	return shares, nil
+}
+
+// CombineSeedShares restores the seed from the shares of a split and returns
+// it as a word list.
+func CombineSeedShares(shares []*SeedShare) (string, error) {
+	if len(shares) == 0 {
+		return "", ErrNotEnoughShares
+	}
+
+	first := shares[0]
+	seen := make(map[int]bool, len(shares))
+	for _, share := range shares {
+		if share.ID != first.ID || share.Threshold != first.Threshold || len(share.data) != len(first.data) {
+			return "", ErrMixedShares
+		}
+		if seen[share.Index] {
+			return "", ErrDuplicateShare
+		}
+		seen[share.Index] = true
+	}
+	if len(shares) < first.Threshold {
+		return "", ErrNotEnoughShares
+	}
+	shares = shares[:first.Threshold]
+
+	// Lagrange interpolation of the polynomials at x = 0.
+	secret := make([]byte, len(first.data))
+	for i, share := range shares {
+		xi := byte(share.Index)
+		basis := byte(1)
+		for j, other := range shares {
+			if i == j {
+				continue
+			}
+			xj := byte(other.Index)
+			basis = gfMul(basis, gfDiv(xj, xj^xi))
+		}
+		for k := range secret {
+			secret[k] ^= gfMul(share.data[k], basis)
+		}
+	}
+	return walletseed.EncodeMnemonic(secret), nil
+}
+
+// SameShare checks if a and b are the same share.
+func SameShare(a, b *SeedShare) bool {
+	return a.ID == b.ID && a.Threshold == b.Threshold && a.Index == b.Index && bytes.Equal(a.data, b.data)
+}
+
+// GF(2^8) arithmetic with the AES polynomial x^8 + x^4 + x^3 + x + 1.
+var gfExp, gfLog [256]byte
+
+func init() {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		gfExp[i] = x
+		gfLog[x] = byte(i)
+		// multiply by the generator 3
+		x ^= x<<1 ^ (x>>7)*0x1b
+	}
+	gfExp[255] = gfExp[0]
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[(int(gfLog[a])+int(gfLog[b]))%255]
+}
+
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[(int(gfLog[a])-int(gfLog[b])+255)%255]
+}
+
+// gfEval evaluates the polynomial with the coefficients at x.
+func gfEval(coefficients []byte, x byte) byte {
+	var y byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ coefficients[i]
+	}
+	return y
+}
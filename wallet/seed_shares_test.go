@@ -0,0 +1,106 @@
+package wallet_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+
+	"decred.org/dcrwallet/v2/walletseed"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/planetdecred/godcr/wallet"
+)
+
+var _ = Describe("Seed shares", func() {
+	const seed = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	It("restores the seed from any threshold shares", func() {
+		shares, err := wallet.SplitSeed(seed, 2, 3)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shares).To(HaveLen(3))
+
+		seedBytes, err := hex.DecodeString(seed)
+		Expect(err).ToNot(HaveOccurred())
+		expected := walletseed.EncodeMnemonic(seedBytes)
+
+		for _, pair := range [][]int{{0, 1}, {0, 2}, {2, 1}} {
+			restored, err := wallet.CombineSeedShares([]*wallet.SeedShare{shares[pair[0]], shares[pair[1]]})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restored).To(Equal(expected))
+		}
+	})
+
+	It("decodes encoded shares", func() {
+		shares, err := wallet.SplitSeed(seed, 3, 5)
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, share := range shares {
+			decoded, err := wallet.DecodeSeedShare(share.String())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(wallet.SameShare(decoded, share)).To(BeTrue())
+		}
+	})
+
+	It("needs the threshold of shares", func() {
+		shares, err := wallet.SplitSeed(seed, 3, 5)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = wallet.CombineSeedShares(shares[:2])
+		Expect(err).To(Equal(wallet.ErrNotEnoughShares))
+
+		_, err = wallet.CombineSeedShares([]*wallet.SeedShare{shares[0], shares[0], shares[1]})
+		Expect(err).To(Equal(wallet.ErrDuplicateShare))
+	})
+
+	It("rejects shares of different splits", func() {
+		a, err := wallet.SplitSeed(seed, 2, 2)
+		Expect(err).ToNot(HaveOccurred())
+		b, err := wallet.SplitSeed(seed, 2, 2)
+		Expect(err).ToNot(HaveOccurred())
+		if a[0].ID == b[0].ID {
+			Skip("the splits have the same random ID")
+		}
+
+		_, err = wallet.CombineSeedShares([]*wallet.SeedShare{a[0], b[1]})
+		Expect(err).To(Equal(wallet.ErrMixedShares))
+	})
+
+	It("rejects invalid splits", func() {
+		_, err := wallet.SplitSeed(seed, 1, 3)
+		Expect(err).To(HaveOccurred())
+		_, err = wallet.SplitSeed(seed, 4, 3)
+		Expect(err).To(HaveOccurred())
+		_, err = wallet.SplitSeed(seed, 2, wallet.MaxSeedShares+1)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("splits the longest seed the shares can be decoded of", func() {
+		longest := strings.Repeat("ab", wallet.MaxSplitSeedBytes)
+		shares, err := wallet.SplitSeed(longest, 2, 2)
+		Expect(err).ToNot(HaveOccurred())
+
+		decoded := make([]*wallet.SeedShare, len(shares))
+		for i, share := range shares {
+			decoded[i], err = wallet.DecodeSeedShare(share.String())
+			Expect(err).ToNot(HaveOccurred())
+		}
+		restored, err := wallet.CombineSeedShares(decoded)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restored).To(Equal(walletseed.EncodeMnemonic(bytes.Repeat([]byte{0xab}, wallet.MaxSplitSeedBytes))))
+
+		_, err = wallet.SplitSeed(longest+"ab", 2, 2)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects a mistyped share", func() {
+		shares, err := wallet.SplitSeed(seed, 2, 3)
+		Expect(err).ToNot(HaveOccurred())
+
+		words := strings.Fields(shares[0].String())
+		words[5], words[7] = words[7], words[5]
+		_, err = wallet.DecodeSeedShare(strings.Join(words, " "))
+		Expect(err).To(HaveOccurred())
+	})
+})
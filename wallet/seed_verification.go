@@ -0,0 +1,135 @@
+package wallet
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/planetdecred/dcrlibwallet"
+)
+
+const (
+	// SeedVerificationIntervalConfigKey is the multiwallet config key of
+	// the number of days after which a seed backup should be verified
+	// again, SeedVerificationOff disables the reminders.
+	SeedVerificationIntervalConfigKey = "seed_verification_interval"
+
+	// SeedVerificationOff is the seed verification interval that disables
+	// the reminders.
+	SeedVerificationOff = "off"
+
+	// DefaultSeedVerificationInterval is the number of days between seed
+	// verifications unless configured otherwise. The intervals are zero
+	// padded to list them in order.
+	DefaultSeedVerificationInterval = "090"
+
+	seedVerifiedConfigKey = "seed_last_verified"
+)
+
+// BackupHealth is the state of the seed backup of a wallet.
+type BackupHealth int
+
+const (
+	// BackupHealthy is a seed backup verified within the verification
+	// interval, or a wallet without a seed.
+	BackupHealthy BackupHealth = iota
+	// BackupMissing is a seed that was never backed up.
+	BackupMissing
+	// BackupStale is a seed backup that was not verified within the
+	// verification interval.
+	BackupStale
+)
+
+// SeedVerificationInterval returns the time after which a seed backup
+// should be verified again, 0 if the reminders are disabled.
+func (wal *Wallet) SeedVerificationInterval() time.Duration {
+	return ParseSeedVerificationInterval(wal.ReadStringConfigValueForKey(SeedVerificationIntervalConfigKey))
+}
+
+// ParseSeedVerificationInterval returns the time of the seed verification
+// interval setting, the default interval if it is empty and 0 if the
+// reminders are disabled.
+func ParseSeedVerificationInterval(value string) time.Duration {
+	if value == "" {
+		value = DefaultSeedVerificationInterval
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days <= 0 {
+		return 0
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// SeedLastVerified returns the time the seed backup of the wallet was last
+// verified, the zero time if it never was.
+func (wal *Wallet) SeedLastVerified(wall *dcrlibwallet.Wallet) time.Time {
+	timestamp := wall.ReadLongConfigValueForKey(seedVerifiedConfigKey, 0)
+	if timestamp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(timestamp, 0)
+}
+
+// SeedBackupStale checks if a seed backup last verified at lastVerified
+// should be verified again at now. A backup without a verification time is
+// not stale, it is given one by SeedBackupHealth.
+func SeedBackupStale(lastVerified time.Time, interval time.Duration, now time.Time) bool {
+	return interval > 0 && !lastVerified.IsZero() && now.Sub(lastVerified) > interval
+}
+
+// MarkSeedVerified records that the seed backup of the wallet was verified
+// now.
+func (wal *Wallet) MarkSeedVerified(walletID int) {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return
+	}
+	wall.SetLongConfigValueForKey(seedVerifiedConfigKey, time.Now().Unix())
+}
+
+// ReverifySeed checks that the seed entered by the user belongs to the
+// wallet and records the verification. The seed stored by the wallet is not
+// needed, it is usually deleted after the first backup.
+func (wal *Wallet) ReverifySeed(walletID int, seed string) error {
+	if err := wal.VerifyWalletSeed(walletID, seed); err != nil {
+		return err
+	}
+	wal.MarkSeedVerified(walletID)
+	return nil
+}
+
+// SeedBackupHealth returns the state of the seed backup of the wallet. The
+// seeds backed up before the verifications were recorded, or backed up
+// without one, are given the first check as their verification time, so
+// that the reminders start one interval later instead of all at once.
+func (wal *Wallet) SeedBackupHealth(wall *dcrlibwallet.Wallet) BackupHealth {
+	switch {
+	case wall.IsWatchingOnlyWallet():
+		return BackupHealthy
+	case wall.EncryptedSeed != nil:
+		return BackupMissing
+	}
+
+	lastVerified := wal.SeedLastVerified(wall)
+	if lastVerified.IsZero() {
+		wall.SetLongConfigValueForKey(seedVerifiedConfigKey, time.Now().Unix())
+		return BackupHealthy
+	}
+
+	if SeedBackupStale(lastVerified, wal.SeedVerificationInterval(), time.Now()) {
+		return BackupStale
+	}
+	return BackupHealthy
+}
+
+// WalletsWithStaleSeedBackup returns the wallets whose seed backup should be
+// verified again.
+func (wal *Wallet) WalletsWithStaleSeedBackup() []*dcrlibwallet.Wallet {
+	var wallets []*dcrlibwallet.Wallet
+	for _, wall := range wal.multi.AllWallets() {
+		if wal.SeedBackupHealth(wall) == BackupStale {
+			wallets = append(wallets, wall)
+		}
+	}
+	return wallets
+}
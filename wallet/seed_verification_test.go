@@ -0,0 +1,70 @@
+package wallet_test
+
+import (
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/planetdecred/dcrlibwallet"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+var _ = Describe("Seed verification", func() {
+	const day = 24 * time.Hour
+
+	It("parses the verification interval", func() {
+		Expect(wallet.ParseSeedVerificationInterval("")).To(Equal(90 * day))
+		Expect(wallet.ParseSeedVerificationInterval("030")).To(Equal(30 * day))
+		Expect(wallet.ParseSeedVerificationInterval("365")).To(Equal(365 * day))
+		Expect(wallet.ParseSeedVerificationInterval(wallet.SeedVerificationOff)).To(BeZero())
+		Expect(wallet.ParseSeedVerificationInterval("0")).To(BeZero())
+	})
+
+	It("finds the backups not verified within the interval", func() {
+		now := time.Now()
+		Expect(wallet.SeedBackupStale(now.Add(-91*day), 90*day, now)).To(BeTrue())
+		Expect(wallet.SeedBackupStale(now.Add(-89*day), 90*day, now)).To(BeFalse())
+		Expect(wallet.SeedBackupStale(now.Add(-400*day), 0, now)).To(BeFalse())
+		Expect(wallet.SeedBackupStale(time.Time{}, 90*day, now)).To(BeFalse())
+	})
+
+	Context("with a wallet backed up before the verifications were recorded", func() {
+		var (
+			wal  *wallet.Wallet
+			wall *dcrlibwallet.Wallet
+			root string
+		)
+
+		BeforeEach(func() {
+			var err error
+			root, err = os.MkdirTemp("", "godcr-seed")
+			Expect(err).ToNot(HaveOccurred())
+			wal, err = wallet.NewWallet(root, dcrlibwallet.Testnet3, "dev", "", time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(wal.InitMultiWallet()).To(Succeed())
+
+			seed, err := dcrlibwallet.GenerateSeed()
+			Expect(err).ToNot(HaveOccurred())
+			wall, err = wal.GetMultiWallet().RestoreWallet("restored", seed, "password", dcrlibwallet.PassphraseTypePass)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			wal.Shutdown()
+			os.RemoveAll(root)
+		})
+
+		It("starts the interval at the first check instead of reminding at once", func() {
+			Expect(wal.SeedLastVerified(wall).IsZero()).To(BeTrue())
+			Expect(wal.SeedBackupHealth(wall)).To(Equal(wallet.BackupHealthy))
+
+			verified := wal.SeedLastVerified(wall)
+			Expect(verified).To(BeTemporally("~", time.Now(), time.Minute))
+			Expect(wal.SeedBackupHealth(wall)).To(Equal(wallet.BackupHealthy))
+			Expect(wal.SeedLastVerified(wall)).To(Equal(verified))
+			Expect(wal.WalletsWithStaleSeedBackup()).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,252 @@
+package wallet
+
+import (
+	"strings"
+	"syscall"
+	"time"
+
+	"decred.org/dcrwallet/v2/errors"
+)
+
+const (
+	// SyncRetryConfigKey is the multiwallet config key of the policy used
+	// to restart a sync that ended with an error.
+	SyncRetryConfigKey = "sync_retry_policy"
+
+	// SyncRetryOff never restarts a failed sync.
+	SyncRetryOff = "off"
+	// SyncRetryFixed restarts a failed sync after syncRetryFixedDelay.
+	SyncRetryFixed = "fixed"
+	// SyncRetryBackoff restarts a failed sync after a delay that doubles
+	// with every failed attempt, up to syncRetryMaxDelay.
+	SyncRetryBackoff = "backoff"
+
+	// DefaultSyncRetry is the retry policy unless configured otherwise.
+	DefaultSyncRetry = SyncRetryBackoff
+
+	syncRetryFixedDelay   = time.Minute
+	syncRetryInitialDelay = 30 * time.Second
+	syncRetryMaxDelay     = 30 * time.Minute
+
+	// maxSyncEvents is the number of sync events kept in the history.
+	maxSyncEvents = 100
+)
+
+// SyncErrorKind is the cause of a sync that ended with an error.
+type SyncErrorKind int
+
+const (
+	// SyncErrorUnknown is an error that does not match any known cause.
+	SyncErrorUnknown SyncErrorKind = iota
+	// SyncErrorNoPeers is a sync that could not reach the Decred network.
+	SyncErrorNoPeers
+	// SyncErrorPeerMisbehaving is a peer that sent invalid data.
+	SyncErrorPeerMisbehaving
+	// SyncErrorDatabase is a failure reading or writing the wallet database.
+	SyncErrorDatabase
+	// SyncErrorDiskFull is a disk without space left for the wallet data.
+	SyncErrorDiskFull
+)
+
+// ClassifySyncError returns the cause of a sync error.
+func ClassifySyncError(err error) SyncErrorKind {
+	if err == nil {
+		return SyncErrorUnknown
+	}
+
+	msg := strings.ToLower(err.Error())
+	switch {
+	case errors.Is(err, syscall.ENOSPC) || strings.Contains(msg, "no space left"):
+		return SyncErrorDiskFull
+	case errors.Is(err, errors.NoPeers) || strings.Contains(msg, "deadline exceeded"):
+		return SyncErrorNoPeers
+	case errors.Is(err, errors.Protocol) || errors.Is(err, errors.Consensus):
+		return SyncErrorPeerMisbehaving
+	case errors.Is(err, errors.IO) || strings.Contains(msg, "database") || strings.Contains(msg, "bolt"):
+		return SyncErrorDatabase
+	}
+	return SyncErrorUnknown
+}
+
+// Title returns a short description of the error kind.
+func (k SyncErrorKind) Title() string {
+	switch k {
+	case SyncErrorNoPeers:
+		return "No peers available"
+	case SyncErrorPeerMisbehaving:
+		return "Peer sent invalid data"
+	case SyncErrorDatabase:
+		return "Wallet database error"
+	case SyncErrorDiskFull:
+		return "Disk full"
+	}
+	return "Sync failed"
+}
+
+// Explanation tells the user what went wrong and what they may do about it.
+func (k SyncErrorKind) Explanation() string {
+	switch k {
+	case SyncErrorNoPeers:
+		return "The wallet could not connect to the Decred network. Check your internet connection, or the address of the specific peer in the settings."
+	case SyncErrorPeerMisbehaving:
+		return "A peer sent blocks or headers that are not valid. The sync stopped to keep the wallet safe, another peer will be used when it restarts."
+	case SyncErrorDatabase:
+		return "The wallet database could not be read or written. Restart the app, if this keeps happening check the wallet files and the log."
+	case SyncErrorDiskFull:
+		return "There is no space left on the disk for the wallet data. Free some space then reconnect."
+	}
+	return "The sync stopped with an unexpected error. Check the log for details."
+}
+
+// Retryable returns true if restarting the sync may fix the error. Database
+// and disk errors need the user to act first.
+func (k SyncErrorKind) Retryable() bool {
+	return k != SyncErrorDatabase && k != SyncErrorDiskFull
+}
+
+// SyncError is a sync that ended with an error.
+type SyncError struct {
+	Kind SyncErrorKind
+	Err  error
+	Time time.Time
+}
+
+// SyncEvent is an entry of the sync event history.
+type SyncEvent struct {
+	Time        time.Time
+	Description string
+	// Err is set for the sync errors.
+	Err *SyncError
+}
+
+// SyncRetryDelay returns the delay before the failed attempt number attempt,
+// counted from 1, is restarted under the retry policy. 0 means the sync is
+// not restarted.
+func SyncRetryDelay(policy string, attempt int) time.Duration {
+	if attempt < 1 {
+		return 0
+	}
+
+	switch policy {
+	case SyncRetryFixed:
+		return syncRetryFixedDelay
+	case SyncRetryBackoff:
+		delay := syncRetryInitialDelay
+		for i := 1; i < attempt && delay < syncRetryMaxDelay; i++ {
+			delay *= 2
+		}
+		if delay > syncRetryMaxDelay {
+			delay = syncRetryMaxDelay
+		}
+		return delay
+	}
+	return 0
+}
+
+// RecordSyncEvent adds an event to the sync event history.
+func (wal *Wallet) RecordSyncEvent(description string) {
+	wal.addSyncEvent(SyncEvent{Time: time.Now(), Description: description})
+}
+
+func (wal *Wallet) addSyncEvent(event SyncEvent) {
+	if event.Err != nil {
+		log.Warnf("Sync event: %s: %v", event.Description, event.Err.Err)
+	} else {
+		log.Infof("Sync event: %s", event.Description)
+	}
+
+	wal.syncMu.Lock()
+	defer wal.syncMu.Unlock()
+	wal.syncEvents = append(wal.syncEvents, event)
+	if len(wal.syncEvents) > maxSyncEvents {
+		wal.syncEvents = wal.syncEvents[len(wal.syncEvents)-maxSyncEvents:]
+	}
+}
+
+// SyncEvents returns the sync event history, the most recent event first.
+func (wal *Wallet) SyncEvents() []SyncEvent {
+	wal.syncMu.Lock()
+	defer wal.syncMu.Unlock()
+
+	events := make([]SyncEvent, len(wal.syncEvents))
+	for i, event := range wal.syncEvents {
+		events[len(events)-1-i] = event
+	}
+	return events
+}
+
+// LastSyncError returns the error of the last failed sync and the time the
+// sync is restarted, the zero time if it is not. The error is nil once a
+// sync completes.
+func (wal *Wallet) LastSyncError() (*SyncError, time.Time) {
+	wal.syncMu.Lock()
+	defer wal.syncMu.Unlock()
+	return wal.lastSyncError, wal.nextSyncRetry
+}
+
+// HandleSyncError records a failed sync and, if the retry policy and the
+// kind of error allow it, calls restart once the retry delay passes.
+func (wal *Wallet) HandleSyncError(err error, restart func()) *SyncError {
+	syncErr := &SyncError{Kind: ClassifySyncError(err), Err: err, Time: time.Now()}
+	wal.addSyncEvent(SyncEvent{Time: syncErr.Time, Description: syncErr.Kind.Title(), Err: syncErr})
+
+	policy := wal.ReadStringConfigValueForKey(SyncRetryConfigKey)
+	if policy == "" {
+		policy = DefaultSyncRetry
+	}
+
+	wal.syncMu.Lock()
+	defer wal.syncMu.Unlock()
+	wal.stopSyncRetry()
+	wal.lastSyncError = syncErr
+	if !syncErr.Kind.Retryable() {
+		return syncErr
+	}
+
+	wal.syncAttempts++
+	delay := SyncRetryDelay(policy, wal.syncAttempts)
+	if delay == 0 {
+		return syncErr
+	}
+
+	wal.nextSyncRetry = time.Now().Add(delay)
+	wal.syncRetryTimer = time.AfterFunc(delay, func() {
+		wal.syncMu.Lock()
+		wal.syncRetryTimer = nil
+		wal.nextSyncRetry = time.Time{}
+		wal.syncMu.Unlock()
+
+		if wal.multi.IsSyncing() || wal.multi.IsSynced() {
+			return
+		}
+		wal.RecordSyncEvent("Restarting sync after error")
+		restart()
+	})
+	return syncErr
+}
+
+// SyncCompleted clears the last sync error and the failed attempts.
+func (wal *Wallet) SyncCompleted() {
+	wal.syncMu.Lock()
+	defer wal.syncMu.Unlock()
+	wal.stopSyncRetry()
+	wal.syncAttempts = 0
+	wal.lastSyncError = nil
+}
+
+// CancelSyncRetry stops a scheduled sync restart, used when the user
+// disconnects.
+func (wal *Wallet) CancelSyncRetry() {
+	wal.syncMu.Lock()
+	defer wal.syncMu.Unlock()
+	wal.stopSyncRetry()
+}
+
+// stopSyncRetry must be called with syncMu held.
+func (wal *Wallet) stopSyncRetry() {
+	if wal.syncRetryTimer != nil {
+		wal.syncRetryTimer.Stop()
+		wal.syncRetryTimer = nil
+	}
+	wal.nextSyncRetry = time.Time{}
+}
@@ -0,0 +1,42 @@
+package wallet_test
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+
+	"decred.org/dcrwallet/v2/errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/planetdecred/godcr/wallet"
+)
+
+var _ = Describe("Sync errors", func() {
+	It("classifies sync errors", func() {
+		Expect(wallet.ClassifySyncError(errors.E(errors.NoPeers))).To(Equal(wallet.SyncErrorNoPeers))
+		Expect(wallet.ClassifySyncError(fmt.Errorf("SPV synchronization deadline exceeded: %v", "context deadline exceeded"))).
+			To(Equal(wallet.SyncErrorNoPeers))
+		Expect(wallet.ClassifySyncError(errors.E("sync", errors.Protocol, "peer announced old header"))).
+			To(Equal(wallet.SyncErrorPeerMisbehaving))
+		Expect(wallet.ClassifySyncError(errors.E(errors.IO, "bolt: read failed"))).To(Equal(wallet.SyncErrorDatabase))
+		Expect(wallet.ClassifySyncError(fmt.Errorf("write wallet.db: %w", syscall.ENOSPC))).To(Equal(wallet.SyncErrorDiskFull))
+		Expect(wallet.ClassifySyncError(fmt.Errorf("something else"))).To(Equal(wallet.SyncErrorUnknown))
+	})
+
+	It("does not retry errors the user must fix", func() {
+		Expect(wallet.SyncErrorNoPeers.Retryable()).To(BeTrue())
+		Expect(wallet.SyncErrorDatabase.Retryable()).To(BeFalse())
+		Expect(wallet.SyncErrorDiskFull.Retryable()).To(BeFalse())
+	})
+
+	It("computes the retry delays of each policy", func() {
+		Expect(wallet.SyncRetryDelay(wallet.SyncRetryOff, 1)).To(BeZero())
+		Expect(wallet.SyncRetryDelay(wallet.SyncRetryFixed, 5)).To(Equal(time.Minute))
+		Expect(wallet.SyncRetryDelay(wallet.SyncRetryBackoff, 1)).To(Equal(30 * time.Second))
+		Expect(wallet.SyncRetryDelay(wallet.SyncRetryBackoff, 3)).To(Equal(2 * time.Minute))
+		Expect(wallet.SyncRetryDelay(wallet.SyncRetryBackoff, 20)).To(Equal(30 * time.Minute))
+		Expect(wallet.SyncRetryDelay(wallet.SyncRetryBackoff, 0)).To(BeZero())
+	})
+})
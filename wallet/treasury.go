@@ -0,0 +1,518 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"decred.org/dcrwallet/v2/errors"
+	dcrwallet "decred.org/dcrwallet/v2/wallet"
+	"github.com/decred/dcrd/blockchain/stake/v4"
+	"github.com/decred/dcrd/blockchain/standalone/v2"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"github.com/planetdecred/dcrlibwallet"
+)
+
+const (
+	TreasuryVoteYes     = "yes"
+	TreasuryVoteNo      = "no"
+	TreasuryVoteAbstain = "abstain"
+
+	// treasuryTalliesConfigKey is the multiwallet config key of the tallies
+	// of the pending treasury spends, counted up to the last fetched block.
+	treasuryTalliesConfigKey = "treasury_tallies"
+
+	// tallyBatchSize is the number of blocks fetched at once from the peers
+	// to count the treasury spend votes.
+	tallyBatchSize = 16
+
+	// treasurySpendsConfigKey is the multiwallet config key under which the
+	// serialized treasury spends added by the user are stored. The wallets
+	// learn about pending tspends from the mempool of their peers, the ones
+	// added by hand cover the tspends the peers have not announced yet.
+	treasurySpendsConfigKey = "treasury_spends"
+
+	// piKeyOffset is the offset of the Pi key in the signature script of
+	// a treasury spend input.
+	piKeyOffset = 66
+	piKeySize   = 33
+)
+
+// TreasurySpend is a pending treasury spend and the voting policies the
+// wallet has for it.
+type TreasurySpend struct {
+	Hash   string
+	Amount int64
+	Expiry uint32
+	PiKey  string
+
+	// Policy is the vote of the wallet for the tspend, either set for the
+	// tspend directly or for its Pi key.
+	Policy string
+	// KeyPolicy is the vote of the wallet for all tspends signed by PiKey.
+	KeyPolicy string
+
+	// PolicyYes, PolicyNo and PolicyAbstain are the number of unspent
+	// tickets of the wallet whose policy votes each way on the tspend. They
+	// are not votes cast yet, see TreasurySpendTally for those.
+	PolicyYes, PolicyNo, PolicyAbstain int
+}
+
+// TreasurySpendTally is the count of the votes cast on chain by all tickets
+// for a treasury spend, in the blocks of its voting window.
+type TreasurySpendTally struct {
+	// Start and End are the heights of the voting window, the votes are
+	// cast in the blocks from Start to End-1.
+	Start uint32 `json:"start"`
+	End   uint32 `json:"end"`
+	// Height is the last block counted and BlockHash its hash, Height is
+	// Start-1 until the voting starts.
+	Height    int32  `json:"height"`
+	BlockHash string `json:"blockhash"`
+
+	Yes int `json:"yes"`
+	No  int `json:"no"`
+
+	// MaxVotes is the number of votes cast in the whole window, Quorum the
+	// number of yes and no votes needed for the vote to count.
+	MaxVotes int `json:"maxvotes"`
+	Quorum   int `json:"quorum"`
+}
+
+// Started returns true if blocks of the voting window were counted.
+func (t *TreasurySpendTally) Started() bool {
+	return t.Height >= int32(t.Start)
+}
+
+// TreasurySpends returns the pending treasury spends known to the wallet,
+// including the ones added with AddTreasurySpend.
+func (wal *Wallet) TreasurySpends(walletID int) ([]*TreasurySpend, error) {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return nil, ErrIDNotExist
+	}
+
+	wal.loadTreasurySpends(wall)
+
+	ctx := context.Background()
+	w := wall.Internal()
+
+	var tickets []*chainhash.Hash
+	err := w.ForUnspentUnexpiredTickets(ctx, func(hash *chainhash.Hash) error {
+		tickets = append(tickets, hash)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	txs := w.GetAllTSpends(ctx)
+	tspends := make([]*TreasurySpend, 0, len(txs))
+	for _, tx := range txs {
+		hash := tx.TxHash()
+		piKey := treasurySpendPiKey(tx)
+
+		tspend := &TreasurySpend{
+			Hash:      hash.String(),
+			Amount:    treasurySpendAmount(tx),
+			Expiry:    tx.Expiry,
+			PiKey:     hex.EncodeToString(piKey),
+			Policy:    treasuryVoteString(w.TSpendPolicy(&hash, nil)),
+			KeyPolicy: treasuryVoteString(w.TreasuryKeyPolicy(piKey, nil)),
+		}
+
+		for _, ticket := range tickets {
+			switch w.TSpendPolicy(&hash, ticket) {
+			case stake.TreasuryVoteYes:
+				tspend.PolicyYes++
+			case stake.TreasuryVoteNo:
+				tspend.PolicyNo++
+			default:
+				tspend.PolicyAbstain++
+			}
+		}
+
+		tspends = append(tspends, tspend)
+	}
+
+	return tspends, nil
+}
+
+// TreasurySpendTally counts the votes cast on chain for the treasury spend
+// in the blocks of its voting window up to the tip of the wallet. The blocks
+// are fetched from the peers of the SPV sync and the tally is saved, only
+// the blocks added since the last count are fetched. The tally counted so
+// far is returned with the error if the blocks cannot be fetched.
+func (wal *Wallet) TreasurySpendTally(walletID int, tspend *TreasurySpend) (*TreasurySpendTally, error) {
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return nil, ErrIDNotExist
+	}
+	hash, err := chainhash.NewHashFromStr(tspend.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hash: %v", err)
+	}
+
+	params := NetworkParams(wal.Net)
+	start, end, err := standalone.CalcTSpendWindow(tspend.Expiry, params.TreasuryVoteInterval, params.TreasuryVoteIntervalMultiplier)
+	if err != nil {
+		return nil, err
+	}
+
+	wal.tallyMu.Lock()
+	defer wal.tallyMu.Unlock()
+
+	tallies := make(map[string]*TreasurySpendTally)
+	_ = wal.multi.ReadUserConfigValue(treasuryTalliesConfigKey, &tallies)
+	defer wal.multi.SaveUserConfigValue(treasuryTalliesConfigKey, tallies)
+
+	ctx := context.Background()
+	w := wall.Internal()
+	tally := tallies[tspend.Hash]
+	if tally != nil && tally.Started() {
+		// Count again if the last counted block was reorganized out.
+		info, err := w.BlockInfo(ctx, dcrwallet.NewBlockIdentifierFromHeight(tally.Height))
+		if err != nil || info.Hash.String() != tally.BlockHash {
+			tally = nil
+		}
+	}
+	if tally == nil || tally.Start != start || tally.End != end {
+		maxVotes := int(params.TicketsPerBlock) * int(end-start)
+		tally = &TreasurySpendTally{
+			Start:    start,
+			End:      end,
+			Height:   int32(start) - 1,
+			MaxVotes: maxVotes,
+			Quorum:   maxVotes * int(params.TreasuryVoteQuorumMultiplier) / int(params.TreasuryVoteQuorumDivisor),
+		}
+		tallies[tspend.Hash] = tally
+	}
+
+	_, tip := w.MainChainTip(ctx)
+	last := int32(end) - 1
+	if tip < last {
+		last = tip
+	}
+	for tally.Height < last {
+		var hashes []*chainhash.Hash
+		for height := tally.Height + 1; height <= last && len(hashes) < tallyBatchSize; height++ {
+			info, err := w.BlockInfo(ctx, dcrwallet.NewBlockIdentifierFromHeight(height))
+			if err != nil {
+				return tally, err
+			}
+			hashes = append(hashes, &info.Hash)
+		}
+
+		blocks, err := wal.multi.FetchBlocks(ctx, hashes)
+		if err != nil {
+			return tally, err
+		}
+		for i, block := range blocks {
+			yes, no := countTreasuryVotes(block, hash)
+			tally.Yes += yes
+			tally.No += no
+			tally.Height++
+			tally.BlockHash = hashes[i].String()
+		}
+	}
+	return tally, nil
+}
+
+// countTreasuryVotes returns the number of yes and no votes for the
+// treasury spend cast by the votes of the block.
+func countTreasuryVotes(block *wire.MsgBlock, tspend *chainhash.Hash) (yes, no int) {
+	for _, tx := range block.STransactions {
+		if !stake.IsSSGen(tx, true) {
+			continue
+		}
+		votes, err := stake.GetSSGenTreasuryVotes(tx.TxOut[len(tx.TxOut)-1].PkScript)
+		if err != nil {
+			// The vote has no treasury votes.
+			continue
+		}
+		for _, vote := range votes {
+			if vote.Hash != *tspend {
+				continue
+			}
+			switch vote.Vote {
+			case stake.TreasuryVoteYes:
+				yes++
+			case stake.TreasuryVoteNo:
+				no++
+			}
+		}
+	}
+	return yes, no
+}
+
+// AddTreasurySpend fetches the treasury spend with the given hash from the
+// configured block explorers and adds it to all wallets so that it can be
+// voted on. It is the fallback for tspends the peers have not announced.
+func (wal *Wallet) AddTreasurySpend(txHash string) error {
+	if _, err := chainhash.NewHashFromStr(txHash); err != nil {
+		return fmt.Errorf("invalid hash: %v", err)
+	}
+
+	serializedTx, err := wal.fetchRawTx(txHash)
+	if err != nil {
+		return err
+	}
+
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(serializedTx)); err != nil {
+		return fmt.Errorf("invalid transaction: %v", err)
+	}
+	if !stake.IsTSpend(&tx) {
+		return fmt.Errorf("%s is not a treasury spend", txHash)
+	}
+
+	var saved []string
+	_ = wal.multi.ReadUserConfigValue(treasurySpendsConfigKey, &saved)
+	for _, s := range saved {
+		if s == hex.EncodeToString(serializedTx) {
+			return nil
+		}
+	}
+
+	saved = append(saved, hex.EncodeToString(serializedTx))
+	wal.multi.SaveUserConfigValue(treasurySpendsConfigKey, saved)
+
+	for _, wall := range wal.multi.AllWallets() {
+		wal.loadTreasurySpends(wall)
+	}
+	return nil
+}
+
+// loadTreasurySpends adds the saved treasury spends to the tspend cache of
+// the wallet. Tspends that are already cached are ignored.
+func (wal *Wallet) loadTreasurySpends(wall *dcrlibwallet.Wallet) {
+	var saved []string
+	if err := wal.multi.ReadUserConfigValue(treasurySpendsConfigKey, &saved); err != nil {
+		return
+	}
+
+	for _, s := range saved {
+		serializedTx, err := hex.DecodeString(s)
+		if err != nil {
+			continue
+		}
+
+		var tx wire.MsgTx
+		if err := tx.Deserialize(bytes.NewReader(serializedTx)); err != nil {
+			continue
+		}
+		_ = wall.Internal().AddTSpend(tx)
+	}
+}
+
+// fetchRawTx fetches the serialized transaction from the block explorers
+// set in the settings that have the dcrdata API, the explorers are tried in
+// order until one has the transaction.
+func (wal *Wallet) fetchRawTx(txHash string) ([]byte, error) {
+	if err := wal.CheckConnection(ProxySubsystemExplorer); err != nil {
+		return nil, err
+	}
+
+	var urls []string
+	for _, e := range wal.BlockExplorersFor(ExplorerTransaction) {
+		if u := rawTxURL(e, txHash); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no block explorer with the dcrdata API is set for %s", wal.Net)
+	}
+
+	var err error
+	for _, u := range urls {
+		var serializedTx []byte
+		serializedTx, err = wal.fetchRawTxFrom(u)
+		if err == nil {
+			return serializedTx, nil
+		}
+		log.Warnf("Error fetching transaction %s from %s: %v", txHash, u, err)
+	}
+	return nil, err
+}
+
+// rawTxURL returns the dcrdata API URL of the serialized transaction, empty
+// if the transaction page of the explorer is not laid out like dcrdata's.
+func rawTxURL(e BlockExplorer, txHash string) string {
+	const txPath = "/tx/" + explorerHashPlaceholder
+	if !strings.HasSuffix(e.TxURL, txPath) {
+		return ""
+	}
+	return strings.TrimSuffix(e.TxURL, txPath) + "/api/tx/hex/" + txHash
+}
+
+func (wal *Wallet) fetchRawTxFrom(url string) ([]byte, error) {
+	resp, err := wal.HTTPClient(ProxySubsystemExplorer).Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%d response from server: %s", resp.StatusCode, string(body))
+	}
+
+	return hex.DecodeString(strings.Trim(strings.TrimSpace(string(body)), `"`))
+}
+
+// SetTreasurySpendPolicy sets the vote policy of the wallet for a treasury
+// spend. If ticketHash is empty the policy is set for all tickets of the
+// wallet. The VSPs of the affected tickets are updated with the new policy.
+func (wal *Wallet) SetTreasurySpendPolicy(walletID int, tspendHash, policy, ticketHash string, passphrase []byte) error {
+	hash, err := chainhash.NewHashFromStr(tspendHash)
+	if err != nil {
+		return fmt.Errorf("invalid hash: %v", err)
+	}
+
+	return wal.setTreasuryPolicy(walletID, ticketHash, passphrase, func(ctx context.Context, wall *dcrlibwallet.Wallet, ticket *chainhash.Hash) error {
+		return wall.Internal().SetTSpendPolicy(ctx, hash, treasuryVote(policy), ticket)
+	}, map[string]string{tspendHash: policy}, nil)
+}
+
+// SetTreasuryKeyPolicy sets the vote policy of the wallet for all treasury
+// spends signed by the Pi key. If ticketHash is empty the policy is set for
+// all tickets of the wallet. The VSPs of the affected tickets are updated
+// with the new policy.
+func (wal *Wallet) SetTreasuryKeyPolicy(walletID int, piKey, policy, ticketHash string, passphrase []byte) error {
+	key, err := hex.DecodeString(piKey)
+	if err != nil || len(key) != piKeySize {
+		return fmt.Errorf("invalid Pi key %q", piKey)
+	}
+
+	return wal.setTreasuryPolicy(walletID, ticketHash, passphrase, func(ctx context.Context, wall *dcrlibwallet.Wallet, ticket *chainhash.Hash) error {
+		return wall.Internal().SetTreasuryKeyPolicy(ctx, key, treasuryVote(policy), ticket)
+	}, nil, map[string]string{piKey: policy})
+}
+
+func (wal *Wallet) setTreasuryPolicy(walletID int, ticketHash string, passphrase []byte,
+	setPolicy func(context.Context, *dcrlibwallet.Wallet, *chainhash.Hash) error,
+	tspendPolicy, treasuryPolicy map[string]string) error {
+
+	wall := wal.multi.WalletWithID(walletID)
+	if wall == nil {
+		return ErrIDNotExist
+	}
+
+	var ticket *chainhash.Hash
+	if ticketHash != "" {
+		hash, err := chainhash.NewHashFromStr(ticketHash)
+		if err != nil {
+			return fmt.Errorf("invalid hash: %v", err)
+		}
+		ticket = hash
+	}
+
+	// The wallet will need to be unlocked to sign the API
+	// request(s) for setting this policy with the VSP.
+	err := wall.UnlockWallet(passphrase)
+	if err != nil {
+		return err
+	}
+	defer wall.LockWallet()
+
+	ctx := context.Background()
+	if err := setPolicy(ctx, wall, ticket); err != nil {
+		return err
+	}
+
+	ticketHashes := make([]*chainhash.Hash, 0)
+	if ticket != nil {
+		ticketHashes = append(ticketHashes, ticket)
+	} else {
+		err = wall.Internal().ForUnspentUnexpiredTickets(ctx, func(hash *chainhash.Hash) error {
+			ticketHashes = append(ticketHashes, hash)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("unable to fetch hashes for all unspent, unexpired tickets: %v", err)
+		}
+	}
+
+	// The policy is saved in the wallet, the VSPs cannot be reached while
+	// the proxy blocks direct connections.
+	if err := wal.CheckConnection(ProxySubsystemVSP); err != nil {
+		return fmt.Errorf("the policy is saved but the VSPs are not updated: %w", err)
+	}
+
+	// Never return errors from this for loop, so all tickets are tried.
+	// The first error will be returned to the caller.
+	var firstErr error
+	for _, tHash := range ticketHashes {
+		vspTicketInfo, err := wall.Internal().VSPTicketInfo(ctx, tHash)
+		if err != nil {
+			// Ignore NotExist error, just means the ticket is not
+			// registered with a VSP, nothing more to do here.
+			if firstErr == nil && !errors.Is(err, errors.NotExist) {
+				firstErr = err
+			}
+			continue
+		}
+
+		vspClient, err := wall.VSPClient(vspTicketInfo.Host, vspTicketInfo.PubKey)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		err = vspClient.SetVoteChoice(ctx, tHash, nil, tspendPolicy, treasuryPolicy)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+func treasurySpendAmount(tx *wire.MsgTx) int64 {
+	var amount int64
+	// the first output is the OP_RETURN carrying the tspend value commitment.
+	for _, out := range tx.TxOut[1:] {
+		amount += out.Value
+	}
+	return amount
+}
+
+func treasurySpendPiKey(tx *wire.MsgTx) []byte {
+	if len(tx.TxIn) == 0 || len(tx.TxIn[0].SignatureScript) < piKeyOffset+piKeySize {
+		return nil
+	}
+	return tx.TxIn[0].SignatureScript[piKeyOffset : piKeyOffset+piKeySize]
+}
+
+func treasuryVote(policy string) stake.TreasuryVoteT {
+	switch policy {
+	case TreasuryVoteYes:
+		return stake.TreasuryVoteYes
+	case TreasuryVoteNo:
+		return stake.TreasuryVoteNo
+	default:
+		return stake.TreasuryVoteInvalid
+	}
+}
+
+func treasuryVoteString(vote stake.TreasuryVoteT) string {
+	switch vote {
+	case stake.TreasuryVoteYes:
+		return TreasuryVoteYes
+	case stake.TreasuryVoteNo:
+		return TreasuryVoteNo
+	default:
+		return TreasuryVoteAbstain
+	}
+}
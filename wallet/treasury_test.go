@@ -0,0 +1,62 @@
+package wallet_test
+
+import (
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/planetdecred/dcrlibwallet"
+	"github.com/planetdecred/godcr/wallet"
+)
+
+var _ = Describe("Treasury spend tally", func() {
+	var (
+		wal  *wallet.Wallet
+		wall *dcrlibwallet.Wallet
+		root string
+	)
+
+	BeforeEach(func() {
+		var err error
+		root, err = os.MkdirTemp("", "godcr-treasury")
+		Expect(err).ToNot(HaveOccurred())
+		wal, err = wallet.NewWallet(root, dcrlibwallet.Testnet3, "dev", "", time.Now())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(wal.InitMultiWallet()).To(Succeed())
+
+		seed, err := dcrlibwallet.GenerateSeed()
+		Expect(err).ToNot(HaveOccurred())
+		wall, err = wal.GetMultiWallet().RestoreWallet("treasury", seed, "password", dcrlibwallet.PassphraseTypePass)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		wal.Shutdown()
+		os.RemoveAll(root)
+	})
+
+	It("waits for the voting window of the treasury spend", func() {
+		params := chaincfg.TestNet3Params()
+		window := uint32(params.TreasuryVoteInterval * params.TreasuryVoteIntervalMultiplier)
+		expiry := uint32(params.TreasuryVoteInterval)*20 + 2
+		tspend := &wallet.TreasurySpend{
+			Hash:   "0000000000000000000000000000000000000000000000000000000000000001",
+			Expiry: expiry,
+		}
+
+		tally, err := wal.TreasurySpendTally(wall.ID, tspend)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tally.Started()).To(BeFalse())
+		Expect(tally.Start).To(Equal(expiry - window - 2))
+		Expect(tally.End).To(Equal(expiry - 2))
+		Expect(tally.MaxVotes).To(Equal(int(params.TicketsPerBlock) * int(window)))
+		Expect(tally.Quorum).To(Equal(tally.MaxVotes / 5))
+
+		tspend.Expiry++
+		_, err = wal.TreasurySpendTally(wall.ID, tspend)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/planetdecred/dcrlibwallet"
@@ -25,6 +26,45 @@ type Wallet struct {
 	version     string
 	logFile     string
 	startUpTime time.Time
+
+	// mixerPassphrases are the spending passphrases used to start the
+	// account mixers on schedule, unlocked with the startup password.
+	mixerMu          sync.Mutex
+	mixerPassphrases map[int][]byte
+	// mixerRunMu keeps the schedules from being run twice at once.
+	mixerRunMu sync.Mutex
+
+	// syncEvents is the sync event history, the failed sync attempts are
+	// restarted by syncRetryTimer under the retry policy.
+	syncMu         sync.Mutex
+	syncEvents     []SyncEvent
+	lastSyncError  *SyncError
+	syncAttempts   int
+	syncRetryTimer *time.Timer
+	nextSyncRetry  time.Time
+
+	// proxyOverride is the proxy set from the command line, proxyClients
+	// are the HTTP clients of the subsystems using the proxy.
+	proxyMu                sync.Mutex
+	proxyOverride          string
+	proxyIsolationOverride bool
+	proxySession           string
+	proxyClients           map[string]*http.Client
+
+	// connectPeers are the persistent peers set from the command line,
+	// explorerURLOverride is the explorer URL template set from the
+	// command line.
+	connectPeers        []string
+	explorerURLOverride string
+
+	// profile is the name of the profile of the app directory.
+	profile string
+
+	// tallyMu keeps the treasury spend tallies from being counted twice at
+	// once.
+	tallyMu sync.Mutex
+
+	shutdownOnce sync.Once
 }
 
 // NewWallet initializies an new Wallet instance.
@@ -33,6 +73,9 @@ func NewWallet(root, net, version, logFile string, buildDate time.Time) (*Wallet
 	if root == "" || net == "" { // This should really be handled by dcrlibwallet
 		return nil, fmt.Errorf(`root directory or network cannot be ""`)
 	}
+	if NetworkParams(net) == nil {
+		return nil, fmt.Errorf("%w %q", ErrUnknownNetwork, net)
+	}
 
 	wal := &Wallet{
 		Root:        root,
@@ -64,7 +107,7 @@ func (wal *Wallet) StartupTime() time.Time {
 
 func (wal *Wallet) InitMultiWallet() error {
 	politeiaHost := dcrlibwallet.PoliteiaMainnetHost
-	if wal.Net == dcrlibwallet.Testnet3 {
+	if wal.Net != dcrlibwallet.Mainnet {
 		politeiaHost = dcrlibwallet.PoliteiaTestnetHost
 	}
 	multiWal, err := dcrlibwallet.NewMultiWallet(wal.Root, "bdb", wal.Net, politeiaHost)
@@ -73,44 +116,41 @@ func (wal *Wallet) InitMultiWallet() error {
 	}
 
 	wal.multi = multiWal
+	wal.multi.SetPeerBanFilter(wal.IsPeerBanned)
+	dcrlibwallet.SetDialer(wal.dialer)
+	wal.applyDEXProxy()
+	wal.applyConnectPeers()
 	return nil
 }
 
-func (wal *Wallet) hdPrefix() string {
-	switch wal.Net {
-	case dcrlibwallet.Testnet3:
-		return dcrlibwallet.TestnetHDPath
-	case "mainnet":
-		return dcrlibwallet.MainnetHDPath
-	default:
-		return ""
-	}
-}
-
-// Shutdown shutsdown the multiwallet
+// Shutdown shutsdown the multiwallet, once as the wallets are shut down
+// before their data is moved.
 func (wal *Wallet) Shutdown() {
-	if wal.multi != nil {
-		wal.multi.Shutdown()
-	}
+	wal.shutdownOnce.Do(func() {
+		if wal.multi != nil {
+			wal.multi.Shutdown()
+		}
+	})
 }
 
 // GetBlockExplorerURL accept transaction hash,
-// return the block explorer URL with respect to the network
+// return the URL of the transaction on the first block explorer.
+// It is empty when the block explorer is turned off in the privacy settings.
 func (wal *Wallet) GetBlockExplorerURL(txnHash string) string {
-	switch wal.Net {
-	case dcrlibwallet.Testnet3:
-		return "https://testnet.dcrdata.org/tx/" + txnHash
-	case "mainnet":
-		return "https://explorer.dcrdata.org/tx/" + txnHash
-	default:
+	explorers := wal.BlockExplorersFor(ExplorerTransaction)
+	if len(explorers) == 0 {
 		return ""
 	}
+	return explorers[0].URL(ExplorerTransaction, txnHash)
 }
 
 //GetUSDExchangeValues gets the exchange rate of DCR - USDT from a specified endpoint
 func (wal *Wallet) GetUSDExchangeValues(target interface{}) error {
+	if err := wal.CheckConnection(ProxySubsystemExchange); err != nil {
+		return err
+	}
 	url := "https://api.bittrex.com/v3/markets/DCR-USDT/ticker"
-	resp, err := http.Get(url)
+	resp, err := wal.HTTPClient(ProxySubsystemExchange).Get(url)
 	if err != nil {
 		return err
 	}